@@ -1,7 +1,20 @@
 package v1
 
+import (
+	"time"
+)
+
 const (
-	DefaultNamespace = "kubesmith"
+	DefaultNamespace         = "kubesmith"
+	DefaultForgePollInterval = time.Minute
+	DefaultForgePipelinePath = ".kubesmith.yml"
+
+	DefaultCommitStatusContext = "kubesmith"
+
+	DefaultForgeScheduleSuccessfulHistoryLimit = 3
+	DefaultForgeScheduleFailedHistoryLimit     = 1
+
+	DefaultNotificationEmailPort = 587
 )
 
 const (
@@ -10,6 +23,47 @@ const (
 	PhaseRunning   = "Running"
 	PhaseSucceeded = "Succeeded"
 	PhaseFailed    = "Failed"
+	PhaseCancelled = "Cancelled"
 )
 
 type Phase string
+
+const (
+	RetryStrategyFailedJobs  = "FailedJobs"
+	RetryStrategyFailedStage = "FailedStage"
+)
+
+type RetryStrategy string
+
+const (
+	ConcurrencyPolicyAllow   = "Allow"
+	ConcurrencyPolicyForbid  = "Forbid"
+	ConcurrencyPolicyReplace = "Replace"
+)
+
+type ConcurrencyPolicy string
+
+const (
+	CommitStatusProviderGitHub = "github"
+	CommitStatusProviderGitLab = "gitlab"
+	CommitStatusProviderGitea  = "gitea"
+)
+
+type CommitStatusProvider string
+
+const (
+	NotificationTriggerSuccess   = "success"
+	NotificationTriggerFailure   = "failure"
+	NotificationTriggerFixed     = "fixed"
+	NotificationTriggerRegressed = "regressed"
+)
+
+type NotificationTrigger string
+
+const (
+	NotificationStatePending   = "Pending"
+	NotificationStateDelivered = "Delivered"
+	NotificationStateFailed    = "Failed"
+)
+
+type NotificationState string
@@ -2,22 +2,98 @@ package v1
 
 import (
 	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
 	"time"
 
 	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/kubesmith/kubesmith/pkg/cron"
 	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // ForgeSpec defines the specification for a Kubesmith Forge.
 type ForgeSpec struct {
-	//
+	Repo           WorkspaceRepo     `json:"repo"`
+	Branches       []string          `json:"branches"`
+	Tags           []string          `json:"tags"`
+	PullRequests   ForgePullRequests `json:"pullRequests"`
+	PollInterval   metav1.Duration   `json:"pollInterval"`
+	DisablePolling bool              `json:"disablePolling"`
+	PipelinePath   string            `json:"pipelinePath"`
+	Webhook        ForgeWebhook      `json:"webhook"`
+	Schedules      []ForgeSchedule   `json:"schedules"`
+	Notifications  []Notification    `json:"notifications"`
+}
+
+// ForgePullRequests are the target branches of the pull requests the forge
+// creates pipelines for; pull requests are only seen through webhooks and the
+// ones bitbucket sends for forks are skipped. Their pipelines build the merge
+// commit with the target branch when merge is set and their jobs may only use
+// the listed secrets.
+type ForgePullRequests struct {
+	Branches []string `json:"branches"`
+	Merge    bool     `json:"merge"`
+	Secrets  []string `json:"secrets"`
+}
+
+// ForgeWebhook is the secret shared with the git server; it signs (or, for
+// gitlab, is sent along with) every webhook sent to the forge
+type ForgeWebhook struct {
+	Secret ForgeWebhookSecret `json:"secret"`
+}
+
+type ForgeWebhookSecret struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+// ForgeSchedule creates a pipeline from the pipeline file of a branch on a
+// cron schedule, much like a CronJob creates jobs. The history limits default
+// to 3 succeeded and 1 failed pipelines when they're unset; 0 keeps none.
+type ForgeSchedule struct {
+	Name                    string            `json:"name"`
+	Cron                    string            `json:"cron"`
+	TimeZone                string            `json:"timeZone"`
+	Branch                  string            `json:"branch"`
+	PipelinePath            string            `json:"pipelinePath"`
+	Environment             map[string]string `json:"environment"`
+	ConcurrencyPolicy       ConcurrencyPolicy `json:"concurrencyPolicy"`
+	Suspend                 bool              `json:"suspend"`
+	StartingDeadlineSeconds int64             `json:"startingDeadlineSeconds"`
+	SuccessfulHistoryLimit  *int              `json:"successfulHistoryLimit,omitempty"`
+	FailedHistoryLimit      *int              `json:"failedHistoryLimit,omitempty"`
 }
 
 // ForgeStatus captures the current status of a Kubesmith Forge.
 type ForgeStatus struct {
-	LastUpdatedTime metav1.Time `json:"lastUpdatedTime"`
+	LastUpdatedTime metav1.Time           `json:"lastUpdatedTime"`
+	LastPolledTime  metav1.Time           `json:"lastPolledTime"`
+	FailureReason   string                `json:"failureReason"`
+	Refs            []ForgeStatusRef      `json:"refs"`
+	Schedules       []ForgeStatusSchedule `json:"schedules"`
+}
+
+// ForgeStatusSchedule is the last time a schedule of the forge was due,
+// together with the pipeline it created then
+type ForgeStatusSchedule struct {
+	Name             string      `json:"name"`
+	LastScheduleTime metav1.Time `json:"lastScheduleTime"`
+	Pipeline         string      `json:"pipeline"`
+	Error            string      `json:"error"`
+}
+
+// ForgeStatusRef is the last commit the forge saw on a watched ref, together
+// with the pipeline it created for it
+type ForgeStatusRef struct {
+	Name     string `json:"name"`
+	Commit   string `json:"commit"`
+	Pipeline string `json:"pipeline"`
+	Error    string `json:"error"`
 }
 
 // +genclient
@@ -63,7 +139,279 @@ func (p *Forge) GetPatchFromOriginal(original Forge) (types.PatchType, []byte, e
 	return types.MergePatchType, patchBytes, nil
 }
 
+func (p *Forge) GetPollInterval() time.Duration {
+	if p.Spec.PollInterval.Duration <= 0 {
+		return DefaultForgePollInterval
+	}
+
+	return p.Spec.PollInterval.Duration
+}
+
+func (p *Forge) GetPipelinePath() string {
+	if p.Spec.PipelinePath == "" {
+		return DefaultForgePipelinePath
+	}
+
+	return p.Spec.PipelinePath
+}
+
+// IsWatchedRef returns whether the ref (e.g. refs/heads/master) matches one
+// of the branch or tag patterns of the forge
+func (p *Forge) IsWatchedRef(name string) bool {
+	patterns := []string{}
+	shortName := ""
+
+	if strings.HasPrefix(name, "refs/heads/") {
+		patterns = p.Spec.Branches
+		shortName = strings.TrimPrefix(name, "refs/heads/")
+	} else if strings.HasPrefix(name, "refs/tags/") {
+		patterns = p.Spec.Tags
+		shortName = strings.TrimPrefix(name, "refs/tags/")
+	}
+
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, shortName); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsWatchedPullRequest returns whether the target branch of a pull request
+// matches one of the pull request branch patterns of the forge
+func (p *Forge) IsWatchedPullRequest(targetBranch string) bool {
+	for _, pattern := range p.Spec.PullRequests.Branches {
+		if matched, _ := path.Match(pattern, targetBranch); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *Forge) HasWebhook() bool {
+	return p.Spec.Webhook.Secret.Name != ""
+}
+
+func (p *Forge) GetStatusSchedule(name string) *ForgeStatusSchedule {
+	for i := range p.Status.Schedules {
+		if p.Status.Schedules[i].Name == name {
+			return &p.Status.Schedules[i]
+		}
+	}
+
+	return nil
+}
+
+func (p *Forge) GetStatusRef(name string) *ForgeStatusRef {
+	for i := range p.Status.Refs {
+		if p.Status.Refs[i].Name == name {
+			return &p.Status.Refs[i]
+		}
+	}
+
+	return nil
+}
+
+// HasBeenPolled returns whether the forge recorded the refs of its repo
+// before; the commits found by the first poll don't create pipelines
+func (p *Forge) HasBeenPolled() bool {
+	return !p.Status.LastPolledTime.IsZero()
+}
+
+// GetNextPollTime returns when the repo of the forge is due to be polled
+func (p *Forge) GetNextPollTime() time.Time {
+	return p.Status.LastPolledTime.Add(p.GetPollInterval())
+}
+
+// GetPipelineForRepo returns the pipeline of the forge building the repo; the
+// pipeline is labeled with the forge and the branch, tag or pull request and
+// the pipeline of a pull request is restricted
+func (p *Forge) GetPipelineForRepo(repo WorkspaceRepo, pipeline Pipeline) Pipeline {
+	labels := map[string]string{}
+	for key, value := range pipeline.GetLabels() {
+		labels[key] = value
+	}
+
+	labels[GetLabelKey("Forge")] = p.GetName()
+	if repo.IsPullRequest() {
+		labels[GetLabelKey("PipelineBranch")] = GetLabelValue(repo.PullRequest.SourceBranch)
+		labels[GetLabelKey("PipelinePullRequest")] = strconv.Itoa(repo.PullRequest.Number)
+	} else if strings.HasPrefix(repo.Ref, "refs/tags/") {
+		labels[GetLabelKey("PipelineTag")] = GetLabelValue(strings.TrimPrefix(repo.Ref, "refs/tags/"))
+	} else {
+		labels[GetLabelKey("PipelineBranch")] = GetLabelValue(strings.TrimPrefix(repo.Ref, "refs/heads/"))
+	}
+
+	pipeline.ObjectMeta = metav1.ObjectMeta{
+		GenerateName: fmt.Sprintf("%s-", p.GetName()),
+		Namespace:    p.GetNamespace(),
+		Labels:       labels,
+		Annotations:  pipeline.GetAnnotations(),
+	}
+
+	pipeline.Spec.Workspace.Repo = repo
+	pipeline.Status = PipelineStatus{}
+
+	// the notifications of the forge replace the ones of the pipeline file
+	if len(p.Spec.Notifications) > 0 {
+		pipeline.Spec.Notifications = p.Spec.Notifications
+	}
+
+	// the pipeline file of a pull request may have been changed by anyone who
+	// can open one; its jobs only get the secrets allowed by the forge, only
+	// the notifications of the forge are sent and the storage is always the
+	// minio server of the pipeline
+	if repo.IsPullRequest() {
+		pipeline.Spec.Workspace.Storage = WorkspaceStorage{}
+		pipeline.Spec.Notifications = p.Spec.Notifications
+		pipeline.Spec.Secrets = PipelineSpecSecrets{
+			Restricted: true,
+			Allowed:    p.Spec.PullRequests.Secrets,
+		}
+	}
+
+	return pipeline
+}
+
 func (p *Forge) Validate() error {
-	// todo: finish this validation
+	if p.Spec.Repo.URL == "" {
+		return errors.New("forge repo url must be specified")
+	}
+
+	if p.Spec.Repo.SSH.Secret.Name != "" && p.Spec.Repo.SSH.Secret.Key == "" {
+		return errors.New("forge repo ssh secret key must be specified")
+	}
+
+	if err := p.Spec.Repo.ValidateCloneOptions(); err != nil {
+		return errors.Wrap(err, "forge repo")
+	}
+
+	if err := p.Spec.Repo.ValidateCommitStatus(); err != nil {
+		return errors.Wrap(err, "forge repo")
+	}
+
+	if p.HasWebhook() && p.Spec.Webhook.Secret.Key == "" {
+		return errors.New("forge webhook secret key must be specified")
+	}
+
+	if len(p.Spec.Branches) == 0 && len(p.Spec.Tags) == 0 && len(p.Spec.PullRequests.Branches) == 0 && len(p.Spec.Schedules) == 0 {
+		return errors.New("forge must watch at least 1 branch, tag or pull request branch or have a schedule")
+	}
+
+	if p.Spec.DisablePolling && !p.HasWebhook() {
+		return errors.New("forge without polling must have a webhook secret")
+	}
+
+	patterns := append(append([]string{}, p.Spec.Branches...), p.Spec.Tags...)
+	for _, pattern := range append(patterns, p.Spec.PullRequests.Branches...) {
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			return fmt.Errorf("forge branch or tag pattern %q is invalid", pattern)
+		}
+	}
+
+	if p.Spec.PollInterval.Duration < 0 {
+		return errors.New("forge poll interval must not be negative")
+	}
+
+	pipelinePath := p.GetPipelinePath()
+	if path.IsAbs(pipelinePath) || strings.HasPrefix(path.Clean(pipelinePath), "..") {
+		return fmt.Errorf("forge pipeline path %q must be relative to the repo", pipelinePath)
+	}
+
+	if err := ValidateNotifications(p.Spec.Notifications); err != nil {
+		return errors.Wrap(err, "forge")
+	}
+
+	names := map[string]bool{}
+	for _, schedule := range p.Spec.Schedules {
+		if err := schedule.Validate(); err != nil {
+			return errors.Wrapf(err, "forge schedule %q", schedule.Name)
+		}
+
+		if names[schedule.Name] {
+			return fmt.Errorf("forge schedule %q must have a unique name", schedule.Name)
+		}
+
+		names[schedule.Name] = true
+	}
+
+	return nil
+}
+
+func (s *ForgeSchedule) GetLocation() (*time.Location, error) {
+	return time.LoadLocation(s.TimeZone)
+}
+
+func (s *ForgeSchedule) GetConcurrencyPolicy() ConcurrencyPolicy {
+	if s.ConcurrencyPolicy == "" {
+		return ConcurrencyPolicyAllow
+	}
+
+	return s.ConcurrencyPolicy
+}
+
+// GetStartingDeadline returns how late a run may start; 0 means it may
+// start however late it is
+func (s *ForgeSchedule) GetStartingDeadline() time.Duration {
+	return time.Duration(s.StartingDeadlineSeconds) * time.Second
+}
+
+func (s *ForgeSchedule) GetSuccessfulHistoryLimit() int {
+	if s.SuccessfulHistoryLimit == nil {
+		return DefaultForgeScheduleSuccessfulHistoryLimit
+	}
+
+	return *s.SuccessfulHistoryLimit
+}
+
+func (s *ForgeSchedule) GetFailedHistoryLimit() int {
+	if s.FailedHistoryLimit == nil {
+		return DefaultForgeScheduleFailedHistoryLimit
+	}
+
+	return *s.FailedHistoryLimit
+}
+
+func (s *ForgeSchedule) Validate() error {
+	if s.Name == "" {
+		return errors.New("name must be specified")
+	}
+
+	if errs := validation.IsValidLabelValue(s.Name); len(errs) > 0 {
+		return fmt.Errorf("name must be a valid label value: %s", strings.Join(errs, "; "))
+	}
+
+	if _, err := cron.NewSchedule(s.Cron); err != nil {
+		return err
+	}
+
+	if _, err := s.GetLocation(); err != nil {
+		return fmt.Errorf("time zone %q is invalid", s.TimeZone)
+	}
+
+	if s.Branch == "" {
+		return errors.New("branch must be specified")
+	}
+
+	if s.PipelinePath != "" && (path.IsAbs(s.PipelinePath) || strings.HasPrefix(path.Clean(s.PipelinePath), "..")) {
+		return fmt.Errorf("pipeline path %q must be relative to the repo", s.PipelinePath)
+	}
+
+	switch s.GetConcurrencyPolicy() {
+	case ConcurrencyPolicyAllow, ConcurrencyPolicyForbid, ConcurrencyPolicyReplace:
+	default:
+		return fmt.Errorf("concurrency policy must be one of %s, %s or %s", ConcurrencyPolicyAllow, ConcurrencyPolicyForbid, ConcurrencyPolicyReplace)
+	}
+
+	if s.StartingDeadlineSeconds < 0 {
+		return errors.New("starting deadline must not be negative")
+	}
+
+	if s.GetSuccessfulHistoryLimit() < 0 || s.GetFailedHistoryLimit() < 0 {
+		return errors.New("history limits must not be negative")
+	}
+
 	return nil
 }
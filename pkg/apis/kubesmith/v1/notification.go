@@ -0,0 +1,196 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/template"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Notification is sent once a pipeline finishes with one of its triggers;
+// fixed and regressed compare the pipeline with the previous one of the
+// same repo and branch. It's sent to exactly one of an outgoing webhook, a
+// slack incoming webhook or an email address.
+type Notification struct {
+	Name     string                `json:"name"`
+	Triggers []NotificationTrigger `json:"triggers"`
+	Webhook  NotificationWebhook   `json:"webhook"`
+	Slack    NotificationSlack     `json:"slack"`
+	Email    NotificationEmail     `json:"email"`
+}
+
+// NotificationWebhook posts the body to the url; the body is a go template of
+// the notification event and defaults to the event as json
+type NotificationWebhook struct {
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+}
+
+// NotificationSlack posts to the incoming webhook url stored in the secret;
+// the text is a go template of the notification event
+type NotificationSlack struct {
+	Secret  NotificationSecret `json:"secret"`
+	Channel string             `json:"channel"`
+	Text    string             `json:"text"`
+}
+
+// NotificationEmail sends a mail through the smtp server; the connection is
+// upgraded with starttls whenever the server supports it. The subject and
+// body are go templates of the notification event.
+type NotificationEmail struct {
+	Host     string             `json:"host"`
+	Port     int                `json:"port"`
+	Username string             `json:"username"`
+	Password NotificationSecret `json:"password"`
+	From     string             `json:"from"`
+	To       []string           `json:"to"`
+	Subject  string             `json:"subject"`
+	Body     string             `json:"body"`
+}
+
+type NotificationSecret struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+// PipelineNotificationStatus is the delivery of a notification for an attempt
+// of a pipeline
+type PipelineNotificationStatus struct {
+	Name          string              `json:"name"`
+	Trigger       NotificationTrigger `json:"trigger"`
+	Attempt       int                 `json:"attempt"`
+	State         NotificationState   `json:"state"`
+	Tries         int                 `json:"tries"`
+	LastTriedTime metav1.Time         `json:"lastTriedTime"`
+	Error         string              `json:"error"`
+}
+
+// NotificationTemplateFuncs are the functions the templates of notifications
+// may use besides the builtin ones; json quotes a value for json bodies
+var NotificationTemplateFuncs = template.FuncMap{
+	"json": func(value interface{}) (string, error) {
+		data, err := json.Marshal(value)
+		return string(data), err
+	},
+}
+
+// helpers
+
+func (n *Notification) IsWebhook() bool {
+	return n.Webhook.URL != ""
+}
+
+func (n *Notification) IsSlack() bool {
+	return n.Slack.Secret.Name != ""
+}
+
+func (n *Notification) IsEmail() bool {
+	return n.Email.Host != ""
+}
+
+// GetTrigger returns the first of the triggers (ordered from the most to the
+// least specific) the notification has
+func (n *Notification) GetTrigger(triggers []NotificationTrigger) NotificationTrigger {
+	for _, trigger := range triggers {
+		for _, notificationTrigger := range n.Triggers {
+			if notificationTrigger == trigger {
+				return trigger
+			}
+		}
+	}
+
+	return ""
+}
+
+func (e *NotificationEmail) GetPort() int {
+	if e.Port == 0 {
+		return DefaultNotificationEmailPort
+	}
+
+	return e.Port
+}
+
+func (s *PipelineNotificationStatus) IsPending() bool {
+	return s.State == NotificationStatePending
+}
+
+func (n *Notification) Validate() error {
+	if n.Name == "" {
+		return errors.New("name must be specified")
+	}
+
+	if len(n.Triggers) == 0 {
+		return errors.New("must have at least 1 trigger")
+	}
+
+	for _, trigger := range n.Triggers {
+		switch trigger {
+		case NotificationTriggerSuccess, NotificationTriggerFailure, NotificationTriggerFixed, NotificationTriggerRegressed:
+		default:
+			return fmt.Errorf("trigger %q is not one of %s, %s, %s or %s", trigger, NotificationTriggerSuccess, NotificationTriggerFailure, NotificationTriggerFixed, NotificationTriggerRegressed)
+		}
+	}
+
+	kinds := 0
+	for _, isKind := range []bool{n.IsWebhook(), n.IsSlack(), n.IsEmail()} {
+		if isKind {
+			kinds++
+		}
+	}
+
+	if kinds != 1 {
+		return errors.New("must have exactly 1 of a webhook url, a slack secret or an email host")
+	}
+
+	templates := map[string]string{}
+	if n.IsWebhook() {
+		templates["webhook body"] = n.Webhook.Body
+	} else if n.IsSlack() {
+		if n.Slack.Secret.Key == "" {
+			return errors.New("slack secret key must be specified")
+		}
+
+		templates["slack text"] = n.Slack.Text
+	} else if n.IsEmail() {
+		if n.Email.From == "" || len(n.Email.To) == 0 {
+			return errors.New("email from and to must be specified")
+		}
+
+		if n.Email.Password.Name != "" && n.Email.Password.Key == "" {
+			return errors.New("email password key must be specified")
+		}
+
+		templates["email subject"] = n.Email.Subject
+		templates["email body"] = n.Email.Body
+	}
+
+	for name, text := range templates {
+		if _, err := template.New(name).Funcs(NotificationTemplateFuncs).Parse(text); err != nil {
+			return errors.Wrapf(err, "%s is not a valid template", name)
+		}
+	}
+
+	return nil
+}
+
+// ValidateNotifications validates a list of notifications of a pipeline or a
+// forge; their names must be unique
+func ValidateNotifications(notifications []Notification) error {
+	names := map[string]bool{}
+	for _, notification := range notifications {
+		if err := notification.Validate(); err != nil {
+			return errors.Wrapf(err, "notification %q", notification.Name)
+		}
+
+		if names[notification.Name] {
+			return fmt.Errorf("notification %q must have a unique name", notification.Name)
+		}
+
+		names[notification.Name] = true
+	}
+
+	return nil
+}
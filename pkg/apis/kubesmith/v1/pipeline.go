@@ -4,28 +4,77 @@ import (
 	"encoding/json"
 	"fmt"
 	"hash/fnv"
+	"path"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/ghodss/yaml"
 	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
 
 type PipelineSpec struct {
-	Workspace   PipelineWorkspace         `json:"workspace"`
-	Environment map[string]string         `json:"environment"`
-	Templates   []PipelineSpecJobTemplate `json:"templates"`
-	Stages      []string                  `json:"stages"`
-	Jobs        []PipelineSpecJob         `json:"jobs"`
+	Workspace     PipelineWorkspace         `json:"workspace"`
+	PipelinePath  string                    `json:"pipelinePath"`
+	Environment   map[string]string         `json:"environment"`
+	Templates     []PipelineSpecJobTemplate `json:"templates"`
+	Stages        []string                  `json:"stages"`
+	Finally       string                    `json:"finally"`
+	Jobs          []PipelineSpecJob         `json:"jobs"`
+	Cancel        bool                      `json:"cancel"`
+	Retry         PipelineSpecRetry         `json:"retry"`
+	Secrets       PipelineSpecSecrets       `json:"secrets"`
+	Concurrency   PipelineSpecConcurrency   `json:"concurrency"`
+	Notifications []Notification            `json:"notifications"`
+	Upstream      PipelineSpecUpstream      `json:"upstream"`
+}
+
+// PipelineSpecUpstream is the job of another pipeline that triggered the
+// pipeline; an upstream pipeline waiting on the pipeline lends it its place
+// among the running pipelines
+type PipelineSpecUpstream struct {
+	Namespace   string `json:"namespace"`
+	Pipeline    string `json:"pipeline"`
+	PipelineJob string `json:"pipelineJob"`
+	Job         string `json:"job"`
+	Wait        bool   `json:"wait"`
+}
+
+// PipelineSpecConcurrency groups pipelines of which only one runs at a time;
+// {repo} and {ref} in the group are replaced with the url and ref of the
+// workspace repo. A pipeline entering the queue cancels the older running
+// pipelines of its group with cancelInProgress and discards the older queued
+// ones with queueLatestOnly.
+type PipelineSpecConcurrency struct {
+	Group            string `json:"group"`
+	CancelInProgress bool   `json:"cancelInProgress"`
+	QueueLatestOnly  bool   `json:"queueLatestOnly"`
+}
+
+// PipelineSpecSecrets restricts the secrets the jobs of a pipeline may use to
+// the allowed ones; forges restrict the pipelines of pull requests so that a
+// pipeline file changed by a pull request can't read any other secret. The
+// jobs of restricted pipelines don't get the token of the service account
+// either, which could read the logs of the other pipelines.
+type PipelineSpecSecrets struct {
+	Restricted bool     `json:"restricted"`
+	Allowed    []string `json:"allowed"`
+}
+
+type PipelineSpecRetry struct {
+	Attempt  int           `json:"attempt"`
+	Strategy RetryStrategy `json:"strategy"`
 }
 
 type PipelineWorkspace struct {
-	Path    string           `json:"path"`
-	Repo    WorkspaceRepo    `json:"repo"`
-	Storage WorkspaceStorage `json:"storage"`
+	Path              string                     `json:"path"`
+	Repo              WorkspaceRepo              `json:"repo"`
+	Storage           WorkspaceStorage           `json:"storage"`
+	UpstreamArtifacts WorkspaceUpstreamArtifacts `json:"upstreamArtifacts"`
 }
 
 type PipelineSpecJobTemplate struct {
@@ -36,6 +85,7 @@ type PipelineSpecJobTemplate struct {
 	Args          []string             `json:"args"`
 	ConfigMapData map[string]string    `json:"configMapData"`
 	Artifacts     PipelineJobArtifacts `json:"artifacts"`
+	Secrets       []PipelineJobSecret  `json:"secrets"`
 	OnlyOn        []string             `json:"onlyOn"`
 }
 
@@ -51,16 +101,32 @@ type PipelineSpecJob struct {
 	Runner        []string             `json:"runner"`
 	AllowFailure  bool                 `json:"allowFailure"`
 	Artifacts     PipelineJobArtifacts `json:"artifacts"`
+	Secrets       []PipelineJobSecret  `json:"secrets"`
 	OnlyOn        []string             `json:"onlyOn"`
+	Trigger       PipelineJobTrigger   `json:"trigger"`
 }
 
 type PipelineStatus struct {
-	StageIndex      int         `json:"stageIndex"`
-	Phase           Phase       `json:"phase"`
-	StartTime       metav1.Time `json:"startTime"`
-	EndTime         metav1.Time `json:"endTime"`
-	LastUpdatedTime metav1.Time `json:"lastUpdatedTime"`
-	FailureReason   string      `json:"failureReason"`
+	StageIndex         int         `json:"stageIndex"`
+	Phase              Phase       `json:"phase"`
+	StartTime          metav1.Time `json:"startTime"`
+	EndTime            metav1.Time `json:"endTime"`
+	LastUpdatedTime    metav1.Time `json:"lastUpdatedTime"`
+	FailureReason      string      `json:"failureReason"`
+	TestReport         TestReport  `json:"testReport"`
+	Attempt            int         `json:"attempt"`
+	PipelineFileLoaded bool        `json:"pipelineFileLoaded"`
+
+	// FinallyPhase is the phase the pipeline ends in once its finally stage
+	// ran after an earlier stage failed or the pipeline was cancelled
+	FinallyPhase Phase `json:"finallyPhase"`
+
+	// Commit is the commit the repo was checked out at by the clone repo job
+	Commit string `json:"commit"`
+
+	// NotifiedAttempt is the last attempt the notifications were sent for
+	NotifiedAttempt int                          `json:"notifiedAttempt"`
+	Notifications   []PipelineNotificationStatus `json:"notifications"`
 }
 
 // +genclient
@@ -106,6 +172,34 @@ func (p *Pipeline) GetResourceLabels() map[string]string {
 	}
 }
 
+// GetWrappedLabels returns the labels of the resources the pipeline controller
+// creates for the pipeline
+func (p *Pipeline) GetWrappedLabels() map[string]string {
+	labels := p.GetResourceLabels()
+	labels[GetLabelKey("Controller")] = "Pipeline"
+
+	return labels
+}
+
+// GetPipelineStageName returns the name of the pipeline stage created for the
+// stage at the (1-based) index
+func (p *Pipeline) GetPipelineStageName(index int) string {
+	return fmt.Sprintf("%s-stage-%d", p.GetResourcePrefix(), index)
+}
+
+// NeedsMinioServer returns whether the storage of the pipeline is incomplete,
+// in which case the pipeline gets a minio server of its own
+func (p *Pipeline) NeedsMinioServer() bool {
+	s3 := p.Spec.Workspace.Storage.S3
+
+	return (s3.Host == "") ||
+		(s3.Port <= 0) ||
+		(s3.BucketName == "") ||
+		(s3.Credentials.Secret.Name == "") ||
+		(s3.Credentials.Secret.AccessKeyKey == "") ||
+		(s3.Credentials.Secret.SecretKeyKey == "")
+}
+
 func (p *Pipeline) GetCurrentStageName() string {
 	if p.Status.StageIndex == 0 {
 		return ""
@@ -116,6 +210,45 @@ func (p *Pipeline) GetCurrentStageName() string {
 	return p.Spec.Stages[p.Status.StageIndex-1]
 }
 
+// GetStageNameByIndex returns the name of the stage at the (1-based) index
+func (p *Pipeline) GetStageNameByIndex(index int) string {
+	if index < 1 || index > len(p.Spec.Stages) {
+		return ""
+	}
+
+	return p.Spec.Stages[index-1]
+}
+
+// HasFinallyStage returns whether the last stage of the pipeline is a finally
+// stage, which still runs after an earlier stage failed or the pipeline was
+// cancelled
+func (p *Pipeline) HasFinallyStage() bool {
+	return p.Spec.Finally != "" && len(p.Spec.Stages) > 0
+}
+
+// IsRunningFinallyStage returns whether the pipeline is running its finally
+// stage
+func (p *Pipeline) IsRunningFinallyStage() bool {
+	return p.IsRunning() && p.HasFinallyStage() && p.Status.StageIndex == len(p.Spec.Stages)
+}
+
+// CanRunFinallyStage returns whether the pipeline is running and has yet to
+// reach its finally stage
+func (p *Pipeline) CanRunFinallyStage() bool {
+	return p.IsRunning() && p.HasFinallyStage() && p.Status.StageIndex < len(p.Spec.Stages)
+}
+
+// RunFinallyStage moves the pipeline on to its finally stage, after which it
+// ends in the given phase rather than succeeding
+func (p *Pipeline) RunFinallyStage(phase Phase, reason string) {
+	p.Status.StageIndex = len(p.Spec.Stages)
+	p.Status.FinallyPhase = phase
+
+	if reason != "" {
+		p.Status.FailureReason = reason
+	}
+}
+
 func (p *Pipeline) GetWorkspacePath() string {
 	path := p.Spec.Workspace.Path
 
@@ -158,6 +291,174 @@ func (p *Pipeline) HasFailed() bool {
 	return p.Status.Phase == PhaseFailed
 }
 
+func (p *Pipeline) IsCancelled() bool {
+	return p.Status.Phase == PhaseCancelled
+}
+
+func (p *Pipeline) IsCancelRequested() bool {
+	return p.Spec.Cancel == true
+}
+
+func (p *Pipeline) HasFinished() bool {
+	return p.HasSucceeded() || p.HasFailed() || p.IsCancelled()
+}
+
+// GetAttempt returns the attempt the pipeline is on; pipelines that were never
+// retried are on their first attempt
+func (p *Pipeline) GetAttempt() int {
+	if p.Status.Attempt < 1 {
+		return 1
+	}
+
+	return p.Status.Attempt
+}
+
+func (p *Pipeline) IsRetryRequested() bool {
+	return p.Spec.Retry.Attempt > p.GetAttempt()
+}
+
+func (p *Pipeline) GetRetryStrategy() RetryStrategy {
+	if p.Spec.Retry.Strategy == "" {
+		return RetryStrategyFailedJobs
+	}
+
+	return p.Spec.Retry.Strategy
+}
+
+// ResetForRetry puts a finished pipeline back into the running phase at the
+// given stage index for the requested attempt
+func (p *Pipeline) ResetForRetry(stageIndex int) {
+	p.Spec.Cancel = false
+	p.Status.StageIndex = stageIndex
+	p.Status.Phase = PhaseRunning
+	p.Status.EndTime = metav1.Time{}
+	p.Status.FailureReason = ""
+	p.Status.FinallyPhase = PhaseEmpty
+	p.Status.Attempt = p.Spec.Retry.Attempt
+}
+
+// GetFinishedTime returns the time the pipeline finished executing, falling
+// back to the last time it was updated for pipelines without an end time
+func (p *Pipeline) GetFinishedTime() time.Time {
+	if !p.Status.EndTime.IsZero() {
+		return p.Status.EndTime.Time
+	}
+
+	return p.Status.LastUpdatedTime.Time
+}
+
+// GetCommit returns the commit the pipeline builds; pipelines of a ref or of
+// the default branch only have one once the repo was cloned
+func (p *Pipeline) GetCommit() string {
+	if p.Spec.Workspace.Repo.Commit != "" {
+		return p.Spec.Workspace.Repo.Commit
+	}
+
+	return p.Status.Commit
+}
+
+// GetSparseCheckoutPatterns returns the patterns of the files checked out of
+// the repo; the pipeline file is checked out as well while it's needed
+func (p *Pipeline) GetSparseCheckoutPatterns() []string {
+	patterns := p.Spec.Workspace.Repo.SparseCheckout
+	if len(patterns) == 0 || !p.NeedsPipelineFile() {
+		return patterns
+	}
+
+	return append(append([]string{}, patterns...), "/"+path.Clean(p.Spec.PipelinePath))
+}
+
+// GetRetentionGroup returns the key used to group pipelines of the same
+// repo and branch when applying a retention policy; the branch label of the
+// forge is used for refs that aren't a branch
+func (p *Pipeline) GetRetentionGroup() string {
+	branch := p.Spec.Workspace.Repo.GetBranch()
+	if branch == "" {
+		branch = p.GetLabels()[GetLabelKey("PipelineBranch")]
+	}
+
+	return fmt.Sprintf("%s#%s", p.Spec.Workspace.Repo.URL, branch)
+}
+
+// GetPredefinedEnvironment returns the environment variables kubesmith sets
+// for every job of the pipeline
+func (p *Pipeline) GetPredefinedEnvironment() map[string]string {
+	env := map[string]string{}
+	repo := p.Spec.Workspace.Repo
+
+	if repo.IsPullRequest() {
+		env["KUBESMITH_PULL_REQUEST"] = strconv.Itoa(repo.PullRequest.Number)
+		env["KUBESMITH_PULL_REQUEST_URL"] = repo.PullRequest.URL
+		env["KUBESMITH_PULL_REQUEST_SOURCE_BRANCH"] = repo.PullRequest.SourceBranch
+		env["KUBESMITH_PULL_REQUEST_TARGET_BRANCH"] = repo.PullRequest.TargetBranch
+	}
+
+	if p.HasUpstream() {
+		env["KUBESMITH_UPSTREAM_NAMESPACE"] = p.Spec.Upstream.Namespace
+		env["KUBESMITH_UPSTREAM_PIPELINE"] = p.Spec.Upstream.Pipeline
+		env["KUBESMITH_UPSTREAM_JOB"] = p.Spec.Upstream.Job
+	}
+
+	return env
+}
+
+// HasUpstream returns whether the pipeline was triggered by a job of another
+// pipeline
+func (p *Pipeline) HasUpstream() bool {
+	return p.Spec.Upstream.Pipeline != ""
+}
+
+// GetTemplateSpec returns the spec of a new pipeline copying the pipeline; the
+// minio server of the pipeline, its cancellation and retries and where it came
+// from are left out
+func (p *Pipeline) GetTemplateSpec() PipelineSpec {
+	spec := *p.Spec.DeepCopy()
+	spec.Cancel = false
+	spec.Retry = PipelineSpecRetry{}
+	spec.Upstream = PipelineSpecUpstream{}
+	spec.Workspace.UpstreamArtifacts = WorkspaceUpstreamArtifacts{}
+
+	if spec.Workspace.Storage.S3.Credentials.Secret.Name == fmt.Sprintf("%s-minio-server", p.GetResourcePrefix()) {
+		spec.Workspace.Storage = WorkspaceStorage{}
+	}
+
+	return spec
+}
+
+// GetConcurrencyGroup returns the concurrency group of the pipeline with its
+// placeholders replaced; pipelines without a group return an empty string
+func (p *Pipeline) GetConcurrencyGroup() string {
+	return strings.NewReplacer(
+		"{repo}", p.Spec.Workspace.Repo.URL,
+		"{ref}", p.Spec.Workspace.Repo.Ref,
+	).Replace(p.Spec.Concurrency.Group)
+}
+
+// IsNewerThan returns whether the pipeline was created after the other one;
+// pipelines created in the same second are ordered by name
+func (p *Pipeline) IsNewerThan(other Pipeline) bool {
+	if !p.CreationTimestamp.Equal(&other.CreationTimestamp) {
+		return other.CreationTimestamp.Before(&p.CreationTimestamp)
+	}
+
+	return p.GetName() > other.GetName()
+}
+
+// IsSecretAllowed returns whether the jobs of the pipeline may use the secret
+func (p *Pipeline) IsSecretAllowed(name string) bool {
+	if !p.Spec.Secrets.Restricted {
+		return true
+	}
+
+	for _, allowed := range p.Spec.Secrets.Allowed {
+		if allowed == name {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (p *Pipeline) GetTemplateByName(name string) (*PipelineSpecJobTemplate, error) {
 	name = strings.ToLower(name)
 
@@ -194,6 +495,55 @@ func (p *Pipeline) SetPhaseToFailed(reason string) {
 	p.Status.FailureReason = reason
 }
 
+// SetPhaseToFailedAtStage fails the pipeline because its current stage failed;
+// a pipeline that has yet to reach its finally stage runs it first. A failing
+// finally stage keeps the failure or cancellation it ran after.
+func (p *Pipeline) SetPhaseToFailedAtStage(reason string) {
+	if p.CanRunFinallyStage() {
+		p.RunFinallyStage(PhaseFailed, reason)
+		return
+	}
+
+	switch p.Status.FinallyPhase {
+	case PhaseCancelled:
+		p.SetPhaseToCancelled()
+	case PhaseFailed:
+		p.SetPhaseToFailed(p.Status.FailureReason)
+	default:
+		p.SetPhaseToFailed(reason)
+	}
+}
+
+// SetPhaseAfterLastStage marks the pipeline as succeeded once its last stage
+// succeeded, unless that was the finally stage running after a failure or a
+// cancellation
+func (p *Pipeline) SetPhaseAfterLastStage() {
+	switch p.Status.FinallyPhase {
+	case PhaseCancelled:
+		p.SetPhaseToCancelled()
+	case PhaseFailed:
+		p.SetPhaseToFailed(p.Status.FailureReason)
+	default:
+		p.SetPhaseToSucceeded()
+	}
+}
+
+func (p *Pipeline) SetPhaseToCancelled() {
+	p.Status.Phase = PhaseCancelled
+	p.Status.EndTime.Time = time.Now()
+
+	// pipelines cancelled by kubesmith already carry the reason why
+	if p.Status.FailureReason == "" {
+		p.Status.FailureReason = "pipeline was cancelled"
+	}
+}
+
+// Supersede requests the cancellation of the pipeline in favor of a newer one
+func (p *Pipeline) Supersede(newer string) {
+	p.Spec.Cancel = true
+	p.Status.FailureReason = fmt.Sprintf("superseded by pipeline %s", newer)
+}
+
 func (p *Pipeline) GetPatchFromOriginal(original Pipeline) (types.PatchType, []byte, error) {
 	p.Status.LastUpdatedTime.Time = time.Now()
 
@@ -226,10 +576,12 @@ func (p *Pipeline) expandJob(oldJob PipelineSpecJob) PipelineJobSpecJob {
 		Runner:        oldJob.Runner,
 		AllowFailure:  oldJob.AllowFailure,
 		Artifacts:     oldJob.Artifacts,
+		Trigger:       oldJob.Trigger,
 	}
 
 	env := map[string]string{}
 	artifacts := PipelineJobArtifacts{}
+	secrets := []PipelineJobSecret{}
 
 	for key, value := range p.Spec.Environment {
 		env[key] = value
@@ -278,6 +630,12 @@ func (p *Pipeline) expandJob(oldJob PipelineSpecJob) PipelineJobSpecJob {
 		for _, artifact := range template.Artifacts.OnFail {
 			artifacts.OnFail = append(artifacts.OnFail, artifact)
 		}
+
+		for _, report := range template.Artifacts.Reports.JUnit {
+			artifacts.Reports.JUnit = append(artifacts.Reports.JUnit, report)
+		}
+
+		secrets = append(secrets, template.Secrets...)
 	}
 
 	for key, value := range oldJob.Environment {
@@ -292,8 +650,20 @@ func (p *Pipeline) expandJob(oldJob PipelineSpecJob) PipelineJobSpecJob {
 		artifacts.OnFail = append(artifacts.OnFail, artifact)
 	}
 
+	for _, report := range oldJob.Artifacts.Reports.JUnit {
+		artifacts.Reports.JUnit = append(artifacts.Reports.JUnit, report)
+	}
+
+	secrets = append(secrets, oldJob.Secrets...)
+
+	// the predefined variables can't be overridden so jobs can rely on them
+	for key, value := range p.GetPredefinedEnvironment() {
+		env[key] = value
+	}
+
 	job.Environment = env
 	job.Artifacts = artifacts
+	job.Secrets = secrets
 
 	return job
 }
@@ -329,7 +699,7 @@ func (p *Pipeline) AdvanceCurrentStage() {
 	stageIndex := p.Status.StageIndex + 1
 
 	if stageIndex > len(p.Spec.Stages) {
-		p.SetPhaseToSucceeded()
+		p.SetPhaseAfterLastStage()
 		return
 	}
 
@@ -359,6 +729,61 @@ func (p *PipelineSpecJob) Validate() error {
 	return nil
 }
 
+// NeedsPipelineFile returns whether the templates, stages and jobs of the
+// pipeline are still to be loaded from the pipeline file of its repo
+func (p *Pipeline) NeedsPipelineFile() bool {
+	return p.Spec.PipelinePath != "" && !p.Status.PipelineFileLoaded
+}
+
+// LoadPipelineFile merges the pipeline file of the repo into the spec. The
+// file is a Pipeline like the ones that are submitted; its templates, stages,
+// finally stage and jobs replace the ones of the spec while the environment
+// of the spec overrides the one of the file.
+func (p *Pipeline) LoadPipelineFile(data []byte) error {
+	file := Pipeline{}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return errors.Wrapf(err, "could not parse pipeline file %s", p.Spec.PipelinePath)
+	}
+
+	if kind := file.Kind; kind != "" && kind != "Pipeline" {
+		return fmt.Errorf("expected a Pipeline in pipeline file %s but found a %s", p.Spec.PipelinePath, kind)
+	}
+
+	environment := map[string]string{}
+	for key, value := range file.Spec.Environment {
+		environment[key] = value
+	}
+
+	for key, value := range p.Spec.Environment {
+		environment[key] = value
+	}
+
+	if p.Spec.Workspace.Path == "" {
+		p.Spec.Workspace.Path = file.Spec.Workspace.Path
+	}
+
+	p.Spec.Environment = environment
+	p.Spec.Templates = file.Spec.Templates
+	p.Spec.Stages = file.Spec.Stages
+	p.Spec.Finally = file.Spec.Finally
+	p.Spec.Jobs = file.Spec.Jobs
+	p.Status.PipelineFileLoaded = true
+
+	return nil
+}
+
+func (p *Pipeline) ValidatePipelinePath() error {
+	if p.Spec.PipelinePath == "" {
+		return nil
+	}
+
+	if path.IsAbs(p.Spec.PipelinePath) || strings.HasPrefix(path.Clean(p.Spec.PipelinePath), "..") {
+		return fmt.Errorf("pipeline path %q must be relative to the repo", p.Spec.PipelinePath)
+	}
+
+	return nil
+}
+
 func (p *Pipeline) ValidateWorkspace() error {
 	validGitURL := regexp.MustCompile(`(?:git|ssh|https?|git@[-\w.]+):(\/\/)?(.*?)(\.git)(\/?|\#[-\d\w._]+?)$`)
 	if !validGitURL.MatchString(p.Spec.Workspace.Repo.URL) {
@@ -373,6 +798,14 @@ func (p *Pipeline) ValidateWorkspace() error {
 		return errors.New("workspace ssh secret key must be specified")
 	}
 
+	if err := p.Spec.Workspace.Repo.ValidateCloneOptions(); err != nil {
+		return errors.Wrap(err, "workspace repo")
+	}
+
+	if err := p.Spec.Workspace.Repo.ValidateCommitStatus(); err != nil {
+		return errors.Wrap(err, "workspace repo")
+	}
+
 	return nil
 }
 
@@ -381,6 +814,10 @@ func (p *Pipeline) ValidateStages() error {
 		return errors.New("pipeline must have at least 1 stage specified")
 	}
 
+	if p.Spec.Finally != "" && strings.ToLower(p.Spec.Finally) != strings.ToLower(p.Spec.Stages[len(p.Spec.Stages)-1]) {
+		return fmt.Errorf("finally stage %q must be the last of the pipeline stages", p.Spec.Finally)
+	}
+
 	for _, stage := range p.Spec.Stages {
 		stage = strings.ToLower(stage)
 		hasJobs := false
@@ -393,7 +830,7 @@ func (p *Pipeline) ValidateStages() error {
 		}
 
 		if !hasJobs {
-			return errors.New("each stage must have at least 1 job specified")
+			return fmt.Errorf("stage %q must have at least 1 job specified", stage)
 		}
 	}
 
@@ -414,7 +851,7 @@ func (p *Pipeline) ValidateJobs() error {
 		}
 
 		if !foundStage {
-			return errors.New("job stage must be specified as a valid stage")
+			return fmt.Errorf("job %q must specify one of the pipeline stages; found %q", job.Name, job.Stage)
 		}
 
 		// check that all extends exist
@@ -430,15 +867,58 @@ func (p *Pipeline) ValidateJobs() error {
 			}
 
 			if !foundExtend {
-				return errors.New("invalid job extension specified")
+				return fmt.Errorf("job %q extends unknown template %q", job.Name, extend)
 			}
 		}
 	}
 
 	// now, get the expanded jobs and validate each of them
-	for _, job := range p.GetExpandedJobs() {
+	for index, job := range p.GetExpandedJobs() {
 		if err := job.Validate(); err != nil {
-			return err
+			if job.Name == "" {
+				return errors.Wrapf(err, "job #%d", index+1)
+			}
+
+			return errors.Wrapf(err, "job %q", job.Name)
+		}
+
+		for _, secret := range job.Secrets {
+			if !p.IsSecretAllowed(secret.Secret) {
+				return fmt.Errorf("job %q may not use secret %q; the secrets of this pipeline are restricted", job.Name, secret.Secret)
+			}
+		}
+
+		if err := p.validateTriggerJob(job); err != nil {
+			return errors.Wrapf(err, "job %q", job.Name)
+		}
+	}
+
+	return nil
+}
+
+// validateTriggerJob checks that the artifacts a trigger job passes on exist
+// and can be read from the namespace of the downstream pipeline
+func (p *Pipeline) validateTriggerJob(job PipelineJobSpecJob) error {
+	if !job.IsTrigger() {
+		return nil
+	}
+
+	// the pipeline file of a pull request must not start other pipelines
+	if p.Spec.Secrets.Restricted {
+		return errors.New("may not trigger pipelines; the secrets of this pipeline are restricted")
+	}
+
+	if !job.Trigger.Artifacts {
+		return nil
+	}
+
+	if job.Trigger.GetNamespace(p.GetNamespace()) != p.GetNamespace() {
+		return errors.New("may only pass artifacts to pipelines in the same namespace")
+	}
+
+	for _, specJob := range p.Spec.Jobs {
+		if specJob.Name == job.Name && len(p.Spec.Stages) > 0 && strings.ToLower(specJob.Stage) == strings.ToLower(p.Spec.Stages[0]) {
+			return errors.New("has no artifacts to pass on in the first stage")
 		}
 	}
 
@@ -450,6 +930,19 @@ func (p *Pipeline) Validate() error {
 		return err
 	}
 
+	if err := p.ValidatePipelinePath(); err != nil {
+		return err
+	}
+
+	if err := ValidateNotifications(p.Spec.Notifications); err != nil {
+		return err
+	}
+
+	// the stages and jobs are validated once they're loaded
+	if p.NeedsPipelineFile() {
+		return nil
+	}
+
 	if err := p.ValidateStages(); err != nil {
 		return err
 	}
@@ -29,19 +29,51 @@ type PipelineJobSpecJob struct {
 	Runner        []string             `json:"runner"`
 	AllowFailure  bool                 `json:"allowFailure"`
 	Artifacts     PipelineJobArtifacts `json:"artifacts"`
+	Secrets       []PipelineJobSecret  `json:"secrets"`
+	Trigger       PipelineJobTrigger   `json:"trigger"`
 }
 
+// PipelineJobSecret exposes the key of a secret to a job as an environment
+// variable
+type PipelineJobSecret struct {
+	Name   string `json:"name"`
+	Secret string `json:"secret"`
+	Key    string `json:"key"`
+}
+
+// PipelineJobWorkspace is where the job runs; the jobs of pipelines whose
+// secrets are restricted don't get the token of the service account of the
+// pipeline, only the anvil sidecar does
 type PipelineJobWorkspace struct {
-	Path    string           `json:"path"`
-	Storage WorkspaceStorage `json:"storage"`
+	Path              string                     `json:"path"`
+	Storage           WorkspaceStorage           `json:"storage"`
+	UpstreamArtifacts WorkspaceUpstreamArtifacts `json:"upstreamArtifacts"`
+	Restricted        bool                       `json:"restricted"`
 }
 
 type PipelineJobStatus struct {
-	Phase           Phase       `json:"phase"`
-	StartTime       metav1.Time `json:"startTime"`
-	EndTime         metav1.Time `json:"endTime"`
-	LastUpdatedTime metav1.Time `json:"lastUpdatedTime"`
-	FailureReason   string      `json:"failureReason"`
+	Phase           Phase              `json:"phase"`
+	StartTime       metav1.Time        `json:"startTime"`
+	EndTime         metav1.Time        `json:"endTime"`
+	LastUpdatedTime metav1.Time        `json:"lastUpdatedTime"`
+	FailureReason   string             `json:"failureReason"`
+	Failure         PipelineJobFailure `json:"failure"`
+	TestReport      TestReport         `json:"testReport"`
+	Attempt         int                `json:"attempt"`
+
+	// Downstream is the pipeline created by a trigger job
+	Downstream PipelineJobDownstream `json:"downstream"`
+}
+
+type PipelineJobFailure struct {
+	Container        string   `json:"container"`
+	Reason           string   `json:"reason"`
+	Message          string   `json:"message"`
+	ExitCode         int32    `json:"exitCode"`
+	OOMKilled        bool     `json:"oomKilled"`
+	Evicted          bool     `json:"evicted"`
+	DeadlineExceeded bool     `json:"deadlineExceeded"`
+	LogTail          []string `json:"logTail"`
 }
 
 // +genclient
@@ -110,6 +142,18 @@ func (p *PipelineJob) GetPipelineJobName() string {
 	return strings.Replace(p.GetName(), fmt.Sprintf("%s-%s-", pipelineName, stageName), "", 1)
 }
 
+func (p *PipelineJob) GetStoragePath() string {
+	return fmt.Sprintf("%s/%s", p.GetPipelineName(), p.GetPipelineStageName())
+}
+
+func (p *PipelineJob) GetArchiveFileName() string {
+	return fmt.Sprintf("%s.tar.gz", p.GetPipelineJobName())
+}
+
+func (p *PipelineJob) GetLogFileName() string {
+	return fmt.Sprintf("%s.log", p.GetPipelineJobName())
+}
+
 func (p *PipelineJob) GetSuccessArtifactPaths() []string {
 	artifacts := []string{}
 
@@ -121,6 +165,21 @@ func (p *PipelineJob) GetSuccessArtifactPaths() []string {
 	return artifacts
 }
 
+func (p *PipelineJob) GetReportsArchiveFileName() string {
+	return fmt.Sprintf("%s-reports.tar.gz", p.GetPipelineJobName())
+}
+
+func (p *PipelineJob) GetJUnitReportPaths() []string {
+	reports := []string{}
+
+	for _, report := range p.Spec.Job.Artifacts.Reports.JUnit {
+		path := fmt.Sprintf("%s%s%s", p.Spec.Workspace.Path, string(os.PathSeparator), report)
+		reports = append(reports, path)
+	}
+
+	return reports
+}
+
 func (p *PipelineJob) GetFailArtifactPaths() []string {
 	artifacts := []string{}
 
@@ -132,6 +191,32 @@ func (p *PipelineJob) GetFailArtifactPaths() []string {
 	return artifacts
 }
 
+// GetWrappedLabels returns the labels of the resources the pipeline job
+// controller creates for the pipeline job
+func (p *PipelineJob) GetWrappedLabels() map[string]string {
+	labels := map[string]string{}
+	for key, value := range p.GetLabels() {
+		labels[key] = value
+	}
+
+	labels[GetLabelKey("Controller")] = "PipelineJob"
+	labels[GetLabelKey("PipelineJobName")] = p.GetName()
+	labels[GetLabelKey("PipelineJobNamespace")] = p.GetNamespace()
+
+	return labels
+}
+
+// UseRunnerScript replaces the command of a job with a runner with the script
+// its configmap holds the runner in
+func (p *PipelineJob) UseRunnerScript() {
+	if len(p.Spec.Job.Runner) == 0 {
+		return
+	}
+
+	p.Spec.Job.Command = []string{"/bin/sh", "-x", "/kubesmith/scripts/pipeline-script.sh"}
+	p.Spec.Job.Args = []string{}
+}
+
 func (p *PipelineJob) GetConfigMapData() map[string]string {
 	if len(p.Spec.Job.ConfigMapData) > 0 {
 		return p.Spec.Job.ConfigMapData
@@ -164,6 +249,43 @@ func (p *PipelineJob) HasFailed() bool {
 	return p.Status.Phase == PhaseFailed
 }
 
+func (p *PipelineJob) IsCancelled() bool {
+	return p.Status.Phase == PhaseCancelled
+}
+
+func (p *PipelineJob) HasFinished() bool {
+	return p.HasSucceeded() || p.HasFailed() || p.IsCancelled()
+}
+
+// GetAttempt returns the attempt the pipeline job is on; pipeline jobs that
+// were never retried are on their first attempt
+func (p *PipelineJob) GetAttempt() int {
+	if p.Status.Attempt < 1 {
+		return 1
+	}
+
+	return p.Status.Attempt
+}
+
+// GetJobName returns the name of the batch job running the current attempt of
+// the pipeline job; retries get their own batch job so the previous one can
+// never be mistaken for the retry
+func (p *PipelineJob) GetJobName() string {
+	if p.GetAttempt() == 1 {
+		return p.GetName()
+	}
+
+	return fmt.Sprintf("%s-attempt-%d", p.GetName(), p.GetAttempt())
+}
+
+// ResetForRetry clears the status of the pipeline job so that it is executed
+// again as its next attempt
+func (p *PipelineJob) ResetForRetry() {
+	p.Status = PipelineJobStatus{
+		Attempt: p.GetAttempt() + 1,
+	}
+}
+
 func (p *PipelineJob) SetPhaseToQueued() {
 	p.Status.Phase = PhaseQueued
 }
@@ -184,6 +306,42 @@ func (p *PipelineJob) SetPhaseToFailed(reason string) {
 	p.Status.FailureReason = reason
 }
 
+func (p *PipelineJob) SetPhaseToFailedWithDetails(failure PipelineJobFailure) {
+	p.Status.Failure = failure
+	p.SetPhaseToFailed(failure.GetSummary())
+}
+
+func (f *PipelineJobFailure) GetSummary() string {
+	summary := "job failed"
+
+	if f.DeadlineExceeded {
+		summary = "job exceeded its active deadline"
+	} else if f.Evicted {
+		summary = "pod was evicted"
+	} else if f.OOMKilled {
+		summary = fmt.Sprintf("container %s was OOMKilled (exit code %d)", f.Container, f.ExitCode)
+	} else if f.Container != "" {
+		summary = fmt.Sprintf("container %s exited with code %d", f.Container, f.ExitCode)
+
+		if f.Reason != "" {
+			summary = fmt.Sprintf("%s (%s)", summary, f.Reason)
+		}
+	} else if f.Reason != "" {
+		summary = fmt.Sprintf("job failed (%s)", f.Reason)
+	}
+
+	if message := strings.TrimSpace(f.Message); message != "" {
+		summary = fmt.Sprintf("%s: %s", summary, message)
+	}
+
+	return summary
+}
+
+func (p *PipelineJob) SetPhaseToCancelled() {
+	p.Status.Phase = PhaseCancelled
+	p.Status.EndTime.Time = time.Now()
+}
+
 func (p *PipelineJob) GetPatchFromOriginal(original PipelineJob) (types.PatchType, []byte, error) {
 	p.Status.LastUpdatedTime.Time = time.Now()
 
@@ -205,11 +363,23 @@ func (p *PipelineJob) GetPatchFromOriginal(original PipelineJob) (types.PatchTyp
 	return types.MergePatchType, patchBytes, nil
 }
 
+func (p *PipelineJobSpecJob) IsTrigger() bool {
+	return p.Trigger.IsSet()
+}
+
 func (p *PipelineJobSpecJob) Validate() error {
 	if p.Name == "" {
 		return errors.New("job name must not be empty")
 	}
 
+	if p.IsTrigger() {
+		if p.Image != "" || len(p.Command) > 0 || len(p.Args) > 0 || len(p.Runner) > 0 {
+			return errors.New("trigger job must not have an image, command/args or runner")
+		}
+
+		return p.Trigger.Validate()
+	}
+
 	if p.Image == "" {
 		return errors.New("job image must not be empty")
 	}
@@ -221,6 +391,12 @@ func (p *PipelineJobSpecJob) Validate() error {
 		return errors.New("job must have either command/args or runner specified; not both")
 	}
 
+	for _, secret := range p.Secrets {
+		if secret.Name == "" || secret.Secret == "" || secret.Key == "" {
+			return errors.New("job secrets must specify a name, secret and key")
+		}
+	}
+
 	return nil
 }
 
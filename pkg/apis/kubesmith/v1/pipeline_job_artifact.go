@@ -3,6 +3,11 @@ package v1
 type PipelineJobArtifactEventType string
 
 type PipelineJobArtifacts struct {
-	OnSuccess []string `json:"onSuccess"`
-	OnFail    []string `json:"onFail"`
+	OnSuccess []string                   `json:"onSuccess"`
+	OnFail    []string                   `json:"onFail"`
+	Reports   PipelineJobArtifactReports `json:"reports"`
+}
+
+type PipelineJobArtifactReports struct {
+	JUnit []string `json:"junit"`
 }
@@ -0,0 +1,61 @@
+package v1
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// PipelineJobTrigger makes a job create a downstream pipeline instead of
+// running a container. The downstream pipeline is a copy of an existing
+// pipeline or a pipeline of a branch of a forge, in the namespace of the job
+// unless another one is given; the parameters are added to its environment.
+// With artifacts, the jobs of its first stage get the artifacts the trigger
+// job was given, which only works within the namespace of the job. With wait,
+// the job succeeds or fails with the downstream pipeline.
+type PipelineJobTrigger struct {
+	Namespace  string            `json:"namespace"`
+	Pipeline   string            `json:"pipeline"`
+	Forge      string            `json:"forge"`
+	Branch     string            `json:"branch"`
+	Parameters map[string]string `json:"parameters"`
+	Artifacts  bool              `json:"artifacts"`
+	Wait       bool              `json:"wait"`
+}
+
+// PipelineJobDownstream is the pipeline created by a trigger job
+type PipelineJobDownstream struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// helpers
+
+func (t *PipelineJobTrigger) IsSet() bool {
+	return t.Pipeline != "" || t.Forge != ""
+}
+
+// GetNamespace returns the namespace the downstream pipeline is created in
+func (t *PipelineJobTrigger) GetNamespace(namespace string) string {
+	if t.Namespace == "" {
+		return namespace
+	}
+
+	return t.Namespace
+}
+
+func (t *PipelineJobTrigger) Validate() error {
+	if t.Pipeline != "" && t.Forge != "" {
+		return errors.New("trigger must have either a pipeline or a forge; not both")
+	}
+
+	if t.Forge != "" && t.Branch == "" {
+		return errors.New("trigger branch must be specified for a forge")
+	}
+
+	return nil
+}
+
+func (d *PipelineJobDownstream) String() string {
+	return fmt.Sprintf("%s/%s", d.Namespace, d.Name)
+}
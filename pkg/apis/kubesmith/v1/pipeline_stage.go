@@ -2,6 +2,9 @@ package v1
 
 import (
 	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	jsonpatch "github.com/evanphx/json-patch"
@@ -25,8 +28,10 @@ type PipelineStageStatus struct {
 }
 
 type PipelineStageWorkspace struct {
-	Path    string           `json:"path"`
-	Storage WorkspaceStorage `json:"storage"`
+	Path              string                     `json:"path"`
+	Storage           WorkspaceStorage           `json:"storage"`
+	UpstreamArtifacts WorkspaceUpstreamArtifacts `json:"upstreamArtifacts"`
+	Restricted        bool                       `json:"restricted"`
 }
 
 // +genclient
@@ -51,6 +56,39 @@ type PipelineStageList struct {
 
 // helpers
 
+// GetStageIndex returns the (1-based) index of the stage in its pipeline's
+// list of stages using the name of the stage
+func (p *PipelineStage) GetStageIndex() int {
+	name := p.GetName()
+	index, err := strconv.Atoi(name[strings.LastIndex(name, "-")+1:])
+	if err != nil {
+		return 0
+	}
+
+	return index
+}
+
+// GetWrappedLabels returns the labels of the resources the pipeline stage
+// controller creates for the pipeline stage
+func (p *PipelineStage) GetWrappedLabels() map[string]string {
+	labels := map[string]string{}
+	for key, value := range p.GetLabels() {
+		labels[key] = value
+	}
+
+	labels[GetLabelKey("Controller")] = "PipelineStage"
+	labels[GetLabelKey("PipelineStageName")] = p.GetName()
+	labels[GetLabelKey("PipelineStageNamespace")] = p.GetNamespace()
+
+	return labels
+}
+
+// GetPipelineJobName returns the name of the pipeline job created for the job
+// at the (1-based) index
+func (p *PipelineStage) GetPipelineJobName(index int) string {
+	return fmt.Sprintf("%s-job-%d", p.GetName(), index)
+}
+
 func (p *PipelineStage) HasNoPhase() bool {
 	return p.Status.Phase == PhaseEmpty
 }
@@ -67,6 +105,14 @@ func (p *PipelineStage) HasFailed() bool {
 	return p.Status.Phase == PhaseFailed
 }
 
+func (p *PipelineStage) IsCancelled() bool {
+	return p.Status.Phase == PhaseCancelled
+}
+
+func (p *PipelineStage) HasFinished() bool {
+	return p.HasSucceeded() || p.HasFailed() || p.IsCancelled()
+}
+
 func (p *PipelineStage) SetPhaseToQueued() {
 	p.Status.Phase = PhaseQueued
 }
@@ -87,6 +133,23 @@ func (p *PipelineStage) SetPhaseToFailed(reason string) {
 	p.Status.FailureReason = reason
 }
 
+// ResetForRetry puts a finished pipeline stage back into the running phase,
+// forgetting the completion of the given pipeline jobs
+func (p *PipelineStage) ResetForRetry(pipelineJobKeys []string) {
+	for _, key := range pipelineJobKeys {
+		delete(p.Status.CompletedPipelineJobs, key)
+	}
+
+	p.Status.Phase = PhaseRunning
+	p.Status.EndTime = metav1.Time{}
+	p.Status.FailureReason = ""
+}
+
+func (p *PipelineStage) SetPhaseToCancelled() {
+	p.Status.Phase = PhaseCancelled
+	p.Status.EndTime.Time = time.Now()
+}
+
 func (p *PipelineStage) GetPatchFromOriginal(original PipelineStage) (types.PatchType, []byte, error) {
 	p.Status.LastUpdatedTime.Time = time.Now()
 
@@ -2,10 +2,13 @@ package v1
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 var (
@@ -16,6 +19,8 @@ var (
 	AddToScheme = SchemeBuilder.AddToScheme
 )
 
+var invalidLabelValueCharacters = regexp.MustCompile(`[^-A-Za-z0-9_.]`)
+
 // GroupName is the group name for the Kubesmith API
 const GroupName = "kubesmith.io"
 
@@ -34,6 +39,17 @@ func GetLabelKey(key string) string {
 	return fmt.Sprintf("%s/%s", GroupName, key)
 }
 
+// GetLabelValue turns a value (like a branch name) into a valid label value;
+// invalid characters are replaced with dashes and long values are truncated
+func GetLabelValue(value string) string {
+	value = invalidLabelValueCharacters.ReplaceAllString(value, "-")
+	if len(value) > validation.LabelValueMaxLength {
+		value = value[:validation.LabelValueMaxLength]
+	}
+
+	return strings.Trim(value, "-_.")
+}
+
 type typeInfo struct {
 	PluralName   string
 	ItemType     runtime.Object
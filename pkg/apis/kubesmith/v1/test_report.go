@@ -0,0 +1,82 @@
+package v1
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// MaxTestReportFailures is the number of failing tests kept in a report
+	MaxTestReportFailures = 10
+
+	// MaxTestReportFailureMessageLength is the length failure messages are cut to
+	MaxTestReportFailureMessageLength = 200
+)
+
+type TestReport struct {
+	Total       int                 `json:"total"`
+	Passed      int                 `json:"passed"`
+	Failed      int                 `json:"failed"`
+	Skipped     int                 `json:"skipped"`
+	Duration    metav1.Duration     `json:"duration"`
+	FailedTests []TestReportFailure `json:"failedTests"`
+	Summary     string              `json:"summary"`
+}
+
+type TestReportFailure struct {
+	Name    string `json:"name"`
+	Message string `json:"message"`
+}
+
+// helpers
+
+func (r *TestReport) HasTests() bool {
+	return r.Total > 0
+}
+
+func (r *TestReport) AddFailure(name, message string) {
+	if len(r.FailedTests) >= MaxTestReportFailures {
+		return
+	}
+
+	message = strings.TrimSpace(message)
+	if len(message) > MaxTestReportFailureMessageLength {
+		message = fmt.Sprintf("%s...", message[:MaxTestReportFailureMessageLength])
+	}
+
+	r.FailedTests = append(r.FailedTests, TestReportFailure{Name: name, Message: message})
+}
+
+func (r *TestReport) Add(other TestReport) {
+	r.Total += other.Total
+	r.Passed += other.Passed
+	r.Failed += other.Failed
+	r.Skipped += other.Skipped
+	r.Duration.Duration += other.Duration.Duration
+
+	for _, failure := range other.FailedTests {
+		r.AddFailure(failure.Name, failure.Message)
+	}
+}
+
+func (r *TestReport) GetSummary() string {
+	if !r.HasTests() {
+		return ""
+	} else if r.Failed == 0 {
+		return fmt.Sprintf("%d tests passed", r.Passed)
+	}
+
+	names := []string{}
+	for _, failure := range r.FailedTests {
+		names = append(names, failure.Name)
+	}
+
+	summary := fmt.Sprintf("%d tests failed: %s", r.Failed, strings.Join(names, ", "))
+	if r.Failed > len(names) {
+		summary = fmt.Sprintf("%s, ...", summary)
+	}
+
+	return summary
+}
@@ -1,5 +1,12 @@
 package v1
 
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
 type WorkspaceStorage struct {
 	S3 WorkspaceStorageS3 `json:"s3"`
 }
@@ -22,9 +29,50 @@ type WorkspaceStorageS3CredentialsS3 struct {
 	SecretKeyKey string `json:"secretKeyKey"`
 }
 
+// WorkspaceUpstreamArtifacts is where the artifacts passed on by the job that
+// triggered a pipeline are stored
+type WorkspaceUpstreamArtifacts struct {
+	Storage WorkspaceStorage `json:"storage"`
+	Path    string           `json:"path"`
+}
+
+// WorkspaceRepo is the repo cloned for a pipeline. The commit is built when
+// it's set, otherwise the head of the ref or of the default branch is, and the
+// commit that was built is recorded in the status of the pipeline. A depth
+// makes a shallow clone, the sparse checkout patterns limit the files that are
+// checked out and lfs pulls the large files of the repo.
 type WorkspaceRepo struct {
-	URL string           `json:"url"`
-	SSH WorkspaceRepoSSH `json:"ssh"`
+	URL            string                    `json:"url"`
+	Ref            string                    `json:"ref"`
+	Commit         string                    `json:"commit"`
+	Author         string                    `json:"author"`
+	PullRequest    WorkspaceRepoPullRequest  `json:"pullRequest"`
+	SSH            WorkspaceRepoSSH          `json:"ssh"`
+	CommitStatus   WorkspaceRepoCommitStatus `json:"commitStatus"`
+	Depth          int                       `json:"depth"`
+	SparseCheckout []string                  `json:"sparseCheckout"`
+	Submodules     WorkspaceRepoSubmodules   `json:"submodules"`
+	LFS            bool                      `json:"lfs"`
+}
+
+// WorkspaceRepoPullRequest describes the pull request a pipeline was created
+// for; the number is 0 for pipelines that aren't building a pull request. The
+// commit is merged into the target branch before building when merge is set.
+type WorkspaceRepoPullRequest struct {
+	Number       int    `json:"number"`
+	Title        string `json:"title"`
+	URL          string `json:"url"`
+	SourceBranch string `json:"sourceBranch"`
+	TargetBranch string `json:"targetBranch"`
+	Merge        bool   `json:"merge"`
+}
+
+// WorkspaceRepoSubmodules checks out the submodules of the repo, and theirs
+// when recursive; they're fetched with the ssh key of the repo so private
+// submodules need ssh or relative urls
+type WorkspaceRepoSubmodules struct {
+	Enabled   bool `json:"enabled"`
+	Recursive bool `json:"recursive"`
 }
 
 type WorkspaceRepoSSH struct {
@@ -35,3 +83,90 @@ type WorkspaceRepoSSHSecret struct {
 	Name string `json:"name"`
 	Key  string `json:"key"`
 }
+
+// WorkspaceRepoCommitStatus reports the state of the pipeline and of each of
+// its jobs back to the git server as commit statuses. The provider is guessed
+// for github.com and gitlab.com, the api url defaults to the one of the
+// provider on the host of the repo url and the target url may contain
+// {namespace} and {pipeline}, which are replaced for every pipeline.
+type WorkspaceRepoCommitStatus struct {
+	Provider  CommitStatusProvider            `json:"provider"`
+	APIURL    string                          `json:"apiURL"`
+	Context   string                          `json:"context"`
+	TargetURL string                          `json:"targetURL"`
+	Secret    WorkspaceRepoCommitStatusSecret `json:"secret"`
+}
+
+// WorkspaceRepoCommitStatusSecret is the api token used to post the commit
+// statuses
+type WorkspaceRepoCommitStatusSecret struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+func (r *WorkspaceRepo) IsPullRequest() bool {
+	return r.PullRequest.Number > 0
+}
+
+// GetBranch returns the branch the ref points to; it's empty for refs that
+// aren't a branch, like tags and pull requests
+func (r *WorkspaceRepo) GetBranch() string {
+	if !strings.HasPrefix(r.Ref, "refs/heads/") {
+		return ""
+	}
+
+	return strings.TrimPrefix(r.Ref, "refs/heads/")
+}
+
+func (r *WorkspaceRepo) ReportsCommitStatus() bool {
+	return r.CommitStatus.Secret.Name != ""
+}
+
+func (r *WorkspaceRepo) GetCommitStatusContext() string {
+	if r.CommitStatus.Context == "" {
+		return DefaultCommitStatusContext
+	}
+
+	return r.CommitStatus.Context
+}
+
+// GetCloneDepth returns the depth of the history that is cloned; pull requests
+// merged into their target branch need the whole history to be merged
+func (r *WorkspaceRepo) GetCloneDepth() int {
+	if r.IsPullRequest() && r.PullRequest.Merge {
+		return 0
+	}
+
+	return r.Depth
+}
+
+func (r *WorkspaceRepo) ValidateCloneOptions() error {
+	if r.Depth < 0 {
+		return errors.New("repo depth must not be negative")
+	}
+
+	for _, pattern := range r.SparseCheckout {
+		if strings.TrimSpace(pattern) == "" {
+			return errors.New("repo sparse checkout patterns must not be empty")
+		}
+	}
+
+	return nil
+}
+
+func (r *WorkspaceRepo) ValidateCommitStatus() error {
+	if !r.ReportsCommitStatus() {
+		return nil
+	}
+
+	if r.CommitStatus.Secret.Key == "" {
+		return errors.New("commit status secret key must be specified")
+	}
+
+	switch r.CommitStatus.Provider {
+	case "", CommitStatusProviderGitHub, CommitStatusProviderGitLab, CommitStatusProviderGitea:
+		return nil
+	}
+
+	return fmt.Errorf("commit status provider %q is not one of %s, %s or %s", r.CommitStatus.Provider, CommitStatusProviderGitHub, CommitStatusProviderGitLab, CommitStatusProviderGitea)
+}
@@ -29,7 +29,7 @@ func (in *Forge) DeepCopyInto(out *Forge) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 	return
 }
@@ -85,9 +85,96 @@ func (in *ForgeList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ForgePullRequests) DeepCopyInto(out *ForgePullRequests) {
+	*out = *in
+	if in.Branches != nil {
+		in, out := &in.Branches, &out.Branches
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Secrets != nil {
+		in, out := &in.Secrets, &out.Secrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ForgePullRequests.
+func (in *ForgePullRequests) DeepCopy() *ForgePullRequests {
+	if in == nil {
+		return nil
+	}
+	out := new(ForgePullRequests)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ForgeSchedule) DeepCopyInto(out *ForgeSchedule) {
+	*out = *in
+	if in.Environment != nil {
+		in, out := &in.Environment, &out.Environment
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SuccessfulHistoryLimit != nil {
+		in, out := &in.SuccessfulHistoryLimit, &out.SuccessfulHistoryLimit
+		*out = new(int)
+		**out = **in
+	}
+	if in.FailedHistoryLimit != nil {
+		in, out := &in.FailedHistoryLimit, &out.FailedHistoryLimit
+		*out = new(int)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ForgeSchedule.
+func (in *ForgeSchedule) DeepCopy() *ForgeSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(ForgeSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ForgeSpec) DeepCopyInto(out *ForgeSpec) {
 	*out = *in
+	in.Repo.DeepCopyInto(&out.Repo)
+	if in.Branches != nil {
+		in, out := &in.Branches, &out.Branches
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.PullRequests.DeepCopyInto(&out.PullRequests)
+	out.PollInterval = in.PollInterval
+	out.Webhook = in.Webhook
+	if in.Schedules != nil {
+		in, out := &in.Schedules, &out.Schedules
+		*out = make([]ForgeSchedule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = make([]Notification, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -105,6 +192,19 @@ func (in *ForgeSpec) DeepCopy() *ForgeSpec {
 func (in *ForgeStatus) DeepCopyInto(out *ForgeStatus) {
 	*out = *in
 	in.LastUpdatedTime.DeepCopyInto(&out.LastUpdatedTime)
+	in.LastPolledTime.DeepCopyInto(&out.LastPolledTime)
+	if in.Refs != nil {
+		in, out := &in.Refs, &out.Refs
+		*out = make([]ForgeStatusRef, len(*in))
+		copy(*out, *in)
+	}
+	if in.Schedules != nil {
+		in, out := &in.Schedules, &out.Schedules
+		*out = make([]ForgeStatusSchedule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -118,6 +218,174 @@ func (in *ForgeStatus) DeepCopy() *ForgeStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ForgeStatusRef) DeepCopyInto(out *ForgeStatusRef) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ForgeStatusRef.
+func (in *ForgeStatusRef) DeepCopy() *ForgeStatusRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ForgeStatusRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ForgeStatusSchedule) DeepCopyInto(out *ForgeStatusSchedule) {
+	*out = *in
+	in.LastScheduleTime.DeepCopyInto(&out.LastScheduleTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ForgeStatusSchedule.
+func (in *ForgeStatusSchedule) DeepCopy() *ForgeStatusSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(ForgeStatusSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ForgeWebhook) DeepCopyInto(out *ForgeWebhook) {
+	*out = *in
+	out.Secret = in.Secret
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ForgeWebhook.
+func (in *ForgeWebhook) DeepCopy() *ForgeWebhook {
+	if in == nil {
+		return nil
+	}
+	out := new(ForgeWebhook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ForgeWebhookSecret) DeepCopyInto(out *ForgeWebhookSecret) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ForgeWebhookSecret.
+func (in *ForgeWebhookSecret) DeepCopy() *ForgeWebhookSecret {
+	if in == nil {
+		return nil
+	}
+	out := new(ForgeWebhookSecret)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Notification) DeepCopyInto(out *Notification) {
+	*out = *in
+	if in.Triggers != nil {
+		in, out := &in.Triggers, &out.Triggers
+		*out = make([]NotificationTrigger, len(*in))
+		copy(*out, *in)
+	}
+	in.Webhook.DeepCopyInto(&out.Webhook)
+	out.Slack = in.Slack
+	in.Email.DeepCopyInto(&out.Email)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Notification.
+func (in *Notification) DeepCopy() *Notification {
+	if in == nil {
+		return nil
+	}
+	out := new(Notification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationEmail) DeepCopyInto(out *NotificationEmail) {
+	*out = *in
+	out.Password = in.Password
+	if in.To != nil {
+		in, out := &in.To, &out.To
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationEmail.
+func (in *NotificationEmail) DeepCopy() *NotificationEmail {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationEmail)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationSecret) DeepCopyInto(out *NotificationSecret) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationSecret.
+func (in *NotificationSecret) DeepCopy() *NotificationSecret {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationSecret)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationSlack) DeepCopyInto(out *NotificationSlack) {
+	*out = *in
+	out.Secret = in.Secret
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationSlack.
+func (in *NotificationSlack) DeepCopy() *NotificationSlack {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationSlack)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationWebhook) DeepCopyInto(out *NotificationWebhook) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationWebhook.
+func (in *NotificationWebhook) DeepCopy() *NotificationWebhook {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationWebhook)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Pipeline) DeepCopyInto(out *Pipeline) {
 	*out = *in
@@ -174,6 +442,27 @@ func (in *PipelineJob) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineJobArtifactReports) DeepCopyInto(out *PipelineJobArtifactReports) {
+	*out = *in
+	if in.JUnit != nil {
+		in, out := &in.JUnit, &out.JUnit
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineJobArtifactReports.
+func (in *PipelineJobArtifactReports) DeepCopy() *PipelineJobArtifactReports {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineJobArtifactReports)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PipelineJobArtifacts) DeepCopyInto(out *PipelineJobArtifacts) {
 	*out = *in
@@ -187,6 +476,7 @@ func (in *PipelineJobArtifacts) DeepCopyInto(out *PipelineJobArtifacts) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	in.Reports.DeepCopyInto(&out.Reports)
 	return
 }
 
@@ -200,6 +490,43 @@ func (in *PipelineJobArtifacts) DeepCopy() *PipelineJobArtifacts {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineJobDownstream) DeepCopyInto(out *PipelineJobDownstream) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineJobDownstream.
+func (in *PipelineJobDownstream) DeepCopy() *PipelineJobDownstream {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineJobDownstream)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineJobFailure) DeepCopyInto(out *PipelineJobFailure) {
+	*out = *in
+	if in.LogTail != nil {
+		in, out := &in.LogTail, &out.LogTail
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineJobFailure.
+func (in *PipelineJobFailure) DeepCopy() *PipelineJobFailure {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineJobFailure)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PipelineJobList) DeepCopyInto(out *PipelineJobList) {
 	*out = *in
@@ -233,6 +560,22 @@ func (in *PipelineJobList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineJobSecret) DeepCopyInto(out *PipelineJobSecret) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineJobSecret.
+func (in *PipelineJobSecret) DeepCopy() *PipelineJobSecret {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineJobSecret)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PipelineJobSpec) DeepCopyInto(out *PipelineJobSpec) {
 	*out = *in
@@ -284,6 +627,12 @@ func (in *PipelineJobSpecJob) DeepCopyInto(out *PipelineJobSpecJob) {
 		copy(*out, *in)
 	}
 	in.Artifacts.DeepCopyInto(&out.Artifacts)
+	if in.Secrets != nil {
+		in, out := &in.Secrets, &out.Secrets
+		*out = make([]PipelineJobSecret, len(*in))
+		copy(*out, *in)
+	}
+	in.Trigger.DeepCopyInto(&out.Trigger)
 	return
 }
 
@@ -303,6 +652,9 @@ func (in *PipelineJobStatus) DeepCopyInto(out *PipelineJobStatus) {
 	in.StartTime.DeepCopyInto(&out.StartTime)
 	in.EndTime.DeepCopyInto(&out.EndTime)
 	in.LastUpdatedTime.DeepCopyInto(&out.LastUpdatedTime)
+	in.Failure.DeepCopyInto(&out.Failure)
+	in.TestReport.DeepCopyInto(&out.TestReport)
+	out.Downstream = in.Downstream
 	return
 }
 
@@ -316,10 +668,34 @@ func (in *PipelineJobStatus) DeepCopy() *PipelineJobStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineJobTrigger) DeepCopyInto(out *PipelineJobTrigger) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineJobTrigger.
+func (in *PipelineJobTrigger) DeepCopy() *PipelineJobTrigger {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineJobTrigger)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PipelineJobWorkspace) DeepCopyInto(out *PipelineJobWorkspace) {
 	*out = *in
 	out.Storage = in.Storage
+	out.UpstreamArtifacts = in.UpstreamArtifacts
 	return
 }
 
@@ -366,10 +742,27 @@ func (in *PipelineList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineNotificationStatus) DeepCopyInto(out *PipelineNotificationStatus) {
+	*out = *in
+	in.LastTriedTime.DeepCopyInto(&out.LastTriedTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineNotificationStatus.
+func (in *PipelineNotificationStatus) DeepCopy() *PipelineNotificationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineNotificationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PipelineSpec) DeepCopyInto(out *PipelineSpec) {
 	*out = *in
-	out.Workspace = in.Workspace
+	in.Workspace.DeepCopyInto(&out.Workspace)
 	if in.Environment != nil {
 		in, out := &in.Environment, &out.Environment
 		*out = make(map[string]string, len(*in))
@@ -396,6 +789,17 @@ func (in *PipelineSpec) DeepCopyInto(out *PipelineSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	out.Retry = in.Retry
+	in.Secrets.DeepCopyInto(&out.Secrets)
+	out.Concurrency = in.Concurrency
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = make([]Notification, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.Upstream = in.Upstream
 	return
 }
 
@@ -409,6 +813,22 @@ func (in *PipelineSpec) DeepCopy() *PipelineSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineSpecConcurrency) DeepCopyInto(out *PipelineSpecConcurrency) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineSpecConcurrency.
+func (in *PipelineSpecConcurrency) DeepCopy() *PipelineSpecConcurrency {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineSpecConcurrency)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PipelineSpecJob) DeepCopyInto(out *PipelineSpecJob) {
 	*out = *in
@@ -447,11 +867,17 @@ func (in *PipelineSpecJob) DeepCopyInto(out *PipelineSpecJob) {
 		copy(*out, *in)
 	}
 	in.Artifacts.DeepCopyInto(&out.Artifacts)
+	if in.Secrets != nil {
+		in, out := &in.Secrets, &out.Secrets
+		*out = make([]PipelineJobSecret, len(*in))
+		copy(*out, *in)
+	}
 	if in.OnlyOn != nil {
 		in, out := &in.OnlyOn, &out.OnlyOn
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	in.Trigger.DeepCopyInto(&out.Trigger)
 	return
 }
 
@@ -493,6 +919,11 @@ func (in *PipelineSpecJobTemplate) DeepCopyInto(out *PipelineSpecJobTemplate) {
 		}
 	}
 	in.Artifacts.DeepCopyInto(&out.Artifacts)
+	if in.Secrets != nil {
+		in, out := &in.Secrets, &out.Secrets
+		*out = make([]PipelineJobSecret, len(*in))
+		copy(*out, *in)
+	}
 	if in.OnlyOn != nil {
 		in, out := &in.OnlyOn, &out.OnlyOn
 		*out = make([]string, len(*in))
@@ -511,6 +942,59 @@ func (in *PipelineSpecJobTemplate) DeepCopy() *PipelineSpecJobTemplate {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineSpecRetry) DeepCopyInto(out *PipelineSpecRetry) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineSpecRetry.
+func (in *PipelineSpecRetry) DeepCopy() *PipelineSpecRetry {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineSpecRetry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineSpecSecrets) DeepCopyInto(out *PipelineSpecSecrets) {
+	*out = *in
+	if in.Allowed != nil {
+		in, out := &in.Allowed, &out.Allowed
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineSpecSecrets.
+func (in *PipelineSpecSecrets) DeepCopy() *PipelineSpecSecrets {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineSpecSecrets)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineSpecUpstream) DeepCopyInto(out *PipelineSpecUpstream) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineSpecUpstream.
+func (in *PipelineSpecUpstream) DeepCopy() *PipelineSpecUpstream {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineSpecUpstream)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PipelineStage) DeepCopyInto(out *PipelineStage) {
 	*out = *in
@@ -626,6 +1110,7 @@ func (in *PipelineStageStatus) DeepCopy() *PipelineStageStatus {
 func (in *PipelineStageWorkspace) DeepCopyInto(out *PipelineStageWorkspace) {
 	*out = *in
 	out.Storage = in.Storage
+	out.UpstreamArtifacts = in.UpstreamArtifacts
 	return
 }
 
@@ -645,6 +1130,14 @@ func (in *PipelineStatus) DeepCopyInto(out *PipelineStatus) {
 	in.StartTime.DeepCopyInto(&out.StartTime)
 	in.EndTime.DeepCopyInto(&out.EndTime)
 	in.LastUpdatedTime.DeepCopyInto(&out.LastUpdatedTime)
+	in.TestReport.DeepCopyInto(&out.TestReport)
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = make([]PipelineNotificationStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -661,8 +1154,9 @@ func (in *PipelineStatus) DeepCopy() *PipelineStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PipelineWorkspace) DeepCopyInto(out *PipelineWorkspace) {
 	*out = *in
-	out.Repo = in.Repo
+	in.Repo.DeepCopyInto(&out.Repo)
 	out.Storage = in.Storage
+	out.UpstreamArtifacts = in.UpstreamArtifacts
 	return
 }
 
@@ -676,10 +1170,56 @@ func (in *PipelineWorkspace) DeepCopy() *PipelineWorkspace {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TestReport) DeepCopyInto(out *TestReport) {
+	*out = *in
+	out.Duration = in.Duration
+	if in.FailedTests != nil {
+		in, out := &in.FailedTests, &out.FailedTests
+		*out = make([]TestReportFailure, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TestReport.
+func (in *TestReport) DeepCopy() *TestReport {
+	if in == nil {
+		return nil
+	}
+	out := new(TestReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TestReportFailure) DeepCopyInto(out *TestReportFailure) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TestReportFailure.
+func (in *TestReportFailure) DeepCopy() *TestReportFailure {
+	if in == nil {
+		return nil
+	}
+	out := new(TestReportFailure)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkspaceRepo) DeepCopyInto(out *WorkspaceRepo) {
 	*out = *in
+	out.PullRequest = in.PullRequest
 	out.SSH = in.SSH
+	out.CommitStatus = in.CommitStatus
+	if in.SparseCheckout != nil {
+		in, out := &in.SparseCheckout, &out.SparseCheckout
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.Submodules = in.Submodules
 	return
 }
 
@@ -693,6 +1233,55 @@ func (in *WorkspaceRepo) DeepCopy() *WorkspaceRepo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceRepoCommitStatus) DeepCopyInto(out *WorkspaceRepoCommitStatus) {
+	*out = *in
+	out.Secret = in.Secret
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceRepoCommitStatus.
+func (in *WorkspaceRepoCommitStatus) DeepCopy() *WorkspaceRepoCommitStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceRepoCommitStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceRepoCommitStatusSecret) DeepCopyInto(out *WorkspaceRepoCommitStatusSecret) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceRepoCommitStatusSecret.
+func (in *WorkspaceRepoCommitStatusSecret) DeepCopy() *WorkspaceRepoCommitStatusSecret {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceRepoCommitStatusSecret)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceRepoPullRequest) DeepCopyInto(out *WorkspaceRepoPullRequest) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceRepoPullRequest.
+func (in *WorkspaceRepoPullRequest) DeepCopy() *WorkspaceRepoPullRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceRepoPullRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkspaceRepoSSH) DeepCopyInto(out *WorkspaceRepoSSH) {
 	*out = *in
@@ -726,6 +1315,22 @@ func (in *WorkspaceRepoSSHSecret) DeepCopy() *WorkspaceRepoSSHSecret {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceRepoSubmodules) DeepCopyInto(out *WorkspaceRepoSubmodules) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceRepoSubmodules.
+func (in *WorkspaceRepoSubmodules) DeepCopy() *WorkspaceRepoSubmodules {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceRepoSubmodules)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkspaceStorage) DeepCopyInto(out *WorkspaceStorage) {
 	*out = *in
@@ -792,3 +1397,20 @@ func (in *WorkspaceStorageS3CredentialsS3) DeepCopy() *WorkspaceStorageS3Credent
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceUpstreamArtifacts) DeepCopyInto(out *WorkspaceUpstreamArtifacts) {
+	*out = *in
+	out.Storage = in.Storage
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceUpstreamArtifacts.
+func (in *WorkspaceUpstreamArtifacts) DeepCopy() *WorkspaceUpstreamArtifacts {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceUpstreamArtifacts)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -34,5 +34,5 @@ func Config(kubeconfig, kubecontext, baseName string) (*rest.Config, error) {
 
 // buildUserAgent builds a User-Agent string from given args.
 func buildUserAgent(command, version, os, arch string) string {
-	return fmt.Sprintf("%s/%s (%s/%s) %s", command, version, os, arch)
+	return fmt.Sprintf("%s/%s (%s/%s)", command, version, os, arch)
 }
@@ -1,9 +1,15 @@
 package client
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/cmd/util/env"
 	clientset "github.com/kubesmith/kubesmith/pkg/generated/clientset/versioned"
+	"github.com/kubesmith/kubesmith/pkg/logging"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
 	"k8s.io/client-go/kubernetes"
 )
@@ -20,7 +26,19 @@ type Factory interface {
 	// configuration: --kubeconfig flag, KUBECONFIG environment variable, in-cluster configuration.
 	KubeClient() (kubernetes.Interface, error)
 
+	// Logger returns the logger configured by the --log-format and --log-level flags. Every
+	// call returns the same logger.
+	Logger() (*logrus.Logger, error)
+
+	// LogConfig returns the values of the --log-format and --log-level flags so that they can
+	// be handed on to the kubesmith processes that are started on behalf of this one.
+	LogConfig() logging.Config
+
 	Namespace() string
+
+	// KubectlFlags returns the kubectl flags that select the same cluster as the --kubeconfig
+	// and --kubecontext flags; it's used by commands that shell out to kubectl.
+	KubectlFlags() []string
 }
 
 type factory struct {
@@ -29,6 +47,9 @@ type factory struct {
 	kubecontext string
 	baseName    string
 	namespace   string
+	logFormat   string
+	logLevel    string
+	logger      *logrus.Logger
 }
 
 func NewFactory(baseName string) Factory {
@@ -44,6 +65,10 @@ func NewFactory(baseName string) Factory {
 	f.flags.StringVar(&f.kubeconfig, "kubeconfig", "", "Path to the kubeconfig file to use to talk to the Kubernetes apiserver. If unset, try the environment variable KUBECONFIG, as well as in-cluster configuration")
 	f.flags.StringVarP(&f.namespace, "namespace", "n", f.namespace, "The namespace in which Kubesmith should operate")
 	f.flags.StringVar(&f.kubecontext, "kubecontext", "", "The context to use to talk to the Kubernetes apiserver. If unset defaults to whatever your current-context is (kubectl config current-context)")
+	f.flags.StringVar(&f.logFormat, "log-format", logging.FormatText, fmt.Sprintf("The format of log output; valid formats are: %s", strings.Join(logging.GetSupportedFormats(), ", ")))
+	env.BindEnvToFlag("log-format", f.flags)
+	f.flags.StringVar(&f.logLevel, "log-level", "info", "The minimum level of log output (debug, info, warning, error)")
+	env.BindEnvToFlag("log-level", f.flags)
 
 	return f
 }
@@ -78,6 +103,41 @@ func (f *factory) KubeClient() (kubernetes.Interface, error) {
 	return kubeClient, nil
 }
 
+func (f *factory) Logger() (*logrus.Logger, error) {
+	if f.logger != nil {
+		return f.logger, nil
+	}
+
+	logger, err := logging.NewLogger(f.logFormat, f.logLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	f.logger = logger
+	return f.logger, nil
+}
+
+func (f *factory) LogConfig() logging.Config {
+	return logging.Config{
+		Format: f.logFormat,
+		Level:  f.logLevel,
+	}
+}
+
 func (f *factory) Namespace() string {
 	return f.namespace
 }
+
+func (f *factory) KubectlFlags() []string {
+	flags := []string{}
+
+	if f.kubeconfig != "" {
+		flags = append(flags, "--kubeconfig", f.kubeconfig)
+	}
+
+	if f.kubecontext != "" {
+		flags = append(flags, "--context", f.kubecontext)
+	}
+
+	return flags
+}
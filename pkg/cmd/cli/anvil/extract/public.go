@@ -8,6 +8,7 @@ import (
 	"github.com/kubesmith/kubesmith/pkg/client"
 	"github.com/kubesmith/kubesmith/pkg/cmd/util/archive"
 	"github.com/kubesmith/kubesmith/pkg/cmd/util/env"
+	"github.com/kubesmith/kubesmith/pkg/logging"
 	"github.com/kubesmith/kubesmith/pkg/s3"
 	"github.com/pkg/errors"
 	uuid "github.com/satori/go.uuid"
@@ -60,8 +61,16 @@ func (o *Options) Complete(args []string, f client.Factory) error {
 		return err
 	}
 
+	logger, err := f.Logger()
+	if err != nil {
+		return err
+	}
+
 	o.S3.client = s3Client
-	o.logger = logrus.New().WithField("name", "extract")
+	o.logger = logger.WithFields(logrus.Fields{
+		logging.FieldCommand: "extract",
+		"s3Path":             o.S3.Path,
+	})
 
 	return nil
 }
@@ -2,13 +2,18 @@ package sidecar
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"strings"
 	"time"
 
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
 	"github.com/kubesmith/kubesmith/pkg/cmd/util/archive"
 	"github.com/kubesmith/kubesmith/pkg/cmd/util/artifacts"
+	"github.com/kubesmith/kubesmith/pkg/cmd/util/junit"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -54,6 +59,14 @@ func (o *Options) checkPodContainerStatuses(pod corev1.Pod) {
 	}
 
 	if allContainersHaveExited {
+		if err := o.uploadContainerLog(); err != nil {
+			o.logger.Info(err)
+		}
+
+		if err := o.processTestReports(); err != nil {
+			o.logger.Info(err)
+		}
+
 		if exitCode == 0 {
 			if err := o.processSuccessfulPod(); err != nil {
 				o.logger.Info(err)
@@ -89,6 +102,17 @@ func (o *Options) waitForContextToFinish() {
 func (o *Options) processSuccessfulPod() error {
 	o.logger.Info("processing successful pod")
 
+	// the pipeline and commit files have to be uploaded before the
+	// artifacts; the pipeline controller loads them once it finds the
+	// artifacts
+	if err := o.uploadFile("pipeline file", o.PipelineFile, o.PipelineFileName); err != nil {
+		return err
+	}
+
+	if err := o.uploadFile("commit file", o.CommitFile, o.CommitFileName); err != nil {
+		return err
+	}
+
 	// detect any artifacts that were expected to be created
 	detectedArtifacts := artifacts.DetectFromCSV(o.SuccessArtifactPaths)
 	if len(detectedArtifacts) == 0 {
@@ -137,7 +161,158 @@ func (o *Options) compressArtifactsAndUpload(artifacts []string) error {
 	return nil
 }
 
+// uploadFile uploads a file of the pod to the s3 path under the given name;
+// files that don't exist are skipped
+func (o *Options) uploadFile(description, file, fileName string) error {
+	if file == "" {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		o.logger.Infof("The %s %s does not exist; skipping...", description, file)
+		return nil
+	} else if err != nil {
+		return errors.Wrapf(err, "could not read %s %s", description, file)
+	}
+
+	filePath := o.getLocalFilePath(fileName)
+	if err := ioutil.WriteFile(filePath, data, 0644); err != nil {
+		return errors.Wrapf(err, "could not copy %s to %s", description, filePath)
+	}
+
+	o.logger.Infof("Copied %s; Uploading to S3...", description)
+	if err := o.S3.client.UploadFileToBucket(filePath, o.S3.BucketName, o.S3.Path); err != nil {
+		return errors.Wrapf(err, "Could not upload %s to S3", description)
+	}
+
+	if err := os.Remove(filePath); err != nil {
+		o.logger.Infof("Could not clean up local %s at %s ...", description, filePath)
+	}
+
+	o.logger.Infof("Successfully uploaded %s!", description)
+	return nil
+}
+
+func (o *Options) uploadContainerLog() error {
+	if o.LogContainerName == "" {
+		return nil
+	}
+
+	filePath := o.getLocalFilePath(o.LogFileName)
+	o.logger.Infof("Collecting log of container %s to %s ...", o.LogContainerName, filePath)
+
+	// open a stream to the log of the container
+	request := o.kubeClient.CoreV1().Pods(o.Pod.Namespace).GetLogs(o.Pod.Name, &corev1.PodLogOptions{
+		Container: o.LogContainerName,
+	})
+
+	stream, err := request.Stream()
+	if err != nil {
+		return errors.Wrapf(err, "could not stream log of container %s", o.LogContainerName)
+	}
+
+	defer stream.Close()
+
+	// copy the log stream to a local file
+	localFile, err := os.Create(filePath)
+	if err != nil {
+		return errors.Wrapf(err, "could not create log file at %s", filePath)
+	}
+
+	if _, err := io.Copy(localFile, stream); err != nil {
+		localFile.Close()
+		return errors.Wrap(err, "could not write container log to file")
+	}
+
+	if err := localFile.Close(); err != nil {
+		return errors.Wrap(err, "could not close log file")
+	}
+
+	// upload the log to s3
+	o.logger.Info("Collected log; Uploading to S3...")
+	if err := o.S3.client.UploadFileToBucket(filePath, o.S3.BucketName, o.S3.Path); err != nil {
+		return errors.Wrap(err, "Could not upload log to S3")
+	}
+
+	// cleanup!
+	if err := os.Remove(filePath); err != nil {
+		o.logger.Infof("Could not clean up local log at %s ...", filePath)
+	}
+
+	o.logger.Info("Successfully uploaded container log!")
+	return nil
+}
+
+func (o *Options) processTestReports() error {
+	if o.JUnitReportPaths == "" {
+		return nil
+	}
+
+	o.logger.Info("processing junit reports")
+
+	// detect any reports that were expected to be created
+	detectedReports := artifacts.DetectFromCSV(o.JUnitReportPaths)
+	if len(detectedReports) == 0 {
+		return errors.New("No junit reports were detected")
+	}
+
+	// summarize the reports so the forge can record them on the pipeline job
+	report, err := junit.ParseFiles(detectedReports)
+	if err != nil {
+		o.logger.Info(err)
+	}
+
+	if err := o.writeTestReport(report); err != nil {
+		o.logger.Info(errors.Wrap(err, "could not write test report"))
+	}
+
+	// compress the reports and then upload them
+	filePath := o.getLocalFilePath(o.ReportsArchiveName)
+	o.logger.Infof("Detected junit report(s); Compressing to %s ...", filePath)
+
+	if err := archive.CreateArchive(filePath, detectedReports); err != nil {
+		return errors.Wrapf(err, "could not create reports archive at %s", filePath)
+	}
+
+	o.logger.Info("Compressed reports; Uploading to S3...")
+	if err := o.S3.client.UploadFileToBucket(filePath, o.S3.BucketName, o.S3.Path); err != nil {
+		return errors.Wrap(err, "Could not upload reports to S3")
+	}
+
+	if err := os.Remove(filePath); err != nil {
+		o.logger.Infof("Could not clean up local reports archive at %s ...", filePath)
+	}
+
+	o.logger.Info("Successfully compressed and uploaded junit reports!")
+	return nil
+}
+
+func (o *Options) writeTestReport(report api.TestReport) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	// the termination message of a container is capped, so drop failing tests
+	// from the report until it fits
+	for len(data) > maxTestReportSize && len(report.FailedTests) > 0 {
+		report.FailedTests = report.FailedTests[:len(report.FailedTests)-1]
+
+		if data, err = json.Marshal(report); err != nil {
+			return err
+		}
+	}
+
+	o.logger.WithField("tests", report.Total).Info(report.GetSummary())
+	return ioutil.WriteFile(o.TestReportFile, data, 0644)
+}
+
 func (o *Options) getArchiveFilePath() string {
+	return o.getLocalFilePath(o.ArchiveFile.Name)
+}
+
+func (o *Options) getLocalFilePath(name string) string {
 	path := strings.TrimRight(o.ArchiveFile.Path, "/")
 	path = strings.TrimRight(path, "\\")
 
@@ -145,6 +320,6 @@ func (o *Options) getArchiveFilePath() string {
 		"%s%s%s",
 		path,
 		string(os.PathSeparator),
-		o.ArchiveFile.Name,
+		name,
 	)
 }
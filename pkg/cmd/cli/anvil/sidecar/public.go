@@ -11,6 +11,7 @@ import (
 	"github.com/kubesmith/kubesmith/pkg/client"
 	"github.com/kubesmith/kubesmith/pkg/cmd/util/archive"
 	"github.com/kubesmith/kubesmith/pkg/cmd/util/env"
+	"github.com/kubesmith/kubesmith/pkg/logging"
 	"github.com/kubesmith/kubesmith/pkg/s3"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -55,6 +56,24 @@ func (o *Options) BindFlags(flags *pflag.FlagSet) {
 	env.BindEnvToFlag("success-artifact-paths", flags)
 	flags.StringVar(&o.FailArtifactPaths, "fail-artifact-paths", "", "A comma-separated list of artifact paths that anvil will look to upload when the pod fails; please note that golang glob patterns are supported")
 	env.BindEnvToFlag("fail-artifact-paths", flags)
+	flags.StringVar(&o.LogContainerName, "log-container-name", "", "The name of the container whose log will be uploaded once all containers have exited; no log is uploaded when empty")
+	env.BindEnvToFlag("log-container-name", flags)
+	flags.StringVar(&o.LogFileName, "log-file-name", "job.log", "The name of the log file that will be uploaded to the s3 path")
+	env.BindEnvToFlag("log-file-name", flags)
+	flags.StringVar(&o.JUnitReportPaths, "junit-report-paths", "", "A comma-separated list of junit xml report paths that anvil will parse and upload once all containers have exited; please note that golang glob patterns are supported")
+	env.BindEnvToFlag("junit-report-paths", flags)
+	flags.StringVar(&o.ReportsArchiveName, "reports-archive-file-name", "reports.tar.gz", "The name of the compressed file that will be created if junit reports are found")
+	env.BindEnvToFlag("reports-archive-file-name", flags)
+	flags.StringVar(&o.TestReportFile, "test-report-file", "/dev/termination-log", "The file where the summary of the parsed junit reports will be written to")
+	env.BindEnvToFlag("test-report-file", flags)
+	flags.StringVar(&o.PipelineFile, "pipeline-file", "", "The pipeline file of the repo that anvil will upload before the artifacts when the pod succeeds; nothing is uploaded when empty or when the file does not exist")
+	env.BindEnvToFlag("pipeline-file", flags)
+	flags.StringVar(&o.PipelineFileName, "pipeline-file-name", "pipeline.yaml", "The name the pipeline file will be uploaded as to the s3 path")
+	env.BindEnvToFlag("pipeline-file-name", flags)
+	flags.StringVar(&o.CommitFile, "commit-file", "", "The file with the commit the repo was checked out at that anvil will upload before the artifacts when the pod succeeds; nothing is uploaded when empty or when the file does not exist")
+	env.BindEnvToFlag("commit-file", flags)
+	flags.StringVar(&o.CommitFileName, "commit-file-name", "commit", "The name the commit file will be uploaded as to the s3 path")
+	env.BindEnvToFlag("commit-file-name", flags)
 }
 
 func (o *Options) Validate(c *cobra.Command, args []string, f client.Factory) error {
@@ -88,6 +107,26 @@ func (o *Options) Validate(c *cobra.Command, args []string, f client.Factory) er
 		return fmt.Errorf("Invalid s3 secret key")
 	}
 
+	// make sure a log file name is specified when a log container is given
+	if o.LogContainerName != "" && o.LogFileName == "" {
+		return fmt.Errorf("invalid log file name")
+	}
+
+	// make sure a pipeline file name is specified when a pipeline file is given
+	if o.PipelineFile != "" && o.PipelineFileName == "" {
+		return fmt.Errorf("invalid pipeline file name")
+	}
+
+	// make sure a commit file name is specified when a commit file is given
+	if o.CommitFile != "" && o.CommitFileName == "" {
+		return fmt.Errorf("invalid commit file name")
+	}
+
+	// make sure a valid reports archive extension was specified
+	if o.JUnitReportPaths != "" && !archive.IsValidArchiveExtension(o.getLocalFilePath(o.ReportsArchiveName)) {
+		return archive.GetInvalidFileFormatError()
+	}
+
 	// make sure the archive file path exists
 	if _, err := os.Stat(o.ArchiveFile.Path); os.IsNotExist(err) {
 		return fmt.Errorf("Archive file path does not exist")
@@ -137,7 +176,16 @@ func (o *Options) Complete(args []string, f client.Factory) error {
 	}
 
 	// create a logger
-	o.logger = logrus.New().WithField("name", "sidecar")
+	logger, err := f.Logger()
+	if err != nil {
+		return err
+	}
+
+	o.logger = logger.WithFields(logrus.Fields{
+		logging.FieldCommand:   "sidecar",
+		logging.FieldNamespace: o.Pod.Namespace,
+		logging.FieldPod:       o.Pod.Name,
+	})
 
 	// create a context
 	o.ctx, o.cancelContext = context.WithTimeout(context.Background(), time.Second*time.Duration(o.TimeoutSeconds))
@@ -175,6 +223,11 @@ func (o *Options) Complete(args []string, f client.Factory) error {
 	// create our pod lister
 	o.podLister = o.kubeInformerFactory.Core().V1().Pods().Lister()
 
+	// identify the pipeline, stage and job of the pod in every log entry
+	if pod, err := o.podLister.Pods(o.Pod.Namespace).Get(o.Pod.Name); err == nil {
+		o.logger = o.logger.WithFields(logging.GetResourceFields(pod))
+	}
+
 	// finally, return
 	return nil
 }
@@ -11,6 +11,9 @@ import (
 	coreListersv1 "k8s.io/client-go/listers/core/v1"
 )
 
+// the kubelet truncates container termination messages beyond this size
+const maxTestReportSize = 4096
+
 type Options struct {
 	Sidecar              OptionsSidecar
 	Pod                  OptionsPod
@@ -20,6 +23,15 @@ type Options struct {
 	WatchIntervalSeconds int
 	SuccessArtifactPaths string
 	FailArtifactPaths    string
+	LogContainerName     string
+	LogFileName          string
+	JUnitReportPaths     string
+	ReportsArchiveName   string
+	TestReportFile       string
+	PipelineFile         string
+	PipelineFileName     string
+	CommitFile           string
+	CommitFileName       string
 
 	kubeClient          kubernetes.Interface
 	logger              logrus.FieldLogger
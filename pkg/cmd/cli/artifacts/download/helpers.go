@@ -0,0 +1,37 @@
+package download
+
+import (
+	"os"
+
+	"github.com/kubesmith/kubesmith/pkg/client"
+	"github.com/kubesmith/kubesmith/pkg/cmd"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(f client.Factory) *cobra.Command {
+	o := NewOptions()
+
+	c := &cobra.Command{
+		Use:   "download PIPELINE JOB",
+		Short: "Downloads the artifacts of a pipeline job",
+		Long: `Downloads the artifact archive of a pipeline job from the pipeline's workspace
+storage, optionally extracting it. Storage that is only reachable inside the
+cluster is reached through a kubectl port-forward.`,
+		Run: func(c *cobra.Command, args []string) {
+			cmd.CheckError(o.Complete(args, f))
+			cmd.CheckError(o.Validate(c, args, f))
+			cmd.CheckError(o.Run(c, f))
+		},
+	}
+
+	o.BindFlags(c.Flags())
+
+	return c
+}
+
+func NewOptions() *Options {
+	return &Options{
+		OutputDir: ".",
+		out:       os.Stdout,
+	}
+}
@@ -0,0 +1,80 @@
+package download
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/cmd/util/archive"
+	"github.com/kubesmith/kubesmith/pkg/cmd/util/storage"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func (o *Options) getPipelineJob(pipeline api.Pipeline) (*api.PipelineJob, error) {
+	selector := labels.SelectorFromSet(labels.Set{
+		api.GetLabelKey("PipelineName"):      pipeline.GetName(),
+		api.GetLabelKey("PipelineNamespace"): pipeline.GetNamespace(),
+	})
+
+	list, err := o.client.KubesmithV1().PipelineJobs(pipeline.GetNamespace()).List(metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list pipeline jobs")
+	}
+
+	for _, job := range list.Items {
+		if o.JobName == job.GetName() || strings.EqualFold(o.JobName, job.Spec.Job.Name) {
+			return &job, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no job named %s was found for pipeline %s", o.JobName, o.PipelineName)
+}
+
+func (o *Options) getArchiveKind() string {
+	if o.Reports {
+		return "test reports"
+	}
+
+	return "artifacts"
+}
+
+func (o *Options) downloadArchive(connection *storage.Connection, remotePath, localPath string) error {
+	if err := os.MkdirAll(filepath.Dir(localPath), os.ModePerm); err != nil {
+		return errors.Wrap(err, "could not create the output directory")
+	}
+
+	if err := connection.Client.DownloadFile(connection.BucketName, remotePath, localPath); err != nil {
+		return errors.Wrapf(err, "could not download %s", remotePath)
+	}
+
+	return nil
+}
+
+func (o *Options) extractArchive(connection *storage.Connection, remotePath, fileName string) error {
+	tempDir, err := ioutil.TempDir("", "kubesmith-artifacts-")
+	if err != nil {
+		return errors.Wrap(err, "could not create a temporary directory")
+	}
+
+	defer os.RemoveAll(tempDir)
+
+	// the archive keeps its name so that its format can be detected
+	archivePath := filepath.Join(tempDir, fileName)
+	if err := o.downloadArchive(connection, remotePath, archivePath); err != nil {
+		return err
+	}
+
+	if err := archive.ExtractArchive(archivePath, o.OutputDir); err != nil {
+		return errors.Wrapf(err, "could not extract %s", fileName)
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package download
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/kubesmith/kubesmith/pkg/client"
+	"github.com/kubesmith/kubesmith/pkg/cmd/util/storage"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (o *Options) BindFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&o.OutputDir, "output-dir", "d", o.OutputDir, "The directory the archive is downloaded or extracted to")
+	flags.BoolVar(&o.Extract, "extract", false, "Extracts the archive into the output directory instead of saving it")
+	flags.BoolVar(&o.Reports, "reports", false, "Downloads the job's test reports archive instead of its artifacts")
+	flags.StringVar(&o.S3.Host, "s3-host", "", "Overrides the host of the pipeline's s3 server")
+	flags.IntVar(&o.S3.Port, "s3-port", 0, "Overrides the port of the pipeline's s3 server")
+}
+
+func (o *Options) Validate(c *cobra.Command, args []string, f client.Factory) error {
+	if o.PipelineName == "" {
+		return fmt.Errorf("a pipeline name must be specified")
+	}
+
+	if o.JobName == "" {
+		return fmt.Errorf("a job name must be specified")
+	}
+
+	if o.OutputDir == "" {
+		return fmt.Errorf("an output directory must be specified")
+	}
+
+	if o.S3.Port < 0 {
+		return fmt.Errorf("invalid s3 port")
+	}
+
+	return nil
+}
+
+func (o *Options) Complete(args []string, f client.Factory) error {
+	if len(args) > 0 {
+		o.PipelineName = args[0]
+	}
+
+	if len(args) > 1 {
+		o.JobName = args[1]
+	}
+
+	client, err := f.Client()
+	if err != nil {
+		return err
+	}
+	o.client = client
+
+	kubeClient, err := f.KubeClient()
+	if err != nil {
+		return err
+	}
+	o.kubeClient = kubeClient
+	o.namespace = f.Namespace()
+	o.kubectlFlags = f.KubectlFlags()
+
+	return nil
+}
+
+func (o *Options) Run(c *cobra.Command, f client.Factory) error {
+	pipeline, err := o.client.KubesmithV1().Pipelines(o.namespace).Get(o.PipelineName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, "could not retrieve pipeline")
+	}
+
+	job, err := o.getPipelineJob(*pipeline)
+	if err != nil {
+		return err
+	}
+
+	fileName := job.GetArchiveFileName()
+	if o.Reports {
+		fileName = job.GetReportsArchiveFileName()
+	}
+
+	connection, err := storage.Connect(*pipeline, o.kubeClient, o.kubectlFlags, o.S3)
+	if err != nil {
+		return err
+	}
+
+	defer connection.Close()
+
+	remotePath := fmt.Sprintf("%s/%s", job.GetStoragePath(), fileName)
+	exists, err := connection.Client.FileExists(connection.BucketName, remotePath)
+	if err != nil {
+		return errors.Wrap(err, "could not read the pipeline's storage")
+	} else if !exists {
+		return fmt.Errorf("no %s were stored for job %s", o.getArchiveKind(), job.Spec.Job.Name)
+	}
+
+	if o.Extract {
+		if err := o.extractArchive(connection, remotePath, fileName); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(o.out, "extracted %s to %s\n", fileName, o.OutputDir)
+		return nil
+	}
+
+	localPath := filepath.Join(o.OutputDir, fileName)
+	if err := o.downloadArchive(connection, remotePath, localPath); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(o.out, "downloaded %s\n", localPath)
+	return nil
+}
@@ -0,0 +1,24 @@
+package download
+
+import (
+	"io"
+
+	"github.com/kubesmith/kubesmith/pkg/cmd/util/storage"
+	kubesmithClient "github.com/kubesmith/kubesmith/pkg/generated/clientset/versioned"
+	"k8s.io/client-go/kubernetes"
+)
+
+type Options struct {
+	PipelineName string
+	JobName      string
+	OutputDir    string
+	Extract      bool
+	Reports      bool
+	S3           storage.Overrides
+
+	namespace    string
+	kubectlFlags []string
+	out          io.Writer
+	client       kubesmithClient.Interface
+	kubeClient   kubernetes.Interface
+}
@@ -0,0 +1,23 @@
+package artifacts
+
+import (
+	"github.com/kubesmith/kubesmith/pkg/client"
+	"github.com/kubesmith/kubesmith/pkg/cmd/cli/artifacts/download"
+	"github.com/kubesmith/kubesmith/pkg/cmd/cli/artifacts/list"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(f client.Factory) *cobra.Command {
+	c := &cobra.Command{
+		Use:   "artifacts",
+		Short: "Lists and downloads the artifacts of pipeline jobs",
+		Long:  "Lists and downloads the artifacts of pipeline jobs",
+	}
+
+	c.AddCommand(
+		download.NewCommand(f),
+		list.NewCommand(f),
+	)
+
+	return c
+}
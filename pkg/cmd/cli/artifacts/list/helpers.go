@@ -0,0 +1,36 @@
+package list
+
+import (
+	"os"
+
+	"github.com/kubesmith/kubesmith/pkg/client"
+	"github.com/kubesmith/kubesmith/pkg/cmd"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(f client.Factory) *cobra.Command {
+	o := NewOptions()
+
+	c := &cobra.Command{
+		Use:   "list PIPELINE [JOB]",
+		Short: "Lists the artifacts stored for a pipeline's jobs",
+		Long: `Lists the artifact archives, test report archives and logs stored in the
+pipeline's workspace storage for each of its jobs. Storage that is only
+reachable inside the cluster is reached through a kubectl port-forward.`,
+		Run: func(c *cobra.Command, args []string) {
+			cmd.CheckError(o.Complete(args, f))
+			cmd.CheckError(o.Validate(c, args, f))
+			cmd.CheckError(o.Run(c, f))
+		},
+	}
+
+	o.BindFlags(c.Flags())
+
+	return c
+}
+
+func NewOptions() *Options {
+	return &Options{
+		out: os.Stdout,
+	}
+}
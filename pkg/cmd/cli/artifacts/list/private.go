@@ -0,0 +1,96 @@
+package list
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/s3"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func (o *Options) getPipelineJobs(pipeline api.Pipeline) ([]api.PipelineJob, error) {
+	selector := labels.SelectorFromSet(labels.Set{
+		api.GetLabelKey("PipelineName"):      pipeline.GetName(),
+		api.GetLabelKey("PipelineNamespace"): pipeline.GetNamespace(),
+	})
+
+	list, err := o.client.KubesmithV1().PipelineJobs(pipeline.GetNamespace()).List(metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list pipeline jobs")
+	}
+
+	jobs := []api.PipelineJob{}
+	for _, job := range list.Items {
+		if o.JobName == "" || o.JobName == job.GetName() || strings.EqualFold(o.JobName, job.Spec.Job.Name) {
+			jobs = append(jobs, job)
+		}
+	}
+
+	sort.SliceStable(jobs, func(i, j int) bool {
+		if jobs[i].CreationTimestamp.Equal(&jobs[j].CreationTimestamp) {
+			return jobs[i].GetName() < jobs[j].GetName()
+		}
+
+		return jobs[i].CreationTimestamp.Before(&jobs[j].CreationTimestamp)
+	})
+
+	return jobs, nil
+}
+
+func getArtifactRows(jobs []api.PipelineJob, objects []s3.Object) [][]string {
+	sizes := map[string]int64{}
+	for _, object := range objects {
+		sizes[object.Key] = object.Size
+	}
+
+	rows := [][]string{}
+	for _, job := range jobs {
+		files := []struct {
+			kind string
+			name string
+		}{
+			{"artifacts", job.GetArchiveFileName()},
+			{"reports", job.GetReportsArchiveFileName()},
+			{"log", job.GetLogFileName()},
+		}
+
+		for _, file := range files {
+			size, ok := sizes[fmt.Sprintf("%s/%s", job.GetStoragePath(), file.name)]
+			if !ok {
+				continue
+			}
+
+			rows = append(rows, []string{
+				job.GetPipelineStageName(),
+				job.Spec.Job.Name,
+				file.kind,
+				file.name,
+				getHumanSize(size),
+			})
+		}
+	}
+
+	return rows
+}
+
+func getHumanSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f%ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,92 @@
+package list
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kubesmith/kubesmith/pkg/client"
+	"github.com/kubesmith/kubesmith/pkg/cmd/util/storage"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (o *Options) BindFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&o.S3.Host, "s3-host", "", "Overrides the host of the pipeline's s3 server")
+	flags.IntVar(&o.S3.Port, "s3-port", 0, "Overrides the port of the pipeline's s3 server")
+}
+
+func (o *Options) Validate(c *cobra.Command, args []string, f client.Factory) error {
+	if o.PipelineName == "" {
+		return fmt.Errorf("a pipeline name must be specified")
+	}
+
+	if o.S3.Port < 0 {
+		return fmt.Errorf("invalid s3 port")
+	}
+
+	return nil
+}
+
+func (o *Options) Complete(args []string, f client.Factory) error {
+	if len(args) > 0 {
+		o.PipelineName = args[0]
+	}
+
+	if len(args) > 1 {
+		o.JobName = args[1]
+	}
+
+	client, err := f.Client()
+	if err != nil {
+		return err
+	}
+	o.client = client
+
+	kubeClient, err := f.KubeClient()
+	if err != nil {
+		return err
+	}
+	o.kubeClient = kubeClient
+	o.namespace = f.Namespace()
+	o.kubectlFlags = f.KubectlFlags()
+
+	return nil
+}
+
+func (o *Options) Run(c *cobra.Command, f client.Factory) error {
+	pipeline, err := o.client.KubesmithV1().Pipelines(o.namespace).Get(o.PipelineName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, "could not retrieve pipeline")
+	}
+
+	jobs, err := o.getPipelineJobs(*pipeline)
+	if err != nil {
+		return err
+	} else if len(jobs) == 0 {
+		return fmt.Errorf("no pipeline jobs were found for pipeline %s", o.PipelineName)
+	}
+
+	connection, err := storage.Connect(*pipeline, o.kubeClient, o.kubectlFlags, o.S3)
+	if err != nil {
+		return err
+	}
+
+	defer connection.Close()
+
+	objects, err := connection.Client.GetObjectsFromPath(connection.BucketName, pipeline.GetResourcePrefix())
+	if err != nil {
+		return errors.Wrap(err, "could not list the pipeline's storage")
+	}
+
+	writer := tabwriter.NewWriter(o.out, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(writer, strings.Join([]string{"STAGE", "JOB", "TYPE", "FILE", "SIZE"}, "\t"))
+
+	for _, row := range getArtifactRows(jobs, objects) {
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+
+	return writer.Flush()
+}
@@ -9,16 +9,16 @@ import (
 	"strings"
 	"syscall"
 
-	"github.com/golang/glog"
 	"github.com/kubesmith/kubesmith/pkg/client"
 	"github.com/kubesmith/kubesmith/pkg/cmd"
+	"github.com/kubesmith/kubesmith/pkg/commitstatus"
 	"github.com/kubesmith/kubesmith/pkg/controllers/forge"
 	"github.com/kubesmith/kubesmith/pkg/controllers/job"
 	"github.com/kubesmith/kubesmith/pkg/controllers/pipeline"
 	pipelinejob "github.com/kubesmith/kubesmith/pkg/controllers/pipeline-job"
 	pipelinestage "github.com/kubesmith/kubesmith/pkg/controllers/pipeline-stage"
 	kubesmithInformers "github.com/kubesmith/kubesmith/pkg/generated/informers/externalversions"
-	"github.com/sirupsen/logrus"
+	"github.com/kubesmith/kubesmith/pkg/notification"
 	"github.com/spf13/cobra"
 	kubeInformers "k8s.io/client-go/informers"
 )
@@ -51,12 +51,12 @@ func NewServer(o *Options) *Server {
 
 	go func() {
 		sig := <-sigs
-		glog.Infof("Received signal %s, shutting down", sig)
+		o.logger.Infof("Received signal %s, shutting down", sig)
 		cancelContext()
 	}()
 
-	// setup a logger for this pipeline controller
-	logger := logrus.New()
+	// every controller shares the logger configured by the command line flags
+	logger := o.logger
 
 	// setup our informers
 	kubesmithInformerFactory := kubesmithInformers.NewSharedInformerFactoryWithOptions(
@@ -71,21 +71,45 @@ func NewServer(o *Options) *Server {
 		kubeInformers.WithNamespace(o.Namespace),
 	)
 
+	// the pipeline, stage and job controllers share the reporter posting the
+	// commit statuses of their pipelines
+	commitStatusReporter := commitstatus.NewReporter(logger, kubeInformerFactory.Core().V1().Secrets())
+
+	// the pipeline controller hands its finished pipelines to the notifier
+	notifier := notification.NewNotifier(
+		logger,
+		o.client.KubesmithV1(),
+		kubesmithInformerFactory.Kubesmith().V1().Pipelines(),
+		kubeInformerFactory.Core().V1().Secrets(),
+	)
+
 	// setup our controllers
 	forgeController := forge.NewForgeController(
 		logger,
 		o.kubeClient,
 		o.client.KubesmithV1(),
 		kubesmithInformerFactory.Kubesmith().V1().Forges(),
+		kubesmithInformerFactory.Kubesmith().V1().Pipelines(),
+		kubeInformerFactory.Core().V1().Secrets(),
 	)
 
 	pipelineController := pipeline.NewPipelineController(
 		o.MaxRunningPipelines,
+		pipeline.RetentionPolicy{
+			KeepLast:     o.RetentionKeepLast,
+			TTL:          o.RetentionTTL,
+			SucceededTTL: o.RetentionSucceededTTL,
+			FailedTTL:    o.RetentionFailedTTL,
+		},
 		logger,
+		o.logConfig,
 		o.kubeClient,
 		o.client.KubesmithV1(),
+		commitStatusReporter,
+		notifier,
 		kubesmithInformerFactory.Kubesmith().V1().Pipelines(),
 		kubesmithInformerFactory.Kubesmith().V1().PipelineStages(),
+		kubesmithInformerFactory.Kubesmith().V1().PipelineJobs(),
 		kubeInformerFactory.Core().V1().Secrets(),
 		kubeInformerFactory.Apps().V1().Deployments(),
 		kubeInformerFactory.Core().V1().Services(),
@@ -99,6 +123,7 @@ func NewServer(o *Options) *Server {
 		logger,
 		o.kubeClient,
 		o.client.KubesmithV1(),
+		commitStatusReporter,
 		kubesmithInformerFactory.Kubesmith().V1().Pipelines(),
 		kubesmithInformerFactory.Kubesmith().V1().PipelineStages(),
 		kubesmithInformerFactory.Kubesmith().V1().PipelineJobs(),
@@ -107,8 +132,11 @@ func NewServer(o *Options) *Server {
 	pipelineJobController := pipelinejob.NewPipelineJobController(
 		o.MaxRunningPipelineJobs,
 		logger,
+		o.logConfig,
 		o.kubeClient,
 		o.client.KubesmithV1(),
+		commitStatusReporter,
+		kubesmithInformerFactory.Kubesmith().V1().Pipelines(),
 		kubesmithInformerFactory.Kubesmith().V1().PipelineJobs(),
 		kubesmithInformerFactory.Kubesmith().V1().PipelineStages(),
 		kubeInformerFactory.Core().V1().ConfigMaps(),
@@ -120,9 +148,19 @@ func NewServer(o *Options) *Server {
 		o.kubeClient,
 		o.client.KubesmithV1(),
 		kubeInformerFactory.Batch().V1().Jobs(),
+		kubeInformerFactory.Core().V1().Pods(),
+		kubesmithInformerFactory.Kubesmith().V1().Pipelines(),
 		kubesmithInformerFactory.Kubesmith().V1().PipelineJobs(),
 	)
 
+	webhookHandler := forge.NewWebhookHandler(
+		o.Namespace,
+		logger,
+		forgeController,
+		kubesmithInformerFactory.Kubesmith().V1().Forges(),
+		kubeInformerFactory.Core().V1().Secrets(),
+	)
+
 	// finally, return the server
 	return &Server{
 		options:    o,
@@ -141,6 +179,9 @@ func NewServer(o *Options) *Server {
 		pipelineStageController: pipelineStageController,
 		pipelineJobController:   pipelineJobController,
 		jobController:           jobController,
+		commitStatusReporter:    commitStatusReporter,
+		notifier:                notifier,
+		webhookHandler:          webhookHandler,
 	}
 }
 
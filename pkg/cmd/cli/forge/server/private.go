@@ -1,18 +1,23 @@
 package server
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
 	"sync"
+	"time"
 
-	"github.com/golang/glog"
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
 	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/tools/cache"
 )
 
 func (s *Server) runControllers() error {
 	var wg sync.WaitGroup
 
-	// start the forge controller
+	// start the forge controller; polls and webhook events are processed by
+	// a single worker so they never race on the refs of a forge
 	wg.Add(1)
 	go func() {
 		s.forgeController.Run(s.ctx, 1)
@@ -47,19 +52,31 @@ func (s *Server) runControllers() error {
 		wg.Done()
 	}()
 
+	// start the commit status reporter
+	wg.Add(1)
+	go func() {
+		s.commitStatusReporter.Run(s.ctx, 1)
+		wg.Done()
+	}()
+
+	// start the notifier
+	wg.Add(1)
+	go func() {
+		s.notifier.Run(s.ctx, 1)
+		wg.Done()
+	}()
+
 	// start the shared informers after all of our controllers
 	go s.kubesmithInformerFactory.Start(s.ctx.Done())
 	go s.kubeInformerFactory.Start(s.ctx.Done())
 
-	// setup the cache sync waiter
-	cache.WaitForCacheSync(
-		s.ctx.Done(),
-		s.kubesmithInformerFactory.Kubesmith().V1().Pipelines().Informer().HasSynced,
-	)
+	// wait for all of the informer caches to sync
+	s.kubesmithInformerFactory.WaitForCacheSync(s.ctx.Done())
+	s.kubeInformerFactory.WaitForCacheSync(s.ctx.Done())
 
 	<-s.ctx.Done()
 
-	glog.Info("Waiting for all controllers to shut down gracefully")
+	s.logger.Info("Waiting for all controllers to shut down gracefully")
 	wg.Wait()
 
 	return nil
@@ -70,6 +87,12 @@ func (s *Server) run() error {
 		return err
 	}
 
+	go s.runHTTPServer()
+
+	if s.options.EnableProfiling {
+		go s.runProfilingServer()
+	}
+
 	if err := s.runControllers(); err != nil {
 		return err
 	}
@@ -78,12 +101,126 @@ func (s *Server) run() error {
 }
 
 func (s *Server) namespaceExists(namespace string) error {
-	glog.V(1).Infof("Checking existence of %s", namespace)
+	s.logger.Debugf("Checking existence of %s", namespace)
 
 	if _, err := s.kubeClient.CoreV1().Namespaces().Get(namespace, metav1.GetOptions{}); err != nil {
 		return errors.WithStack(err)
 	}
 
-	glog.V(1).Info("Namespace exists")
+	s.logger.Debug("Namespace exists")
 	return nil
 }
+
+func (s *Server) getControllers() []serverController {
+	return []serverController{
+		{name: "forge", controller: s.forgeController},
+		{name: "pipeline", controller: s.pipelineController},
+		{name: "pipeline stage", controller: s.pipelineStageController},
+		{name: "pipeline job", controller: s.pipelineJobController},
+		{name: "job", controller: s.jobController},
+		{name: "commit status", controller: s.commitStatusReporter},
+		{name: "notification", controller: s.notifier},
+	}
+}
+
+func (s *Server) runHTTPServer() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.HandleFunc("/readyz", s.handleReadyz)
+	mux.Handle("/webhooks/", s.webhookHandler)
+
+	s.logger.Infof("Serving health and webhook endpoints on %s", s.options.HTTPAddress)
+	s.serveHTTP(s.options.HTTPAddress, mux)
+}
+
+// runProfilingServer serves the pprof endpoints on their own listener so that
+// they aren't exposed along with the webhooks
+func (s *Server) runProfilingServer() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	s.logger.Infof("Serving profiling endpoints on %s", s.options.ProfilingAddress)
+	s.serveHTTP(s.options.ProfilingAddress, mux)
+}
+
+func (s *Server) serveHTTP(address string, handler http.Handler) {
+	server := &http.Server{
+		Addr:    address,
+		Handler: handler,
+	}
+
+	// shutdown the http server once the context is done
+	go func() {
+		<-s.ctx.Done()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+
+		server.Shutdown(ctx)
+	}()
+
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		s.logger.Error(errors.Wrapf(err, "http server on %s stopped unexpectedly", address))
+		s.cancelContext()
+	}
+}
+
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	for _, c := range s.getControllers() {
+		if err := c.controller.Healthy(); err != nil {
+			writeHealthResponse(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+	}
+
+	writeHealthResponse(w, http.StatusOK, "ok")
+}
+
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if err := s.namespaceExists(s.namespace); err != nil {
+		writeHealthResponse(w, http.StatusServiceUnavailable, fmt.Sprintf("namespace %s does not exist", s.namespace))
+		return
+	}
+
+	if err := s.customResourcesInstalled(); err != nil {
+		writeHealthResponse(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+
+	if _, err := s.client.KubesmithV1().Pipelines(s.namespace).List(metav1.ListOptions{Limit: 1}); err != nil {
+		writeHealthResponse(w, http.StatusServiceUnavailable, fmt.Sprintf("cannot list pipelines: %s", err))
+		return
+	}
+
+	writeHealthResponse(w, http.StatusOK, "ok")
+}
+
+func (s *Server) customResourcesInstalled() error {
+	resources, err := s.kubeClient.Discovery().ServerResourcesForGroupVersion(api.SchemeGroupVersion.String())
+	if err != nil {
+		return errors.Wrap(err, "could not discover kubesmith resources")
+	}
+
+	installed := map[string]bool{}
+	for _, resource := range resources.APIResources {
+		installed[resource.Name] = true
+	}
+
+	for kind, info := range api.CustomResources() {
+		if !installed[info.PluralName] {
+			return fmt.Errorf("custom resource %s is not installed", kind)
+		}
+	}
+
+	return nil
+}
+
+func writeHealthResponse(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprintln(w, message)
+}
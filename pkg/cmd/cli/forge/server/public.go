@@ -16,6 +16,20 @@ func (o *Options) BindFlags(flags *pflag.FlagSet) {
 	env.BindEnvToFlag("max-running-pipelines", flags)
 	flags.IntVar(&o.MaxRunningPipelineJobs, "max-running-pipeline-jobs", 3, "The maximum number of pipelines that can run in the namespace at any given time")
 	env.BindEnvToFlag("max-running-pipeline-jobs", flags)
+	flags.StringVar(&o.HTTPAddress, "http-address", ":8080", "The address where the health (/healthz), readiness (/readyz), and webhook (/webhooks/<forge>) endpoints are served")
+	env.BindEnvToFlag("http-address", flags)
+	flags.BoolVar(&o.EnableProfiling, "enable-profiling", false, "Indicates whether the pprof endpoints (/debug/pprof) are served")
+	env.BindEnvToFlag("enable-profiling", flags)
+	flags.StringVar(&o.ProfilingAddress, "profiling-address", "localhost:6060", "The address where the pprof endpoints are served when profiling is enabled")
+	env.BindEnvToFlag("profiling-address", flags)
+	flags.IntVar(&o.RetentionKeepLast, "retention-keep-last", 0, "The number of finished pipelines to keep per repo/branch; 0 keeps all of them")
+	env.BindEnvToFlag("retention-keep-last", flags)
+	flags.DurationVar(&o.RetentionTTL, "retention-ttl", 0, "How long finished pipelines are kept after completion; 0 keeps them forever")
+	env.BindEnvToFlag("retention-ttl", flags)
+	flags.DurationVar(&o.RetentionSucceededTTL, "retention-succeeded-ttl", 0, "How long successful pipelines are kept after completion; overrides --retention-ttl")
+	env.BindEnvToFlag("retention-succeeded-ttl", flags)
+	flags.DurationVar(&o.RetentionFailedTTL, "retention-failed-ttl", 0, "How long failed pipelines are kept after completion; overrides --retention-ttl")
+	env.BindEnvToFlag("retention-failed-ttl", flags)
 }
 
 func (o *Options) Validate(c *cobra.Command, args []string, f client.Factory) error {
@@ -27,10 +41,33 @@ func (o *Options) Validate(c *cobra.Command, args []string, f client.Factory) er
 		return errors.New("The maximum number of running pipeline jobs must be 1 or more")
 	}
 
+	if o.HTTPAddress == "" {
+		return errors.New("The http address must be specified")
+	}
+
+	if o.EnableProfiling && o.ProfilingAddress == "" {
+		return errors.New("The profiling address must be specified when profiling is enabled")
+	}
+
+	if o.RetentionKeepLast < 0 {
+		return errors.New("The number of finished pipelines to keep must be 0 or more")
+	}
+
+	if o.RetentionTTL < 0 || o.RetentionSucceededTTL < 0 || o.RetentionFailedTTL < 0 {
+		return errors.New("The retention ttls must not be negative")
+	}
+
 	return nil
 }
 
 func (o *Options) Complete(args []string, f client.Factory) error {
+	logger, err := f.Logger()
+	if err != nil {
+		return err
+	}
+	o.logger = logger
+	o.logConfig = f.LogConfig()
+
 	client, err := f.Client()
 	if err != nil {
 		return err
@@ -2,10 +2,13 @@ package server
 
 import (
 	"context"
+	"net/http"
+	"time"
 
 	"github.com/kubesmith/kubesmith/pkg/controllers"
 	kubesmithClient "github.com/kubesmith/kubesmith/pkg/generated/clientset/versioned"
 	kubesmithInformers "github.com/kubesmith/kubesmith/pkg/generated/informers/externalversions"
+	"github.com/kubesmith/kubesmith/pkg/logging"
 	"github.com/sirupsen/logrus"
 	kubeInformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
@@ -15,9 +18,18 @@ type Options struct {
 	Namespace              string
 	MaxRunningPipelines    int
 	MaxRunningPipelineJobs int
+	HTTPAddress            string
+	EnableProfiling        bool
+	ProfilingAddress       string
+	RetentionKeepLast      int
+	RetentionTTL           time.Duration
+	RetentionSucceededTTL  time.Duration
+	RetentionFailedTTL     time.Duration
 
 	client     kubesmithClient.Interface
 	kubeClient kubernetes.Interface
+	logger     *logrus.Logger
+	logConfig  logging.Config
 }
 
 type Server struct {
@@ -37,4 +49,12 @@ type Server struct {
 	pipelineStageController controllers.Interface
 	pipelineJobController   controllers.Interface
 	jobController           controllers.Interface
+	commitStatusReporter    controllers.Interface
+	notifier                controllers.Interface
+	webhookHandler          http.Handler
+}
+
+type serverController struct {
+	name       string
+	controller controllers.Interface
 }
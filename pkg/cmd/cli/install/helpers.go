@@ -0,0 +1,44 @@
+package install
+
+import (
+	"os"
+
+	"github.com/kubesmith/kubesmith/pkg/client"
+	"github.com/kubesmith/kubesmith/pkg/cmd"
+	"github.com/kubesmith/kubesmith/pkg/cmd/cli/pipeline/view"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(f client.Factory) *cobra.Command {
+	o := NewOptions()
+
+	c := &cobra.Command{
+		Use:   "install",
+		Short: "Installs the kubesmith crds and a forge",
+		Long: `Installs the kubesmith custom resource definitions and a forge in the namespace
+given by --namespace, together with the service account and the least privileged
+roles it needs. The schemas of the custom resource definitions are generated from
+the kubesmith api types. Existing resources are replaced, so running install again
+upgrades an installation. With --dry-run the resources are printed instead.`,
+		Run: func(c *cobra.Command, args []string) {
+			cmd.CheckError(o.Complete(args, f))
+			cmd.CheckError(o.Validate(c, args, f))
+			cmd.CheckError(o.Run(c, f))
+		},
+	}
+
+	o.BindFlags(c.Flags())
+
+	return c
+}
+
+func NewOptions() *Options {
+	return &Options{
+		Image:                  "kubesmith/kubesmith",
+		ImagePullPolicy:        "Always",
+		MaxRunningPipelines:    1,
+		MaxRunningPipelineJobs: 3,
+		Output:                 view.OutputFormatYAML,
+		out:                    os.Stdout,
+	}
+}
@@ -0,0 +1,51 @@
+package install
+
+import (
+	"fmt"
+
+	"github.com/kubesmith/kubesmith/pkg/cmd/cli/pipeline/view"
+	"github.com/kubesmith/kubesmith/pkg/install"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func (o *Options) getConfig() install.Config {
+	return install.Config{
+		Namespace:              o.namespace,
+		Image:                  o.Image,
+		ImagePullPolicy:        corev1.PullPolicy(o.ImagePullPolicy),
+		MaxRunningPipelines:    o.MaxRunningPipelines,
+		MaxRunningPipelineJobs: o.MaxRunningPipelineJobs,
+		RetentionKeepLast:      o.RetentionKeepLast,
+		RetentionTTL:           o.RetentionTTL,
+		RetentionSucceededTTL:  o.RetentionSucceededTTL,
+		RetentionFailedTTL:     o.RetentionFailedTTL,
+		ExtraArgs:              o.ForgeArgs,
+	}
+}
+
+func (o *Options) printResources(resources []install.Resource) error {
+	objects := []interface{}{}
+	for _, resource := range resources {
+		objects = append(objects, resource.Object)
+	}
+
+	if o.Output == view.OutputFormatJSON {
+		return view.PrintObject(o.out, map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "List",
+			"items":      objects,
+		}, o.Output)
+	}
+
+	for index, object := range objects {
+		if index > 0 {
+			fmt.Fprintln(o.out, "---")
+		}
+
+		if err := view.PrintObject(o.out, object, o.Output); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
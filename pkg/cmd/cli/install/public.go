@@ -0,0 +1,81 @@
+package install
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubesmith/kubesmith/pkg/client"
+	"github.com/kubesmith/kubesmith/pkg/cmd/cli/pipeline/view"
+	"github.com/kubesmith/kubesmith/pkg/install"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func (o *Options) BindFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&o.Image, "image", o.Image, "The image of the forge")
+	flags.StringVar(&o.ImagePullPolicy, "image-pull-policy", o.ImagePullPolicy, "The pull policy of the forge image; one of: Always, IfNotPresent, Never")
+	flags.IntVar(&o.MaxRunningPipelines, "max-running-pipelines", o.MaxRunningPipelines, "The maximum number of pipelines the forge runs at any given time")
+	flags.IntVar(&o.MaxRunningPipelineJobs, "max-running-pipeline-jobs", o.MaxRunningPipelineJobs, "The maximum number of pipeline jobs the forge runs at any given time")
+	flags.IntVar(&o.RetentionKeepLast, "retention-keep-last", 0, "The number of finished pipelines the forge keeps per repo/branch; 0 keeps all of them")
+	flags.DurationVar(&o.RetentionTTL, "retention-ttl", 0, "How long the forge keeps finished pipelines; 0 keeps them forever")
+	flags.DurationVar(&o.RetentionSucceededTTL, "retention-succeeded-ttl", 0, "How long the forge keeps successful pipelines; overrides --retention-ttl")
+	flags.DurationVar(&o.RetentionFailedTTL, "retention-failed-ttl", 0, "How long the forge keeps failed pipelines; overrides --retention-ttl")
+	flags.StringArrayVar(&o.ForgeArgs, "forge-arg", []string{}, "An additional argument for the forge server (e.g. --forge-arg=--log-level=debug); may be repeated")
+	flags.BoolVar(&o.DryRun, "dry-run", false, "Prints the resources instead of installing them")
+	flags.StringVarP(&o.Output, "output", "o", o.Output, "The output format of --dry-run; one of: yaml, json")
+}
+
+func (o *Options) Validate(c *cobra.Command, args []string, f client.Factory) error {
+	switch corev1.PullPolicy(o.ImagePullPolicy) {
+	case corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullNever:
+	default:
+		return fmt.Errorf("invalid image pull policy %q", o.ImagePullPolicy)
+	}
+
+	if o.MaxRunningPipelines < 1 || o.MaxRunningPipelineJobs < 1 {
+		return fmt.Errorf("the forge must be able to run at least one pipeline and pipeline job")
+	}
+
+	if o.RetentionKeepLast < 0 {
+		return fmt.Errorf("invalid retention keep last")
+	}
+
+	return view.ValidateOutputFormat(o.Output, view.OutputFormatYAML, view.OutputFormatJSON)
+}
+
+func (o *Options) Complete(args []string, f client.Factory) error {
+	o.namespace = f.Namespace()
+
+	if o.DryRun {
+		return nil
+	}
+
+	kubeClient, err := f.KubeClient()
+	if err != nil {
+		return err
+	}
+	o.kubeClient = kubeClient
+
+	return nil
+}
+
+func (o *Options) Run(c *cobra.Command, f client.Factory) error {
+	resources := install.GetResources(o.getConfig())
+
+	if o.DryRun {
+		return o.printResources(resources)
+	}
+
+	restClient := o.kubeClient.CoreV1().RESTClient()
+	for _, resource := range resources {
+		action, err := install.Apply(restClient, resource)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(o.out, "%s/%s %s\n", strings.ToLower(resource.Kind), resource.Object.GetName(), action)
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package install
+
+import (
+	"io"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+type Options struct {
+	Image                  string
+	ImagePullPolicy        string
+	MaxRunningPipelines    int
+	MaxRunningPipelineJobs int
+	RetentionKeepLast      int
+	RetentionTTL           time.Duration
+	RetentionSucceededTTL  time.Duration
+	RetentionFailedTTL     time.Duration
+	ForgeArgs              []string
+	DryRun                 bool
+	Output                 string
+
+	namespace  string
+	out        io.Writer
+	kubeClient kubernetes.Interface
+}
@@ -0,0 +1,37 @@
+package lint
+
+import (
+	"os"
+
+	"github.com/kubesmith/kubesmith/pkg/client"
+	"github.com/kubesmith/kubesmith/pkg/cmd"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(f client.Factory) *cobra.Command {
+	o := NewOptions()
+
+	c := &cobra.Command{
+		Use:   "lint [-f FILE]... [FILE...]",
+		Short: "Checks pipeline files for problems without a cluster",
+		Long: `Checks pipeline files for problems without a cluster. Every problem is printed as
+file:line:column: severity: message. The exit code is 1 when errors were found (or
+warnings with --strict) and 0 otherwise, which makes it usable in pre-commit hooks.`,
+		Run: func(c *cobra.Command, args []string) {
+			cmd.CheckError(o.Complete(args, f))
+			cmd.CheckError(o.Validate(c, args, f))
+			cmd.CheckError(o.Run(c, f))
+		},
+	}
+
+	o.BindFlags(c.Flags())
+
+	return c
+}
+
+func NewOptions() *Options {
+	return &Options{
+		in:  os.Stdin,
+		out: os.Stdout,
+	}
+}
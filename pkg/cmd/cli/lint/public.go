@@ -0,0 +1,71 @@
+package lint
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/kubesmith/kubesmith/pkg/client"
+	"github.com/kubesmith/kubesmith/pkg/cmd"
+	"github.com/kubesmith/kubesmith/pkg/cmd/util/lint"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func (o *Options) BindFlags(flags *pflag.FlagSet) {
+	flags.StringArrayVarP(&o.FileNames, "filename", "f", []string{}, "A file that contains pipelines to check; use - to read from stdin")
+	flags.BoolVar(&o.Strict, "strict", false, "Treat warnings as errors")
+}
+
+func (o *Options) Validate(c *cobra.Command, args []string, f client.Factory) error {
+	if len(o.FileNames) == 0 {
+		return fmt.Errorf("at least one file must be specified")
+	}
+
+	return nil
+}
+
+func (o *Options) Complete(args []string, f client.Factory) error {
+	o.FileNames = append(o.FileNames, args...)
+
+	return nil
+}
+
+func (o *Options) Run(c *cobra.Command, f client.Factory) error {
+	errorCount, warningCount := 0, 0
+
+	for _, fileName := range o.FileNames {
+		var data []byte
+		var err error
+
+		if fileName == "-" {
+			fileName = "<stdin>"
+			data, err = ioutil.ReadAll(o.in)
+		} else {
+			data, err = ioutil.ReadFile(fileName)
+		}
+
+		if err != nil {
+			return errors.Wrapf(err, "could not read %s", fileName)
+		}
+
+		for _, issue := range lint.Lint(data) {
+			fmt.Fprintf(o.out, "%s:%s\n", fileName, issue)
+
+			if issue.Severity == lint.SeverityError {
+				errorCount++
+			} else {
+				warningCount++
+			}
+		}
+	}
+
+	if errorCount > 0 || (o.Strict && warningCount > 0) {
+		return &cmd.ExitError{
+			Code:    exitCodeProblemsFound,
+			Message: fmt.Sprintf("found %d error(s) and %d warning(s)", errorCount, warningCount),
+		}
+	}
+
+	return nil
+}
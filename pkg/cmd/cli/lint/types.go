@@ -0,0 +1,17 @@
+package lint
+
+import (
+	"io"
+)
+
+const (
+	exitCodeProblemsFound = 1
+)
+
+type Options struct {
+	FileNames []string
+	Strict    bool
+
+	in  io.Reader
+	out io.Writer
+}
@@ -0,0 +1,21 @@
+package local
+
+import (
+	"github.com/kubesmith/kubesmith/pkg/client"
+	"github.com/kubesmith/kubesmith/pkg/cmd/cli/local/run"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(f client.Factory) *cobra.Command {
+	c := &cobra.Command{
+		Use:   "local",
+		Short: "Runs pipelines on this machine without kubernetes",
+		Long:  "Runs pipelines on this machine without kubernetes",
+	}
+
+	c.AddCommand(
+		run.NewCommand(f),
+	)
+
+	return c
+}
@@ -0,0 +1,40 @@
+package run
+
+import (
+	"os"
+
+	"github.com/kubesmith/kubesmith/pkg/client"
+	"github.com/kubesmith/kubesmith/pkg/cmd"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(f client.Factory) *cobra.Command {
+	o := NewOptions()
+
+	c := &cobra.Command{
+		Use:   "run -f FILE",
+		Short: "Runs a pipeline on this machine",
+		Long: `Runs a pipeline on this machine. Every job runs its runner script (or command) as a
+local process in a fresh copy of the source directory, together with the artifacts of
+the previous stage. Job images are not used, so the tools the jobs need must be
+installed locally. The exit code is 0 when the pipeline succeeded, 2 when it failed
+and 1 when it could not be run.`,
+		Run: func(c *cobra.Command, args []string) {
+			cmd.CheckError(o.Complete(args, f))
+			cmd.CheckError(o.Validate(c, args, f))
+			cmd.CheckError(o.Run(c, f))
+		},
+	}
+
+	o.BindFlags(c.Flags())
+
+	return c
+}
+
+func NewOptions() *Options {
+	return &Options{
+		SourcePath: ".",
+		in:         os.Stdin,
+		out:        os.Stdout,
+	}
+}
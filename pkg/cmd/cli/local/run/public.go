@@ -0,0 +1,117 @@
+package run
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/client"
+	"github.com/kubesmith/kubesmith/pkg/cmd"
+	"github.com/kubesmith/kubesmith/pkg/local"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func (o *Options) BindFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&o.FileName, "filename", "f", "", "The file that contains the pipeline to run; use - to read from stdin")
+	flags.StringVar(&o.SourcePath, "source", o.SourcePath, "The directory that is copied into the workspace of every job")
+	flags.StringVar(&o.WorkPath, "work-dir", "", "The directory the workspaces and artifacts of the jobs are created in (default: a temporary directory)")
+	flags.BoolVar(&o.KeepWork, "keep-work-dir", false, "Keep the work directory after the pipeline finished")
+	flags.BoolVar(&o.Parallel, "parallel", false, "Run the jobs of a stage at the same time instead of one after the other")
+}
+
+func (o *Options) Validate(c *cobra.Command, args []string, f client.Factory) error {
+	if o.FileName == "" {
+		return fmt.Errorf("a pipeline file must be specified")
+	}
+
+	if info, err := os.Stat(o.SourcePath); err != nil || !info.IsDir() {
+		return fmt.Errorf("the source %s must be a directory", o.SourcePath)
+	}
+
+	if kind := o.pipeline.Kind; kind != "" && kind != "Pipeline" {
+		return fmt.Errorf("expected a Pipeline in %s but found a %s", o.FileName, kind)
+	}
+
+	return local.Validate(o.pipeline)
+}
+
+func (o *Options) Complete(args []string, f client.Factory) error {
+	if o.FileName == "" {
+		return nil
+	}
+
+	var data []byte
+	var err error
+
+	if o.FileName == "-" {
+		data, err = ioutil.ReadAll(o.in)
+	} else {
+		data, err = ioutil.ReadFile(o.FileName)
+	}
+
+	if err != nil {
+		return errors.Wrapf(err, "could not read pipeline file %s", o.FileName)
+	}
+
+	if err := yaml.Unmarshal(data, &o.pipeline); err != nil {
+		return errors.Wrapf(err, "could not parse pipeline file %s", o.FileName)
+	}
+
+	// like the forge, load the stages and jobs from the pipeline file of the
+	// source when the pipeline refers to one
+	if o.pipeline.NeedsPipelineFile() {
+		if err := o.pipeline.ValidatePipelinePath(); err != nil {
+			return err
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join(o.SourcePath, o.pipeline.Spec.PipelinePath))
+		if err != nil {
+			return errors.Wrapf(err, "could not read pipeline file %s from the source", o.pipeline.Spec.PipelinePath)
+		}
+
+		if err := o.pipeline.LoadPipelineFile(data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (o *Options) Run(c *cobra.Command, f client.Factory) error {
+	workPath := o.WorkPath
+	if workPath == "" {
+		tempPath, err := ioutil.TempDir("", "kubesmith-local-")
+		if err != nil {
+			return errors.Wrap(err, "could not create a work directory")
+		}
+
+		workPath = tempPath
+	}
+
+	if o.KeepWork {
+		fmt.Fprintf(o.out, "work directory: %s\n", workPath)
+	} else {
+		defer os.RemoveAll(workPath)
+	}
+
+	executor := local.NewExecutor(o.pipeline, o.SourcePath, workPath, o.Parallel, o.out)
+	result, err := executor.Run()
+	if err != nil {
+		return err
+	}
+
+	if result.Phase == api.PhaseFailed {
+		return &cmd.ExitError{
+			Code:    exitCodePipelineFailed,
+			Message: fmt.Sprintf("pipeline failed: %s", result.FailureReason),
+		}
+	}
+
+	fmt.Fprintln(o.out, "pipeline succeeded")
+	return nil
+}
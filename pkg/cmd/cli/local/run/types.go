@@ -0,0 +1,23 @@
+package run
+
+import (
+	"io"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+)
+
+const (
+	exitCodePipelineFailed = 2
+)
+
+type Options struct {
+	FileName   string
+	SourcePath string
+	WorkPath   string
+	KeepWork   bool
+	Parallel   bool
+
+	pipeline api.Pipeline
+	in       io.Reader
+	out      io.Writer
+}
@@ -0,0 +1,35 @@
+package logs
+
+import (
+	"os"
+
+	"github.com/kubesmith/kubesmith/pkg/client"
+	"github.com/kubesmith/kubesmith/pkg/cmd"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(f client.Factory) *cobra.Command {
+	o := NewOptions()
+
+	c := &cobra.Command{
+		Use:   "logs PIPELINE [JOB]",
+		Short: "Prints the logs of a pipeline's jobs",
+		Long: `Prints the logs of a pipeline's jobs. Logs of running jobs are read from their
+pods; logs of finished jobs are read from the pipeline's workspace storage.`,
+		Run: func(c *cobra.Command, args []string) {
+			cmd.CheckError(o.Complete(args, f))
+			cmd.CheckError(o.Validate(c, args, f))
+			cmd.CheckError(o.Run(c, f))
+		},
+	}
+
+	o.BindFlags(c.Flags())
+
+	return c
+}
+
+func NewOptions() *Options {
+	return &Options{
+		out: os.Stdout,
+	}
+}
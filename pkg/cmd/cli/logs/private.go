@@ -0,0 +1,176 @@
+package logs
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/cmd/util/storage"
+	"github.com/kubesmith/kubesmith/pkg/templates"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func (o *Options) getPipelineJobs(pipeline api.Pipeline) ([]api.PipelineJob, error) {
+	selector := labels.SelectorFromSet(labels.Set{
+		api.GetLabelKey("PipelineName"):      pipeline.GetName(),
+		api.GetLabelKey("PipelineNamespace"): pipeline.GetNamespace(),
+	})
+
+	list, err := o.client.KubesmithV1().PipelineJobs(pipeline.GetNamespace()).List(metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list pipeline jobs")
+	}
+
+	jobs := []api.PipelineJob{}
+	for _, job := range list.Items {
+		if o.JobName == "" || o.JobName == job.GetName() || strings.EqualFold(o.JobName, job.Spec.Job.Name) {
+			jobs = append(jobs, job)
+		}
+	}
+
+	sort.SliceStable(jobs, func(i, j int) bool {
+		if jobs[i].CreationTimestamp.Equal(&jobs[j].CreationTimestamp) {
+			return jobs[i].GetName() < jobs[j].GetName()
+		}
+
+		return jobs[i].CreationTimestamp.Before(&jobs[j].CreationTimestamp)
+	})
+
+	return jobs, nil
+}
+
+func (o *Options) printPipelineJobLog(pipeline api.Pipeline, job api.PipelineJob) error {
+	// trigger jobs run no pod; the pipeline they created has the logs
+	if job.Spec.Job.IsTrigger() {
+		downstream := "no pipeline yet"
+		if job.Status.Downstream.Name != "" {
+			downstream = fmt.Sprintf("pipeline %s", job.Status.Downstream.String())
+		}
+
+		fmt.Fprintf(o.out, "pipeline job %s has no log; it triggers %s\n", job.GetName(), downstream)
+		return nil
+	}
+
+	if job.HasNoPhase() || job.IsQueued() || job.IsRunning() {
+		return o.streamPodLog(job)
+	}
+
+	storageErr := o.streamStorageLog(pipeline, job)
+	if storageErr == nil {
+		return nil
+	}
+
+	// the log may not have been archived; try the pod if it's still around
+	if err := o.streamPodLog(job); err != nil {
+		return errors.Wrap(storageErr, "could not read log from storage")
+	}
+
+	return nil
+}
+
+func (o *Options) streamPodLog(job api.PipelineJob) error {
+	var stream io.ReadCloser
+
+	openStream := func() (*corev1.Pod, error) {
+		pod, err := o.getPipelineJobPod(job)
+		if err != nil {
+			return nil, err
+		}
+
+		request := o.kubeClient.CoreV1().Pods(pod.GetNamespace()).GetLogs(pod.GetName(), &corev1.PodLogOptions{
+			Container: templates.PipelineJobPrimaryContainerName,
+			Follow:    o.Follow,
+		})
+
+		stream, err = request.Stream()
+		if err != nil {
+			return pod, errors.Wrap(err, "could not stream pod log")
+		}
+
+		return pod, nil
+	}
+
+	if o.Follow {
+		// wait for the pod and its container to start when following; pods
+		// that are gone, or whose job finished without the container ever
+		// starting, won't have a log to wait for
+		err := wait.PollImmediateInfinite(time.Second*2, func() (bool, error) {
+			pod, streamErr := openStream()
+			if streamErr == nil {
+				return true, nil
+			} else if pod != nil && (pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed) {
+				return false, streamErr
+			}
+
+			current, err := o.client.KubesmithV1().PipelineJobs(job.GetNamespace()).Get(job.GetName(), metav1.GetOptions{})
+			if err != nil {
+				return false, errors.Wrap(err, "could not get pipeline job")
+			} else if current.HasFinished() || current.GetAttempt() != job.GetAttempt() {
+				return false, streamErr
+			}
+
+			return false, nil
+		})
+
+		if err != nil {
+			return err
+		}
+	} else if _, err := openStream(); err != nil {
+		return err
+	}
+
+	defer stream.Close()
+
+	_, err := io.Copy(o.out, stream)
+	return err
+}
+
+func (o *Options) getPipelineJobPod(job api.PipelineJob) (*corev1.Pod, error) {
+	set := labels.Set{
+		api.GetLabelKey("PipelineJobName"):      job.GetName(),
+		api.GetLabelKey("PipelineJobNamespace"): job.GetNamespace(),
+	}
+
+	// the pods of earlier attempts stick around until they are cleaned up
+	if job.GetAttempt() > 1 {
+		set[api.GetLabelKey("PipelineJobAttempt")] = strconv.Itoa(job.GetAttempt())
+	}
+
+	pods, err := o.kubeClient.CoreV1().Pods(job.GetNamespace()).List(metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(set).String(),
+	})
+
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list pods")
+	} else if len(pods.Items) == 0 {
+		return nil, fmt.Errorf("no pod was found for pipeline job %s", job.GetName())
+	}
+
+	return &pods.Items[0], nil
+}
+
+func (o *Options) streamStorageLog(pipeline api.Pipeline, job api.PipelineJob) error {
+	// the connection is shared by the jobs so that at most one port forward is started
+	if o.storage == nil {
+		connection, err := storage.Connect(pipeline, o.kubeClient, o.kubectlFlags, o.S3)
+		if err != nil {
+			return err
+		}
+
+		o.storage = connection
+	}
+
+	remotePath := fmt.Sprintf("%s/%s", job.GetStoragePath(), job.GetLogFileName())
+	return o.storage.Client.StreamFile(o.storage.BucketName, remotePath, o.out)
+}
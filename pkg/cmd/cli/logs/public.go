@@ -0,0 +1,94 @@
+package logs
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/kubesmith/kubesmith/pkg/client"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (o *Options) BindFlags(flags *pflag.FlagSet) {
+	flags.BoolVarP(&o.Follow, "follow", "f", false, "Follow the log of running jobs until they finish")
+	flags.StringVar(&o.S3.Host, "s3-host", "", "Overrides the host of the pipeline's s3 server when reading logs of finished jobs")
+	flags.IntVar(&o.S3.Port, "s3-port", 0, "Overrides the port of the pipeline's s3 server when reading logs of finished jobs")
+}
+
+// SetOutput changes where the logs are written to; they are written to stdout
+// by default
+func (o *Options) SetOutput(out io.Writer) {
+	o.out = out
+}
+
+func (o *Options) Validate(c *cobra.Command, args []string, f client.Factory) error {
+	if o.PipelineName == "" {
+		return fmt.Errorf("a pipeline name must be specified")
+	}
+
+	if o.S3.Port < 0 {
+		return fmt.Errorf("invalid s3 port")
+	}
+
+	return nil
+}
+
+func (o *Options) Complete(args []string, f client.Factory) error {
+	if len(args) > 0 {
+		o.PipelineName = args[0]
+	}
+
+	if len(args) > 1 {
+		o.JobName = args[1]
+	}
+
+	client, err := f.Client()
+	if err != nil {
+		return err
+	}
+	o.client = client
+
+	kubeClient, err := f.KubeClient()
+	if err != nil {
+		return err
+	}
+	o.kubeClient = kubeClient
+	o.namespace = f.Namespace()
+	o.kubectlFlags = f.KubectlFlags()
+
+	return nil
+}
+
+func (o *Options) Run(c *cobra.Command, f client.Factory) error {
+	pipeline, err := o.client.KubesmithV1().Pipelines(o.namespace).Get(o.PipelineName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, "could not retrieve pipeline")
+	}
+
+	defer func() {
+		if o.storage != nil {
+			o.storage.Close()
+		}
+	}()
+
+	jobs, err := o.getPipelineJobs(*pipeline)
+	if err != nil {
+		return err
+	} else if len(jobs) == 0 {
+		return fmt.Errorf("no pipeline jobs were found for pipeline %s", o.PipelineName)
+	}
+
+	for _, job := range jobs {
+		if len(jobs) > 1 {
+			fmt.Fprintf(o.out, "==> %s (%s) <==\n", job.Spec.Job.Name, job.GetName())
+		}
+
+		if err := o.printPipelineJobLog(*pipeline, job); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
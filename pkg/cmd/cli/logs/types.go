@@ -0,0 +1,23 @@
+package logs
+
+import (
+	"io"
+
+	"github.com/kubesmith/kubesmith/pkg/cmd/util/storage"
+	kubesmithClient "github.com/kubesmith/kubesmith/pkg/generated/clientset/versioned"
+	"k8s.io/client-go/kubernetes"
+)
+
+type Options struct {
+	PipelineName string
+	JobName      string
+	Follow       bool
+	S3           storage.Overrides
+
+	namespace    string
+	kubectlFlags []string
+	out          io.Writer
+	client       kubesmithClient.Interface
+	kubeClient   kubernetes.Interface
+	storage      *storage.Connection
+}
@@ -0,0 +1,58 @@
+package cancel
+
+import (
+	"fmt"
+
+	"github.com/kubesmith/kubesmith/pkg/client"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func (o *Options) BindFlags(flags *pflag.FlagSet) {}
+
+func (o *Options) Validate(c *cobra.Command, args []string, f client.Factory) error {
+	if len(o.PipelineNames) == 0 {
+		return fmt.Errorf("at least one pipeline name must be specified")
+	}
+
+	return nil
+}
+
+func (o *Options) Complete(args []string, f client.Factory) error {
+	o.PipelineNames = args
+
+	client, err := f.Client()
+	if err != nil {
+		return err
+	}
+	o.client = client
+	o.namespace = f.Namespace()
+
+	return nil
+}
+
+func (o *Options) Run(c *cobra.Command, f client.Factory) error {
+	patch := []byte(`{"spec":{"cancel":true}}`)
+
+	for _, name := range o.PipelineNames {
+		pipeline, err := o.client.KubesmithV1().Pipelines(o.namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "could not retrieve pipeline %s", name)
+		}
+
+		if pipeline.HasFinished() {
+			return fmt.Errorf("pipeline %s has already finished (%s)", name, pipeline.Status.Phase)
+		}
+
+		if _, err := o.client.KubesmithV1().Pipelines(o.namespace).Patch(name, types.MergePatchType, patch); err != nil {
+			return errors.Wrapf(err, "could not cancel pipeline %s", name)
+		}
+
+		fmt.Fprintf(o.out, "pipeline %s cancellation requested\n", name)
+	}
+
+	return nil
+}
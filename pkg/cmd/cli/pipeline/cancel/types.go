@@ -0,0 +1,15 @@
+package cancel
+
+import (
+	"io"
+
+	kubesmithClient "github.com/kubesmith/kubesmith/pkg/generated/clientset/versioned"
+)
+
+type Options struct {
+	PipelineNames []string
+
+	namespace string
+	out       io.Writer
+	client    kubesmithClient.Interface
+}
@@ -0,0 +1,63 @@
+package describe
+
+import (
+	"fmt"
+
+	"github.com/kubesmith/kubesmith/pkg/client"
+	"github.com/kubesmith/kubesmith/pkg/cmd/cli/pipeline/view"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (o *Options) BindFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&o.Output, "output", "o", "", "The output format; one of: json, yaml")
+}
+
+func (o *Options) Validate(c *cobra.Command, args []string, f client.Factory) error {
+	if o.PipelineName == "" {
+		return fmt.Errorf("a pipeline name must be specified")
+	}
+
+	return view.ValidateOutputFormat(
+		o.Output,
+		view.OutputFormatTable,
+		view.OutputFormatJSON,
+		view.OutputFormatYAML,
+	)
+}
+
+func (o *Options) Complete(args []string, f client.Factory) error {
+	if len(args) > 0 {
+		o.PipelineName = args[0]
+	}
+
+	client, err := f.Client()
+	if err != nil {
+		return err
+	}
+	o.client = client
+	o.namespace = f.Namespace()
+
+	return nil
+}
+
+func (o *Options) Run(c *cobra.Command, f client.Factory) error {
+	pipeline, err := o.client.KubesmithV1().Pipelines(o.namespace).Get(o.PipelineName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, "could not retrieve pipeline")
+	}
+
+	pipelineView, err := view.GetPipelineView(o.client, *pipeline)
+	if err != nil {
+		return err
+	}
+
+	switch o.Output {
+	case view.OutputFormatJSON, view.OutputFormatYAML:
+		return view.PrintObject(o.out, pipelineView, o.Output)
+	default:
+		return view.PrintPipelineTree(o.out, *pipelineView)
+	}
+}
@@ -0,0 +1,16 @@
+package describe
+
+import (
+	"io"
+
+	kubesmithClient "github.com/kubesmith/kubesmith/pkg/generated/clientset/versioned"
+)
+
+type Options struct {
+	Output       string
+	PipelineName string
+
+	namespace string
+	out       io.Writer
+	client    kubesmithClient.Interface
+}
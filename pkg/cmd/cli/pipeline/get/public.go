@@ -0,0 +1,68 @@
+package get
+
+import (
+	"fmt"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/client"
+	"github.com/kubesmith/kubesmith/pkg/cmd/cli/pipeline/view"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (o *Options) BindFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&o.Output, "output", "o", "", "The output format; one of: json, yaml, wide")
+}
+
+func (o *Options) Validate(c *cobra.Command, args []string, f client.Factory) error {
+	if len(o.PipelineNames) == 0 {
+		return fmt.Errorf("at least one pipeline name must be specified")
+	}
+
+	return view.ValidateOutputFormat(
+		o.Output,
+		view.OutputFormatTable,
+		view.OutputFormatWide,
+		view.OutputFormatJSON,
+		view.OutputFormatYAML,
+	)
+}
+
+func (o *Options) Complete(args []string, f client.Factory) error {
+	o.PipelineNames = args
+
+	client, err := f.Client()
+	if err != nil {
+		return err
+	}
+	o.client = client
+	o.namespace = f.Namespace()
+
+	return nil
+}
+
+func (o *Options) Run(c *cobra.Command, f client.Factory) error {
+	pipelines := []api.Pipeline{}
+
+	for _, name := range o.PipelineNames {
+		pipeline, err := o.client.KubesmithV1().Pipelines(o.namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "could not retrieve pipeline %s", name)
+		}
+
+		pipelines = append(pipelines, *pipeline)
+	}
+
+	switch o.Output {
+	case view.OutputFormatJSON, view.OutputFormatYAML:
+		if len(pipelines) == 1 {
+			return view.PrintObject(o.out, pipelines[0], o.Output)
+		}
+
+		return view.PrintObject(o.out, api.PipelineList{Items: pipelines}, o.Output)
+	default:
+		return view.PrintPipelineTable(o.out, pipelines, o.Output == view.OutputFormatWide)
+	}
+}
@@ -0,0 +1,33 @@
+package pipeline
+
+import (
+	"github.com/kubesmith/kubesmith/pkg/client"
+	"github.com/kubesmith/kubesmith/pkg/cmd/cli/pipeline/cancel"
+	"github.com/kubesmith/kubesmith/pkg/cmd/cli/pipeline/describe"
+	"github.com/kubesmith/kubesmith/pkg/cmd/cli/pipeline/get"
+	"github.com/kubesmith/kubesmith/pkg/cmd/cli/pipeline/list"
+	"github.com/kubesmith/kubesmith/pkg/cmd/cli/pipeline/render"
+	"github.com/kubesmith/kubesmith/pkg/cmd/cli/pipeline/retry"
+	"github.com/kubesmith/kubesmith/pkg/cmd/cli/pipeline/run"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(f client.Factory) *cobra.Command {
+	c := &cobra.Command{
+		Use:   "pipeline",
+		Short: "Submits and inspects kubesmith pipelines",
+		Long:  "Submits and inspects kubesmith pipelines",
+	}
+
+	c.AddCommand(
+		cancel.NewCommand(f),
+		describe.NewCommand(f),
+		get.NewCommand(f),
+		list.NewCommand(f),
+		render.NewCommand(f),
+		retry.NewCommand(f),
+		run.NewCommand(f),
+	)
+
+	return c
+}
@@ -0,0 +1,65 @@
+package list
+
+import (
+	"sort"
+
+	"github.com/kubesmith/kubesmith/pkg/client"
+	"github.com/kubesmith/kubesmith/pkg/cmd/cli/pipeline/view"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func (o *Options) BindFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&o.Output, "output", "o", "", "The output format; one of: json, yaml, wide")
+	flags.StringVarP(&o.Selector, "selector", "l", "", "A label selector to filter the pipelines by")
+}
+
+func (o *Options) Validate(c *cobra.Command, args []string, f client.Factory) error {
+	if _, err := labels.Parse(o.Selector); err != nil {
+		return errors.Wrap(err, "invalid selector")
+	}
+
+	return view.ValidateOutputFormat(
+		o.Output,
+		view.OutputFormatTable,
+		view.OutputFormatWide,
+		view.OutputFormatJSON,
+		view.OutputFormatYAML,
+	)
+}
+
+func (o *Options) Complete(args []string, f client.Factory) error {
+	client, err := f.Client()
+	if err != nil {
+		return err
+	}
+	o.client = client
+	o.namespace = f.Namespace()
+
+	return nil
+}
+
+func (o *Options) Run(c *cobra.Command, f client.Factory) error {
+	pipelines, err := o.client.KubesmithV1().Pipelines(o.namespace).List(metav1.ListOptions{
+		LabelSelector: o.Selector,
+	})
+
+	if err != nil {
+		return errors.Wrap(err, "could not list pipelines")
+	}
+
+	// newest pipelines first
+	sort.SliceStable(pipelines.Items, func(i, j int) bool {
+		return pipelines.Items[j].CreationTimestamp.Before(&pipelines.Items[i].CreationTimestamp)
+	})
+
+	switch o.Output {
+	case view.OutputFormatJSON, view.OutputFormatYAML:
+		return view.PrintObject(o.out, pipelines, o.Output)
+	default:
+		return view.PrintPipelineTable(o.out, pipelines.Items, o.Output == view.OutputFormatWide)
+	}
+}
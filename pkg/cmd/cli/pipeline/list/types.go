@@ -0,0 +1,16 @@
+package list
+
+import (
+	"io"
+
+	kubesmithClient "github.com/kubesmith/kubesmith/pkg/generated/clientset/versioned"
+)
+
+type Options struct {
+	Output   string
+	Selector string
+
+	namespace string
+	out       io.Writer
+	client    kubesmithClient.Interface
+}
@@ -0,0 +1,46 @@
+package render
+
+import (
+	"os"
+
+	"github.com/kubesmith/kubesmith/pkg/client"
+	"github.com/kubesmith/kubesmith/pkg/cmd"
+	"github.com/kubesmith/kubesmith/pkg/cmd/cli/pipeline/view"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(f client.Factory) *cobra.Command {
+	o := NewOptions()
+
+	c := &cobra.Command{
+		Use:   "render -f FILE",
+		Short: "Prints the kubernetes objects a pipeline would create without creating them",
+		Long: `Prints the kubernetes objects the forge would create for a pipeline without
+submitting anything: the pipeline's service account, role and role binding, the
+minio server it gets when it has no storage configured, the clone repo job and,
+for every stage, the pipeline stage with its expanded jobs, the pipeline jobs and
+their configmaps and batch jobs. Nothing is read from or written to the cluster;
+objects that depend on the outcome of earlier stages are rendered as if every
+stage succeeded. Pipelines without a name get a placeholder suffix, since the
+names of the objects are derived from it. The stages of a pipeline with a
+pipelinePath are only known once its repo is cloned and are left out.`,
+		Run: func(c *cobra.Command, args []string) {
+			cmd.CheckError(o.Complete(args, f))
+			cmd.CheckError(o.Validate(c, args, f))
+			cmd.CheckError(o.Run(c, f))
+		},
+	}
+
+	o.BindFlags(c.Flags())
+
+	return c
+}
+
+func NewOptions() *Options {
+	return &Options{
+		Output: view.OutputFormatYAML,
+		in:     os.Stdin,
+		out:    os.Stdout,
+		errOut: os.Stderr,
+	}
+}
@@ -0,0 +1,203 @@
+package render
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/controllers/pipeline/minio"
+	"github.com/kubesmith/kubesmith/pkg/logging"
+	"github.com/kubesmith/kubesmith/pkg/templates"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (o *Options) loadPipeline() error {
+	var data []byte
+	var err error
+
+	if o.FileName == "-" {
+		data, err = ioutil.ReadAll(o.in)
+	} else {
+		data, err = ioutil.ReadFile(o.FileName)
+	}
+
+	if err != nil {
+		return errors.Wrapf(err, "could not read pipeline file %s", o.FileName)
+	}
+
+	if err := yaml.Unmarshal(data, &o.pipeline); err != nil {
+		return errors.Wrapf(err, "could not parse pipeline file %s", o.FileName)
+	}
+
+	if kind := o.pipeline.Kind; kind != "" && kind != "Pipeline" {
+		return fmt.Errorf("expected a Pipeline in %s but found a %s", o.FileName, kind)
+	}
+
+	if namespace := o.pipeline.GetNamespace(); namespace != "" && namespace != o.namespace {
+		return fmt.Errorf("the namespace from %s (%s) does not match the namespace to render for (%s)", o.FileName, namespace, o.namespace)
+	}
+
+	o.pipeline.SetNamespace(o.namespace)
+
+	if o.Name != "" {
+		o.pipeline.SetName(o.Name)
+	}
+
+	// the api server would generate the name; the resource names depend on it
+	if o.pipeline.GetName() == "" {
+		name := strings.TrimSuffix(o.pipeline.GetGenerateName(), "-")
+		if name == "" {
+			name = "pipeline"
+		}
+
+		o.pipeline.SetName(fmt.Sprintf("%s-%s", name, placeholderNameSuffix))
+		o.pipeline.SetGenerateName("")
+	}
+
+	o.pipeline.Status = api.PipelineStatus{}
+
+	return nil
+}
+
+// renderPipeline walks through the pipeline the way the controllers do and
+// collects the objects they would create, in the order they would create them
+func (o *Options) renderPipeline() []interface{} {
+	pipeline := *o.pipeline.DeepCopy()
+	pipeline.SetPhaseToRunning()
+
+	serviceAccount := templates.GetPipelineServiceAccount(pipeline)
+	serviceAccount.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"}
+
+	role := templates.GetPipelineRole(pipeline)
+	role.TypeMeta = metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "Role"}
+
+	roleBinding := templates.GetPipelineRoleBinding(pipeline)
+	roleBinding.TypeMeta = metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "RoleBinding"}
+
+	objects := []interface{}{&serviceAccount, &role, &roleBinding}
+
+	if pipeline.NeedsMinioServer() {
+		minioObjects := []interface{}{}
+		pipeline, minioObjects = renderMinioServer(pipeline)
+		objects = append(objects, minioObjects...)
+	}
+
+	cloneRepo := *pipeline.DeepCopy()
+	cloneRepo.ObjectMeta.Labels = pipeline.GetWrappedLabels()
+
+	cloneRepoJob := templates.GetJobCloneRepo(cloneRepo, o.logConfig)
+	cloneRepoJob.TypeMeta = metav1.TypeMeta{APIVersion: "batch/v1", Kind: "Job"}
+	cloneRepoJob.SetNamespace(pipeline.GetNamespace())
+	objects = append(objects, &cloneRepoJob)
+
+	// the pipeline is printed with the storage configuration the forge patches in
+	rendered := *pipeline.DeepCopy()
+	rendered.TypeMeta = metav1.TypeMeta{APIVersion: api.SchemeGroupVersion.String(), Kind: "Pipeline"}
+	rendered.Status = api.PipelineStatus{}
+	objects = append([]interface{}{&rendered}, objects...)
+
+	// the stages of a pipeline file are only known once the repo is cloned
+	for pipeline.IsRunning() && !pipeline.NeedsPipelineFile() {
+		objects = append(objects, renderPipelineStage(pipeline, o.logConfig)...)
+		pipeline.AdvanceCurrentStage()
+	}
+
+	return objects
+}
+
+func renderMinioServer(pipeline api.Pipeline) (api.Pipeline, []interface{}) {
+	labels := pipeline.GetWrappedLabels()
+
+	minioServer := minio.NewMinioServer(
+		pipeline.GetNamespace(),
+		pipeline.GetResourcePrefix(),
+		nil,
+		labels,
+		nil,
+		nil,
+		nil,
+		nil,
+	)
+
+	name := minioServer.GetResourceName()
+
+	secret := templates.GetMinioSecret(name, minio.MINIO_DEFAULT_ACCESS_KEY_KEY, minio.MINIO_DEFAULT_SECRET_KEY_KEY, labels)
+	secret.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"}
+	secret.SetNamespace(pipeline.GetNamespace())
+
+	deployment := templates.GetMinioDeployment(
+		name,
+		pipeline.GetResourcePrefix(),
+		minio.MINIO_DEFAULT_ACCESS_KEY_KEY,
+		minio.MINIO_DEFAULT_SECRET_KEY_KEY,
+		minio.MINIO_DEFAULT_PORT,
+		labels,
+		secret,
+	)
+	deployment.TypeMeta = metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"}
+	deployment.SetNamespace(pipeline.GetNamespace())
+
+	service := templates.GetMinioService(name, minio.MINIO_DEFAULT_PORT, labels)
+	service.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Service"}
+	service.SetNamespace(pipeline.GetNamespace())
+
+	storage := &pipeline.Spec.Workspace.Storage.S3
+	storage.Host = fmt.Sprintf("%s.%s.svc", name, pipeline.GetNamespace())
+	storage.Port = minio.MINIO_DEFAULT_PORT
+	storage.UseSSL = false
+	storage.BucketName = minioServer.GetBucketName()
+	storage.Credentials.Secret.Name = name
+	storage.Credentials.Secret.AccessKeyKey = minio.MINIO_DEFAULT_ACCESS_KEY_KEY
+	storage.Credentials.Secret.SecretKeyKey = minio.MINIO_DEFAULT_SECRET_KEY_KEY
+
+	return pipeline, []interface{}{&secret, &deployment, &service}
+}
+
+func renderPipelineStage(pipeline api.Pipeline, logConfig logging.Config) []interface{} {
+	name := pipeline.GetPipelineStageName(pipeline.Status.StageIndex)
+
+	wrapped := *pipeline.DeepCopy()
+	wrapped.ObjectMeta.Labels = pipeline.GetWrappedLabels()
+
+	stage := templates.GetPipelineStage(name, wrapped)
+	stage.SetNamespace(pipeline.GetNamespace())
+	objects := []interface{}{&stage}
+
+	jobStage := *stage.DeepCopy()
+	jobStage.ObjectMeta.Labels = stage.GetWrappedLabels()
+
+	for index, jobSpec := range stage.Spec.Jobs {
+		job := templates.GetPipelineJob(stage.GetPipelineJobName(index+1), jobStage, jobSpec)
+		job.SetNamespace(pipeline.GetNamespace())
+		objects = append(objects, &job)
+		objects = append(objects, renderPipelineJob(job, logConfig)...)
+	}
+
+	return objects
+}
+
+func renderPipelineJob(original api.PipelineJob, logConfig logging.Config) []interface{} {
+	// trigger jobs create a downstream pipeline instead of running a batch job
+	if original.Spec.Job.IsTrigger() {
+		return []interface{}{}
+	}
+
+	job := *original.DeepCopy()
+	job.ObjectMeta.Labels = original.GetWrappedLabels()
+
+	// the pipeline job controller runs the runner script instead of the command
+	job.UseRunnerScript()
+
+	configMap := templates.GetPipelineJobJobConfigMap(job)
+	configMap.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"}
+	configMap.SetNamespace(job.GetNamespace())
+
+	batchJob := templates.GetPipelineJobJob(job, logConfig)
+	batchJob.TypeMeta = metav1.TypeMeta{APIVersion: "batch/v1", Kind: "Job"}
+	batchJob.SetNamespace(job.GetNamespace())
+
+	return []interface{}{&configMap, &batchJob}
+}
@@ -0,0 +1,72 @@
+package render
+
+import (
+	"fmt"
+
+	"github.com/kubesmith/kubesmith/pkg/client"
+	"github.com/kubesmith/kubesmith/pkg/cmd/cli/pipeline/view"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func (o *Options) BindFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&o.FileName, "filename", "f", "", "The file that contains the pipeline to render; use - to read from stdin")
+	flags.StringVar(&o.Name, "name", "", "Overrides the name of the pipeline from the file")
+	flags.StringVarP(&o.Output, "output", "o", o.Output, "The output format; one of: yaml, json")
+}
+
+func (o *Options) Validate(c *cobra.Command, args []string, f client.Factory) error {
+	if o.FileName == "" {
+		return errors.New("a pipeline file must be specified")
+	}
+
+	if err := view.ValidateOutputFormat(o.Output, view.OutputFormatYAML, view.OutputFormatJSON); err != nil {
+		return err
+	}
+
+	if err := o.pipeline.Validate(); err != nil {
+		return errors.Wrap(err, "invalid pipeline")
+	}
+
+	return nil
+}
+
+func (o *Options) Complete(args []string, f client.Factory) error {
+	o.namespace = f.Namespace()
+	o.logConfig = f.LogConfig()
+
+	if o.FileName == "" {
+		return nil
+	}
+
+	return o.loadPipeline()
+}
+
+func (o *Options) Run(c *cobra.Command, f client.Factory) error {
+	objects := o.renderPipeline()
+
+	if o.pipeline.NeedsPipelineFile() {
+		fmt.Fprintf(o.errOut, "warning: the stages and jobs are loaded from %s once the repo is cloned; they are not rendered\n", o.pipeline.Spec.PipelinePath)
+	}
+
+	if o.Output == view.OutputFormatJSON {
+		return view.PrintObject(o.out, map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "List",
+			"items":      objects,
+		}, o.Output)
+	}
+
+	for index, object := range objects {
+		if index > 0 {
+			fmt.Fprintln(o.out, "---")
+		}
+
+		if err := view.PrintObject(o.out, object, o.Output); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package render
+
+import (
+	"io"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/logging"
+)
+
+const (
+	placeholderNameSuffix = "xxxxx"
+)
+
+type Options struct {
+	FileName string
+	Name     string
+	Output   string
+
+	namespace string
+	logConfig logging.Config
+	pipeline  api.Pipeline
+	in        io.Reader
+	out       io.Writer
+	errOut    io.Writer
+}
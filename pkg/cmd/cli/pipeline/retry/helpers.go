@@ -0,0 +1,36 @@
+package retry
+
+import (
+	"os"
+
+	"github.com/kubesmith/kubesmith/pkg/client"
+	"github.com/kubesmith/kubesmith/pkg/cmd"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(f client.Factory) *cobra.Command {
+	o := NewOptions()
+
+	c := &cobra.Command{
+		Use:   "retry PIPELINE",
+		Short: "Retries a failed or cancelled pipeline from the stage that did not succeed",
+		Long: `Retries a failed or cancelled pipeline from the stage that did not succeed. Only the
+jobs of that stage that did not succeed are run again unless --failed-stage is given;
+the repo archive and the artifacts of the earlier stages are reused.`,
+		Run: func(c *cobra.Command, args []string) {
+			cmd.CheckError(o.Complete(args, f))
+			cmd.CheckError(o.Validate(c, args, f))
+			cmd.CheckError(o.Run(c, f))
+		},
+	}
+
+	o.BindFlags(c.Flags())
+
+	return c
+}
+
+func NewOptions() *Options {
+	return &Options{
+		out: os.Stdout,
+	}
+}
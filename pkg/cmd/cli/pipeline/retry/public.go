@@ -0,0 +1,80 @@
+package retry
+
+import (
+	"encoding/json"
+	"fmt"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/client"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func (o *Options) BindFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&o.FailedStage, "failed-stage", false, "Rerun every job of the failed stage instead of only the jobs that did not succeed")
+}
+
+func (o *Options) Validate(c *cobra.Command, args []string, f client.Factory) error {
+	if o.PipelineName == "" {
+		return fmt.Errorf("a pipeline name must be specified")
+	}
+
+	return nil
+}
+
+func (o *Options) Complete(args []string, f client.Factory) error {
+	if len(args) > 0 {
+		o.PipelineName = args[0]
+	}
+
+	client, err := f.Client()
+	if err != nil {
+		return err
+	}
+	o.client = client
+	o.namespace = f.Namespace()
+
+	return nil
+}
+
+func (o *Options) Run(c *cobra.Command, f client.Factory) error {
+	pipeline, err := o.client.KubesmithV1().Pipelines(o.namespace).Get(o.PipelineName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "could not retrieve pipeline %s", o.PipelineName)
+	}
+
+	if !pipeline.HasFailed() && !pipeline.IsCancelled() {
+		return fmt.Errorf("only failed or cancelled pipelines can be retried; pipeline %s is %s", o.PipelineName, pipeline.Status.Phase)
+	} else if pipeline.IsRetryRequested() {
+		return fmt.Errorf("a retry of pipeline %s was already requested", o.PipelineName)
+	}
+
+	strategy := api.RetryStrategy(api.RetryStrategyFailedJobs)
+	if o.FailedStage {
+		strategy = api.RetryStrategyFailedStage
+	}
+
+	attempt := pipeline.GetAttempt() + 1
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"retry": api.PipelineSpecRetry{
+				Attempt:  attempt,
+				Strategy: strategy,
+			},
+		},
+	})
+
+	if err != nil {
+		return errors.Wrap(err, "could not create retry patch")
+	}
+
+	if _, err := o.client.KubesmithV1().Pipelines(o.namespace).Patch(o.PipelineName, types.MergePatchType, patch); err != nil {
+		return errors.Wrapf(err, "could not retry pipeline %s", o.PipelineName)
+	}
+
+	fmt.Fprintf(o.out, "pipeline %s retry requested (attempt %d)\n", o.PipelineName, attempt)
+	return nil
+}
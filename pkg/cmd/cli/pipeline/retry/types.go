@@ -0,0 +1,16 @@
+package retry
+
+import (
+	"io"
+
+	kubesmithClient "github.com/kubesmith/kubesmith/pkg/generated/clientset/versioned"
+)
+
+type Options struct {
+	PipelineName string
+	FailedStage  bool
+
+	namespace string
+	out       io.Writer
+	client    kubesmithClient.Interface
+}
@@ -0,0 +1,39 @@
+package run
+
+import (
+	"os"
+
+	"github.com/kubesmith/kubesmith/pkg/client"
+	"github.com/kubesmith/kubesmith/pkg/cmd"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(f client.Factory) *cobra.Command {
+	o := NewOptions()
+
+	c := &cobra.Command{
+		Use:   "run -f FILE",
+		Short: "Submits a pipeline and optionally follows it until it finishes",
+		Long: `Submits a pipeline and optionally follows it until it finishes. When waiting or
+following, the exit code reflects the outcome of the pipeline: 0 when it succeeded,
+2 when it failed, 3 when it was cancelled and 1 when the pipeline could not be submitted or watched.`,
+		Run: func(c *cobra.Command, args []string) {
+			cmd.CheckError(o.Complete(args, f))
+			cmd.CheckError(o.Validate(c, args, f))
+			cmd.CheckError(o.Run(c, f))
+		},
+	}
+
+	o.BindFlags(c.Flags())
+
+	return c
+}
+
+func NewOptions() *Options {
+	return &Options{
+		out:       os.Stdout,
+		in:        os.Stdin,
+		phases:    map[string]string{},
+		streaming: map[string]bool{},
+	}
+}
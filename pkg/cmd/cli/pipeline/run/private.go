@@ -0,0 +1,275 @@
+package run
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/cmd"
+	"github.com/kubesmith/kubesmith/pkg/cmd/cli/logs"
+	"github.com/kubesmith/kubesmith/pkg/cmd/util/prefix"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func (o *Options) loadPipeline() error {
+	var data []byte
+	var err error
+
+	if o.FileName == "-" {
+		data, err = ioutil.ReadAll(o.in)
+	} else {
+		data, err = ioutil.ReadFile(o.FileName)
+	}
+
+	if err != nil {
+		return errors.Wrapf(err, "could not read pipeline file %s", o.FileName)
+	}
+
+	if err := yaml.Unmarshal(data, &o.pipeline); err != nil {
+		return errors.Wrapf(err, "could not parse pipeline file %s", o.FileName)
+	}
+
+	if kind := o.pipeline.Kind; kind != "" && kind != "Pipeline" {
+		return fmt.Errorf("expected a Pipeline in %s but found a %s", o.FileName, kind)
+	}
+
+	if namespace := o.pipeline.GetNamespace(); namespace != "" && namespace != o.namespace {
+		return fmt.Errorf("the namespace from %s (%s) does not match the namespace to run in (%s)", o.FileName, namespace, o.namespace)
+	}
+
+	o.pipeline.SetNamespace(o.namespace)
+
+	if o.Name != "" {
+		o.pipeline.SetName(o.Name)
+	}
+
+	if o.GenerateName || o.pipeline.GetName() == "" {
+		name := o.pipeline.GetName()
+		if name == "" {
+			name = "pipeline"
+		}
+
+		o.pipeline.SetName("")
+		o.pipeline.SetGenerateName(fmt.Sprintf("%s-", strings.TrimSuffix(name, "-")))
+	}
+
+	// the forge owns the status of the pipeline
+	o.pipeline.Status = api.PipelineStatus{}
+
+	return nil
+}
+
+func parseParams(params []string) (map[string]string, error) {
+	parsed := map[string]string{}
+
+	for _, param := range params {
+		parts := strings.SplitN(param, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("invalid param %q; params must be in the form KEY=VALUE", param)
+		}
+
+		parsed[strings.TrimSpace(parts[0])] = parts[1]
+	}
+
+	return parsed, nil
+}
+
+func (o *Options) waitForPipeline(name string) (*api.Pipeline, error) {
+	var pipeline *api.Pipeline
+
+	err := wait.PollImmediateInfinite(time.Second*2, func() (bool, error) {
+		var err error
+
+		pipeline, err = o.client.KubesmithV1().Pipelines(o.namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return false, errors.Wrap(err, "could not retrieve pipeline")
+		}
+
+		if o.Follow {
+			if err := o.printStageProgress(*pipeline); err != nil {
+				return false, err
+			}
+		}
+
+		o.printPipelineProgress(*pipeline)
+		return pipeline.HasFinished(), nil
+	})
+
+	return pipeline, err
+}
+
+func (o *Options) printPipelineProgress(pipeline api.Pipeline) {
+	status := string(pipeline.Status.Phase)
+	if pipeline.HasFinished() {
+		status = fmt.Sprintf("%s%s", status, formatDuration(pipeline.Status.StartTime, pipeline.Status.EndTime))
+	}
+
+	if o.hasStatusChanged(pipeline.GetName(), status) && status != "" {
+		o.printf("pipeline %s: %s\n", pipeline.GetName(), status)
+	}
+}
+
+func (o *Options) printStageProgress(pipeline api.Pipeline) error {
+	selector := labels.SelectorFromSet(labels.Set{
+		api.GetLabelKey("PipelineName"):      pipeline.GetName(),
+		api.GetLabelKey("PipelineNamespace"): pipeline.GetNamespace(),
+	})
+
+	stages, err := o.client.KubesmithV1().PipelineStages(o.namespace).List(metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return errors.Wrap(err, "could not list pipeline stages")
+	}
+
+	jobs, err := o.client.KubesmithV1().PipelineJobs(o.namespace).List(metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return errors.Wrap(err, "could not list pipeline jobs")
+	}
+
+	sort.Slice(stages.Items, func(i, j int) bool {
+		return stages.Items[i].GetStageIndex() < stages.Items[j].GetStageIndex()
+	})
+
+	sort.Slice(jobs.Items, func(i, j int) bool {
+		return jobs.Items[i].GetName() < jobs.Items[j].GetName()
+	})
+
+	for _, stage := range stages.Items {
+		index := stage.GetStageIndex()
+		status := string(stage.Status.Phase)
+		if stage.HasFinished() {
+			status = fmt.Sprintf("%s%s", status, formatDuration(stage.Status.StartTime, stage.Status.EndTime))
+		}
+
+		if o.hasStatusChanged(stage.GetName(), status) && status != "" {
+			o.printf("stage %d/%d %s: %s\n", index, len(pipeline.Spec.Stages), pipeline.GetStageNameByIndex(index), status)
+		}
+
+		for _, job := range jobs.Items {
+			if job.GetLabels()[api.GetLabelKey("PipelineStageName")] == stage.GetName() {
+				o.printJobProgress(pipeline, job)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (o *Options) printJobProgress(pipeline api.Pipeline, job api.PipelineJob) {
+	status := string(job.Status.Phase)
+	if job.HasFinished() {
+		status = fmt.Sprintf("%s%s", status, formatDuration(job.Status.StartTime, job.Status.EndTime))
+	}
+
+	if job.HasFailed() && job.Status.FailureReason != "" {
+		status = fmt.Sprintf("%s: %s", status, job.Status.FailureReason)
+	}
+
+	if summary := job.Status.TestReport.Summary; summary != "" {
+		status = fmt.Sprintf("%s [%s]", status, summary)
+	}
+
+	if o.hasStatusChanged(job.GetName(), status) && status != "" {
+		o.printf("  job %q: %s\n", job.Spec.Job.Name, status)
+	}
+
+	// trigger jobs run no pod so they have no log to stream
+	if (job.IsRunning() || job.HasFinished()) && !job.Spec.Job.IsTrigger() {
+		o.streamJobLog(pipeline, job)
+	}
+}
+
+func (o *Options) streamJobLog(pipeline api.Pipeline, job api.PipelineJob) {
+	if o.streaming[job.GetName()] {
+		return
+	}
+
+	o.streaming[job.GetName()] = true
+	o.streams.Add(1)
+
+	go func() {
+		defer o.streams.Done()
+
+		writer := prefix.NewWriter(fmt.Sprintf("[%s] ", job.Spec.Job.Name), o.out, &o.outLock)
+		defer writer.Flush()
+
+		logOptions := logs.NewOptions()
+		logOptions.Follow = true
+		logOptions.SetOutput(writer)
+
+		if err := logOptions.Complete([]string{pipeline.GetName(), job.GetName()}, o.factory); err != nil {
+			o.printf("[%s] could not stream log: %v\n", job.Spec.Job.Name, err)
+			return
+		}
+
+		if err := logOptions.Run(nil, o.factory); err != nil {
+			o.printf("[%s] could not stream log: %v\n", job.Spec.Job.Name, err)
+		}
+	}()
+}
+
+// waitForStreams waits for the logs that are still being streamed to catch up
+// with the pipeline; streams that don't end in time are given up on
+func (o *Options) waitForStreams(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		o.streams.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		o.printf("gave up waiting for the logs of the jobs after %s\n", timeout)
+	}
+}
+
+func (o *Options) getOutcome(pipeline api.Pipeline) error {
+	if summary := pipeline.Status.TestReport.Summary; summary != "" {
+		o.printf("tests: %s\n", summary)
+	}
+
+	if pipeline.HasFailed() {
+		return &cmd.ExitError{
+			Code:    exitCodePipelineFailed,
+			Message: fmt.Sprintf("pipeline %s failed: %s", pipeline.GetName(), pipeline.Status.FailureReason),
+		}
+	} else if pipeline.IsCancelled() {
+		return &cmd.ExitError{
+			Code:    exitCodePipelineCancelled,
+			Message: fmt.Sprintf("pipeline %s was cancelled", pipeline.GetName()),
+		}
+	}
+
+	return nil
+}
+
+func (o *Options) hasStatusChanged(key, status string) bool {
+	if o.phases[key] == status {
+		return false
+	}
+
+	o.phases[key] = status
+	return true
+}
+
+func (o *Options) printf(format string, args ...interface{}) {
+	o.outLock.Lock()
+	defer o.outLock.Unlock()
+
+	fmt.Fprintf(o.out, format, args...)
+}
+
+func formatDuration(start, end metav1.Time) string {
+	if start.IsZero() || end.IsZero() {
+		return ""
+	}
+
+	return fmt.Sprintf(" (%s)", end.Sub(start.Time).Round(time.Second))
+}
@@ -0,0 +1,84 @@
+package run
+
+import (
+	"github.com/kubesmith/kubesmith/pkg/client"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func (o *Options) BindFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&o.FileName, "filename", "f", "", "The file that contains the pipeline to submit; use - to read from stdin")
+	flags.StringVar(&o.Name, "name", "", "Overrides the name of the pipeline from the file")
+	flags.BoolVar(&o.GenerateName, "generate-name", false, "Appends a random suffix to the name of the pipeline so the same file can be submitted repeatedly")
+	flags.StringArrayVar(&o.Params, "param", []string{}, "Sets an environment variable (KEY=VALUE) for every job of the pipeline; may be repeated")
+	flags.BoolVar(&o.Wait, "wait", false, "Waits for the pipeline to finish and exits with a code that reflects its outcome")
+	flags.BoolVar(&o.Follow, "follow", false, "Prints the progress of the pipeline's stages and jobs and streams their logs; implies --wait")
+}
+
+func (o *Options) Validate(c *cobra.Command, args []string, f client.Factory) error {
+	if o.FileName == "" {
+		return errors.New("a pipeline file must be specified")
+	}
+
+	if err := o.pipeline.Validate(); err != nil {
+		return errors.Wrap(err, "invalid pipeline")
+	}
+
+	return nil
+}
+
+func (o *Options) Complete(args []string, f client.Factory) error {
+	o.factory = f
+	o.namespace = f.Namespace()
+
+	if o.FileName != "" {
+		if err := o.loadPipeline(); err != nil {
+			return err
+		}
+	}
+
+	params, err := parseParams(o.Params)
+	if err != nil {
+		return err
+	}
+
+	if len(params) > 0 && o.pipeline.Spec.Environment == nil {
+		o.pipeline.Spec.Environment = map[string]string{}
+	}
+
+	for key, value := range params {
+		o.pipeline.Spec.Environment[key] = value
+	}
+
+	client, err := f.Client()
+	if err != nil {
+		return err
+	}
+	o.client = client
+
+	return nil
+}
+
+func (o *Options) Run(c *cobra.Command, f client.Factory) error {
+	created, err := o.client.KubesmithV1().Pipelines(o.namespace).Create(&o.pipeline)
+	if err != nil {
+		return errors.Wrap(err, "could not create pipeline")
+	}
+
+	o.printf("pipeline %s created in namespace %s\n", created.GetName(), created.GetNamespace())
+
+	if !o.Wait && !o.Follow {
+		return nil
+	}
+
+	finished, err := o.waitForPipeline(created.GetName())
+	if err != nil {
+		return err
+	}
+
+	// let the logs that are still being streamed catch up
+	o.waitForStreams(streamsTimeout)
+
+	return o.getOutcome(*finished)
+}
@@ -0,0 +1,43 @@
+package run
+
+import (
+	"io"
+	gosync "sync"
+	"time"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/client"
+	kubesmithClient "github.com/kubesmith/kubesmith/pkg/generated/clientset/versioned"
+)
+
+const (
+	exitCodePipelineFailed    = 2
+	exitCodePipelineCancelled = 3
+
+	// streamsTimeout is how long the logs of the jobs are waited on once the
+	// pipeline has finished
+	streamsTimeout = 30 * time.Second
+)
+
+type Options struct {
+	FileName     string
+	Name         string
+	GenerateName bool
+	Params       []string
+	Wait         bool
+	Follow       bool
+
+	namespace string
+	pipeline  api.Pipeline
+	in        io.Reader
+	out       io.Writer
+	outLock   gosync.Mutex
+	factory   client.Factory
+	client    kubesmithClient.Interface
+
+	// phases holds the last printed status of the pipeline, its stages and
+	// its jobs so that only changes are printed
+	phases    map[string]string
+	streaming map[string]bool
+	streams   gosync.WaitGroup
+}
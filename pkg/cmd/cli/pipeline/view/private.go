@@ -0,0 +1,213 @@
+package view
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func getStageJobs(stage api.PipelineStage, jobs []api.PipelineJob) []api.PipelineJob {
+	stageJobs := []api.PipelineJob{}
+
+	for _, job := range jobs {
+		if job.GetLabels()[api.GetLabelKey("PipelineStageName")] == stage.GetName() {
+			stageJobs = append(stageJobs, job)
+		}
+	}
+
+	sort.Slice(stageJobs, func(i, j int) bool {
+		return stageJobs[i].GetName() < stageJobs[j].GetName()
+	})
+
+	return stageJobs
+}
+
+func getStageLine(stage StageView, last bool) treeLine {
+	phase, duration, reason := "Pending", "-", ""
+
+	if stage.PipelineStage != nil {
+		phase = getPhase(stage.PipelineStage.Status.Phase)
+		duration = GetDuration(stage.PipelineStage.Status.StartTime, stage.PipelineStage.Status.EndTime)
+		reason = stage.PipelineStage.Status.FailureReason
+	}
+
+	return treeLine{
+		name:    fmt.Sprintf("%s%d. %s", getTreeBranch(last), stage.Index, stage.Name),
+		columns: []string{phase, duration, reason},
+	}
+}
+
+func getJobLines(job api.PipelineJob, indent string, last bool) []treeLine {
+	lines := []treeLine{
+		treeLine{
+			name: fmt.Sprintf("%s%s%s", indent, getTreeBranch(last), job.Spec.Job.Name),
+			columns: []string{
+				getPhase(job.Status.Phase),
+				GetDuration(job.Status.StartTime, job.Status.EndTime),
+				job.Status.FailureReason,
+			},
+		},
+	}
+
+	detailIndent := fmt.Sprintf("%s%s", indent, getTreeIndent(last))
+	lines = append(lines, treeLine{name: fmt.Sprintf("%sresource: %s", detailIndent, job.GetName())})
+
+	if attempt := job.GetAttempt(); attempt > 1 {
+		lines = append(lines, treeLine{name: fmt.Sprintf("%sattempt: %d", detailIndent, attempt)})
+	}
+
+	if summary := job.Status.TestReport.Summary; summary != "" {
+		lines = append(lines, treeLine{name: fmt.Sprintf("%stests: %s", detailIndent, summary)})
+	}
+
+	for _, artifact := range getJobArtifacts(job) {
+		lines = append(lines, treeLine{name: fmt.Sprintf("%sartifact: %s", detailIndent, artifact)})
+	}
+
+	return lines
+}
+
+// printTreeLines aligns the columns of the lines that have them; lines
+// without columns are printed as they are so they don't widen the tree
+func printTreeLines(out io.Writer, lines []treeLine) {
+	widths := []int{}
+
+	for _, line := range lines {
+		if len(line.columns) == 0 {
+			continue
+		}
+
+		cells := append([]string{line.name}, line.columns...)
+		for i, cell := range cells {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+
+			if width := utf8.RuneCountInString(cell); width > widths[i] {
+				widths[i] = width
+			}
+		}
+	}
+
+	for _, line := range lines {
+		if len(line.columns) == 0 {
+			fmt.Fprintln(out, line.name)
+			continue
+		}
+
+		cells := append([]string{line.name}, line.columns...)
+		text := ""
+
+		for i, cell := range cells {
+			if i == len(cells)-1 {
+				text += cell
+				break
+			}
+
+			text += cell + strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)+2)
+		}
+
+		fmt.Fprintln(out, strings.TrimRight(text, " "))
+	}
+}
+
+func getJobArtifacts(job api.PipelineJob) []string {
+	artifacts := []string{}
+	storagePath := job.GetStoragePath()
+
+	if len(job.Spec.Job.Artifacts.OnSuccess) > 0 || len(job.Spec.Job.Artifacts.OnFail) > 0 {
+		artifacts = append(artifacts, fmt.Sprintf(
+			"%s/%s (onSuccess: %s; onFail: %s)",
+			storagePath,
+			job.GetArchiveFileName(),
+			getOptional(strings.Join(job.Spec.Job.Artifacts.OnSuccess, ", ")),
+			getOptional(strings.Join(job.Spec.Job.Artifacts.OnFail, ", ")),
+		))
+	}
+
+	if len(job.Spec.Job.Artifacts.Reports.JUnit) > 0 {
+		artifacts = append(artifacts, fmt.Sprintf(
+			"%s/%s (junit: %s)",
+			storagePath,
+			job.GetReportsArchiveFileName(),
+			strings.Join(job.Spec.Job.Artifacts.Reports.JUnit, ", "),
+		))
+	}
+
+	if job.HasSucceeded() || job.HasFailed() {
+		artifacts = append(artifacts, fmt.Sprintf("%s/%s", storagePath, job.GetLogFileName()))
+	}
+
+	return artifacts
+}
+
+func getStageColumn(pipeline api.Pipeline) string {
+	if pipeline.Status.StageIndex == 0 {
+		return "-"
+	}
+
+	return fmt.Sprintf(
+		"%d/%d %s",
+		pipeline.Status.StageIndex,
+		len(pipeline.Spec.Stages),
+		pipeline.GetStageNameByIndex(pipeline.Status.StageIndex),
+	)
+}
+
+func getTreeBranch(last bool) string {
+	if last {
+		return "└─ "
+	}
+
+	return "├─ "
+}
+
+func getTreeIndent(last bool) string {
+	if last {
+		return "   "
+	}
+
+	return "│  "
+}
+
+func getPhase(phase api.Phase) string {
+	if phase == api.PhaseEmpty {
+		return "Pending"
+	}
+
+	return string(phase)
+}
+
+func getOptional(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
+
+func getTimestamp(timestamp metav1.Time) string {
+	if timestamp.IsZero() {
+		return "-"
+	}
+
+	return fmt.Sprintf("%s (%s ago)", timestamp.Format(time.RFC3339), getShortDuration(time.Since(timestamp.Time)))
+}
+
+func getShortDuration(duration time.Duration) string {
+	if duration < time.Minute {
+		return fmt.Sprintf("%ds", int(duration.Seconds()))
+	} else if duration < time.Hour {
+		return fmt.Sprintf("%dm", int(duration.Minutes()))
+	} else if duration < time.Hour*48 {
+		return fmt.Sprintf("%dh", int(duration.Hours()))
+	}
+
+	return fmt.Sprintf("%dd", int(duration.Hours()/24))
+}
@@ -0,0 +1,202 @@
+package view
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ghodss/yaml"
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	kubesmithClient "github.com/kubesmith/kubesmith/pkg/generated/clientset/versioned"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func ValidateOutputFormat(format string, allowed ...string) error {
+	for _, value := range allowed {
+		if format == value {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid output format %q; valid formats are: %s", format, strings.Join(allowed, ", "))
+}
+
+func GetPipelineView(client kubesmithClient.Interface, pipeline api.Pipeline) (*PipelineView, error) {
+	selector := labels.SelectorFromSet(labels.Set{
+		api.GetLabelKey("PipelineName"):      pipeline.GetName(),
+		api.GetLabelKey("PipelineNamespace"): pipeline.GetNamespace(),
+	})
+
+	stages, err := client.KubesmithV1().PipelineStages(pipeline.GetNamespace()).List(metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list pipeline stages")
+	}
+
+	jobs, err := client.KubesmithV1().PipelineJobs(pipeline.GetNamespace()).List(metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list pipeline jobs")
+	}
+
+	view := &PipelineView{
+		Pipeline: pipeline,
+		Stages:   []StageView{},
+	}
+
+	for index, name := range pipeline.Spec.Stages {
+		stageView := StageView{
+			Index:        index + 1,
+			Name:         name,
+			PipelineJobs: []api.PipelineJob{},
+		}
+
+		for i := range stages.Items {
+			if stages.Items[i].GetStageIndex() == stageView.Index {
+				stageView.PipelineStage = &stages.Items[i]
+			}
+		}
+
+		if stageView.PipelineStage != nil {
+			stageView.PipelineJobs = getStageJobs(*stageView.PipelineStage, jobs.Items)
+		}
+
+		view.Stages = append(view.Stages, stageView)
+	}
+
+	return view, nil
+}
+
+func PrintObject(out io.Writer, obj interface{}, format string) error {
+	var data []byte
+	var err error
+
+	switch format {
+	case OutputFormatJSON:
+		data, err = json.MarshalIndent(obj, "", "  ")
+		data = append(data, '\n')
+	case OutputFormatYAML:
+		data, err = yaml.Marshal(obj)
+	default:
+		return fmt.Errorf("cannot print objects as %q", format)
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "could not marshal output")
+	}
+
+	_, err = out.Write(data)
+	return err
+}
+
+func PrintPipelineTable(out io.Writer, pipelines []api.Pipeline, wide bool) error {
+	writer := tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+
+	headers := []string{"NAME", "PHASE", "STAGE", "DURATION", "AGE"}
+	if wide {
+		headers = append(headers, "TESTS", "REASON")
+	}
+
+	fmt.Fprintln(writer, strings.Join(headers, "\t"))
+
+	for _, pipeline := range pipelines {
+		columns := []string{
+			pipeline.GetName(),
+			getPhase(pipeline.Status.Phase),
+			getStageColumn(pipeline),
+			GetDuration(pipeline.Status.StartTime, pipeline.Status.EndTime),
+			GetAge(pipeline.CreationTimestamp),
+		}
+
+		if wide {
+			columns = append(
+				columns,
+				getOptional(pipeline.Status.TestReport.Summary),
+				getOptional(pipeline.Status.FailureReason),
+			)
+		}
+
+		fmt.Fprintln(writer, strings.Join(columns, "\t"))
+	}
+
+	return writer.Flush()
+}
+
+func PrintPipelineTree(out io.Writer, view PipelineView) error {
+	pipeline := view.Pipeline
+	writer := tabwriter.NewWriter(out, 0, 8, 1, ' ', 0)
+
+	fmt.Fprintf(writer, "Name:\t%s\n", pipeline.GetName())
+	fmt.Fprintf(writer, "Namespace:\t%s\n", pipeline.GetNamespace())
+	fmt.Fprintf(writer, "Repo:\t%s\n", getOptional(pipeline.Spec.Workspace.Repo.URL))
+
+	if repo := pipeline.Spec.Workspace.Repo; repo.Ref != "" || pipeline.GetCommit() != "" {
+		fmt.Fprintf(writer, "Ref:\t%s\n", getOptional(repo.Ref))
+		fmt.Fprintf(writer, "Commit:\t%s\n", getOptional(pipeline.GetCommit()))
+
+		if repo.Author != "" {
+			fmt.Fprintf(writer, "Author:\t%s\n", repo.Author)
+		}
+
+		if repo.IsPullRequest() {
+			fmt.Fprintf(writer, "Pull Request:\t#%d %s\n", repo.PullRequest.Number, repo.PullRequest.Title)
+		}
+	}
+
+	fmt.Fprintf(writer, "Phase:\t%s\n", getPhase(pipeline.Status.Phase))
+
+	if attempt := pipeline.GetAttempt(); attempt > 1 {
+		fmt.Fprintf(writer, "Attempt:\t%d\n", attempt)
+	}
+
+	fmt.Fprintf(writer, "Started:\t%s\n", getTimestamp(pipeline.Status.StartTime))
+	fmt.Fprintf(writer, "Duration:\t%s\n", GetDuration(pipeline.Status.StartTime, pipeline.Status.EndTime))
+
+	if pipeline.Status.FailureReason != "" {
+		fmt.Fprintf(writer, "Reason:\t%s\n", pipeline.Status.FailureReason)
+	}
+
+	if pipeline.Status.TestReport.HasTests() {
+		fmt.Fprintf(writer, "Tests:\t%s\n", pipeline.Status.TestReport.Summary)
+	}
+
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out, "\nStages:")
+	lines := []treeLine{}
+
+	for i, stage := range view.Stages {
+		lastStage := i == len(view.Stages)-1
+		lines = append(lines, getStageLine(stage, lastStage))
+
+		for j, job := range stage.PipelineJobs {
+			lines = append(lines, getJobLines(job, getTreeIndent(lastStage), j == len(stage.PipelineJobs)-1)...)
+		}
+	}
+
+	printTreeLines(out, lines)
+	return nil
+}
+
+func GetDuration(start, end metav1.Time) string {
+	if start.IsZero() {
+		return "-"
+	} else if end.IsZero() {
+		return fmt.Sprintf("%s (running)", time.Since(start.Time).Round(time.Second))
+	}
+
+	return end.Sub(start.Time).Round(time.Second).String()
+}
+
+func GetAge(timestamp metav1.Time) string {
+	if timestamp.IsZero() {
+		return "-"
+	}
+
+	return getShortDuration(time.Since(timestamp.Time))
+}
@@ -0,0 +1,33 @@
+package view
+
+import (
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+)
+
+const (
+	OutputFormatTable = ""
+	OutputFormatWide  = "wide"
+	OutputFormatJSON  = "json"
+	OutputFormatYAML  = "yaml"
+)
+
+// PipelineView joins a pipeline with its stages and their jobs using the
+// labels the forge puts on every resource it creates for a pipeline
+type PipelineView struct {
+	Pipeline api.Pipeline `json:"pipeline"`
+	Stages   []StageView  `json:"stages"`
+}
+
+// StageView describes one of the stages of a pipeline; stages that were not
+// scheduled yet have no PipelineStage
+type StageView struct {
+	Index         int                `json:"index"`
+	Name          string             `json:"name"`
+	PipelineStage *api.PipelineStage `json:"pipelineStage,omitempty"`
+	PipelineJobs  []api.PipelineJob  `json:"pipelineJobs"`
+}
+
+type treeLine struct {
+	name    string
+	columns []string
+}
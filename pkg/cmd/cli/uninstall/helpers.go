@@ -0,0 +1,38 @@
+package uninstall
+
+import (
+	"os"
+
+	"github.com/kubesmith/kubesmith/pkg/client"
+	"github.com/kubesmith/kubesmith/pkg/cmd"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand(f client.Factory) *cobra.Command {
+	o := NewOptions()
+
+	c := &cobra.Command{
+		Use:   "uninstall",
+		Short: "Removes the forge and the kubesmith crds",
+		Long: `Removes the forge installed in the namespace given by --namespace together with
+its service account and roles, and the kubesmith custom resource definitions.
+Deleting the custom resource definitions deletes every pipeline in the cluster;
+use --keep-crds when other forges are still installed. The namespace is only
+deleted with --delete-namespace.`,
+		Run: func(c *cobra.Command, args []string) {
+			cmd.CheckError(o.Complete(args, f))
+			cmd.CheckError(o.Validate(c, args, f))
+			cmd.CheckError(o.Run(c, f))
+		},
+	}
+
+	o.BindFlags(c.Flags())
+
+	return c
+}
+
+func NewOptions() *Options {
+	return &Options{
+		out: os.Stdout,
+	}
+}
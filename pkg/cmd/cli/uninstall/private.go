@@ -0,0 +1,26 @@
+package uninstall
+
+import (
+	"github.com/kubesmith/kubesmith/pkg/install"
+)
+
+// getResources returns the installed resources in the reverse order of their
+// creation so that the forge stops before its permissions are revoked
+func (o *Options) getResources() []install.Resource {
+	installed := install.GetResources(install.Config{Namespace: o.namespace})
+	resources := []install.Resource{}
+
+	for i := len(installed) - 1; i >= 0; i-- {
+		resource := installed[i]
+
+		if resource.Kind == install.KindNamespace && !o.DeleteNamespace {
+			continue
+		} else if resource.Kind == install.KindCustomResourceDefinition && o.KeepCRDs {
+			continue
+		}
+
+		resources = append(resources, resource)
+	}
+
+	return resources
+}
@@ -0,0 +1,57 @@
+package uninstall
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubesmith/kubesmith/pkg/client"
+	"github.com/kubesmith/kubesmith/pkg/install"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func (o *Options) BindFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&o.KeepCRDs, "keep-crds", false, "Keeps the custom resource definitions and with them every pipeline")
+	flags.BoolVar(&o.DeleteNamespace, "delete-namespace", false, "Deletes the namespace of the forge and everything in it")
+	flags.BoolVar(&o.DryRun, "dry-run", false, "Prints the resources that would be deleted instead of deleting them")
+}
+
+func (o *Options) Validate(c *cobra.Command, args []string, f client.Factory) error {
+	return nil
+}
+
+func (o *Options) Complete(args []string, f client.Factory) error {
+	o.namespace = f.Namespace()
+
+	if o.DryRun {
+		return nil
+	}
+
+	kubeClient, err := f.KubeClient()
+	if err != nil {
+		return err
+	}
+	o.kubeClient = kubeClient
+
+	return nil
+}
+
+func (o *Options) Run(c *cobra.Command, f client.Factory) error {
+	for _, resource := range o.getResources() {
+		name := fmt.Sprintf("%s/%s", strings.ToLower(resource.Kind), resource.Object.GetName())
+
+		if o.DryRun {
+			fmt.Fprintf(o.out, "%s would be deleted\n", name)
+			continue
+		}
+
+		action, err := install.Delete(o.kubeClient.CoreV1().RESTClient(), resource)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(o.out, "%s %s\n", name, action)
+	}
+
+	return nil
+}
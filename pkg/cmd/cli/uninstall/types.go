@@ -0,0 +1,17 @@
+package uninstall
+
+import (
+	"io"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+type Options struct {
+	KeepCRDs        bool
+	DeleteNamespace bool
+	DryRun          bool
+
+	namespace  string
+	out        io.Writer
+	kubeClient kubernetes.Interface
+}
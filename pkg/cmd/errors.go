@@ -3,12 +3,32 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/golang/glog"
 )
 
+// ExitError is returned by commands that need to exit with a specific code
+type ExitError struct {
+	Code    int
+	Message string
+}
+
+func (e *ExitError) Error() string {
+	return e.Message
+}
+
 func CheckError(err error) {
 	if err != nil {
+		if exitErr, ok := err.(*ExitError); ok {
+			if exitErr.Message != "" {
+				fmt.Fprintln(os.Stderr, exitErr.Message)
+			}
+
+			glog.Flush()
+			os.Exit(exitErr.Code)
+		}
+
 		if err != context.Canceled {
 			glog.Exit(fmt.Errorf("An error occurred: %v", err))
 		}
@@ -5,7 +5,14 @@ import (
 
 	"github.com/kubesmith/kubesmith/pkg/client"
 	"github.com/kubesmith/kubesmith/pkg/cmd/cli/anvil"
+	"github.com/kubesmith/kubesmith/pkg/cmd/cli/artifacts"
 	"github.com/kubesmith/kubesmith/pkg/cmd/cli/forge"
+	"github.com/kubesmith/kubesmith/pkg/cmd/cli/install"
+	"github.com/kubesmith/kubesmith/pkg/cmd/cli/lint"
+	"github.com/kubesmith/kubesmith/pkg/cmd/cli/local"
+	"github.com/kubesmith/kubesmith/pkg/cmd/cli/logs"
+	"github.com/kubesmith/kubesmith/pkg/cmd/cli/pipeline"
+	"github.com/kubesmith/kubesmith/pkg/cmd/cli/uninstall"
 	"github.com/kubesmith/kubesmith/pkg/cmd/cli/version"
 	"github.com/spf13/cobra"
 )
@@ -25,11 +32,19 @@ Kubernetes -- giving you the full power of Kubernetes.`,
 
 	c.AddCommand(
 		anvil.NewCommand(f),
+		artifacts.NewCommand(f),
 		forge.NewCommand(f),
+		install.NewCommand(f),
+		lint.NewCommand(f),
+		local.NewCommand(f),
+		logs.NewCommand(f),
+		pipeline.NewCommand(f),
+		uninstall.NewCommand(f),
 		version.NewCommand(f),
 	)
 
-	// add the glog flags
+	// add the glog flags; these only affect the output of the kubernetes client
+	// libraries, kubesmith itself logs with the --log-format and --log-level flags
 	c.PersistentFlags().AddGoFlagSet(flag.CommandLine)
 
 	// Work around https://github.com/golang/glog/pull/13.
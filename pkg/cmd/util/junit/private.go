@@ -0,0 +1,68 @@
+package junit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+)
+
+func addSuiteToReport(suite testSuite, report *api.TestReport) {
+	for _, nested := range suite.Suites {
+		addSuiteToReport(nested, report)
+	}
+
+	for _, test := range suite.Cases {
+		addCaseToReport(test, report)
+	}
+}
+
+func addCaseToReport(test testCase, report *api.TestReport) {
+	report.Total++
+	report.Duration.Duration += parseDuration(test.Time)
+
+	if result := test.getFailure(); result != nil {
+		report.Failed++
+		report.AddFailure(test.getFullName(), result.getMessage())
+	} else if test.Skipped != nil {
+		report.Skipped++
+	} else {
+		report.Passed++
+	}
+}
+
+func parseDuration(value string) time.Duration {
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0
+	}
+
+	return time.Duration(seconds * float64(time.Second))
+}
+
+func (t *testCase) getFailure() *testResult {
+	if t.Failure != nil {
+		return t.Failure
+	}
+
+	return t.Error
+}
+
+func (t *testCase) getFullName() string {
+	if t.ClassName == "" {
+		return t.Name
+	}
+
+	return fmt.Sprintf("%s.%s", t.ClassName, t.Name)
+}
+
+func (r *testResult) getMessage() string {
+	if message := strings.TrimSpace(r.Message); message != "" {
+		return message
+	}
+
+	// fall back to the first line of the failure output
+	return strings.SplitN(strings.TrimSpace(r.Body), "\n", 2)[0]
+}
@@ -0,0 +1,38 @@
+package junit
+
+import (
+	"encoding/xml"
+	"os"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/pkg/errors"
+)
+
+func ParseFiles(filePaths []string) (api.TestReport, error) {
+	report := api.TestReport{}
+
+	for _, filePath := range filePaths {
+		if err := ParseFile(filePath, &report); err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+func ParseFile(filePath string, report *api.TestReport) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return errors.Wrapf(err, "could not open junit report %s", filePath)
+	}
+
+	defer file.Close()
+
+	suite := testSuite{}
+	if err := xml.NewDecoder(file).Decode(&suite); err != nil {
+		return errors.Wrapf(err, "could not parse junit report %s", filePath)
+	}
+
+	addSuiteToReport(suite, report)
+	return nil
+}
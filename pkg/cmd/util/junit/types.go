@@ -0,0 +1,22 @@
+package junit
+
+// testSuite is decoded from both <testsuites> and <testsuite> root elements,
+// since both may contain nested suites and only the latter contains test cases
+type testSuite struct {
+	Suites []testSuite `xml:"testsuite"`
+	Cases  []testCase  `xml:"testcase"`
+}
+
+type testCase struct {
+	Name      string      `xml:"name,attr"`
+	ClassName string      `xml:"classname,attr"`
+	Time      string      `xml:"time,attr"`
+	Failure   *testResult `xml:"failure"`
+	Error     *testResult `xml:"error"`
+	Skipped   *testResult `xml:"skipped"`
+}
+
+type testResult struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
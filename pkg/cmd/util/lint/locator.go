@@ -0,0 +1,174 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+)
+
+func newLocator(lines []string, lineOffset int) *locator {
+	l := &locator{
+		positions:         map[string]Position{},
+		start:             Position{Line: lineOffset + 1, Column: 1},
+		frames:            []*locatorFrame{&locatorFrame{pending: true, keyIndent: -1}},
+		blockScalarIndent: -1,
+	}
+
+	startFound := false
+	for index, line := range lines {
+		content := strings.TrimLeft(line, " ")
+		indent := len(line) - len(content)
+
+		if l.blockScalarIndent >= 0 {
+			if strings.TrimSpace(content) == "" || indent > l.blockScalarIndent {
+				continue
+			}
+
+			l.blockScalarIndent = -1
+		}
+
+		if strings.TrimSpace(content) == "" || strings.HasPrefix(content, "#") {
+			continue
+		}
+
+		lineNumber := lineOffset + index + 1
+		if !startFound {
+			l.start = Position{Line: lineNumber, Column: indent + 1}
+			startFound = true
+		}
+
+		l.closeFrames(indent, isSequenceEntry(content))
+		l.addEntry(content, indent, lineNumber)
+	}
+
+	return l
+}
+
+// closeFrames pops the frames that a line at the given indent can not belong to
+func (l *locator) closeFrames(indent int, isSeq bool) {
+	top := l.frames[len(l.frames)-1]
+	if top.pending {
+		if indent > top.keyIndent || (isSeq && indent == top.keyIndent) {
+			top.pending = false
+			top.indent = indent
+			top.isSeq = isSeq
+			top.index = -1
+		} else {
+			l.frames = l.frames[:len(l.frames)-1]
+		}
+	}
+
+	for len(l.frames) > 1 {
+		top := l.frames[len(l.frames)-1]
+		if top.indent > indent || (top.indent == indent && top.isSeq != isSeq) {
+			l.frames = l.frames[:len(l.frames)-1]
+			continue
+		}
+
+		break
+	}
+}
+
+func (l *locator) addEntry(content string, column, lineNumber int) {
+	top := l.frames[len(l.frames)-1]
+
+	if isSequenceEntry(content) {
+		if !top.isSeq || top.indent != column {
+			return
+		}
+
+		top.index++
+		path := fmt.Sprintf("%s[%d]", top.path, top.index)
+		l.record(path, lineNumber, column)
+
+		rest := strings.TrimPrefix(content, "-")
+		value := strings.TrimLeft(rest, " ")
+		if value == "" || strings.HasPrefix(value, "#") {
+			l.frames = append(l.frames, &locatorFrame{path: path, pending: true, keyIndent: column})
+			return
+		}
+
+		// the entry itself starts a mapping or a sequence on the same line
+		childColumn := column + 1 + len(rest) - len(value)
+		l.frames = append(l.frames, &locatorFrame{
+			path:   path,
+			indent: childColumn,
+			isSeq:  isSequenceEntry(value),
+			index:  -1,
+		})
+
+		l.addEntry(value, childColumn, lineNumber)
+		return
+	}
+
+	key, value, ok := splitKey(content)
+	if !ok || top.isSeq || top.indent != column {
+		return
+	}
+
+	path := key
+	if top.path != "" {
+		path = fmt.Sprintf("%s.%s", top.path, key)
+	}
+
+	if _, ok := l.positions[path]; ok {
+		l.duplicates = append(l.duplicates, locatorDuplicate{
+			key:      key,
+			path:     path,
+			position: Position{Line: lineNumber, Column: column + 1},
+		})
+	}
+
+	l.record(path, lineNumber, column)
+
+	if value == "" || strings.HasPrefix(value, "#") {
+		l.frames = append(l.frames, &locatorFrame{path: path, pending: true, keyIndent: column})
+	} else if strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">") {
+		l.blockScalarIndent = column
+	}
+}
+
+func (l *locator) record(path string, lineNumber, column int) {
+	if _, ok := l.positions[path]; ok {
+		return
+	}
+
+	l.positions[path] = Position{Line: lineNumber, Column: column + 1}
+}
+
+func isSequenceEntry(content string) bool {
+	return content == "-" || strings.HasPrefix(content, "- ")
+}
+
+// splitKey splits a "key: value" line; quoted keys are unquoted and the
+// returned value is trimmed
+func splitKey(content string) (string, string, bool) {
+	if strings.HasPrefix(content, `"`) || strings.HasPrefix(content, "'") {
+		end := strings.Index(content[1:], content[:1])
+		if end < 0 {
+			return "", "", false
+		}
+
+		key := content[1 : end+1]
+		rest := content[end+2:]
+		if rest != ":" && !strings.HasPrefix(rest, ": ") {
+			return "", "", false
+		}
+
+		return key, strings.TrimSpace(rest[1:]), true
+	}
+
+	if strings.HasPrefix(content, "{") || strings.HasPrefix(content, "[") {
+		return "", "", false
+	}
+
+	if strings.HasSuffix(content, ":") {
+		return content[:len(content)-1], "", true
+	}
+
+	index := strings.Index(content, ": ")
+	if index < 0 {
+		return "", "", false
+	}
+
+	return content[:index], strings.TrimSpace(content[index+2:]), true
+}
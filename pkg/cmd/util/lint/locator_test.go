@@ -0,0 +1,160 @@
+package lint
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testLocatorDocument = `# a pipeline
+apiVersion: kubesmith.io/v1
+kind: Pipeline
+metadata:
+  name: app
+spec:
+  environment:
+    "QUOTED_KEY": value
+    'SINGLE': value
+  stages:
+  - build
+  - test
+  jobs:
+  - name: build
+    stage: build
+    configMapData:
+      script.sh: |
+        name: not-a-key
+        - not-an-entry
+    runner:
+    - make
+
+  -   name: test
+      stage: test
+      artifacts:
+        onSuccess:
+          - report.xml
+  - {name: inline, stage: test}
+  -
+    name: nested
+    stage: test
+  - - a nested sequence
+    - in a sequence
+`
+
+func TestLocatorLocate(t *testing.T) {
+	locator := newLocator(strings.Split(testLocatorDocument, "\n"), 0)
+
+	tests := []struct {
+		path     string
+		expected Position
+	}{
+		{path: "apiVersion", expected: Position{Line: 2, Column: 1}},
+		{path: "metadata.name", expected: Position{Line: 5, Column: 3}},
+		{path: "spec.environment.QUOTED_KEY", expected: Position{Line: 8, Column: 5}},
+		{path: "spec.environment.SINGLE", expected: Position{Line: 9, Column: 5}},
+		{path: "spec.stages[0]", expected: Position{Line: 11, Column: 3}},
+		{path: "spec.stages[1]", expected: Position{Line: 12, Column: 3}},
+		{path: "spec.jobs[0]", expected: Position{Line: 14, Column: 3}},
+		{path: "spec.jobs[0].name", expected: Position{Line: 14, Column: 5}},
+		{path: "spec.jobs[0].configMapData.script.sh", expected: Position{Line: 17, Column: 7}},
+		{path: "spec.jobs[0].runner[0]", expected: Position{Line: 21, Column: 5}},
+		{path: "spec.jobs[1].name", expected: Position{Line: 23, Column: 7}},
+		{path: "spec.jobs[1].artifacts.onSuccess[0]", expected: Position{Line: 27, Column: 11}},
+		{path: "spec.jobs[3].name", expected: Position{Line: 30, Column: 5}},
+		{path: "spec.jobs[4][1]", expected: Position{Line: 33, Column: 5}},
+
+		// the contents of block scalars and flow style yaml fall back to the
+		// closest parent
+		{path: "spec.jobs[0].configMapData.script.sh.name", expected: Position{Line: 17, Column: 7}},
+		{path: "spec.jobs[2].name", expected: Position{Line: 28, Column: 3}},
+
+		// paths that don't exist fall back to the closest parent and then to
+		// the start of the document
+		{path: "spec.jobs[0].image", expected: Position{Line: 14, Column: 3}},
+		{path: "spec.templates[0].name", expected: Position{Line: 6, Column: 1}},
+		{path: "status", expected: Position{Line: 2, Column: 1}},
+		{path: "", expected: Position{Line: 2, Column: 1}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			if position := locator.Locate(test.path); position != test.expected {
+				t.Errorf("expected %s at %+v, got %+v", test.path, test.expected, position)
+			}
+		})
+	}
+}
+
+func TestLocatorLineOffset(t *testing.T) {
+	// the documents after the first one of a file start at an offset
+	locator := newLocator([]string{"", "kind: Pipeline", "spec:", "  stages:", "  - build"}, 10)
+
+	tests := map[string]Position{
+		"kind":           {Line: 12, Column: 1},
+		"spec.stages[0]": {Line: 15, Column: 3},
+		"metadata":       {Line: 12, Column: 1},
+	}
+
+	for path, expected := range tests {
+		if position := locator.Locate(path); position != expected {
+			t.Errorf("expected %s at %+v, got %+v", path, expected, position)
+		}
+	}
+}
+
+func TestLocatorDuplicates(t *testing.T) {
+	lines := strings.Split(`kind: Pipeline
+spec:
+  stages:
+  - build
+  jobs:
+  - name: build
+    script: |
+      name: not-a-key
+    image: alpine
+    image: golang
+  - name: test
+    image: alpine
+  stages:
+  - test
+kind: Pipeline`, "\n")
+
+	expected := []locatorDuplicate{
+		{key: "image", path: "spec.jobs[0].image", position: Position{Line: 10, Column: 5}},
+		{key: "stages", path: "spec.stages", position: Position{Line: 13, Column: 3}},
+		{key: "kind", path: "kind", position: Position{Line: 15, Column: 1}},
+	}
+
+	if duplicates := newLocator(lines, 0).duplicates; !reflect.DeepEqual(duplicates, expected) {
+		t.Errorf("expected duplicates %+v, got %+v", expected, duplicates)
+	}
+}
+
+func TestSplitKey(t *testing.T) {
+	tests := []struct {
+		content string
+		key     string
+		value   string
+		ok      bool
+	}{
+		{content: "name: build", key: "name", value: "build", ok: true},
+		{content: "jobs:", key: "jobs", value: "", ok: true},
+		{content: "url: https://example.com:8080/repo.git", key: "url", value: "https://example.com:8080/repo.git", ok: true},
+		{content: `"quoted: key": value`, key: "quoted: key", value: "value", ok: true},
+		{content: "'single':", key: "single", value: "", ok: true},
+		{content: `"unterminated: value`, ok: false},
+		{content: `"key"value`, ok: false},
+		{content: "{name: inline}", ok: false},
+		{content: "[a, b]", ok: false},
+		{content: "just a value", ok: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.content, func(t *testing.T) {
+			key, value, ok := splitKey(test.content)
+			if ok != test.ok || key != test.key || value != test.value {
+				t.Errorf("expected (%q, %q, %t), got (%q, %q, %t)", test.key, test.value, test.ok, key, value, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,476 @@
+package lint
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// maxGenerateNameLength leaves room for the random suffix kubernetes appends
+// to generated names while keeping them usable as label values
+const maxGenerateNameLength = validation.LabelValueMaxLength - 5
+
+var yamlErrorLine = regexp.MustCompile(`line (\d+)`)
+
+func splitDocuments(data []byte) []document {
+	documents := []document{}
+	lines := strings.Split(string(data), "\n")
+	start := 0
+
+	for index := 0; index <= len(lines); index++ {
+		if index < len(lines) {
+			line := strings.TrimRight(lines[index], " \r")
+			if line != "---" && !strings.HasPrefix(line, "--- ") {
+				continue
+			}
+		}
+
+		docLines := lines[start:index]
+		if hasContent(docLines) {
+			documents = append(documents, document{
+				data:       []byte(strings.Join(docLines, "\n")),
+				lines:      docLines,
+				lineOffset: start,
+			})
+		}
+
+		start = index + 1
+	}
+
+	return documents
+}
+
+func hasContent(lines []string) bool {
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func lintDocument(doc document) []Issue {
+	l := &linter{
+		locator: newLocator(doc.lines, doc.lineOffset),
+		issues:  []Issue{},
+	}
+
+	if err := yaml.Unmarshal(doc.data, &l.raw); err != nil {
+		position := l.locator.start
+		if match := yamlErrorLine.FindStringSubmatch(err.Error()); match != nil {
+			line, _ := strconv.Atoi(match[1])
+			position = Position{Line: doc.lineOffset + line, Column: 1}
+		}
+
+		return []Issue{{Line: position.Line, Column: position.Column, Severity: SeverityError, Message: err.Error()}}
+	}
+
+	l.checkDuplicateKeys()
+	l.checkUnknownFields(l.raw, reflect.TypeOf(api.Pipeline{}), "")
+
+	if err := yaml.Unmarshal(doc.data, &l.pipeline); err != nil {
+		l.addError("", "could not decode pipeline: %v", err)
+		return l.issues
+	}
+
+	l.checkTypeMeta()
+	l.checkObjectMeta()
+	l.checkWorkspace()
+
+	// the templates, stages and jobs of a pipeline file are only known once
+	// the repo is cloned
+	if l.pipeline.NeedsPipelineFile() {
+		l.checkPipelinePath()
+	} else {
+		l.checkStages()
+		l.checkTemplates()
+		l.checkJobs()
+	}
+
+	l.checkEnvironment("spec.environment", l.pipeline.Spec.Environment)
+
+	// anything the checks above missed is still caught by the validation the
+	// controllers run
+	if !HasErrors(l.issues) {
+		if err := l.pipeline.Validate(); err != nil {
+			l.addError("spec", "%v", err)
+		}
+	}
+
+	return l.issues
+}
+
+func (l *linter) addIssue(severity Severity, path, format string, args ...interface{}) {
+	position := l.locator.Locate(path)
+
+	l.issues = append(l.issues, Issue{
+		Path:     path,
+		Line:     position.Line,
+		Column:   position.Column,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (l *linter) addError(path, format string, args ...interface{}) {
+	l.addIssue(SeverityError, path, format, args...)
+}
+
+func (l *linter) addWarning(path, format string, args ...interface{}) {
+	l.addIssue(SeverityWarning, path, format, args...)
+}
+
+// checkDuplicateKeys reports the keys that are defined more than once in a
+// mapping; everything but the last of them is silently dropped when decoding
+func (l *linter) checkDuplicateKeys() {
+	for _, duplicate := range l.locator.duplicates {
+		first := l.locator.Locate(duplicate.path)
+
+		l.issues = append(l.issues, Issue{
+			Path:     duplicate.path,
+			Line:     duplicate.position.Line,
+			Column:   duplicate.position.Column,
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("duplicate key %q replaces the one at line %d", duplicate.key, first.Line),
+		})
+	}
+}
+
+// checkUnknownFields walks the decoded document alongside the type it is
+// decoded into and reports every key that the type has no field for
+func (l *linter) checkUnknownFields(value interface{}, t reflect.Type, path string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			return
+		}
+
+		fields := getJSONFields(t)
+		for _, key := range getSortedKeys(values) {
+			fieldPath := joinPath(path, key)
+			field, ok := fields[key]
+			if !ok {
+				l.addError(fieldPath, "unknown field %q", key)
+				continue
+			}
+
+			l.checkUnknownFields(values[key], field, fieldPath)
+		}
+	case reflect.Slice:
+		values, ok := value.([]interface{})
+		if !ok {
+			return
+		}
+
+		for index, item := range values {
+			l.checkUnknownFields(item, t.Elem(), fmt.Sprintf("%s[%d]", path, index))
+		}
+	case reflect.Map:
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			return
+		}
+
+		for _, key := range getSortedKeys(values) {
+			l.checkUnknownFields(values[key], t.Elem(), joinPath(path, key))
+		}
+	}
+}
+
+func getJSONFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		if name == "" && field.Anonymous {
+			for key, value := range getJSONFields(field.Type) {
+				fields[key] = value
+			}
+
+			continue
+		} else if name == "" {
+			name = field.Name
+		}
+
+		fields[name] = field.Type
+	}
+
+	return fields
+}
+
+func getSortedKeys(values map[string]interface{}) []string {
+	keys := []string{}
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return fmt.Sprintf("%s.%s", path, key)
+}
+
+func (l *linter) checkTypeMeta() {
+	if apiVersion := l.pipeline.APIVersion; apiVersion != "" && apiVersion != api.SchemeGroupVersion.String() {
+		l.addError("apiVersion", "apiVersion must be %s; found %s", api.SchemeGroupVersion.String(), apiVersion)
+	}
+
+	if kind := l.pipeline.Kind; kind != "" && kind != "Pipeline" {
+		l.addError("kind", "kind must be Pipeline; found %s", kind)
+	}
+}
+
+func (l *linter) checkObjectMeta() {
+	// the name of the pipeline ends up in the labels of everything it creates
+	if name := l.pipeline.GetName(); name != "" {
+		for _, message := range validation.IsDNS1123Subdomain(name) {
+			l.addError("metadata.name", "invalid pipeline name %q: %s", name, message)
+		}
+
+		for _, message := range validation.IsValidLabelValue(name) {
+			l.addError("metadata.name", "invalid pipeline name %q: it is used as a label value and %s", name, message)
+		}
+	}
+
+	if generateName := l.pipeline.GetGenerateName(); len(generateName) > maxGenerateNameLength {
+		l.addError("metadata.generateName", "generateName must be no more than %d characters so that generated names fit in a label value", maxGenerateNameLength)
+	}
+}
+
+func (l *linter) checkWorkspace() {
+	if err := l.pipeline.ValidateWorkspace(); err != nil {
+		l.addError("spec.workspace.repo", "%v", err)
+	}
+}
+
+func (l *linter) checkPipelinePath() {
+	if err := l.pipeline.ValidatePipelinePath(); err != nil {
+		l.addError("spec.pipelinePath", "%v", err)
+	}
+
+	pipelinePath := l.pipeline.Spec.PipelinePath
+	if len(l.pipeline.Spec.Templates) > 0 {
+		l.addWarning("spec.templates", "templates are replaced by the ones of the pipeline file %s", pipelinePath)
+	}
+
+	if len(l.pipeline.Spec.Stages) > 0 {
+		l.addWarning("spec.stages", "stages are replaced by the ones of the pipeline file %s", pipelinePath)
+	}
+
+	if l.pipeline.Spec.Finally != "" {
+		l.addWarning("spec.finally", "the finally stage is replaced by the one of the pipeline file %s", pipelinePath)
+	}
+
+	if len(l.pipeline.Spec.Jobs) > 0 {
+		l.addWarning("spec.jobs", "jobs are replaced by the ones of the pipeline file %s", pipelinePath)
+	}
+}
+
+func (l *linter) checkStages() {
+	if len(l.pipeline.Spec.Stages) == 0 {
+		l.addError("spec.stages", "pipeline must have at least 1 stage specified")
+		return
+	}
+
+	seen := map[string]bool{}
+	for index, stage := range l.pipeline.Spec.Stages {
+		path := fmt.Sprintf("spec.stages[%d]", index)
+		key := strings.ToLower(stage)
+
+		if stage == "" {
+			l.addError(path, "stage name must not be empty")
+			continue
+		} else if seen[key] {
+			l.addError(path, "duplicate stage %q", stage)
+			continue
+		}
+
+		seen[key] = true
+		if len(l.getStageJobs(key)) == 0 {
+			l.addError(path, "stage %q has no jobs and can never run", stage)
+		}
+	}
+
+	finally := l.pipeline.Spec.Finally
+	if finally != "" && strings.ToLower(finally) != strings.ToLower(l.pipeline.Spec.Stages[len(l.pipeline.Spec.Stages)-1]) {
+		l.addError("spec.finally", "finally stage %q must be the last of the pipeline stages", finally)
+	}
+}
+
+func (l *linter) getStageJobs(stage string) []api.PipelineSpecJob {
+	jobs := []api.PipelineSpecJob{}
+
+	for _, job := range l.pipeline.Spec.Jobs {
+		if strings.ToLower(job.Stage) == stage {
+			jobs = append(jobs, job)
+		}
+	}
+
+	return jobs
+}
+
+func (l *linter) checkTemplates() {
+	seen := map[string]bool{}
+	used := map[string]bool{}
+
+	for _, job := range l.pipeline.Spec.Jobs {
+		for _, extend := range job.Extends {
+			used[strings.ToLower(extend)] = true
+		}
+	}
+
+	for index, template := range l.pipeline.Spec.Templates {
+		path := fmt.Sprintf("spec.templates[%d]", index)
+		key := strings.ToLower(template.Name)
+
+		if template.Name == "" {
+			l.addError(path, "template name must not be empty")
+		} else if seen[key] {
+			l.addError(joinPath(path, "name"), "duplicate template %q", template.Name)
+		} else if !used[key] {
+			l.addWarning(joinPath(path, "name"), "template %q is not extended by any job", template.Name)
+		}
+
+		seen[key] = true
+		l.checkEnvironment(joinPath(path, "environment"), template.Environment)
+		l.checkConfigMapData(joinPath(path, "configMapData"), template.ConfigMapData)
+		l.checkArtifacts(joinPath(path, "artifacts"), template.Artifacts)
+	}
+}
+
+func (l *linter) checkJobs() {
+	seen := map[string]bool{}
+	stages := map[string]bool{}
+	templates := map[string]bool{}
+
+	for _, stage := range l.pipeline.Spec.Stages {
+		stages[strings.ToLower(stage)] = true
+	}
+
+	for _, template := range l.pipeline.Spec.Templates {
+		templates[strings.ToLower(template.Name)] = true
+	}
+
+	expandedJobs := l.pipeline.GetExpandedJobs()
+	for index, job := range l.pipeline.Spec.Jobs {
+		path := fmt.Sprintf("spec.jobs[%d]", index)
+		name := job.Name
+		key := strings.ToLower(job.Name)
+
+		if job.Name == "" {
+			l.addError(path, "job name must not be empty")
+			name = fmt.Sprintf("#%d", index+1)
+		} else if seen[key] {
+			l.addError(joinPath(path, "name"), "duplicate job %q", job.Name)
+		}
+
+		seen[key] = true
+
+		if job.Stage == "" {
+			l.addError(path, "job %q must specify a stage", name)
+		} else if !stages[strings.ToLower(job.Stage)] {
+			l.addError(joinPath(path, "stage"), "job %q is in unknown stage %q", name, job.Stage)
+		}
+
+		for extendIndex, extend := range job.Extends {
+			if !templates[strings.ToLower(extend)] {
+				l.addError(fmt.Sprintf("%s.extends[%d]", path, extendIndex), "job %q extends unknown template %q", name, extend)
+			}
+		}
+
+		expanded := expandedJobs[index]
+		if expanded.IsTrigger() {
+			if expanded.Image != "" || len(expanded.Command) > 0 || len(expanded.Args) > 0 || len(expanded.Runner) > 0 {
+				l.addError(path, "trigger job %q must not have an image, command/args or runner", name)
+			}
+
+			if err := expanded.Trigger.Validate(); err != nil {
+				l.addError(joinPath(path, "trigger"), "job %q: %v", name, err)
+			}
+		} else if expanded.Image == "" {
+			l.addError(path, "job %q has no image; set one on the job or on a template it extends", name)
+		}
+
+		if (len(expanded.Command) > 0 || len(expanded.Args) > 0) && len(expanded.Runner) > 0 {
+			l.addError(path, "job %q must have either command/args or runner specified; not both", name)
+		}
+
+		l.checkEnvironment(joinPath(path, "environment"), job.Environment)
+		l.checkConfigMapData(joinPath(path, "configMapData"), job.ConfigMapData)
+		l.checkArtifacts(joinPath(path, "artifacts"), job.Artifacts)
+	}
+}
+
+func (l *linter) checkEnvironment(path string, environment map[string]string) {
+	for _, key := range getSortedStringKeys(environment) {
+		for _, message := range validation.IsEnvVarName(key) {
+			l.addError(joinPath(path, key), "invalid environment variable name %q: %s", key, message)
+		}
+	}
+}
+
+func (l *linter) checkConfigMapData(path string, data map[string]string) {
+	for _, key := range getSortedStringKeys(data) {
+		for _, message := range validation.IsConfigMapKey(key) {
+			l.addError(joinPath(path, key), "invalid configMapData key %q: %s", key, message)
+		}
+	}
+}
+
+func (l *linter) checkArtifacts(path string, artifacts api.PipelineJobArtifacts) {
+	l.checkGlobs(joinPath(path, "onSuccess"), artifacts.OnSuccess)
+	l.checkGlobs(joinPath(path, "onFail"), artifacts.OnFail)
+	l.checkGlobs(joinPath(path, "reports.junit"), artifacts.Reports.JUnit)
+}
+
+func (l *linter) checkGlobs(path string, globs []string) {
+	for index, glob := range globs {
+		globPath := fmt.Sprintf("%s[%d]", path, index)
+
+		if strings.TrimSpace(glob) == "" {
+			l.addError(globPath, "artifact path must not be empty")
+		} else if _, err := filepath.Match(glob, ""); err != nil {
+			l.addError(globPath, "invalid artifact glob %q: %v", glob, err)
+		} else if strings.Contains(glob, ",") {
+			// artifact paths are handed to the sidecar as a comma separated list
+			l.addError(globPath, "artifact glob %q must not contain a comma", glob)
+		}
+	}
+}
+
+func getSortedStringKeys(values map[string]string) []string {
+	keys := []string{}
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,61 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Lint checks every pipeline document in data and returns the issues found,
+// ordered by their position in data
+func Lint(data []byte) []Issue {
+	issues := []Issue{}
+
+	for _, doc := range splitDocuments(data) {
+		issues = append(issues, lintDocument(doc)...)
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Line != issues[j].Line {
+			return issues[i].Line < issues[j].Line
+		}
+
+		return issues[i].Column < issues[j].Column
+	})
+
+	return issues
+}
+
+// HasErrors returns true if any of the issues is an error (and not a warning)
+func HasErrors(issues []Issue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String formats the issue as line:column: severity: message so that it can
+// be prefixed with a file name
+func (i Issue) String() string {
+	return fmt.Sprintf("%d:%d: %s: %s", i.Line, i.Column, i.Severity, i.Message)
+}
+
+// Locate returns the position of the field with the given path, falling back
+// to its closest parent that could be located
+func (l *locator) Locate(path string) Position {
+	for {
+		if position, ok := l.positions[path]; ok {
+			return position
+		}
+
+		index := strings.LastIndexAny(path, ".[")
+		if index <= 0 {
+			return l.start
+		}
+
+		path = path[:index]
+	}
+}
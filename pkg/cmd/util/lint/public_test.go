@@ -0,0 +1,120 @@
+package lint
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testLintWorkspace = `apiVersion: kubesmith.io/v1
+kind: Pipeline
+metadata:
+  name: app
+spec:
+  workspace:
+    repo:
+      url: git@github.com:kubesmith/app.git
+      ssh:
+        secret:
+          name: ssh
+          key: id_rsa
+`
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		expected []string
+	}{
+		{
+			name: "stages and jobs",
+			spec: `  stages:
+  - build
+  jobs:
+  - name: build
+    stage: build
+    image: alpine
+    runner:
+    - make
+`,
+			expected: []string{},
+		},
+		{
+			name: "pipeline without stages",
+			spec: `  jobs: []
+`,
+			expected: []string{"5:1: error: pipeline must have at least 1 stage specified"},
+		},
+		{
+			name: "pipeline file",
+			spec: `  pipelinePath: ci/pipeline.yml
+`,
+			expected: []string{},
+		},
+		{
+			name: "pipeline file outside of the repo",
+			spec: `  pipelinePath: ../pipeline.yml
+`,
+			expected: []string{`13:3: error: pipeline path "../pipeline.yml" must be relative to the repo`},
+		},
+		{
+			name: "pipeline file replaces the stages and jobs",
+			spec: `  pipelinePath: .kubesmith.yml
+  stages:
+  - build
+  jobs:
+  - name: build
+    stage: build
+`,
+			expected: []string{
+				"14:3: warning: stages are replaced by the ones of the pipeline file .kubesmith.yml",
+				"16:3: warning: jobs are replaced by the ones of the pipeline file .kubesmith.yml",
+			},
+		},
+		{
+			name: "finally stage that is not the last stage",
+			spec: `  stages:
+  - build
+  - cleanup
+  finally: build
+  jobs:
+  - name: build
+    stage: build
+    image: alpine
+  - name: cleanup
+    stage: cleanup
+    image: alpine
+`,
+			expected: []string{`16:3: error: finally stage "build" must be the last of the pipeline stages`},
+		},
+		{
+			name: "duplicate key",
+			spec: `  stages:
+  - build
+  jobs:
+  - name: build
+    stage: build
+    image: alpine
+    artifacts:
+      onSuccess:
+      - build
+    artifacts:
+      onFail:
+      - build
+`,
+			expected: []string{`22:5: error: duplicate key "artifacts" replaces the one at line 19`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			issues := []string{}
+			for _, issue := range Lint([]byte(testLintWorkspace + test.spec)) {
+				issues = append(issues, issue.String())
+			}
+
+			if !reflect.DeepEqual(issues, test.expected) {
+				t.Errorf("expected issues %q, got %q", test.expected, issues)
+			}
+		})
+	}
+}
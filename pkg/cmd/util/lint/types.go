@@ -0,0 +1,77 @@
+package lint
+
+import (
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+type Severity string
+
+// Issue is a single problem found in a pipeline document; Path is the field
+// the issue is about (e.g. spec.jobs[2].extends[0]) and Line/Column point at
+// it in the linted file
+type Issue struct {
+	Path     string
+	Line     int
+	Column   int
+	Severity Severity
+	Message  string
+}
+
+// Position is a 1-based line and column in a linted file
+type Position struct {
+	Line   int
+	Column int
+}
+
+type linter struct {
+	pipeline api.Pipeline
+	raw      interface{}
+	locator  *locator
+	issues   []Issue
+}
+
+// locator maps the paths of the fields of a yaml document to the position
+// of their keys (or sequence entries) in the file; it understands the block
+// style yaml that pipelines are written in and falls back to the closest
+// parent for anything else
+type locator struct {
+	positions map[string]Position
+	start     Position
+	frames    []*locatorFrame
+
+	// duplicates are the keys that were already defined in their mapping;
+	// yaml keeps only the last of them
+	duplicates []locatorDuplicate
+
+	// blockScalarIndent is the indent of the key owning a block scalar (| or >)
+	// that is being skipped; -1 when not in a block scalar
+	blockScalarIndent int
+}
+
+type locatorFrame struct {
+	path    string
+	indent  int
+	isSeq   bool
+	index   int
+	pending bool
+
+	// keyIndent is the indent of the key that opened a pending frame
+	keyIndent int
+}
+
+type locatorDuplicate struct {
+	key      string
+	path     string
+	position Position
+}
+
+type document struct {
+	data       []byte
+	lines      []string
+	lineOffset int
+}
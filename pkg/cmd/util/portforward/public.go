@@ -0,0 +1,88 @@
+package portforward
+
+import (
+	"bufio"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Start forwards a random local port to the given port of a service by running
+// `kubectl port-forward`; kubectlFlags select the cluster to talk to
+func Start(kubectlFlags []string, namespace, service string, port int) (*PortForward, error) {
+	kubectl, err := exec.LookPath("kubectl")
+	if err != nil {
+		return nil, errors.Wrap(err, "kubectl is needed to reach services inside the cluster")
+	}
+
+	args := append([]string{}, kubectlFlags...)
+	args = append(args, "port-forward", "--namespace", namespace, fmt.Sprintf("svc/%s", service), fmt.Sprintf(":%d", port))
+
+	p := &PortForward{
+		command: exec.Command(kubectl, args...),
+		stderr:  &lockedBuffer{},
+	}
+
+	stdout, err := p.command.StdoutPipe()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read the output of kubectl")
+	}
+
+	p.command.Stderr = p.stderr
+	if err := p.command.Start(); err != nil {
+		return nil, errors.Wrap(err, "could not start kubectl port-forward")
+	}
+
+	localPort := make(chan int, 1)
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			if match := forwardingLine.FindStringSubmatch(scanner.Text()); match != nil {
+				port, _ := strconv.Atoi(match[1])
+				localPort <- port
+			}
+		}
+
+		close(localPort)
+	}()
+
+	select {
+	case port, ok := <-localPort:
+		if !ok {
+			p.command.Wait()
+			return nil, fmt.Errorf("kubectl port-forward to svc/%s failed: %s", service, strings.TrimSpace(p.stderr.String()))
+		}
+
+		p.LocalPort = port
+		return p, nil
+	case <-time.After(startTimeout):
+		p.Stop()
+		return nil, fmt.Errorf("timed out waiting for kubectl port-forward to svc/%s", service)
+	}
+}
+
+// Stop ends the port forward
+func (p *PortForward) Stop() {
+	if p.command.Process != nil {
+		p.command.Process.Kill()
+		p.command.Wait()
+	}
+}
+
+func (b *lockedBuffer) Write(data []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.buffer.Write(data)
+}
+
+func (b *lockedBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.buffer.String()
+}
@@ -0,0 +1,27 @@
+package portforward
+
+import (
+	"bytes"
+	"os/exec"
+	"regexp"
+	gosync "sync"
+	"time"
+)
+
+// startTimeout is how long kubectl gets to establish the port forward
+const startTimeout = 30 * time.Second
+
+var forwardingLine = regexp.MustCompile(`Forwarding from 127\.0\.0\.1:(\d+)`)
+
+// PortForward is a running `kubectl port-forward` to a service in the cluster
+type PortForward struct {
+	LocalPort int
+
+	command *exec.Cmd
+	stderr  *lockedBuffer
+}
+
+type lockedBuffer struct {
+	lock   gosync.Mutex
+	buffer bytes.Buffer
+}
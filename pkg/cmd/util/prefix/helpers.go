@@ -0,0 +1,16 @@
+package prefix
+
+import (
+	"io"
+	gosync "sync"
+)
+
+// NewWriter returns a writer that prefixes every line with the given prefix;
+// writers that share an output must share the lock as well
+func NewWriter(prefix string, out io.Writer, lock *gosync.Mutex) *Writer {
+	return &Writer{
+		prefix: prefix,
+		out:    out,
+		lock:   lock,
+	}
+}
@@ -0,0 +1,12 @@
+package prefix
+
+import (
+	"fmt"
+)
+
+func (w *Writer) writeLine(line []byte) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	fmt.Fprintf(w.out, "%s%s", w.prefix, line)
+}
@@ -0,0 +1,29 @@
+package prefix
+
+import (
+	"bytes"
+)
+
+func (w *Writer) Write(data []byte) (int, error) {
+	w.buffer = append(w.buffer, data...)
+
+	for {
+		index := bytes.IndexByte(w.buffer, '\n')
+		if index < 0 {
+			break
+		}
+
+		w.writeLine(w.buffer[:index+1])
+		w.buffer = w.buffer[index+1:]
+	}
+
+	return len(data), nil
+}
+
+// Flush writes whatever is left in the buffer as a final line
+func (w *Writer) Flush() {
+	if len(w.buffer) > 0 {
+		w.writeLine(append(w.buffer, '\n'))
+		w.buffer = nil
+	}
+}
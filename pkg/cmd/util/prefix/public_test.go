@@ -0,0 +1,54 @@
+package prefix
+
+import (
+	"bytes"
+	gosync "sync"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	tests := []struct {
+		name     string
+		writes   []string
+		expected string
+	}{
+		{
+			name:     "every line is prefixed",
+			writes:   []string{"one\ntwo\n"},
+			expected: "[job] one\n[job] two\n",
+		},
+		{
+			name:     "partial lines are buffered until they are complete",
+			writes:   []string{"o", "ne\ntw", "o\n"},
+			expected: "[job] one\n[job] two\n",
+		},
+		{
+			name:     "the last partial line is written on flush",
+			writes:   []string{"one\ntwo"},
+			expected: "[job] one\n[job] two\n",
+		},
+		{
+			name:     "nothing is written when nothing was written",
+			writes:   []string{},
+			expected: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			writer := NewWriter("[job] ", out, &gosync.Mutex{})
+
+			for _, data := range test.writes {
+				if n, err := writer.Write([]byte(data)); err != nil || n != len(data) {
+					t.Fatalf("expected to write %d bytes, wrote %d: %v", len(data), n, err)
+				}
+			}
+
+			writer.Flush()
+			if out.String() != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package prefix
+
+import (
+	"io"
+	gosync "sync"
+)
+
+// Writer prefixes every line written to it; it's used to tell apart the output
+// of jobs that are printed at the same time. Writes are buffered until a full
+// line is available and every line is written while holding the shared lock
+type Writer struct {
+	prefix string
+	out    io.Writer
+	lock   *gosync.Mutex
+	buffer []byte
+}
@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"strings"
+)
+
+// getClusterService returns the service and namespace of hosts that are only
+// resolvable inside the cluster (e.g. minio.kubesmith.svc)
+func getClusterService(host, defaultNamespace string) (string, string, bool) {
+	for _, suffix := range []string{".svc.cluster.local", ".svc"} {
+		if !strings.HasSuffix(host, suffix) {
+			continue
+		}
+
+		parts := strings.Split(strings.TrimSuffix(host, suffix), ".")
+		if len(parts) == 1 {
+			return parts[0], defaultNamespace, true
+		}
+
+		return parts[0], parts[1], true
+	}
+
+	return "", "", false
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/cmd/util/portforward"
+	"github.com/kubesmith/kubesmith/pkg/s3"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Connect creates a client for the workspace storage of the pipeline using the
+// credentials stored in the cluster. Storage that is only reachable inside the
+// cluster (like the minio server a pipeline gets when it has no storage
+// configured) is reached through a kubectl port-forward; Close stops it.
+func Connect(pipeline api.Pipeline, kubeClient kubernetes.Interface, kubectlFlags []string, overrides Overrides) (*Connection, error) {
+	storage := pipeline.Spec.Workspace.Storage.S3
+	if storage.Host == "" || storage.BucketName == "" {
+		return nil, errors.New("the pipeline has no storage configured yet")
+	}
+
+	secret, err := kubeClient.CoreV1().Secrets(pipeline.GetNamespace()).Get(storage.Credentials.Secret.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch s3 credentials")
+	}
+
+	connection := &Connection{BucketName: storage.BucketName}
+	host, port, useSSL := storage.Host, storage.Port, storage.UseSSL
+
+	if overrides.Host != "" {
+		host = overrides.Host
+	} else if service, namespace, ok := getClusterService(host, pipeline.GetNamespace()); ok {
+		portForward, err := portforward.Start(kubectlFlags, namespace, service, port)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not reach the pipeline's storage")
+		}
+
+		connection.portForward = portForward
+		host, port = "127.0.0.1", portForward.LocalPort
+	}
+
+	if overrides.Port > 0 && connection.portForward == nil {
+		port = overrides.Port
+	}
+
+	client, err := s3.NewS3Client(
+		host,
+		port,
+		string(secret.Data[storage.Credentials.Secret.AccessKeyKey]),
+		string(secret.Data[storage.Credentials.Secret.SecretKeyKey]),
+		useSSL,
+	)
+
+	if err != nil {
+		connection.Close()
+		return nil, errors.Wrap(err, "could not create an s3 client")
+	}
+
+	connection.Client = client
+	return connection, nil
+}
+
+// Close stops the port forward of the connection, if any
+func (c *Connection) Close() {
+	if c.portForward != nil {
+		c.portForward.Stop()
+	}
+}
@@ -0,0 +1,21 @@
+package storage
+
+import (
+	"github.com/kubesmith/kubesmith/pkg/cmd/util/portforward"
+	"github.com/kubesmith/kubesmith/pkg/s3"
+)
+
+// Overrides replace the host and port of a pipeline's s3 server, e.g. when it
+// is exposed outside of the cluster; zero values keep the pipeline's own
+type Overrides struct {
+	Host string
+	Port int
+}
+
+// Connection is a client for the workspace storage of a pipeline
+type Connection struct {
+	Client     *s3.S3Client
+	BucketName string
+
+	portForward *portforward.PortForward
+}
@@ -0,0 +1,75 @@
+package commitstatus
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/logging"
+	"github.com/sirupsen/logrus"
+	coreInformersv1 "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// NewReporter creates a reporter reading the api tokens from the secrets of
+// the informer; it posts nothing until it's run
+func NewReporter(logger logrus.FieldLogger, secretInformer coreInformersv1.SecretInformer) *Reporter {
+	return &Reporter{
+		logger:       logger.WithField(logging.FieldController, "CommitStatus"),
+		queue:        workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(retryBaseDelay, retryMaxDelay), "CommitStatus"),
+		secretLister: secretInformer.Lister(),
+		secretSynced: secretInformer.Informer().HasSynced,
+		requests:     map[string]request{},
+		reported:     map[string]reportedStatus{},
+	}
+}
+
+// NewProvider creates the provider posting the commit statuses of the repo
+// with the token
+func NewProvider(repo api.WorkspaceRepo, token string) (Provider, error) {
+	host, path, err := getRepoPath(repo.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := repo.CommitStatus.Provider
+	if provider == "" {
+		switch host {
+		case "github.com":
+			provider = api.CommitStatusProviderGitHub
+		case "gitlab.com":
+			provider = api.CommitStatusProviderGitLab
+		default:
+			return nil, fmt.Errorf("commit status provider must be specified for %s", host)
+		}
+	}
+
+	apiURL := strings.TrimSuffix(repo.CommitStatus.APIURL, "/")
+	client := &http.Client{Timeout: requestTimeout}
+
+	switch provider {
+	case api.CommitStatusProviderGitHub:
+		if apiURL == "" && host == "github.com" {
+			apiURL = "https://api.github.com"
+		} else if apiURL == "" {
+			apiURL = fmt.Sprintf("https://%s/api/v3", host)
+		}
+
+		return &gitHubProvider{client: client, apiURL: apiURL, repo: path, token: token}, nil
+	case api.CommitStatusProviderGitLab:
+		if apiURL == "" {
+			apiURL = fmt.Sprintf("https://%s/api/v4", host)
+		}
+
+		return &gitLabProvider{client: client, apiURL: apiURL, repo: path, token: token}, nil
+	case api.CommitStatusProviderGitea:
+		if apiURL == "" {
+			apiURL = fmt.Sprintf("https://%s/api/v1", host)
+		}
+
+		return &giteaProvider{client: client, apiURL: apiURL, repo: path, token: token}, nil
+	}
+
+	return nil, fmt.Errorf("commit status provider %q is not supported", provider)
+}
@@ -0,0 +1,283 @@
+package commitstatus
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/logging"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+func (r *Reporter) report(pipeline api.Pipeline, statusContext string, state State, description string) {
+	repo := pipeline.Spec.Workspace.Repo
+	if !repo.ReportsCommitStatus() {
+		return
+	}
+
+	logger := r.logger.WithFields(logging.GetResourceFields(&pipeline)).WithFields(logrus.Fields{
+		"context": statusContext,
+		"state":   state,
+	})
+
+	// statuses are posted on commits; pipelines of a ref have nothing to
+	// post them on until the clone repo job resolved the commit
+	commit := pipeline.GetCommit()
+	if commit == "" {
+		logger.Debug("skipping commit status; pipeline has no commit")
+		return
+	}
+
+	targetURL := strings.NewReplacer(
+		"{namespace}", pipeline.GetNamespace(),
+		"{pipeline}", pipeline.GetName(),
+	).Replace(repo.CommitStatus.TargetURL)
+
+	status := Status{
+		Commit:      commit,
+		Context:     statusContext,
+		State:       state,
+		Description: truncate(description, maxDescriptionLength),
+		TargetURL:   targetURL,
+	}
+
+	key := fmt.Sprintf("%s#%s#%s", repo.URL, status.Commit, status.Context)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if pending, ok := r.requests[key]; ok && pending.status == status {
+		return
+	} else if !ok && r.reported[key].status == status {
+		return
+	}
+
+	logger.Debug("queueing commit status")
+	r.requests[key] = request{
+		namespace: pipeline.GetNamespace(),
+		repo:      repo,
+		status:    status,
+	}
+
+	r.queue.Add(key)
+}
+
+func (r *Reporter) runWorker() {
+	for r.processNextItem() {
+		//
+	}
+}
+
+func (r *Reporter) processNextItem() bool {
+	key, quit := r.queue.Get()
+	if quit {
+		return false
+	}
+	defer r.queue.Done(key)
+
+	r.lock.Lock()
+	req, ok := r.requests[key.(string)]
+	r.lock.Unlock()
+
+	if !ok {
+		r.queue.Forget(key)
+		return true
+	}
+
+	logger := r.logger.WithFields(logrus.Fields{
+		"commit":  req.status.Commit,
+		"context": req.status.Context,
+		"state":   req.status.State,
+	})
+
+	if err := r.send(req); err != nil {
+		if r.queue.NumRequeues(key) < maxRetries {
+			logger.Error(errors.Wrap(err, "could not post commit status; retrying"))
+			r.queue.AddRateLimited(key)
+			return true
+		}
+
+		logger.Error(errors.Wrap(err, "could not post commit status; dropping it"))
+		r.forgetRequest(key.(string), req)
+		r.queue.Forget(key)
+		return true
+	}
+
+	logger.Info("posted commit status")
+
+	r.lock.Lock()
+	r.reported[key.(string)] = reportedStatus{status: req.status, time: time.Now()}
+	r.lock.Unlock()
+
+	r.forgetRequest(key.(string), req)
+	r.queue.Forget(key)
+	r.pruneReported()
+
+	return true
+}
+
+func (r *Reporter) send(req request) error {
+	secret, err := r.secretLister.Secrets(req.namespace).Get(req.repo.CommitStatus.Secret.Name)
+	if err != nil {
+		return errors.Wrap(err, "could not get commit status secret")
+	}
+
+	token, ok := secret.Data[req.repo.CommitStatus.Secret.Key]
+	if !ok {
+		return fmt.Errorf("commit status secret does not have key %q", req.repo.CommitStatus.Secret.Key)
+	}
+
+	provider, err := NewProvider(req.repo, strings.TrimSpace(string(token)))
+	if err != nil {
+		return err
+	}
+
+	return provider.SetStatus(req.status)
+}
+
+// forgetRequest removes a request that was handled unless a newer status was
+// reported in the meantime
+func (r *Reporter) forgetRequest(key string, req request) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.requests[key].status == req.status {
+		delete(r.requests, key)
+	}
+}
+
+// pruneReported forgets the statuses that were posted a long time ago so that
+// the reporter doesn't grow with every commit it has seen
+func (r *Reporter) pruneReported() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := time.Now()
+	if now.Sub(r.lastPruned) < time.Hour {
+		return
+	}
+
+	for key, reported := range r.reported {
+		if now.Sub(reported.time) > reportedTTL {
+			delete(r.reported, key)
+		}
+	}
+
+	r.lastPruned = now
+}
+
+func (r *Reporter) setRunning(running bool) {
+	r.runningLock.Lock()
+	defer r.runningLock.Unlock()
+
+	r.running = running
+}
+
+func (r *Reporter) isRunning() bool {
+	r.runningLock.RLock()
+	defer r.runningLock.RUnlock()
+
+	return r.running
+}
+
+func (p *gitHubProvider) getState(state State) string {
+	switch state {
+	case StateRunning:
+		return "pending"
+	case StateCancelled:
+		return "error"
+	}
+
+	return string(state)
+}
+
+func (p *gitLabProvider) getState(state State) string {
+	switch state {
+	case StateFailure:
+		return "failed"
+	case StateCancelled:
+		return "canceled"
+	}
+
+	return string(state)
+}
+
+func (p *giteaProvider) getState(state State) string {
+	switch state {
+	case StateRunning:
+		return "pending"
+	case StateCancelled:
+		return "error"
+	}
+
+	return string(state)
+}
+
+// post sends the json body to the endpoint and returns the response body; any
+// status other than 2xx is an error
+func post(client *http.Client, endpoint string, header map[string]string, body []byte) (string, error) {
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", errors.Wrap(err, "could not create request")
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "could not send request")
+	}
+	defer resp.Body.Close()
+
+	response, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return string(response), fmt.Errorf("%s responded with %s: %s", req.URL.Host, resp.Status, truncate(strings.TrimSpace(string(response)), 200))
+	}
+
+	return string(response), nil
+}
+
+// getRepoPath returns the host and the path (without .git) of an https, ssh or
+// scp-like git url
+func getRepoPath(repoURL string) (string, string, error) {
+	host, path := "", ""
+
+	if strings.Contains(repoURL, "://") {
+		parsed, err := url.Parse(repoURL)
+		if err != nil {
+			return "", "", errors.Wrap(err, "could not parse repo url")
+		}
+
+		host, path = parsed.Hostname(), parsed.Path
+	} else if parts := strings.SplitN(repoURL, ":", 2); len(parts) == 2 {
+		host, path = parts[0], parts[1]
+
+		if index := strings.LastIndex(host, "@"); index >= 0 {
+			host = host[index+1:]
+		}
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || path == "" {
+		return "", "", fmt.Errorf("could not find the repo of url %q", repoURL)
+	}
+
+	return host, path, nil
+}
+
+func truncate(value string, length int) string {
+	if len(value) <= length {
+		return value
+	}
+
+	return value[:length-3] + "..."
+}
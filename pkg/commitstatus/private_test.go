@@ -0,0 +1,269 @@
+package commitstatus
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coreListersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// newTestReporter creates a reporter that finds the token secret and retries
+// without waiting
+func newTestReporter(t *testing.T) *Reporter {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	indexer.Add(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "token", Namespace: "kubesmith"},
+		Data:       map[string][]byte{"token": []byte("t0ken\n")},
+	})
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+
+	queue := workqueue.NewRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(time.Millisecond, time.Millisecond))
+	t.Cleanup(queue.ShutDown)
+
+	return &Reporter{
+		logger:       logger,
+		queue:        queue,
+		secretLister: coreListersv1.NewSecretLister(indexer),
+		requests:     map[string]request{},
+		reported:     map[string]reportedStatus{},
+	}
+}
+
+func newTestPipeline(apiURL, commit string) api.Pipeline {
+	pipeline := api.Pipeline{ObjectMeta: metav1.ObjectMeta{Name: "app-1", Namespace: "kubesmith"}}
+	pipeline.Spec.Workspace.Repo = api.WorkspaceRepo{
+		URL:    "https://github.example.com/kubesmith/app.git",
+		Commit: commit,
+		CommitStatus: api.WorkspaceRepoCommitStatus{
+			Provider:  api.CommitStatusProviderGitHub,
+			APIURL:    apiURL,
+			TargetURL: "https://ci.example.com/{namespace}/{pipeline}",
+			Secret:    api.WorkspaceRepoCommitStatusSecret{Name: "token", Key: "token"},
+		},
+	}
+
+	return pipeline
+}
+
+// processQueue processes the queued statuses until the queue is empty,
+// waiting for the ones that are retried
+func processQueue(t *testing.T, r *Reporter) {
+	deadline := time.Now().Add(5 * time.Second)
+
+	for time.Now().Before(deadline) {
+		r.lock.Lock()
+		pending := len(r.requests)
+		r.lock.Unlock()
+
+		if pending == 0 {
+			return
+		}
+
+		if r.queue.Len() == 0 {
+			time.Sleep(time.Millisecond)
+			continue
+		}
+
+		r.processNextItem()
+	}
+
+	t.Fatal("timed out waiting for the statuses to be posted")
+}
+
+func TestReportDedupe(t *testing.T) {
+	server, received := newTestServer(t, http.StatusCreated, "{}")
+	r := newTestReporter(t)
+	pipeline := newTestPipeline(server.URL, "0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c")
+
+	// the same status is only queued once
+	r.ReportPipeline(pipeline, StatePending, "pipeline is pending")
+	r.ReportPipeline(pipeline, StatePending, "pipeline is pending")
+	if r.queue.Len() != 1 {
+		t.Fatalf("expected 1 queued status, got %d", r.queue.Len())
+	}
+
+	// only the latest status of a context is posted
+	r.ReportPipeline(pipeline, StateRunning, "pipeline is running")
+	if r.queue.Len() != 1 {
+		t.Fatalf("expected 1 queued status, got %d", r.queue.Len())
+	}
+
+	// the statuses of the jobs have contexts of their own
+	r.ReportPipelineJob(pipeline, "test", StateRunning, "job is running")
+	if r.queue.Len() != 2 {
+		t.Fatalf("expected 2 queued statuses, got %d", r.queue.Len())
+	}
+
+	processQueue(t, r)
+
+	if len(*received) != 2 {
+		t.Fatalf("expected 2 posted statuses, got %d", len(*received))
+	}
+
+	expected := map[string]string{
+		"kubesmith":      "pending",
+		"kubesmith/test": "pending",
+	}
+
+	for _, request := range *received {
+		if state, ok := expected[request.Body["context"]]; !ok || request.Body["state"] != state {
+			t.Errorf("unexpected status %v", request.Body)
+		}
+
+		if request.Body["target_url"] != "https://ci.example.com/kubesmith/app-1" {
+			t.Errorf("expected the target url to be expanded, got %s", request.Body["target_url"])
+		}
+
+		if request.Header.Get("Authorization") != "token t0ken" {
+			t.Errorf("expected the token of the secret, got %q", request.Header.Get("Authorization"))
+		}
+	}
+
+	// statuses that were posted aren't posted again
+	r.ReportPipeline(pipeline, StateRunning, "pipeline is running")
+	if r.queue.Len() != 0 {
+		t.Fatalf("expected the posted status to be skipped, got %d queued", r.queue.Len())
+	}
+
+	r.ReportPipeline(pipeline, StateSuccess, "pipeline succeeded")
+	processQueue(t, r)
+
+	if len(*received) != 3 || (*received)[2].Body["state"] != "success" {
+		t.Errorf("expected the changed status to be posted, got %v", *received)
+	}
+}
+
+func TestReportSkipped(t *testing.T) {
+	r := newTestReporter(t)
+
+	// repos without a token secret don't report statuses
+	pipeline := newTestPipeline("https://github.example.com/api/v3", "0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c")
+	pipeline.Spec.Workspace.Repo.CommitStatus.Secret = api.WorkspaceRepoCommitStatusSecret{}
+	r.ReportPipeline(pipeline, StateRunning, "pipeline is running")
+
+	// pipelines of a ref have no commit until it's resolved
+	pipeline = newTestPipeline("https://github.example.com/api/v3", "")
+	r.ReportPipeline(pipeline, StateRunning, "pipeline is running")
+
+	if r.queue.Len() != 0 {
+		t.Errorf("expected no queued statuses, got %d", r.queue.Len())
+	}
+
+	// the commit resolved by the clone repo job is used
+	pipeline.Status.Commit = "0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c"
+	r.ReportPipeline(pipeline, StateRunning, "pipeline is running")
+
+	if r.queue.Len() != 1 {
+		t.Errorf("expected 1 queued status, got %d", r.queue.Len())
+	}
+}
+
+func TestProcessNextItemRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		posted   bool
+	}{
+		{
+			name:     "failed posts are retried",
+			failures: 2,
+			posted:   true,
+		},
+		{
+			name:     "statuses are dropped after the last retry",
+			failures: maxRetries + 1,
+			posted:   false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attempts := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				attempts++
+				if attempts <= test.failures {
+					w.WriteHeader(http.StatusBadGateway)
+					return
+				}
+
+				w.WriteHeader(http.StatusCreated)
+			}))
+			defer server.Close()
+
+			r := newTestReporter(t)
+			pipeline := newTestPipeline(server.URL, "0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c")
+
+			r.ReportPipeline(pipeline, StateRunning, "pipeline is running")
+			processQueue(t, r)
+
+			expectedAttempts := test.failures + 1
+			if !test.posted {
+				expectedAttempts = maxRetries + 1
+			}
+
+			if attempts != expectedAttempts {
+				t.Errorf("expected %d attempts, got %d", expectedAttempts, attempts)
+			}
+
+			_, posted := r.reported[getTestKey(pipeline)]
+			if posted != test.posted {
+				t.Errorf("expected the status to be posted: %t, got %t", test.posted, posted)
+			}
+
+			if r.queue.Len() != 0 {
+				t.Errorf("expected the queue to be empty, got %d", r.queue.Len())
+			}
+		})
+	}
+}
+
+func TestProcessNextItemKeepsNewerStatus(t *testing.T) {
+	r := newTestReporter(t)
+	pipeline := newTestPipeline("", "0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c")
+
+	// a newer status is reported while the first one is posted
+	posted := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body := map[string]string{}
+		json.NewDecoder(req.Body).Decode(&body)
+		posted = append(posted, body["state"])
+
+		if len(posted) == 1 {
+			r.ReportPipeline(pipeline, StateSuccess, "pipeline succeeded")
+		}
+
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	pipeline.Spec.Workspace.Repo.CommitStatus.APIURL = server.URL
+	r.ReportPipeline(pipeline, StateRunning, "pipeline is running")
+
+	r.processNextItem()
+	if request, ok := r.requests[getTestKey(pipeline)]; !ok || request.status.State != StateSuccess {
+		t.Fatalf("expected the newer status to be pending, got %+v", r.requests)
+	}
+
+	processQueue(t, r)
+	if expected := []string{"pending", "success"}; !reflect.DeepEqual(posted, expected) {
+		t.Errorf("expected the states %v to be posted, got %v", expected, posted)
+	}
+}
+
+func getTestKey(pipeline api.Pipeline) string {
+	repo := pipeline.Spec.Workspace.Repo
+	return repo.URL + "#" + pipeline.GetCommit() + "#" + repo.GetCommitStatusContext()
+}
@@ -0,0 +1,127 @@
+package commitstatus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	gosync "sync"
+	"time"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+)
+
+func (r *Reporter) Run(ctx context.Context, workers int) error {
+	var wg gosync.WaitGroup
+
+	defer func() {
+		r.setRunning(false)
+		r.queue.ShutDown()
+		wg.Wait()
+	}()
+
+	r.logger.Debug("Waiting for caches to sync")
+	if !cache.WaitForCacheSync(ctx.Done(), r.secretSynced) {
+		return errors.New("timed out waiting for caches to sync")
+	}
+
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			wait.Until(r.runWorker, time.Second, ctx.Done())
+			wg.Done()
+		}()
+	}
+
+	r.setRunning(true)
+	<-ctx.Done()
+
+	return nil
+}
+
+func (r *Reporter) Healthy() error {
+	if !r.isRunning() {
+		return errors.New("commit status reporter is not running")
+	}
+
+	if !r.secretSynced() {
+		return errors.New("commit status reporter caches have not synced")
+	}
+
+	return nil
+}
+
+// ReportPipeline posts the overall status of the pipeline
+func (r *Reporter) ReportPipeline(pipeline api.Pipeline, state State, description string) {
+	r.report(pipeline, pipeline.Spec.Workspace.Repo.GetCommitStatusContext(), state, description)
+}
+
+// ReportPipelineJob posts the status of a job of the pipeline; each job has a
+// context of its own below the one of the pipeline
+func (r *Reporter) ReportPipelineJob(pipeline api.Pipeline, jobName string, state State, description string) {
+	statusContext := fmt.Sprintf("%s/%s", pipeline.Spec.Workspace.Repo.GetCommitStatusContext(), jobName)
+	r.report(pipeline, statusContext, state, description)
+}
+
+func (p *gitHubProvider) SetStatus(status Status) error {
+	body, _ := json.Marshal(map[string]string{
+		"state":       p.getState(status.State),
+		"context":     status.Context,
+		"description": status.Description,
+		"target_url":  status.TargetURL,
+	})
+
+	endpoint := fmt.Sprintf("%s/repos/%s/statuses/%s", p.apiURL, p.repo, status.Commit)
+	header := map[string]string{
+		"Accept":        "application/vnd.github.v3+json",
+		"Authorization": fmt.Sprintf("token %s", p.token),
+	}
+
+	_, err := post(p.client, endpoint, header, body)
+	return err
+}
+
+func (p *gitLabProvider) SetStatus(status Status) error {
+	body, _ := json.Marshal(map[string]string{
+		"state":       p.getState(status.State),
+		"name":        status.Context,
+		"description": status.Description,
+		"target_url":  status.TargetURL,
+	})
+
+	endpoint := fmt.Sprintf("%s/projects/%s/statuses/%s", p.apiURL, url.PathEscape(p.repo), status.Commit)
+	header := map[string]string{
+		"PRIVATE-TOKEN": p.token,
+	}
+
+	response, err := post(p.client, endpoint, header, body)
+
+	// gitlab refuses to set the state a status already has, which happens
+	// when a status is posted again after a restart
+	if err != nil && strings.Contains(response, "Cannot transition status") {
+		return nil
+	}
+
+	return err
+}
+
+func (p *giteaProvider) SetStatus(status Status) error {
+	body, _ := json.Marshal(map[string]string{
+		"state":       p.getState(status.State),
+		"context":     status.Context,
+		"description": status.Description,
+		"target_url":  status.TargetURL,
+	})
+
+	endpoint := fmt.Sprintf("%s/repos/%s/statuses/%s", p.apiURL, p.repo, status.Commit)
+	header := map[string]string{
+		"Authorization": fmt.Sprintf("token %s", p.token),
+	}
+
+	_, err := post(p.client, endpoint, header, body)
+	return err
+}
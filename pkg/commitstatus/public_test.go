@@ -0,0 +1,302 @@
+package commitstatus
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+)
+
+// receivedRequest is a request received by a test server
+type receivedRequest struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   map[string]string
+}
+
+// newTestServer records the requests it receives and answers them with the
+// status and body
+func newTestServer(t *testing.T, status int, body string) (*httptest.Server, *[]receivedRequest) {
+	received := []receivedRequest{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+
+		request := receivedRequest{Method: r.Method, Path: r.URL.EscapedPath(), Header: r.Header, Body: map[string]string{}}
+		if err := json.Unmarshal(data, &request.Body); err != nil {
+			t.Errorf("could not parse request body %q: %v", data, err)
+		}
+
+		received = append(received, request)
+
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}))
+
+	t.Cleanup(server.Close)
+	return server, &received
+}
+
+func TestSetStatus(t *testing.T) {
+	status := Status{
+		Commit:      "0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c",
+		Context:     "kubesmith/test",
+		Description: "job test is running",
+		TargetURL:   "https://ci.example.com/kubesmith/app-1",
+	}
+
+	tests := []struct {
+		name     string
+		repo     string
+		provider api.CommitStatusProvider
+		state    State
+		path     string
+		header   map[string]string
+		body     map[string]string
+	}{
+		{
+			name:     "github",
+			repo:     "https://github.example.com/kubesmith/app.git",
+			provider: api.CommitStatusProviderGitHub,
+			state:    StateRunning,
+			path:     "/repos/kubesmith/app/statuses/0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c",
+			header:   map[string]string{"Authorization": "token t0ken", "Accept": "application/vnd.github.v3+json"},
+			body:     map[string]string{"state": "pending", "context": status.Context, "description": status.Description, "target_url": status.TargetURL},
+		},
+		{
+			name:     "github cancelled",
+			repo:     "git@github.example.com:kubesmith/app.git",
+			provider: api.CommitStatusProviderGitHub,
+			state:    StateCancelled,
+			path:     "/repos/kubesmith/app/statuses/0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c",
+			header:   map[string]string{"Authorization": "token t0ken"},
+			body:     map[string]string{"state": "error", "context": status.Context, "description": status.Description, "target_url": status.TargetURL},
+		},
+		{
+			name:     "gitlab escapes the project path",
+			repo:     "https://gitlab.example.com/kubesmith/group/app.git",
+			provider: api.CommitStatusProviderGitLab,
+			state:    StateFailure,
+			path:     "/projects/kubesmith%2Fgroup%2Fapp/statuses/0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c",
+			header:   map[string]string{"PRIVATE-TOKEN": "t0ken"},
+			body:     map[string]string{"state": "failed", "name": status.Context, "description": status.Description, "target_url": status.TargetURL},
+		},
+		{
+			name:     "gitlab running",
+			repo:     "ssh://git@gitlab.example.com:2222/kubesmith/app.git",
+			provider: api.CommitStatusProviderGitLab,
+			state:    StateRunning,
+			path:     "/projects/kubesmith%2Fapp/statuses/0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c",
+			header:   map[string]string{"PRIVATE-TOKEN": "t0ken"},
+			body:     map[string]string{"state": "running", "name": status.Context, "description": status.Description, "target_url": status.TargetURL},
+		},
+		{
+			name:     "gitea",
+			repo:     "https://gitea.example.com/kubesmith/app",
+			provider: api.CommitStatusProviderGitea,
+			state:    StateSuccess,
+			path:     "/repos/kubesmith/app/statuses/0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c",
+			header:   map[string]string{"Authorization": "token t0ken"},
+			body:     map[string]string{"state": "success", "context": status.Context, "description": status.Description, "target_url": status.TargetURL},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, received := newTestServer(t, http.StatusCreated, "{}")
+
+			repo := api.WorkspaceRepo{
+				URL: test.repo,
+				CommitStatus: api.WorkspaceRepoCommitStatus{
+					Provider: test.provider,
+					APIURL:   server.URL + "/",
+				},
+			}
+
+			provider, err := NewProvider(repo, "t0ken")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			status := status
+			status.State = test.state
+			if err := provider.SetStatus(status); err != nil {
+				t.Fatalf("could not set status: %v", err)
+			}
+
+			if len(*received) != 1 {
+				t.Fatalf("expected 1 request, got %d", len(*received))
+			}
+
+			request := (*received)[0]
+			if request.Method != http.MethodPost {
+				t.Errorf("expected a %s request, got %s", http.MethodPost, request.Method)
+			}
+
+			if request.Path != test.path {
+				t.Errorf("expected path %s, got %s", test.path, request.Path)
+			}
+
+			if contentType := request.Header.Get("Content-Type"); contentType != "application/json" {
+				t.Errorf("expected content type application/json, got %s", contentType)
+			}
+
+			for key, value := range test.header {
+				if request.Header.Get(key) != value {
+					t.Errorf("expected header %s to be %q, got %q", key, value, request.Header.Get(key))
+				}
+			}
+
+			if !reflect.DeepEqual(request.Body, test.body) {
+				t.Errorf("expected body %v, got %v", test.body, request.Body)
+			}
+		})
+	}
+}
+
+func TestSetStatusErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider api.CommitStatusProvider
+		status   int
+		body     string
+		err      bool
+	}{
+		{
+			name:     "github error",
+			provider: api.CommitStatusProviderGitHub,
+			status:   http.StatusUnprocessableEntity,
+			body:     `{"message": "Validation Failed"}`,
+			err:      true,
+		},
+		{
+			name:     "gitlab refuses to set the state the status already has",
+			provider: api.CommitStatusProviderGitLab,
+			status:   http.StatusBadRequest,
+			body:     `{"message": "Cannot transition status via :run from :running"}`,
+		},
+		{
+			name:     "gitlab error",
+			provider: api.CommitStatusProviderGitLab,
+			status:   http.StatusUnauthorized,
+			body:     `{"message": "401 Unauthorized"}`,
+			err:      true,
+		},
+		{
+			name:     "gitea error",
+			provider: api.CommitStatusProviderGitea,
+			status:   http.StatusInternalServerError,
+			body:     "",
+			err:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, _ := newTestServer(t, test.status, test.body)
+
+			repo := api.WorkspaceRepo{
+				URL: "https://git.example.com/kubesmith/app.git",
+				CommitStatus: api.WorkspaceRepoCommitStatus{
+					Provider: test.provider,
+					APIURL:   server.URL,
+				},
+			}
+
+			provider, err := NewProvider(repo, "t0ken")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = provider.SetStatus(Status{Commit: "0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c", Context: "kubesmith", State: StateRunning})
+			if test.err && err == nil {
+				t.Error("expected an error")
+			} else if !test.err && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	tests := []struct {
+		name     string
+		repo     api.WorkspaceRepo
+		expected Provider
+		err      bool
+	}{
+		{
+			name:     "github.com is detected",
+			repo:     api.WorkspaceRepo{URL: "git@github.com:kubesmith/app.git"},
+			expected: &gitHubProvider{apiURL: "https://api.github.com", repo: "kubesmith/app", token: "t0ken"},
+		},
+		{
+			name:     "github enterprise",
+			repo:     api.WorkspaceRepo{URL: "https://github.example.com/kubesmith/app", CommitStatus: api.WorkspaceRepoCommitStatus{Provider: api.CommitStatusProviderGitHub}},
+			expected: &gitHubProvider{apiURL: "https://github.example.com/api/v3", repo: "kubesmith/app", token: "t0ken"},
+		},
+		{
+			name:     "gitlab.com is detected",
+			repo:     api.WorkspaceRepo{URL: "https://gitlab.com/kubesmith/group/app.git"},
+			expected: &gitLabProvider{apiURL: "https://gitlab.com/api/v4", repo: "kubesmith/group/app", token: "t0ken"},
+		},
+		{
+			name:     "gitea",
+			repo:     api.WorkspaceRepo{URL: "https://gitea.example.com/kubesmith/app.git", CommitStatus: api.WorkspaceRepoCommitStatus{Provider: api.CommitStatusProviderGitea}},
+			expected: &giteaProvider{apiURL: "https://gitea.example.com/api/v1", repo: "kubesmith/app", token: "t0ken"},
+		},
+		{
+			name: "other hosts need a provider",
+			repo: api.WorkspaceRepo{URL: "https://git.example.com/kubesmith/app.git"},
+			err:  true,
+		},
+		{
+			name: "unsupported provider",
+			repo: api.WorkspaceRepo{URL: "https://git.example.com/kubesmith/app.git", CommitStatus: api.WorkspaceRepoCommitStatus{Provider: "svn"}},
+			err:  true,
+		},
+		{
+			name: "url without a repo",
+			repo: api.WorkspaceRepo{URL: "https://github.com/"},
+			err:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			provider, err := NewProvider(test.repo, "t0ken")
+			if test.err {
+				if err == nil {
+					t.Errorf("expected an error, got provider %+v", provider)
+				}
+
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			// the http clients only differ by their pointers
+			switch p := provider.(type) {
+			case *gitHubProvider:
+				p.client = nil
+			case *gitLabProvider:
+				p.client = nil
+			case *giteaProvider:
+				p.client = nil
+			}
+
+			if !reflect.DeepEqual(provider, test.expected) {
+				t.Errorf("expected provider %+v, got %+v", test.expected, provider)
+			}
+		})
+	}
+}
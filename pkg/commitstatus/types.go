@@ -0,0 +1,107 @@
+package commitstatus
+
+import (
+	"net/http"
+	gosync "sync"
+	"time"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/sirupsen/logrus"
+	coreListersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+type State string
+
+const (
+	StatePending   State = "pending"
+	StateRunning   State = "running"
+	StateSuccess   State = "success"
+	StateFailure   State = "failure"
+	StateCancelled State = "cancelled"
+)
+
+const (
+	// maxRetries is how many times a status is retried before it's dropped;
+	// the retries back off from retryBaseDelay up to retryMaxDelay
+	maxRetries     = 5
+	retryBaseDelay = time.Second
+	retryMaxDelay  = time.Minute
+
+	// reportedTTL is how long the statuses that were posted are remembered
+	// to skip posting them again
+	reportedTTL = 24 * time.Hour
+
+	// maxDescriptionLength is the longest description github accepts
+	maxDescriptionLength = 140
+
+	requestTimeout = 30 * time.Second
+)
+
+// Status is the state of a pipeline (or of one of its jobs) on a commit
+type Status struct {
+	Commit      string
+	Context     string
+	State       State
+	Description string
+	TargetURL   string
+}
+
+// Provider posts commit statuses to a repo of a git server
+type Provider interface {
+	SetStatus(status Status) error
+}
+
+// Reporter posts the commit statuses of pipelines in the background; the
+// statuses are deduped so that only changes are posted and failed posts are
+// retried with a backoff. Only the latest status of a commit and context is
+// posted when several are reported before it could be sent.
+type Reporter struct {
+	logger       logrus.FieldLogger
+	queue        workqueue.RateLimitingInterface
+	secretLister coreListersv1.SecretLister
+	secretSynced cache.InformerSynced
+
+	lock       gosync.Mutex
+	requests   map[string]request
+	reported   map[string]reportedStatus
+	lastPruned time.Time
+
+	runningLock gosync.RWMutex
+	running     bool
+}
+
+// request is a status waiting to be posted, together with the repo it's
+// posted to and the namespace of the token secret
+type request struct {
+	namespace string
+	repo      api.WorkspaceRepo
+	status    Status
+}
+
+type reportedStatus struct {
+	status Status
+	time   time.Time
+}
+
+type gitHubProvider struct {
+	client *http.Client
+	apiURL string
+	repo   string
+	token  string
+}
+
+type gitLabProvider struct {
+	client *http.Client
+	apiURL string
+	repo   string
+	token  string
+}
+
+type giteaProvider struct {
+	client *http.Client
+	apiURL string
+	repo   string
+	token  string
+}
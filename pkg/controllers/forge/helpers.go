@@ -1,14 +1,19 @@
 package forge
 
 import (
+	"net/http"
+	"reflect"
+	"time"
+
 	"github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
-	"github.com/kubesmith/kubesmith/pkg/controllers"
 	"github.com/kubesmith/kubesmith/pkg/controllers/generic"
 	kubesmithv1 "github.com/kubesmith/kubesmith/pkg/generated/clientset/versioned/typed/kubesmith/v1"
 	informers "github.com/kubesmith/kubesmith/pkg/generated/informers/externalversions/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/logging"
 	"github.com/kubesmith/kubesmith/pkg/sync"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/util/clock"
+	coreInformersv1 "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 )
@@ -18,20 +23,29 @@ func NewForgeController(
 	kubeClient kubernetes.Interface,
 	kubesmithClient kubesmithv1.KubesmithV1Interface,
 	forgeInformer informers.ForgeInformer,
-) controllers.Interface {
+	pipelineInformer informers.PipelineInformer,
+	secretInformer coreInformersv1.SecretInformer,
+) *ForgeController {
 	c := &ForgeController{
-		GenericController: generic.NewGenericController("Forge"),
-		logger:            logger.WithField("controller", "Forge"),
+		GenericController: generic.NewGenericController("Forge", logger),
+		logger:            logger.WithField(logging.FieldController, "Forge"),
 		kubeClient:        kubeClient,
 		kubesmithClient:   kubesmithClient,
 		forgeLister:       forgeInformer.Lister(),
+		pipelineLister:    pipelineInformer.Lister(),
+		secretLister:      secretInformer.Lister(),
 		clock:             &clock.RealClock{},
+		polls:             map[string]time.Time{},
 	}
 
 	c.SyncHandler = c.processForge
+	c.ResyncFunc = c.processSchedules
+	c.ResyncPeriod = scheduleResyncPeriod
 	c.CacheSyncWaiters = append(
 		c.CacheSyncWaiters,
 		forgeInformer.Informer().HasSynced,
+		pipelineInformer.Informer().HasSynced,
+		secretInformer.Informer().HasSynced,
 	)
 
 	forgeInformer.Informer().AddEventHandler(
@@ -42,8 +56,15 @@ func NewForgeController(
 				c.Queue.Add(sync.ForgeAddAction(*forge))
 			},
 			UpdateFunc: func(oldObj, updatedObj interface{}) {
+				oldForge := oldObj.(*v1.Forge)
 				updatedForge := updatedObj.(*v1.Forge)
 
+				// every poll patches the status; polls are queued by the
+				// controller itself so only changes to the spec matter
+				if reflect.DeepEqual(oldForge.Spec, updatedForge.Spec) {
+					return
+				}
+
 				c.Queue.Add(sync.ForgeUpdateAction(*updatedForge))
 			},
 			DeleteFunc: func(obj interface{}) {
@@ -56,3 +77,22 @@ func NewForgeController(
 
 	return c
 }
+
+// NewWebhookHandler creates the handler serving the webhooks of the forges in
+// the namespace; it's mounted on /webhooks/ by the forge server and queues the
+// events for the forge controller
+func NewWebhookHandler(
+	namespace string,
+	logger *logrus.Logger,
+	forgeController *ForgeController,
+	forgeInformer informers.ForgeInformer,
+	secretInformer coreInformersv1.SecretInformer,
+) http.Handler {
+	return &WebhookHandler{
+		logger:       logger.WithField(logging.FieldController, "Webhook"),
+		namespace:    namespace,
+		queue:        forgeController.Queue,
+		forgeLister:  forgeInformer.Lister(),
+		secretLister: secretInformer.Lister(),
+	}
+}
@@ -1,17 +1,35 @@
 package forge
 
 import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ghodss/yaml"
 	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/controllers/generic"
+	"github.com/kubesmith/kubesmith/pkg/cron"
+	kubesmithv1 "github.com/kubesmith/kubesmith/pkg/generated/clientset/versioned/typed/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/git"
+	"github.com/kubesmith/kubesmith/pkg/logging"
 	"github.com/kubesmith/kubesmith/pkg/sync"
+	"github.com/kubesmith/kubesmith/pkg/webhook"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	coreListersv1 "k8s.io/client-go/listers/core/v1"
 )
 
-func (c *ForgeController) processForge(action sync.SyncAction) error {
+func (c *ForgeController) processForge(action sync.SyncAction, logger logrus.FieldLogger) error {
 	cachedForge := action.GetObject().(api.Forge)
-	logger := c.logger.WithFields(logrus.Fields{
-		"Name": cachedForge.GetName(),
+	logger = logger.WithFields(logrus.Fields{
+		logging.FieldNamespace: cachedForge.GetNamespace(),
+		logging.FieldForge:     cachedForge.GetName(),
 	})
 
 	switch action.GetAction() {
@@ -20,29 +38,673 @@ func (c *ForgeController) processForge(action sync.SyncAction) error {
 	default:
 		forge, err := c.forgeLister.Forges(cachedForge.GetNamespace()).Get(cachedForge.GetName())
 		if apierrors.IsNotFound(err) {
-			c.logger.Info("unable to find forge")
+			logger.Info("unable to find forge")
 			return nil
 		} else if err != nil {
 			return errors.Wrap(err, "error getting forge")
 		}
 
-		_ = forge
-		logger.Info("todo: processing forge")
+		if action.GetAction() == sync.SyncActionWebhook {
+			event := action.(*sync.ForgeSyncAction).GetEvent()
+			return c.processWebhookEvent(*forge.DeepCopy(), event, logger.WithField("ref", event.Ref))
+		}
+
+		return c.processPolledForge(*forge.DeepCopy(), logger)
+	}
+}
+
+func (c *ForgeController) processPolledForge(original api.Forge, logger logrus.FieldLogger) error {
+	if err := original.Validate(); err != nil {
+		if original.Status.FailureReason == err.Error() {
+			return nil
+		}
+
+		logger.Info("marking forge as invalid")
+		forge := *original.DeepCopy()
+		forge.Status.FailureReason = err.Error()
+
+		if _, err := generic.PatchForge(c.kubesmithClient, forge, original); err != nil {
+			return errors.Wrap(err, "could not mark forge as invalid")
+		}
+
+		logger.Info("marked forge as invalid")
+		return nil
+	}
+
+	if original.Spec.DisablePolling {
+		logger.Debug("polling is disabled")
+		return nil
+	}
+
+	remaining := original.GetNextPollTime().Sub(c.clock.Now())
+	if original.HasBeenPolled() && remaining > 0 {
+		c.schedulePoll(original, remaining)
+		return nil
+	}
+
+	logger.Info("polling repo")
+	forge := *original.DeepCopy()
+	refs, err := c.getWatchedRefs(original)
+	if err != nil {
+		logger.WithError(err).Info("could not poll repo")
+		forge.Status.FailureReason = err.Error()
+		forge.Status.LastPolledTime.Time = c.clock.Now()
+
+		if _, err := generic.PatchForge(c.kubesmithClient, forge, original); err != nil {
+			return errors.Wrap(err, "could not patch forge")
+		}
+
+		c.schedulePoll(original, original.GetPollInterval())
+		return nil
+	}
+
+	// the refs are compared to the ones of the latest forge rather than the
+	// cached one, which may not have the commits recorded by the webhook yet
+	latest, err := c.kubesmithClient.Forges(original.GetNamespace()).Get(original.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, "could not get forge")
 	}
 
+	failureReason := ""
+	statusRefs := []api.ForgeStatusRef{}
+	for _, ref := range refs {
+		statusRef := latest.GetStatusRef(ref.Name)
+		if statusRef != nil && statusRef.Commit == ref.Commit {
+			statusRefs = append(statusRefs, *statusRef)
+			continue
+		}
+
+		// the commits found by the first poll are already in the repo; only
+		// the ones pushed afterwards are built
+		if !latest.HasBeenPolled() {
+			statusRefs = append(statusRefs, api.ForgeStatusRef{Name: ref.Name, Commit: ref.Commit})
+			continue
+		}
+
+		created, err := c.createPipelineForRef(original, ref, logger.WithField("ref", ref.Name))
+		if err != nil {
+			// the ref keeps its last seen commit so the next poll tries again;
+			// the pipelines created for the other refs are still recorded
+			logger.WithError(err).WithField("ref", ref.Name).Info("could not create pipeline for ref")
+			failureReason = err.Error()
+
+			if statusRef != nil {
+				statusRefs = append(statusRefs, *statusRef)
+			}
+
+			continue
+		}
+
+		statusRefs = append(statusRefs, *created)
+	}
+
+	sort.Slice(statusRefs, func(i, j int) bool {
+		return statusRefs[i].Name < statusRefs[j].Name
+	})
+
+	forge = *latest.DeepCopy()
+	forge.Status.Refs = statusRefs
+	forge.Status.FailureReason = failureReason
+	forge.Status.LastPolledTime.Time = c.clock.Now()
+
+	if _, err := generic.PatchForge(c.kubesmithClient, forge, *latest); err != nil {
+		return errors.Wrap(err, "could not patch forge")
+	}
+
+	logger.WithField("refs", len(statusRefs)).Info("polled repo")
+	c.schedulePoll(original, original.GetPollInterval())
+
 	return nil
 }
 
 func (c *ForgeController) processDeletedForge(original api.Forge, logger logrus.FieldLogger) error {
-	logger.Info("todo: processing deleted forge")
+	c.pollsLock.Lock()
+	defer c.pollsLock.Unlock()
+
+	delete(c.polls, getForgeKey(original))
+	logger.Info("stopped polling repo")
+
+	return nil
+}
+
+// processSchedules creates the pipelines of the schedules that are due; it
+// runs every scheduleResyncPeriod rather than being queued
+func (c *ForgeController) processSchedules() {
+	forges, err := c.forgeLister.List(labels.Everything())
+	if err != nil {
+		c.logger.WithError(err).Error("could not list forges")
+		return
+	}
+
+	for _, forge := range forges {
+		if len(forge.Spec.Schedules) == 0 || forge.Validate() != nil {
+			continue
+		}
+
+		logger := c.logger.WithFields(logrus.Fields{
+			logging.FieldNamespace: forge.GetNamespace(),
+			logging.FieldForge:     forge.GetName(),
+		})
+
+		if err := c.processForgeSchedules(*forge.DeepCopy(), logger); err != nil {
+			logger.WithError(err).Error("could not process schedules")
+		}
+	}
+}
+
+func (c *ForgeController) processForgeSchedules(original api.Forge, logger logrus.FieldLogger) error {
+	statuses := []api.ForgeStatusSchedule{}
+
+	for _, schedule := range original.Spec.Schedules {
+		scheduleLogger := logger.WithField("schedule", schedule.Name)
+		status := api.ForgeStatusSchedule{Name: schedule.Name}
+		if existing := original.GetStatusSchedule(schedule.Name); existing != nil {
+			status = *existing
+		}
+
+		if err := c.enforceScheduleHistoryLimits(original, schedule, scheduleLogger); err != nil {
+			return err
+		}
+
+		status, err := c.runScheduleIfDue(original, schedule, status, scheduleLogger)
+		if err != nil {
+			return err
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	if reflect.DeepEqual(statuses, original.Status.Schedules) {
+		return nil
+	}
+
+	forge := *original.DeepCopy()
+	forge.Status.Schedules = statuses
+
+	if _, err := generic.PatchForge(c.kubesmithClient, forge, original); err != nil {
+		return errors.Wrap(err, "could not patch forge")
+	}
+
+	return nil
+}
+
+// runScheduleIfDue creates a pipeline for the latest activation of the
+// schedule since it last ran, following its starting deadline and concurrency
+// policy; new schedules start counting from when they're first seen
+func (c *ForgeController) runScheduleIfDue(original api.Forge, schedule api.ForgeSchedule, status api.ForgeStatusSchedule, logger logrus.FieldLogger) (api.ForgeStatusSchedule, error) {
+	location, _ := schedule.GetLocation()
+	cronSchedule, _ := cron.NewSchedule(schedule.Cron)
+	now := c.clock.Now().In(location)
+
+	if status.LastScheduleTime.IsZero() {
+		status.LastScheduleTime.Time = now
+		return status, nil
+	}
+
+	if schedule.Suspend {
+		return status, nil
+	}
+
+	scheduled := time.Time{}
+	missed := 0
+	for next := cronSchedule.Next(status.LastScheduleTime.In(location)); !next.IsZero() && !next.After(now); next = cronSchedule.Next(next) {
+		scheduled = next
+		missed++
+
+		// the schedule carries on from now instead of looking for the latest
+		// of too many missed runs
+		if missed > maxMissedScheduleRuns {
+			logger.Warnf("schedule missed more than %d runs; skipping them", maxMissedScheduleRuns)
+			status.Error = fmt.Sprintf("missed more than %d runs since %s; check the clock or set a starting deadline", maxMissedScheduleRuns, status.LastScheduleTime.Format(time.RFC3339))
+			status.LastScheduleTime.Time = now
+			status.Pipeline = ""
+			return status, nil
+		}
+	}
+
+	if scheduled.IsZero() {
+		return status, nil
+	}
+
+	logger = logger.WithField("scheduledTime", scheduled.Format(time.RFC3339))
+	if deadline := schedule.GetStartingDeadline(); deadline > 0 && now.Sub(scheduled) > deadline {
+		logger.Info("schedule missed its starting deadline; skipping")
+		status.LastScheduleTime.Time = scheduled
+		status.Pipeline = ""
+		status.Error = fmt.Sprintf("missed the starting deadline of the run at %s", scheduled.Format(time.RFC3339))
+		return status, nil
+	}
+
+	active, err := c.getScheduledPipelines(original, schedule, false)
+	if err != nil {
+		return status, err
+	}
+
+	switch schedule.GetConcurrencyPolicy() {
+	case api.ConcurrencyPolicyForbid:
+		// the run is retried until it misses its starting deadline
+		if len(active) > 0 {
+			logger.Info("previous pipeline of schedule is still running; postponing")
+			return status, nil
+		}
+	case api.ConcurrencyPolicyReplace:
+		for _, pipeline := range active {
+			if err := c.cancelPipeline(*pipeline.DeepCopy(), logger.WithField(logging.FieldPipeline, pipeline.GetName())); err != nil {
+				return status, err
+			}
+		}
+	}
+
+	repo := original.Spec.Repo
+	repo.Ref = fmt.Sprintf("refs/heads/%s", schedule.Branch)
+
+	pipelinePath := schedule.PipelinePath
+	if pipelinePath == "" {
+		pipelinePath = original.GetPipelinePath()
+	}
+
+	status.LastScheduleTime.Time = scheduled
+	pipeline, err := readPipeline(c.secretLister, original, repo, pipelinePath)
+	if invalid, ok := err.(*invalidPipelineError); ok {
+		logger.WithError(invalid).Info("branch has no valid pipeline; skipping")
+		status.Pipeline = ""
+		status.Error = invalid.Error()
+		return status, nil
+	} else if err != nil {
+		return status, err
+	}
+
+	pipeline.Labels[api.GetLabelKey("ForgeSchedule")] = schedule.Name
+	if len(schedule.Environment) > 0 && pipeline.Spec.Environment == nil {
+		pipeline.Spec.Environment = map[string]string{}
+	}
+
+	for key, value := range schedule.Environment {
+		pipeline.Spec.Environment[key] = value
+	}
+
+	created, err := submitPipeline(c.kubesmithClient, pipeline, logger)
+	if err != nil {
+		return status, err
+	}
+
+	status.Pipeline = created.GetName()
+	status.Error = ""
+
+	return status, nil
+}
+
+func (c *ForgeController) getScheduledPipelines(original api.Forge, schedule api.ForgeSchedule, finished bool) ([]*api.Pipeline, error) {
+	selector := labels.SelectorFromSet(labels.Set{
+		api.GetLabelKey("Forge"):         original.GetName(),
+		api.GetLabelKey("ForgeSchedule"): schedule.Name,
+	})
+
+	pipelines, err := c.pipelineLister.Pipelines(original.GetNamespace()).List(selector)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list the pipelines of the schedule")
+	}
+
+	matching := []*api.Pipeline{}
+	for _, pipeline := range pipelines {
+		if pipeline.HasFinished() == finished {
+			matching = append(matching, pipeline)
+		}
+	}
+
+	return matching, nil
+}
+
+func (c *ForgeController) cancelPipeline(original api.Pipeline, logger logrus.FieldLogger) error {
+	if original.IsCancelRequested() {
+		return nil
+	}
+
+	logger.Info("replacing running pipeline; requesting cancellation")
+	pipeline := *original.DeepCopy()
+	pipeline.Spec.Cancel = true
+
+	patchType, patchBytes, err := pipeline.GetPatchFromOriginal(original)
+	if err != nil {
+		return err
+	}
+
+	if _, err := c.kubesmithClient.Pipelines(original.GetNamespace()).Patch(original.GetName(), patchType, patchBytes); err != nil {
+		return errors.Wrap(err, "could not request cancellation of pipeline")
+	}
+
+	logger.Info("requested cancellation of pipeline")
+	return nil
+}
+
+// enforceScheduleHistoryLimits deletes the oldest finished pipelines of the
+// schedule beyond its history limits
+func (c *ForgeController) enforceScheduleHistoryLimits(original api.Forge, schedule api.ForgeSchedule, logger logrus.FieldLogger) error {
+	finished, err := c.getScheduledPipelines(original, schedule, true)
+	if err != nil {
+		return err
+	}
+
+	succeeded := []*api.Pipeline{}
+	failed := []*api.Pipeline{}
+
+	for _, pipeline := range finished {
+		if pipeline.HasSucceeded() {
+			succeeded = append(succeeded, pipeline)
+		} else {
+			failed = append(failed, pipeline)
+		}
+	}
+
+	expired := append(
+		getPipelinesBeyondLimit(succeeded, schedule.GetSuccessfulHistoryLimit()),
+		getPipelinesBeyondLimit(failed, schedule.GetFailedHistoryLimit())...,
+	)
+
+	propagationPolicy := metav1.DeletePropagationBackground
+	deleteOptions := metav1.DeleteOptions{
+		PropagationPolicy: &propagationPolicy,
+	}
+
+	for _, pipeline := range expired {
+		pipelineLogger := logger.WithField(logging.FieldPipeline, pipeline.GetName())
+		pipelineLogger.Info("pipeline is beyond the history limit of the schedule; deleting")
+
+		if err := c.kubesmithClient.Pipelines(pipeline.GetNamespace()).Delete(pipeline.GetName(), &deleteOptions); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return errors.Wrap(err, "could not delete pipeline")
+			}
+		}
+
+		pipelineLogger.Info("deleted pipeline")
+	}
+
 	return nil
 }
 
-func (c *ForgeController) patchForge(updated, original api.Forge) (*api.Forge, error) {
-	patchType, patchBytes, err := updated.GetPatchFromOriginal(original)
+func (c *ForgeController) schedulePoll(original api.Forge, after time.Duration) {
+	c.pollsLock.Lock()
+	defer c.pollsLock.Unlock()
+
+	key := getForgeKey(original)
+	now := c.clock.Now()
+	next := now.Add(after)
+
+	if scheduled, ok := c.polls[key]; ok && scheduled.After(now) && !scheduled.After(next) {
+		return
+	}
+
+	c.polls[key] = next
+	c.Queue.AddAfter(sync.ForgeUpdateAction(original), after)
+}
+
+func (c *ForgeController) getWatchedRefs(original api.Forge) ([]git.Ref, error) {
+	client, err := getGitClient(c.secretLister, original)
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := client.GetRefs()
 	if err != nil {
 		return nil, err
 	}
 
-	return c.kubesmithClient.Forges(original.GetNamespace()).Patch(original.GetName(), patchType, patchBytes)
+	watched := []git.Ref{}
+	for _, ref := range refs {
+		if original.IsWatchedRef(ref.Name) {
+			watched = append(watched, ref)
+		}
+	}
+
+	return watched, nil
+}
+
+// createPipelineForRef creates the pipeline found in the repo at the commit of
+// the ref; pipelines that can't be read are recorded so they aren't read again
+// until the ref moves
+func (c *ForgeController) createPipelineForRef(original api.Forge, ref git.Ref, logger logrus.FieldLogger) (*api.ForgeStatusRef, error) {
+	statusRef := &api.ForgeStatusRef{Name: ref.Name, Commit: ref.Commit}
+
+	repo := original.Spec.Repo
+	repo.Ref = ref.Name
+	repo.Commit = ref.Commit
+
+	pipeline, err := createPipeline(c.kubesmithClient, c.secretLister, original, repo, logger)
+	if invalid, ok := err.(*invalidPipelineError); ok {
+		logger.WithError(invalid).Info("ref has no valid pipeline; skipping")
+		statusRef.Error = invalid.Error()
+		return statusRef, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	statusRef.Pipeline = pipeline.GetName()
+	return statusRef, nil
+}
+
+func getGitClient(secretLister coreListersv1.SecretLister, original api.Forge) (*git.GitClient, error) {
+	ssh := original.Spec.Repo.SSH
+	if ssh.Secret.Name == "" {
+		return git.NewGitClient(original.Spec.Repo.URL, nil), nil
+	}
+
+	key, err := getSecretValue(secretLister, original.GetNamespace(), ssh.Secret.Name, ssh.Secret.Key)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get the repo ssh key")
+	}
+
+	return git.NewGitClient(original.Spec.Repo.URL, key), nil
+}
+
+func getSecretValue(secretLister coreListersv1.SecretLister, namespace, name, key string) ([]byte, error) {
+	secret, err := secretLister.Secrets(namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := secret.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("secret %s has no %q key", name, key)
+	} else if len(value) == 0 {
+		return nil, fmt.Errorf("secret %s has an empty %q key", name, key)
+	}
+
+	return value, nil
+}
+
+// createPipeline creates the pipeline found in the repo at the commit (or, for
+// abbreviated commits, the ref) of the workspace repo
+func createPipeline(
+	kubesmithClient kubesmithv1.KubesmithV1Interface,
+	secretLister coreListersv1.SecretLister,
+	original api.Forge,
+	repo api.WorkspaceRepo,
+	logger logrus.FieldLogger,
+) (*api.Pipeline, error) {
+	pipeline, err := readPipeline(secretLister, original, repo, original.GetPipelinePath())
+	if err != nil {
+		return nil, err
+	}
+
+	return submitPipeline(kubesmithClient, pipeline, logger)
+}
+
+func readPipeline(secretLister coreListersv1.SecretLister, original api.Forge, repo api.WorkspaceRepo, pipelinePath string) (api.Pipeline, error) {
+	pipeline := api.Pipeline{}
+	client, err := getGitClient(secretLister, original)
+	if err != nil {
+		return pipeline, err
+	}
+
+	fetch := repo.Commit
+	if len(fetch) != commitLength {
+		fetch = repo.Ref
+	}
+
+	content, commit, err := client.GetFile(fetch, pipelinePath)
+	if err == git.ErrFileNotFound {
+		return pipeline, &invalidPipelineError{reason: fmt.Sprintf("%s does not exist", pipelinePath)}
+	} else if err != nil {
+		return pipeline, errors.Wrap(err, "could not read pipeline from repo")
+	}
+
+	if err := yaml.Unmarshal(content, &pipeline); err != nil {
+		return pipeline, &invalidPipelineError{reason: fmt.Sprintf("could not parse %s: %s", pipelinePath, err)}
+	}
+
+	repo.Commit = commit
+	return original.GetPipelineForRepo(repo, pipeline), nil
+}
+
+func submitPipeline(kubesmithClient kubesmithv1.KubesmithV1Interface, pipeline api.Pipeline, logger logrus.FieldLogger) (*api.Pipeline, error) {
+	logger.Info("creating pipeline")
+	created, err := kubesmithClient.Pipelines(pipeline.GetNamespace()).Create(&pipeline)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create pipeline")
+	}
+
+	logger.WithField(logging.FieldPipeline, created.GetName()).Info("created pipeline")
+	return created, nil
+}
+
+func getPipelinesBeyondLimit(pipelines []*api.Pipeline, limit int) []*api.Pipeline {
+	if len(pipelines) <= limit {
+		return []*api.Pipeline{}
+	}
+
+	// newest first, so everything after the pipelines to keep is beyond it
+	sort.Slice(pipelines, func(i, j int) bool {
+		return pipelines[i].GetFinishedTime().After(pipelines[j].GetFinishedTime())
+	})
+
+	return pipelines[limit:]
+}
+
+func getForgeKey(original api.Forge) string {
+	return fmt.Sprintf("%s/%s", original.GetNamespace(), original.GetName())
+}
+
+// getIgnoredEventReason returns why an event of a webhook isn't built, if it
+// isn't
+func getIgnoredEventReason(original api.Forge, event webhook.Event) string {
+	switch {
+	case event.Deleted:
+		return "deleted"
+	case event.Type == webhook.EventTypePullRequest && !original.IsWatchedPullRequest(event.PullRequest.TargetBranch):
+		return fmt.Sprintf("pull request branch %s is not watched", event.PullRequest.TargetBranch)
+	case event.Type != webhook.EventTypePullRequest && !original.IsWatchedRef(event.Ref):
+		return "not watched"
+	}
+
+	return ""
+}
+
+// processWebhookEvent creates the pipeline of an event sent to the webhook of
+// the forge. Git servers deliver events again when they weren't answered in
+// time so commits that were already built, by a poll or an earlier delivery,
+// are skipped; the commits of pushed refs are recorded so polls don't build
+// them again
+func (c *ForgeController) processWebhookEvent(original api.Forge, event webhook.Event, logger logrus.FieldLogger) error {
+	repo := original.Spec.Repo
+	repo.Ref = event.Ref
+	repo.Commit = event.Commit
+	repo.Author = event.Author
+	repo.PullRequest = api.WorkspaceRepoPullRequest{
+		Number:       event.PullRequest.Number,
+		Title:        event.PullRequest.Title,
+		URL:          event.PullRequest.URL,
+		SourceBranch: event.PullRequest.SourceBranch,
+		TargetBranch: event.PullRequest.TargetBranch,
+		Merge:        original.Spec.PullRequests.Merge,
+	}
+
+	if event.Type == webhook.EventTypePullRequest {
+		built, err := c.isPullRequestCommitBuilt(original, event)
+		if err != nil {
+			return err
+		} else if built {
+			logger.Info("pull request commit was already built; skipping")
+			return nil
+		}
+
+		pipeline, err := createPipeline(c.kubesmithClient, c.secretLister, original, repo, logger)
+		if invalid, ok := err.(*invalidPipelineError); ok {
+			logger.WithError(invalid).Info("commit has no valid pipeline; skipping")
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		logger.WithField(logging.FieldPipeline, pipeline.GetName()).Info("processed webhook")
+		return nil
+	}
+
+	// the cached forge may not have the refs recorded by the last poll yet
+	latest, err := c.kubesmithClient.Forges(original.GetNamespace()).Get(original.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, "could not get forge")
+	}
+
+	if statusRef := latest.GetStatusRef(event.Ref); statusRef != nil && statusRef.Commit == event.Commit {
+		logger.Info("commit was already built; skipping")
+		return nil
+	}
+
+	statusRef := api.ForgeStatusRef{Name: event.Ref, Commit: event.Commit}
+	pipeline, err := createPipeline(c.kubesmithClient, c.secretLister, *latest, repo, logger)
+	if invalid, ok := err.(*invalidPipelineError); ok {
+		logger.WithError(invalid).Info("commit has no valid pipeline; skipping")
+		statusRef.Error = invalid.Error()
+	} else if err != nil {
+		return err
+	} else {
+		statusRef.Commit = pipeline.Spec.Workspace.Repo.Commit
+		statusRef.Pipeline = pipeline.GetName()
+	}
+
+	forge := *latest.DeepCopy()
+	if existing := forge.GetStatusRef(statusRef.Name); existing != nil {
+		*existing = statusRef
+	} else {
+		forge.Status.Refs = append(forge.Status.Refs, statusRef)
+		sort.Slice(forge.Status.Refs, func(i, j int) bool {
+			return forge.Status.Refs[i].Name < forge.Status.Refs[j].Name
+		})
+	}
+
+	if _, err := generic.PatchForge(c.kubesmithClient, forge, *latest); err != nil {
+		return errors.Wrap(err, "could not record the commit of the ref")
+	}
+
+	logger.WithField(logging.FieldPipeline, statusRef.Pipeline).Info("processed webhook")
+	return nil
+}
+
+// isPullRequestCommitBuilt checks whether a pipeline was already created for
+// the commit of the pull request; pull requests aren't recorded in the refs
+// of the forge
+func (c *ForgeController) isPullRequestCommitBuilt(original api.Forge, event webhook.Event) (bool, error) {
+	selector := labels.SelectorFromSet(labels.Set{
+		api.GetLabelKey("Forge"):               original.GetName(),
+		api.GetLabelKey("PipelinePullRequest"): strconv.Itoa(event.PullRequest.Number),
+	})
+
+	pipelines, err := c.pipelineLister.Pipelines(original.GetNamespace()).List(selector)
+	if err != nil {
+		return false, errors.Wrap(err, "could not list the pipelines of the pull request")
+	}
+
+	for _, pipeline := range pipelines {
+		if pipeline.Spec.Workspace.Repo.Commit == event.Commit {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func writeWebhookResponse(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprintln(w, message)
 }
@@ -0,0 +1,325 @@
+package forge
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/controllers/generic"
+	"github.com/kubesmith/kubesmith/pkg/generated/clientset/versioned/fake"
+	kubesmithInformers "github.com/kubesmith/kubesmith/pkg/generated/informers/externalversions"
+	"github.com/kubesmith/kubesmith/pkg/git/gittest"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	coreListersv1 "k8s.io/client-go/listers/core/v1"
+	ktesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+)
+
+const testPipelineFile = "spec:\n  stages:\n  - build\n"
+
+// newTestController creates a forge controller whose client holds the forge;
+// the pipelines created through the client are named like the api server
+// would and returned in the order they were created
+func newTestController(forge api.Forge, now time.Time) (*ForgeController, *fake.Clientset, *[]api.Pipeline) {
+	client := fake.NewSimpleClientset(&forge)
+
+	created := []api.Pipeline{}
+	client.PrependReactor("create", "pipelines", func(action ktesting.Action) (bool, runtime.Object, error) {
+		pipeline := action.(ktesting.CreateAction).GetObject().(*api.Pipeline).DeepCopy()
+		pipeline.SetName(fmt.Sprintf("%s%d", pipeline.GetGenerateName(), len(created)+1))
+
+		created = append(created, *pipeline)
+		return true, pipeline, nil
+	})
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+
+	factory := kubesmithInformers.NewSharedInformerFactory(client, 0)
+	controller := &ForgeController{
+		GenericController: generic.NewGenericController("Forge", logger),
+		logger:            logger,
+		kubesmithClient:   client.KubesmithV1(),
+		forgeLister:       factory.Kubesmith().V1().Forges().Lister(),
+		pipelineLister:    factory.Kubesmith().V1().Pipelines().Lister(),
+		secretLister:      coreListersv1.NewSecretLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})),
+		clock:             clock.NewFakeClock(now),
+		polls:             map[string]time.Time{},
+	}
+
+	return controller, client, &created
+}
+
+func TestProcessPolledForge(t *testing.T) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	polled := metav1.NewTime(now.Add(-time.Hour))
+
+	tests := []struct {
+		name string
+
+		// setup pushes to the repo and returns the cached forge and the
+		// refs recorded in the latest forge, which default to the cached ones
+		setup func(repo *gittest.Repo, forge *api.Forge) []api.ForgeStatusRef
+
+		// expected returns the refs expected after the poll
+		expected  func(repo *gittest.Repo) []api.ForgeStatusRef
+		pipelines []string
+		failure   string
+	}{
+		{
+			name: "the first poll records the refs without building them",
+			setup: func(repo *gittest.Repo, forge *api.Forge) []api.ForgeStatusRef {
+				repo.Commit("main", map[string]string{".kubesmith.yml": testPipelineFile})
+				forge.Status.LastPolledTime = metav1.Time{}
+				return nil
+			},
+			expected: func(repo *gittest.Repo) []api.ForgeStatusRef {
+				return []api.ForgeStatusRef{{Name: "refs/heads/main", Commit: repo.RevParse("main")}}
+			},
+			pipelines: []string{},
+		},
+		{
+			name: "new commits are built",
+			setup: func(repo *gittest.Repo, forge *api.Forge) []api.ForgeStatusRef {
+				first := repo.Commit("main", map[string]string{".kubesmith.yml": testPipelineFile})
+				repo.Commit("main", map[string]string{"README": "changed"})
+				forge.Status.Refs = []api.ForgeStatusRef{{Name: "refs/heads/main", Commit: first, Pipeline: "app-old"}}
+				return nil
+			},
+			expected: func(repo *gittest.Repo) []api.ForgeStatusRef {
+				return []api.ForgeStatusRef{{Name: "refs/heads/main", Commit: repo.RevParse("main"), Pipeline: "app-1"}}
+			},
+			pipelines: []string{"app-1"},
+		},
+		{
+			name: "unchanged refs are not built again",
+			setup: func(repo *gittest.Repo, forge *api.Forge) []api.ForgeStatusRef {
+				commit := repo.Commit("main", map[string]string{".kubesmith.yml": testPipelineFile})
+				forge.Status.Refs = []api.ForgeStatusRef{{Name: "refs/heads/main", Commit: commit, Pipeline: "app-old"}}
+				return nil
+			},
+			expected: func(repo *gittest.Repo) []api.ForgeStatusRef {
+				return []api.ForgeStatusRef{{Name: "refs/heads/main", Commit: repo.RevParse("main"), Pipeline: "app-old"}}
+			},
+			pipelines: []string{},
+		},
+		{
+			name: "new branches and tags are built and deleted ones are dropped",
+			setup: func(repo *gittest.Repo, forge *api.Forge) []api.ForgeStatusRef {
+				commit := repo.Commit("main", map[string]string{".kubesmith.yml": testPipelineFile})
+				repo.Tag("v1.0.0", true)
+				repo.Tag("unwatched", true)
+				repo.Commit("release-1", map[string]string{})
+				repo.Commit("other", map[string]string{})
+				forge.Status.Refs = []api.ForgeStatusRef{
+					{Name: "refs/heads/deleted", Commit: commit},
+					{Name: "refs/heads/main", Commit: commit},
+				}
+				return nil
+			},
+			expected: func(repo *gittest.Repo) []api.ForgeStatusRef {
+				return []api.ForgeStatusRef{
+					{Name: "refs/heads/main", Commit: repo.RevParse("main")},
+					{Name: "refs/heads/release-1", Commit: repo.RevParse("release-1"), Pipeline: "app-1"},
+					{Name: "refs/tags/v1.0.0", Commit: repo.RevParse("v1.0.0^{commit}"), Pipeline: "app-2"},
+				}
+			},
+			pipelines: []string{"app-1", "app-2"},
+		},
+		{
+			name: "commits without a pipeline file are recorded with the reason",
+			setup: func(repo *gittest.Repo, forge *api.Forge) []api.ForgeStatusRef {
+				first := repo.Commit("main", map[string]string{".kubesmith.yml": testPipelineFile})
+				repo.Commit("main", map[string]string{".kubesmith.yml": ""})
+				forge.Status.Refs = []api.ForgeStatusRef{{Name: "refs/heads/main", Commit: first}}
+				return nil
+			},
+			expected: func(repo *gittest.Repo) []api.ForgeStatusRef {
+				return []api.ForgeStatusRef{{Name: "refs/heads/main", Commit: repo.RevParse("main"), Error: ".kubesmith.yml does not exist"}}
+			},
+			pipelines: []string{},
+		},
+		{
+			name: "commits recorded by the webhook since the forge was cached are not built again",
+			setup: func(repo *gittest.Repo, forge *api.Forge) []api.ForgeStatusRef {
+				first := repo.Commit("main", map[string]string{".kubesmith.yml": testPipelineFile})
+				second := repo.Commit("main", map[string]string{"README": "changed"})
+				forge.Status.Refs = []api.ForgeStatusRef{{Name: "refs/heads/main", Commit: first, Pipeline: "app-old"}}
+				return []api.ForgeStatusRef{{Name: "refs/heads/main", Commit: second, Pipeline: "app-webhook"}}
+			},
+			expected: func(repo *gittest.Repo) []api.ForgeStatusRef {
+				return []api.ForgeStatusRef{{Name: "refs/heads/main", Commit: repo.RevParse("main"), Pipeline: "app-webhook"}}
+			},
+			pipelines: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repo := gittest.NewRepo(t)
+			cached := api.Forge{
+				ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "kubesmith"},
+				Spec: api.ForgeSpec{
+					Repo:     api.WorkspaceRepo{URL: repo.Bare},
+					Branches: []string{"main", "release-*"},
+					Tags:     []string{"v*"},
+				},
+				Status: api.ForgeStatus{LastPolledTime: polled},
+			}
+
+			refs := test.setup(repo, &cached)
+			latest := *cached.DeepCopy()
+			if refs != nil {
+				latest.Status.Refs = refs
+			}
+
+			controller, client, created := newTestController(latest, now)
+			if err := controller.processPolledForge(cached, controller.logger); err != nil {
+				t.Fatalf("could not poll forge: %v", err)
+			}
+
+			forge, err := client.KubesmithV1().Forges("kubesmith").Get("app", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if expected := test.expected(repo); !reflect.DeepEqual(forge.Status.Refs, expected) {
+				t.Errorf("expected refs %+v, got %+v", expected, forge.Status.Refs)
+			}
+
+			if forge.Status.FailureReason != test.failure {
+				t.Errorf("expected failure reason %q, got %q", test.failure, forge.Status.FailureReason)
+			}
+
+			if !forge.Status.LastPolledTime.Time.Equal(now) {
+				t.Errorf("expected the forge to be polled at %s, got %s", now, forge.Status.LastPolledTime)
+			}
+
+			names := []string{}
+			for _, pipeline := range *created {
+				names = append(names, pipeline.GetName())
+
+				ref := forge.GetStatusRef(pipeline.Spec.Workspace.Repo.Ref)
+				if ref == nil || ref.Commit != pipeline.Spec.Workspace.Repo.Commit {
+					t.Errorf("pipeline %s was created for %s at %s which is not recorded", pipeline.GetName(), pipeline.Spec.Workspace.Repo.Ref, pipeline.Spec.Workspace.Repo.Commit)
+				}
+			}
+
+			if !reflect.DeepEqual(names, test.pipelines) {
+				t.Errorf("expected pipelines %v, got %v", test.pipelines, names)
+			}
+		})
+	}
+}
+
+func TestProcessPolledForgeUnreachableRepo(t *testing.T) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	refs := []api.ForgeStatusRef{{Name: "refs/heads/main", Commit: "1111111111111111111111111111111111111111"}}
+
+	cached := api.Forge{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "kubesmith"},
+		Spec: api.ForgeSpec{
+			Repo:     api.WorkspaceRepo{URL: filepath.Join(os.TempDir(), "kubesmith-missing-repo.git")},
+			Branches: []string{"main"},
+		},
+		Status: api.ForgeStatus{LastPolledTime: metav1.NewTime(now.Add(-time.Hour)), Refs: refs},
+	}
+
+	controller, client, _ := newTestController(cached, now)
+	if err := controller.processPolledForge(cached, controller.logger); err != nil {
+		t.Fatalf("could not poll forge: %v", err)
+	}
+
+	forge, err := client.KubesmithV1().Forges("kubesmith").Get("app", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if forge.Status.FailureReason == "" {
+		t.Error("expected the failure reason to be set")
+	}
+
+	if !reflect.DeepEqual(forge.Status.Refs, refs) {
+		t.Errorf("expected refs %+v to be kept, got %+v", refs, forge.Status.Refs)
+	}
+}
+
+func TestRunScheduleIfDueMissedRuns(t *testing.T) {
+	now := time.Date(2020, 1, 1, 12, 0, 30, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		lastScheduleTime time.Time
+		lastRun          time.Time
+		err              string
+	}{
+		{
+			// the latest run is found, and then skipped for missing its
+			// deadline
+			name:             "missed runs up to the limit",
+			lastScheduleTime: now.Add(-maxMissedScheduleRuns * time.Minute),
+			lastRun:          time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC),
+			err:              "missed the starting deadline of the run at 2020-01-01T12:00:00Z",
+		},
+		{
+			name:             "missed runs beyond the limit",
+			lastScheduleTime: now.Add(-(maxMissedScheduleRuns + 1) * time.Minute),
+			lastRun:          now,
+			err:              "missed more than 100 runs since 2020-01-01T10:19:30Z; check the clock or set a starting deadline",
+		},
+		{
+			name:             "a year of missed runs",
+			lastScheduleTime: now.AddDate(-1, 0, 0),
+			lastRun:          now,
+			err:              "missed more than 100 runs since 2019-01-01T12:00:30Z; check the clock or set a starting deadline",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schedule := api.ForgeSchedule{
+				Name:                    "nightly",
+				Cron:                    "* * * * *",
+				Branch:                  "main",
+				StartingDeadlineSeconds: 10,
+			}
+
+			forge := api.Forge{
+				ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "kubesmith"},
+				Spec:       api.ForgeSpec{Schedules: []api.ForgeSchedule{schedule}},
+			}
+
+			controller, _, created := newTestController(forge, now)
+			status := api.ForgeStatusSchedule{
+				Name:             schedule.Name,
+				LastScheduleTime: metav1.NewTime(test.lastScheduleTime),
+				Pipeline:         "app-1",
+			}
+
+			status, err := controller.runScheduleIfDue(forge, schedule, status, controller.logger)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !status.LastScheduleTime.Time.Equal(test.lastRun) {
+				t.Errorf("expected the last run at %s, got %s", test.lastRun, status.LastScheduleTime)
+			}
+
+			if status.Error != test.err {
+				t.Errorf("expected error %q, got %q", test.err, status.Error)
+			}
+
+			if status.Pipeline != "" || len(*created) > 0 {
+				t.Errorf("expected no pipeline, got %q and %d created", status.Pipeline, len(*created))
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package forge
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/kubesmith/kubesmith/pkg/logging"
+	"github.com/kubesmith/kubesmith/pkg/sync"
+	"github.com/kubesmith/kubesmith/pkg/webhook"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeWebhookResponse(w, http.StatusMethodNotAllowed, "webhooks must be posted")
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, webhookPathPrefix)
+	logger := h.logger.WithFields(logrus.Fields{
+		logging.FieldNamespace: h.namespace,
+		logging.FieldForge:     name,
+	})
+
+	forge, err := h.forgeLister.Forges(h.namespace).Get(name)
+	if apierrors.IsNotFound(err) || name == "" {
+		writeWebhookResponse(w, http.StatusNotFound, fmt.Sprintf("forge %q does not exist", name))
+		return
+	} else if err != nil {
+		logger.WithError(err).Error("could not get forge")
+		writeWebhookResponse(w, http.StatusInternalServerError, "could not get forge")
+		return
+	}
+
+	if !forge.HasWebhook() {
+		writeWebhookResponse(w, http.StatusForbidden, "forge has no webhook secret")
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookSize))
+	if err != nil {
+		writeWebhookResponse(w, http.StatusBadRequest, "could not read webhook")
+		return
+	}
+
+	payload, err := webhook.NewPayload(r.Header, body)
+	if err != nil {
+		writeWebhookResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	secret := forge.Spec.Webhook.Secret
+	value, err := getSecretValue(h.secretLister, forge.GetNamespace(), secret.Name, secret.Key)
+	if err != nil {
+		logger.WithError(err).Error("could not get the webhook secret")
+		writeWebhookResponse(w, http.StatusInternalServerError, "could not get the webhook secret")
+		return
+	}
+
+	if err := payload.Verify(value); err != nil {
+		logger.WithField("provider", payload.GetProvider()).Info("webhook signature is invalid; rejecting")
+		writeWebhookResponse(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	events, err := payload.GetEvents()
+	if err != nil {
+		writeWebhookResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// the pipelines are created by the forge controller; reading them from
+	// the repo takes longer than git servers wait for an answer
+	results := []string{}
+	for _, event := range events {
+		if reason := getIgnoredEventReason(*forge, event); reason != "" {
+			results = append(results, fmt.Sprintf("%s: %s; ignoring", event.Ref, reason))
+			continue
+		}
+
+		h.queue.Add(sync.ForgeWebhookAction(*forge.DeepCopy(), event))
+		logger.WithField("ref", event.Ref).Info("queued webhook")
+		results = append(results, fmt.Sprintf("%s: queued", event.Ref))
+	}
+
+	if len(results) == 0 {
+		writeWebhookResponse(w, http.StatusOK, "webhook has no push, tag or pull request events")
+		return
+	}
+
+	writeWebhookResponse(w, http.StatusAccepted, strings.Join(results, "\n"))
+}
+
+func (e *invalidPipelineError) Error() string {
+	return e.reason
+}
@@ -1,12 +1,36 @@
 package forge
 
 import (
+	gosync "sync"
+	"time"
+
 	"github.com/kubesmith/kubesmith/pkg/controllers/generic"
 	kubesmithv1 "github.com/kubesmith/kubesmith/pkg/generated/clientset/versioned/typed/kubesmith/v1"
 	listers "github.com/kubesmith/kubesmith/pkg/generated/listers/kubesmith/v1"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/client-go/kubernetes"
+	coreListersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	// commitLength is the length of a full sha1 commit; abbreviated commits
+	// can't be fetched
+	commitLength = 40
+
+	// maxWebhookSize is the largest webhook payload that is read
+	maxWebhookSize = 25 << 20
+
+	webhookPathPrefix = "/webhooks/"
+
+	// scheduleResyncPeriod is how often the schedules of the forges are
+	// checked; like a CronJob, a run may start this much late
+	scheduleResyncPeriod = 10 * time.Second
+
+	// maxMissedScheduleRuns is how many missed runs of a schedule are looked
+	// at before giving up on them, like a CronJob does
+	maxMissedScheduleRuns = 100
 )
 
 type ForgeController struct {
@@ -16,6 +40,30 @@ type ForgeController struct {
 	kubeClient      kubernetes.Interface
 	kubesmithClient kubesmithv1.KubesmithV1Interface
 
-	forgeLister listers.ForgeLister
-	clock       clock.Clock
+	forgeLister    listers.ForgeLister
+	pipelineLister listers.PipelineLister
+	secretLister   coreListersv1.SecretLister
+	clock          clock.Clock
+
+	// polls is when the next poll of every forge (by namespace/name) is
+	// queued for so that updates don't queue the same poll twice
+	polls     map[string]time.Time
+	pollsLock gosync.Mutex
+}
+
+// WebhookHandler receives the pushes, tags and pull requests sent to
+// /webhooks/<forge> by the git server of a forge and queues them for the
+// forge controller, which creates their pipelines
+type WebhookHandler struct {
+	logger    logrus.FieldLogger
+	namespace string
+	queue     workqueue.Interface
+
+	forgeLister  listers.ForgeLister
+	secretLister coreListersv1.SecretLister
+}
+
+// invalidPipelineError is returned for commits whose pipeline can't be read
+type invalidPipelineError struct {
+	reason string
 }
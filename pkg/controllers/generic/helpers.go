@@ -1,12 +1,140 @@
 package generic
 
-import "k8s.io/client-go/util/workqueue"
+import (
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	kubesmithv1 "github.com/kubesmith/kubesmith/pkg/generated/clientset/versioned/typed/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/logging"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/workqueue"
+)
 
-func NewGenericController(name string) *GenericController {
+func NewGenericController(name string, logger logrus.FieldLogger) *GenericController {
 	c := &GenericController{
-		Name:  name,
-		Queue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), name),
+		Name:   name,
+		Logger: logger.WithField(logging.FieldController, name),
+		Queue:  workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), name),
 	}
 
 	return c
 }
+
+// IsMissingSubresource reports whether a request to a subresource failed
+// because it isn't served. Patches are sent to the resource and then to its
+// status subresource: once a crd enables the subresource, the resource ignores
+// changes to the status and the subresource ignores everything else, while
+// crds installed without it don't serve it and keep the status on the resource.
+// Resources that don't exist are named in the details of the error while
+// paths that aren't served have no details or an unexpected response.
+func IsMissingSubresource(err error) bool {
+	if !apierrors.IsNotFound(err) {
+		return false
+	}
+
+	status, ok := err.(apierrors.APIStatus)
+	if !ok {
+		return false
+	}
+
+	details := status.Status().Details
+	if details == nil || details.Name == "" {
+		return true
+	}
+
+	for _, cause := range details.Causes {
+		if cause.Type == metav1.CauseTypeUnexpectedServerResponse {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PatchWithStatus sends a patch to a resource and then to its status
+// subresource; the resource is returned as patched by the resource when the
+// status subresource isn't served
+func PatchWithStatus(patch func(subresources ...string) (runtime.Object, error)) (runtime.Object, error) {
+	patched, err := patch()
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := patch("status")
+	if IsMissingSubresource(err) {
+		return patched, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+func PatchPipeline(client kubesmithv1.KubesmithV1Interface, updated, original api.Pipeline) (*api.Pipeline, error) {
+	patchType, patchBytes, err := updated.GetPatchFromOriginal(original)
+	if err != nil {
+		return nil, err
+	}
+
+	patched, err := PatchWithStatus(func(subresources ...string) (runtime.Object, error) {
+		return client.Pipelines(original.GetNamespace()).Patch(original.GetName(), patchType, patchBytes, subresources...)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return patched.(*api.Pipeline), nil
+}
+
+func PatchPipelineStage(client kubesmithv1.KubesmithV1Interface, updated, original api.PipelineStage) (*api.PipelineStage, error) {
+	patchType, patchBytes, err := updated.GetPatchFromOriginal(original)
+	if err != nil {
+		return nil, err
+	}
+
+	patched, err := PatchWithStatus(func(subresources ...string) (runtime.Object, error) {
+		return client.PipelineStages(original.GetNamespace()).Patch(original.GetName(), patchType, patchBytes, subresources...)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return patched.(*api.PipelineStage), nil
+}
+
+func PatchPipelineJob(client kubesmithv1.KubesmithV1Interface, updated, original api.PipelineJob) (*api.PipelineJob, error) {
+	patchType, patchBytes, err := updated.GetPatchFromOriginal(original)
+	if err != nil {
+		return nil, err
+	}
+
+	patched, err := PatchWithStatus(func(subresources ...string) (runtime.Object, error) {
+		return client.PipelineJobs(original.GetNamespace()).Patch(original.GetName(), patchType, patchBytes, subresources...)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return patched.(*api.PipelineJob), nil
+}
+
+func PatchForge(client kubesmithv1.KubesmithV1Interface, updated, original api.Forge) (*api.Forge, error) {
+	patchType, patchBytes, err := updated.GetPatchFromOriginal(original)
+	if err != nil {
+		return nil, err
+	}
+
+	patched, err := PatchWithStatus(func(subresources ...string) (runtime.Object, error) {
+		return client.Forges(original.GetNamespace()).Patch(original.GetName(), patchType, patchBytes, subresources...)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return patched.(*api.Forge), nil
+}
@@ -1,9 +1,10 @@
 package generic
 
 import (
-	"github.com/golang/glog"
+	"github.com/kubesmith/kubesmith/pkg/logging"
 	"github.com/kubesmith/kubesmith/pkg/sync"
 	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
 	"k8s.io/client-go/tools/cache"
 )
 
@@ -22,7 +23,10 @@ func (c *GenericController) processNextWorkItem() bool {
 	// it back with rate-limiting below
 	defer c.Queue.Done(key)
 
-	err := c.SyncHandler(key.(sync.SyncAction))
+	// every reconcile gets its own id so its log entries can be correlated
+	logger := c.Logger.WithField(logging.FieldReconcileID, uuid.NewV4().String())
+
+	err := c.SyncHandler(key.(sync.SyncAction), logger)
 	if err == nil {
 		// If you had no error, tell the queue to stop tracking history for your key. This will reset
 		// things like failure counts for per-item rate limiting.
@@ -30,7 +34,7 @@ func (c *GenericController) processNextWorkItem() bool {
 		return true
 	}
 
-	glog.Error(errors.Wrap(err, "Error in syncHandler, re-adding item to queue"))
+	logger.Error(errors.Wrap(err, "Error in syncHandler, re-adding item to queue"))
 	// we had an error processing the item so add it back
 	// into the queue for re-processing with rate-limiting
 	c.Queue.AddRateLimited(key)
@@ -41,8 +45,8 @@ func (c *GenericController) processNextWorkItem() bool {
 func (c *GenericController) enqueue(obj interface{}) {
 	key, err := cache.MetaNamespaceKeyFunc(obj)
 	if err != nil {
-		glog.Info("Error creating queue key, item not added to queue")
-		glog.Error(err)
+		c.Logger.Info("Error creating queue key, item not added to queue")
+		c.Logger.Error(err)
 		return
 	}
 
@@ -52,3 +56,17 @@ func (c *GenericController) enqueue(obj interface{}) {
 func (c *GenericController) enqueueSecond(_, obj interface{}) {
 	c.enqueue(obj)
 }
+
+func (c *GenericController) setRunning(running bool) {
+	c.runningLock.Lock()
+	defer c.runningLock.Unlock()
+
+	c.running = running
+}
+
+func (c *GenericController) isRunning() bool {
+	c.runningLock.RLock()
+	defer c.runningLock.RUnlock()
+
+	return c.running
+}
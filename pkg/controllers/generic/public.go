@@ -3,10 +3,10 @@ package generic
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
-	"github.com/golang/glog"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 )
@@ -19,22 +19,23 @@ func (c *GenericController) Run(ctx context.Context, numWorkers int) error {
 	var wg sync.WaitGroup
 
 	defer func() {
-		glog.V(1).Info("Waiting for workers to finish their work...")
+		c.setRunning(false)
+		c.Logger.Debug("Waiting for workers to finish their work...")
 
 		c.Queue.ShutDown()
 		wg.Wait()
 
-		glog.V(1).Info("All workers have finished")
+		c.Logger.Debug("All workers have finished")
 	}()
 
-	glog.V(1).Info("Starting controller")
-	defer glog.V(1).Info("Shutting down controller")
+	c.Logger.Debug("Starting controller")
+	defer c.Logger.Debug("Shutting down controller")
 
-	glog.V(1).Info("Waiting for caches to sync")
+	c.Logger.Debug("Waiting for caches to sync")
 	if !cache.WaitForCacheSync(ctx.Done(), c.CacheSyncWaiters...) {
 		return errors.New("timed out waiting for caches to sync")
 	}
-	glog.V(1).Info("Caches are synced")
+	c.Logger.Debug("Caches are synced")
 
 	if c.SyncHandler != nil {
 		wg.Add(numWorkers)
@@ -58,7 +59,22 @@ func (c *GenericController) Run(ctx context.Context, numWorkers int) error {
 		}()
 	}
 
+	c.setRunning(true)
 	<-ctx.Done()
 
 	return nil
 }
+
+func (c *GenericController) Healthy() error {
+	if !c.isRunning() {
+		return fmt.Errorf("%s controller is not running", c.Name)
+	}
+
+	for _, hasSynced := range c.CacheSyncWaiters {
+		if !hasSynced() {
+			return fmt.Errorf("%s controller caches have not synced", c.Name)
+		}
+	}
+
+	return nil
+}
@@ -1,18 +1,24 @@
 package generic
 
 import (
+	gosync "sync"
 	"time"
 
 	"github.com/kubesmith/kubesmith/pkg/sync"
+	"github.com/sirupsen/logrus"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 )
 
 type GenericController struct {
 	Name             string
+	Logger           logrus.FieldLogger
 	Queue            workqueue.RateLimitingInterface
-	SyncHandler      func(action sync.SyncAction) error
+	SyncHandler      func(action sync.SyncAction, logger logrus.FieldLogger) error
 	ResyncFunc       func()
 	ResyncPeriod     time.Duration
 	CacheSyncWaiters []cache.InformerSynced
+
+	runningLock gosync.RWMutex
+	running     bool
 }
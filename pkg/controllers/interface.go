@@ -6,4 +6,8 @@ import "context"
 type Interface interface {
 	// Run runs the component.
 	Run(ctx context.Context, workers int) error
+
+	// Healthy returns an error if the component is not running or its caches
+	// have not synced.
+	Healthy() error
 }
@@ -5,11 +5,13 @@ import (
 	"github.com/kubesmith/kubesmith/pkg/controllers/generic"
 	kubesmithv1 "github.com/kubesmith/kubesmith/pkg/generated/clientset/versioned/typed/kubesmith/v1"
 	informers "github.com/kubesmith/kubesmith/pkg/generated/informers/externalversions/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/logging"
 	"github.com/kubesmith/kubesmith/pkg/sync"
 	"github.com/sirupsen/logrus"
 	batchv1 "k8s.io/api/batch/v1"
 	"k8s.io/apimachinery/pkg/util/clock"
 	batchInformersv1 "k8s.io/client-go/informers/batch/v1"
+	coreInformersv1 "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 )
@@ -19,14 +21,18 @@ func NewJobController(
 	kubeClient kubernetes.Interface,
 	kubesmithClient kubesmithv1.KubesmithV1Interface,
 	jobInformer batchInformersv1.JobInformer,
+	podInformer coreInformersv1.PodInformer,
+	pipelineInformer informers.PipelineInformer,
 	pipelineJobInformer informers.PipelineJobInformer,
 ) controllers.Interface {
 	c := &JobController{
-		GenericController: generic.NewGenericController("Job"),
-		logger:            logger.WithField("controller", "Job"),
+		GenericController: generic.NewGenericController("Job", logger),
+		logger:            logger.WithField(logging.FieldController, "Job"),
 		kubeClient:        kubeClient,
 		kubesmithClient:   kubesmithClient,
 		jobLister:         jobInformer.Lister(),
+		podLister:         podInformer.Lister(),
+		pipelineLister:    pipelineInformer.Lister(),
 		pipelineJobLister: pipelineJobInformer.Lister(),
 		clock:             &clock.RealClock{},
 	}
@@ -35,6 +41,8 @@ func NewJobController(
 	c.CacheSyncWaiters = append(
 		c.CacheSyncWaiters,
 		jobInformer.Informer().HasSynced,
+		podInformer.Informer().HasSynced,
+		pipelineInformer.Informer().HasSynced,
 		pipelineJobInformer.Informer().HasSynced,
 	)
 
@@ -1,24 +1,43 @@
 package job
 
 import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
 	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/controllers/generic"
+	"github.com/kubesmith/kubesmith/pkg/logging"
 	"github.com/kubesmith/kubesmith/pkg/sync"
+	"github.com/kubesmith/kubesmith/pkg/templates"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func (c *JobController) processJob(action sync.SyncAction) error {
+func (c *JobController) processJob(action sync.SyncAction, logger logrus.FieldLogger) error {
 	cachedJob := action.GetObject().(batchv1.Job)
 	job, err := c.jobLister.Jobs(cachedJob.GetNamespace()).Get(cachedJob.GetName())
 	if err != nil {
 		return errors.Wrap(err, "error getting job")
 	}
 
-	// create a new logger for this pipeline's execution
-	logger := c.logger.WithFields(logrus.Fields{
-		"Name": job.GetName(),
-	})
+	// create a new logger for this job's execution
+	logger = logger.WithFields(logging.GetResourceFields(job)).WithField("batchJob", job.GetName())
+
+	// clone repo jobs only matter to their pipeline when they fail
+	if c.isCloneRepoJob(*job) {
+		if job.Status.Failed == 1 {
+			return c.processFailedCloneRepoJob(*job.DeepCopy(), logger)
+		}
+
+		return nil
+	}
 
 	if job.Status.Succeeded == 1 {
 		return c.processSuccessfulJob(*job.DeepCopy(), logger)
@@ -37,16 +56,22 @@ func (c *JobController) processSuccessfulJob(original batchv1.Job, logger logrus
 	}
 	logger.Info("fetched associated pipeline job")
 
-	if pipelineJob.HasSucceeded() || pipelineJob.HasFailed() {
+	if pipelineJob.HasFinished() {
 		logger.Info("pipeline job has already completed; skipping")
 		return nil
 	}
 
+	if original.GetName() != pipelineJob.GetJobName() {
+		logger.Info("job belongs to an earlier attempt of the pipeline job; skipping")
+		return nil
+	}
+
 	logger.Info("marking pipeline job as success")
 	updatedPipelineJob := *pipelineJob.DeepCopy()
+	updatedPipelineJob.Status.TestReport = c.getTestReportFromJob(original, logger)
 	updatedPipelineJob.SetPhaseToSucceeded()
 
-	if _, err := c.patchPipelineJob(updatedPipelineJob, *pipelineJob); err != nil {
+	if _, err := generic.PatchPipelineJob(c.kubesmithClient, updatedPipelineJob, *pipelineJob); err != nil {
 		return errors.Wrap(err, "could not mark pipeline job as success")
 	}
 
@@ -62,18 +87,25 @@ func (c *JobController) processFailedJob(original batchv1.Job, logger logrus.Fie
 	}
 	logger.Info("fetched associated pipeline job")
 
-	if pipelineJob.HasSucceeded() || pipelineJob.HasFailed() {
+	if pipelineJob.HasFinished() {
 		logger.Info("pipeline job has already completed; skipping")
 		return nil
 	}
 
+	if original.GetName() != pipelineJob.GetJobName() {
+		logger.Info("job belongs to an earlier attempt of the pipeline job; skipping")
+		return nil
+	}
+
+	logger.Info("inspecting job for failure details")
+	failure := c.getFailureFromJob(original, logger)
+
 	logger.Info("marking pipeline job as failed")
 	updatedPipelineJob := *pipelineJob.DeepCopy()
+	updatedPipelineJob.Status.TestReport = c.getTestReportFromJob(original, logger)
+	updatedPipelineJob.SetPhaseToFailedWithDetails(failure)
 
-	// todo: improve this failure reason
-	updatedPipelineJob.SetPhaseToFailed("job failed")
-
-	if _, err := c.patchPipelineJob(updatedPipelineJob, *pipelineJob); err != nil {
+	if _, err := generic.PatchPipelineJob(c.kubesmithClient, updatedPipelineJob, *pipelineJob); err != nil {
 		return errors.Wrap(err, "could not mark pipeline job as failed")
 	}
 
@@ -81,6 +113,263 @@ func (c *JobController) processFailedJob(original batchv1.Job, logger logrus.Fie
 	return nil
 }
 
+func (c *JobController) processFailedCloneRepoJob(original batchv1.Job, logger logrus.FieldLogger) error {
+	logger.Info("fetching associated pipeline")
+	pipeline, err := c.getAssociatedPipeline(original)
+	if err != nil {
+		return err
+	}
+	logger.Info("fetched associated pipeline")
+
+	if pipeline.HasFinished() {
+		logger.Info("pipeline has already completed; skipping")
+		return nil
+	}
+
+	if attempt, _ := c.getLabelByKey(original, "PipelineAttempt"); attempt != strconv.Itoa(pipeline.GetAttempt()) {
+		logger.Info("job belongs to an earlier attempt of the pipeline; skipping")
+		return nil
+	}
+
+	logger.Info("inspecting clone repo job for failure details")
+	reason := c.getCloneRepoFailureFromJob(original, logger)
+
+	logger.Info("marking pipeline as failed")
+	updatedPipeline := *pipeline.DeepCopy()
+	updatedPipeline.SetPhaseToFailed(fmt.Sprintf("could not clone repo: %s", reason))
+
+	if _, err := generic.PatchPipeline(c.kubesmithClient, updatedPipeline, *pipeline); err != nil {
+		return errors.Wrap(err, "could not mark pipeline as failed")
+	}
+
+	logger.Info("marked pipeline as failed")
+	return nil
+}
+
+// getCloneRepoFailureFromJob returns the end of the termination message of the
+// first container of the clone repo job that failed; the checkout container
+// falls back to its log when it didn't write a message
+func (c *JobController) getCloneRepoFailureFromJob(original batchv1.Job, logger logrus.FieldLogger) string {
+	reason := "clone repo job failed"
+	for _, condition := range original.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue && condition.Message != "" {
+			reason = condition.Message
+		}
+	}
+
+	pod, err := c.getJobPod(original)
+	if err != nil {
+		logger.Info(errors.Wrap(err, "could not retrieve pod for job"))
+		return reason
+	}
+
+	for _, status := range pod.Status.ContainerStatuses {
+		terminated := status.State.Terminated
+		if terminated == nil || terminated.ExitCode == 0 {
+			continue
+		}
+
+		lines := []string{}
+		for _, line := range strings.Split(strings.TrimSpace(terminated.Message), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, line)
+			}
+		}
+
+		if len(lines) > cloneFailureMessageLines {
+			lines = lines[len(lines)-cloneFailureMessageLines:]
+		}
+
+		if len(lines) == 0 {
+			return fmt.Sprintf("%s exited with code %d", status.Name, terminated.ExitCode)
+		}
+
+		return strings.Join(lines, " ")
+	}
+
+	return reason
+}
+
+func (c *JobController) getFailureFromJob(original batchv1.Job, logger logrus.FieldLogger) api.PipelineJobFailure {
+	failure := api.PipelineJobFailure{}
+
+	// the job itself knows whether it was killed for running too long
+	for _, condition := range original.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			failure.Reason = condition.Reason
+			failure.Message = condition.Message
+			failure.DeadlineExceeded = condition.Reason == "DeadlineExceeded"
+		}
+	}
+
+	pod, err := c.getJobPod(original)
+	if err != nil {
+		logger.Info(errors.Wrap(err, "could not retrieve pod for job"))
+		return failure
+	}
+
+	if pod.Status.Reason == "Evicted" {
+		failure.Evicted = true
+		failure.Reason = pod.Status.Reason
+		failure.Message = pod.Status.Message
+	}
+
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name != templates.PipelineJobPrimaryContainerName || status.State.Terminated == nil {
+			continue
+		}
+
+		terminated := status.State.Terminated
+		failure.Container = status.Name
+		failure.ExitCode = terminated.ExitCode
+		failure.OOMKilled = terminated.Reason == "OOMKilled"
+
+		if !failure.Evicted && !failure.DeadlineExceeded {
+			failure.Reason = terminated.Reason
+			failure.Message = terminated.Message
+		}
+	}
+
+	logTail, err := c.getContainerLogTail(*pod, templates.PipelineJobPrimaryContainerName)
+	if err != nil {
+		logger.Info(errors.Wrap(err, "could not retrieve log tail for job"))
+	}
+
+	failure.LogTail = logTail
+	return failure
+}
+
+func (c *JobController) getTestReportFromJob(original batchv1.Job, logger logrus.FieldLogger) api.TestReport {
+	report := api.TestReport{}
+
+	pod, err := c.getJobPod(original)
+	if err != nil {
+		logger.Info(errors.Wrap(err, "could not retrieve pod for job"))
+		return report
+	}
+
+	// the anvil sidecar writes the summary of any junit reports it parsed
+	// as its termination message
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name != templates.PipelineJobAnvilSidecarContainerName || status.State.Terminated == nil {
+			continue
+		}
+
+		message := strings.TrimSpace(status.State.Terminated.Message)
+		if message == "" {
+			continue
+		}
+
+		if err := json.Unmarshal([]byte(message), &report); err != nil {
+			logger.Info(errors.Wrap(err, "could not parse test report"))
+		}
+	}
+
+	report.Summary = report.GetSummary()
+	return report
+}
+
+func (c *JobController) getJobPod(original batchv1.Job) (*corev1.Pod, error) {
+	if original.Spec.Selector == nil {
+		return nil, errors.New("job has no selector")
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(original.Spec.Selector)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse job selector")
+	}
+
+	pods, err := c.podLister.Pods(original.GetNamespace()).List(selector)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list pods")
+	} else if len(pods) == 0 {
+		return nil, errors.New("no pods found")
+	}
+
+	// prefer the most recently created pod
+	latest := pods[0]
+	for _, pod := range pods {
+		if latest.CreationTimestamp.Before(&pod.CreationTimestamp) {
+			latest = pod
+		}
+	}
+
+	return latest, nil
+}
+
+func (c *JobController) getContainerLogTail(pod corev1.Pod, containerName string) ([]string, error) {
+	tailLines := int64(failureLogTailLines)
+	request := c.kubeClient.CoreV1().Pods(pod.GetNamespace()).GetLogs(pod.GetName(), &corev1.PodLogOptions{
+		Container: containerName,
+		TailLines: &tailLines,
+	})
+
+	stream, err := request.Stream()
+	if err != nil {
+		return nil, err
+	}
+
+	defer stream.Close()
+	return readLogTail(stream, failureLogTailLines, failureLogTailBytes)
+}
+
+// readLogTail returns the last lines of the log that fit in both the maximum
+// number of lines and the maximum number of bytes; a line is never read into
+// memory past the maximum number of bytes
+func readLogTail(log io.Reader, maxLines, maxBytes int) ([]string, error) {
+	lines := []string{}
+	size := 0
+
+	reader := bufio.NewReader(log)
+	for {
+		line, err := readLogLine(reader, maxBytes)
+		if err == io.EOF {
+			return lines, nil
+		} else if err != nil {
+			return lines, err
+		}
+
+		lines = append(lines, line)
+		size += len(line)
+
+		for len(lines) > maxLines || size > maxBytes {
+			size -= len(lines[0])
+			lines = lines[1:]
+		}
+	}
+}
+
+// readLogLine reads the next line of the log and keeps at most the last bytes
+// of it that fit in the maximum number of bytes; truncated lines start with
+// truncatedLogLinePrefix
+func readLogLine(reader *bufio.Reader, maxBytes int) (string, error) {
+	line := []byte{}
+	truncated := false
+
+	for {
+		fragment, isPrefix, err := reader.ReadLine()
+		if err != nil {
+			return "", err
+		}
+
+		line = append(line, fragment...)
+		if len(line) > maxBytes {
+			line = line[len(line)-maxBytes:]
+			truncated = true
+		}
+
+		if !isPrefix {
+			break
+		}
+	}
+
+	if truncated {
+		return truncatedLogLinePrefix + string(line[len(truncatedLogLinePrefix):]), nil
+	}
+
+	return string(line), nil
+}
+
 func (c *JobController) getAssociatedPipelineJob(original batchv1.Job) (*api.PipelineJob, error) {
 	name, err := c.getLabelByKey(original, "PipelineJobName")
 	if err != nil {
@@ -95,6 +384,24 @@ func (c *JobController) getAssociatedPipelineJob(original batchv1.Job) (*api.Pip
 	return c.pipelineJobLister.PipelineJobs(namespace).Get(name)
 }
 
+func (c *JobController) getAssociatedPipeline(original batchv1.Job) (*api.Pipeline, error) {
+	name, err := c.getLabelByKey(original, "PipelineName")
+	if err != nil {
+		return nil, err
+	}
+
+	namespace, err := c.getLabelByKey(original, "PipelineNamespace")
+	if err != nil {
+		return nil, err
+	}
+
+	return c.pipelineLister.Pipelines(namespace).Get(name)
+}
+
+func (c *JobController) isCloneRepoJob(job batchv1.Job) bool {
+	return job.GetLabels()[api.GetLabelKey("PipelineCloneRepo")] == "true"
+}
+
 func (c *JobController) getLabelByKey(original batchv1.Job, key string) (string, error) {
 	labels := original.GetLabels()
 
@@ -112,14 +419,5 @@ func (c *JobController) jobHasWork(job *batchv1.Job) bool {
 	labels := job.GetLabels()
 	_, hasLabel := labels[api.GetLabelKey("PipelineJobName")]
 
-	return !isActive && (hasSucceeded || hasFailed) && hasLabel
-}
-
-func (c *JobController) patchPipelineJob(updated, original api.PipelineJob) (*api.PipelineJob, error) {
-	patchType, patchBytes, err := updated.GetPatchFromOriginal(original)
-	if err != nil {
-		return nil, err
-	}
-
-	return c.kubesmithClient.PipelineJobs(original.GetNamespace()).Patch(original.GetName(), patchType, patchBytes)
+	return !isActive && (hasSucceeded || hasFailed) && (hasLabel || c.isCloneRepoJob(*job))
 }
@@ -0,0 +1,66 @@
+package job
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadLogTail(t *testing.T) {
+	tests := []struct {
+		name     string
+		log      string
+		maxLines int
+		maxBytes int
+		expected []string
+	}{
+		{
+			name:     "empty log",
+			log:      "",
+			maxLines: 3,
+			maxBytes: 100,
+			expected: []string{},
+		},
+		{
+			name:     "log that fits",
+			log:      "one\ntwo\nthree",
+			maxLines: 3,
+			maxBytes: 100,
+			expected: []string{"one", "two", "three"},
+		},
+		{
+			name:     "too many lines",
+			log:      "one\ntwo\nthree\nfour\n",
+			maxLines: 2,
+			maxBytes: 100,
+			expected: []string{"three", "four"},
+		},
+		{
+			name:     "too many bytes",
+			log:      "one\ntwo\nthree\nfour\n",
+			maxLines: 10,
+			maxBytes: 9,
+			expected: []string{"three", "four"},
+		},
+		{
+			name:     "line longer than the read buffer",
+			log:      "first\n" + strings.Repeat("a", 10000) + "b\n",
+			maxLines: 10,
+			maxBytes: 20,
+			expected: []string{"..." + strings.Repeat("a", 16) + "b"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lines, err := readLogTail(strings.NewReader(test.log), test.maxLines, test.maxBytes)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(lines, test.expected) {
+				t.Errorf("expected %q, got %q", test.expected, lines)
+			}
+		})
+	}
+}
@@ -8,6 +8,25 @@ import (
 	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/client-go/kubernetes"
 	batchListersv1 "k8s.io/client-go/listers/batch/v1"
+	coreListersv1 "k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	// the number of lines from the end of a failed job's log that are kept
+	// in the pipeline job's status
+	failureLogTailLines = 50
+
+	// the number of bytes of the log tail of a failed job that are kept in
+	// the pipeline job's status; lines that don't fit are dropped from the
+	// start of the tail and a single line longer than this keeps its end
+	failureLogTailBytes = 8 * 1024
+
+	// the prefix of the lines of a log tail that were cut short
+	truncatedLogLinePrefix = "..."
+
+	// the number of lines from the end of the message of a failed clone repo
+	// job that are kept in the pipeline's failure reason
+	cloneFailureMessageLines = 5
 )
 
 type JobController struct {
@@ -18,6 +37,8 @@ type JobController struct {
 	kubesmithClient kubesmithv1.KubesmithV1Interface
 
 	jobLister         batchListersv1.JobLister
+	podLister         coreListersv1.PodLister
+	pipelineLister    kubesmithListersv1.PipelineLister
 	pipelineJobLister kubesmithListersv1.PipelineJobLister
 	clock             clock.Clock
 }
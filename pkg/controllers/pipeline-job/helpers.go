@@ -3,10 +3,12 @@ package pipelinejob
 import (
 	"github.com/davecgh/go-spew/spew"
 	"github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/commitstatus"
 	"github.com/kubesmith/kubesmith/pkg/controllers"
 	"github.com/kubesmith/kubesmith/pkg/controllers/generic"
 	kubesmithv1 "github.com/kubesmith/kubesmith/pkg/generated/clientset/versioned/typed/kubesmith/v1"
 	informers "github.com/kubesmith/kubesmith/pkg/generated/informers/externalversions/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/logging"
 	"github.com/kubesmith/kubesmith/pkg/sync"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/util/clock"
@@ -19,19 +21,25 @@ import (
 func NewPipelineJobController(
 	maxRunningPipelineJobs int,
 	logger *logrus.Logger,
+	logConfig logging.Config,
 	kubeClient kubernetes.Interface,
 	kubesmithClient kubesmithv1.KubesmithV1Interface,
+	commitStatusReporter *commitstatus.Reporter,
+	pipelineInformer informers.PipelineInformer,
 	pipelineJobInformer informers.PipelineJobInformer,
 	pipelineStageInformer informers.PipelineStageInformer,
 	configMapInformer coreInformersv1.ConfigMapInformer,
 	jobInformer batchInformersv1.JobInformer,
 ) controllers.Interface {
 	c := &PipelineJobController{
-		GenericController:      generic.NewGenericController("PipelineJob"),
+		GenericController:      generic.NewGenericController("PipelineJob", logger),
 		maxRunningPipelineJobs: maxRunningPipelineJobs,
-		logger:                 logger.WithField("controller", "PipelineJob"),
+		logger:                 logger.WithField(logging.FieldController, "PipelineJob"),
+		logConfig:              logConfig,
 		kubeClient:             kubeClient,
 		kubesmithClient:        kubesmithClient,
+		commitStatusReporter:   commitStatusReporter,
+		pipelineLister:         pipelineInformer.Lister(),
 		pipelineJobLister:      pipelineJobInformer.Lister(),
 		pipelineStageLister:    pipelineStageInformer.Lister(),
 		configMapLister:        configMapInformer.Lister(),
@@ -42,12 +50,23 @@ func NewPipelineJobController(
 	c.SyncHandler = c.processPipelineJob
 	c.CacheSyncWaiters = append(
 		c.CacheSyncWaiters,
+		pipelineInformer.Informer().HasSynced,
 		pipelineJobInformer.Informer().HasSynced,
 		pipelineStageInformer.Informer().HasSynced,
 		configMapInformer.Informer().HasSynced,
 		jobInformer.Informer().HasSynced,
 	)
 
+	pipelineInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			UpdateFunc: func(oldObj, updatedObj interface{}) {
+				pipeline := updatedObj.(*v1.Pipeline)
+
+				c.requeueUpstreamPipelineJob(*pipeline)
+			},
+		},
+	)
+
 	pipelineJobInformer.Informer().AddEventHandler(
 		cache.ResourceEventHandlerFuncs{
 			AddFunc: func(obj interface{}) {
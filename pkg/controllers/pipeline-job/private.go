@@ -2,8 +2,12 @@ package pipelinejob
 
 import (
 	"fmt"
+	"time"
 
 	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/commitstatus"
+	"github.com/kubesmith/kubesmith/pkg/controllers/generic"
+	"github.com/kubesmith/kubesmith/pkg/logging"
 	"github.com/kubesmith/kubesmith/pkg/sync"
 	"github.com/kubesmith/kubesmith/pkg/templates"
 	"github.com/pkg/errors"
@@ -13,11 +17,9 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 )
 
-func (c *PipelineJobController) processPipelineJob(action sync.SyncAction) error {
+func (c *PipelineJobController) processPipelineJob(action sync.SyncAction, logger logrus.FieldLogger) error {
 	cachedJob := action.GetObject().(api.PipelineJob)
-	logger := c.logger.WithFields(logrus.Fields{
-		"Name": cachedJob.GetName(),
-	})
+	logger = logger.WithFields(logging.GetResourceFields(&cachedJob))
 
 	switch action.GetAction() {
 	case sync.SyncActionDelete:
@@ -25,7 +27,7 @@ func (c *PipelineJobController) processPipelineJob(action sync.SyncAction) error
 	default:
 		job, err := c.pipelineJobLister.PipelineJobs(cachedJob.GetNamespace()).Get(cachedJob.GetName())
 		if apierrors.IsNotFound(err) {
-			c.logger.Info("unable to find pipeline job")
+			logger.Info("unable to find pipeline job")
 			return nil
 		} else if err != nil {
 			return errors.Wrap(err, "error getting pipeline job")
@@ -33,7 +35,7 @@ func (c *PipelineJobController) processPipelineJob(action sync.SyncAction) error
 
 		// create a new logger for this pipeline job's execution
 		logger = logger.WithFields(logrus.Fields{
-			"Phase": job.Status.Phase,
+			"phase": job.Status.Phase,
 		})
 
 		// determine the phase and begin execution of the pipeline job
@@ -47,6 +49,8 @@ func (c *PipelineJobController) processPipelineJob(action sync.SyncAction) error
 			return c.processSuccessfulPipelineJob(*job.DeepCopy(), logger)
 		} else if job.HasFailed() {
 			return c.processFailedPipelineJob(*job.DeepCopy(), logger)
+		} else if job.IsCancelled() {
+			return c.processCancelledPipelineJob(*job.DeepCopy(), logger)
 		}
 	}
 
@@ -61,7 +65,7 @@ func (c *PipelineJobController) processEmptyPhasePipelineJob(original api.Pipeli
 		logger.Info("validation failed; marking as failed")
 
 		job.SetPhaseToFailed(err.Error())
-		if _, err := c.patchPipelineJob(job, original); err != nil {
+		if _, err := generic.PatchPipelineJob(c.kubesmithClient, job, original); err != nil {
 			return errors.Wrap(err, "could not mark as failed")
 		}
 
@@ -71,7 +75,7 @@ func (c *PipelineJobController) processEmptyPhasePipelineJob(original api.Pipeli
 
 	logger.Info("validated; marking as queued")
 	job.SetPhaseToQueued()
-	if _, err := c.patchPipelineJob(job, original); err != nil {
+	if _, err := generic.PatchPipelineJob(c.kubesmithClient, job, original); err != nil {
 		return errors.Wrap(err, "could not mark as queued")
 	}
 
@@ -81,6 +85,7 @@ func (c *PipelineJobController) processEmptyPhasePipelineJob(original api.Pipeli
 
 func (c *PipelineJobController) processQueuedPipelineJob(original api.PipelineJob, logger logrus.FieldLogger) error {
 	job := *original.DeepCopy()
+	c.reportCommitStatus(original, commitstatus.StatePending, "job is queued", logger)
 
 	logger.Info("checking if another pipeline job can be run")
 	canRunAnotherPipelineJob, err := c.canRunAnotherPipelineJob(job)
@@ -95,7 +100,7 @@ func (c *PipelineJobController) processQueuedPipelineJob(original api.PipelineJo
 
 	logger.Info("marking as running")
 	job.SetPhaseToRunning()
-	if _, err := c.patchPipelineJob(job, original); err != nil {
+	if _, err := generic.PatchPipelineJob(c.kubesmithClient, job, original); err != nil {
 		return errors.Wrap(err, "could not mark as running")
 	}
 
@@ -104,14 +109,17 @@ func (c *PipelineJobController) processQueuedPipelineJob(original api.PipelineJo
 }
 
 func (c *PipelineJobController) processRunningPipelineJob(original api.PipelineJob, logger logrus.FieldLogger) error {
+	c.reportCommitStatus(original, commitstatus.StateRunning, "job is running", logger)
+
+	if original.Spec.Job.IsTrigger() {
+		return c.processRunningTriggerPipelineJob(original, logger)
+	}
+
 	// update this copy of the job so it has the new labels (which will be used when the pipeline job
-	original.ObjectMeta.Labels = c.getWrappedLabels(original)
+	original.ObjectMeta.Labels = original.GetWrappedLabels()
 
 	// update this copy of the job so that runner is accurately configured (if specified)
-	if len(original.Spec.Job.Runner) > 0 {
-		original.Spec.Job.Command = []string{"/bin/sh", "-x", "/kubesmith/scripts/pipeline-script.sh"}
-		original.Spec.Job.Args = []string{}
-	}
+	original.UseRunnerScript()
 
 	if err := c.ensureJobConfigMapIsScheduled(original, logger); err != nil {
 		return errors.Wrap(err, "could not ensure job configmap is scheduled")
@@ -125,6 +133,11 @@ func (c *PipelineJobController) processRunningPipelineJob(original api.PipelineJ
 }
 
 func (c *PipelineJobController) processSuccessfulPipelineJob(original api.PipelineJob, logger logrus.FieldLogger) error {
+	if original.HasSucceeded() {
+		duration := original.Status.EndTime.Sub(original.Status.StartTime.Time).Round(time.Second)
+		c.reportCommitStatus(original, commitstatus.StateSuccess, fmt.Sprintf("job succeeded in %s", duration), logger)
+	}
+
 	logger.Info("fetching associated pipeline stage")
 	pipelineStage, err := c.getAssociatedPipelineStage(original)
 	if err != nil {
@@ -132,7 +145,7 @@ func (c *PipelineJobController) processSuccessfulPipelineJob(original api.Pipeli
 	}
 	logger.Info("fetched associated pipeline stage")
 
-	if pipelineStage.HasSucceeded() || pipelineStage.HasFailed() {
+	if pipelineStage.HasFinished() {
 		logger.Info("pipeline stage has already completed; skipping")
 		return nil
 	}
@@ -140,7 +153,7 @@ func (c *PipelineJobController) processSuccessfulPipelineJob(original api.Pipeli
 	// stuff an entry into the pipeline stage for this job
 	logger.Info("adding pipeline job completion to pipeline stage")
 	updatedPipelineStage := c.markPipelineJobAsCompleted(original, *pipelineStage.DeepCopy(), api.PhaseSucceeded)
-	if _, err := c.patchPipelineStage(updatedPipelineStage, *pipelineStage); err != nil {
+	if _, err := generic.PatchPipelineStage(c.kubesmithClient, updatedPipelineStage, *pipelineStage); err != nil {
 		return errors.Wrap(err, "could not add pipeline job completion to pipeline stage")
 	}
 	logger.Info("added pipeline job completion to pipeline stage")
@@ -172,7 +185,7 @@ func (c *PipelineJobController) processSuccessfulPipelineJob(original api.Pipeli
 	logger.Info("marking queued job as running")
 	updatedQueuedJob := *queuedJob.DeepCopy()
 	updatedQueuedJob.SetPhaseToRunning()
-	if _, err := c.patchPipelineJob(updatedQueuedJob, *queuedJob); err != nil {
+	if _, err := generic.PatchPipelineJob(c.kubesmithClient, updatedQueuedJob, *queuedJob); err != nil {
 		return errors.Wrap(err, "could not mark queued job to running")
 	}
 
@@ -180,6 +193,224 @@ func (c *PipelineJobController) processSuccessfulPipelineJob(original api.Pipeli
 	return nil
 }
 
+// processRunningTriggerPipelineJob creates the downstream pipeline of a trigger
+// job and, when the job waits on it, succeeds or fails the job along with it
+func (c *PipelineJobController) processRunningTriggerPipelineJob(original api.PipelineJob, logger logrus.FieldLogger) error {
+	job := *original.DeepCopy()
+
+	if original.Status.Downstream.Name == "" {
+		logger.Info("creating downstream pipeline")
+		downstream, err := c.ensureDownstreamPipelineExists(original)
+		if isPermanentError(err) {
+			logger.WithError(err).Info("could not create downstream pipeline; marking as failed")
+
+			job.SetPhaseToFailed(fmt.Sprintf("could not create downstream pipeline: %s", err))
+			if _, err := generic.PatchPipelineJob(c.kubesmithClient, job, original); err != nil {
+				return errors.Wrap(err, "could not mark as failed")
+			}
+
+			logger.Info("marked as failed")
+			return nil
+		} else if err != nil {
+			return errors.Wrap(err, "could not create downstream pipeline")
+		}
+
+		job.Status.Downstream = api.PipelineJobDownstream{
+			Namespace: downstream.GetNamespace(),
+			Name:      downstream.GetName(),
+		}
+
+		if !original.Spec.Job.Trigger.Wait {
+			job.SetPhaseToSucceeded()
+		}
+
+		if _, err := generic.PatchPipelineJob(c.kubesmithClient, job, original); err != nil {
+			return errors.Wrap(err, "could not record downstream pipeline")
+		}
+
+		logger.WithField("downstream", job.Status.Downstream.String()).Info("created downstream pipeline")
+		return nil
+	}
+
+	downstream, err := c.kubesmithClient.Pipelines(original.Status.Downstream.Namespace).Get(original.Status.Downstream.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		job.SetPhaseToFailed(fmt.Sprintf("downstream pipeline %s was deleted", original.Status.Downstream.String()))
+	} else if err != nil {
+		return errors.Wrap(err, "could not get downstream pipeline")
+	} else if !downstream.HasFinished() {
+		logger.Info("waiting for downstream pipeline")
+		c.Queue.AddAfter(sync.PipelineJobUpdateAction(original), downstreamPollInterval)
+		return nil
+	} else if downstream.HasSucceeded() {
+		job.SetPhaseToSucceeded()
+	} else if downstream.IsCancelled() {
+		job.SetPhaseToFailed(fmt.Sprintf("downstream pipeline %s was cancelled", original.Status.Downstream.String()))
+	} else {
+		job.SetPhaseToFailed(fmt.Sprintf("downstream pipeline %s failed: %s", original.Status.Downstream.String(), downstream.Status.FailureReason))
+	}
+
+	logger.Infof("downstream pipeline has finished; marking as %s", job.Status.Phase)
+	if _, err := generic.PatchPipelineJob(c.kubesmithClient, job, original); err != nil {
+		return errors.Wrapf(err, "could not mark as %s", job.Status.Phase)
+	}
+
+	logger.Infof("marked as %s", job.Status.Phase)
+	return nil
+}
+
+// ensureDownstreamPipelineExists creates the downstream pipeline of a trigger
+// job; the pipeline is named after the batch job the trigger job would have
+// had so that every attempt creates exactly one
+func (c *PipelineJobController) ensureDownstreamPipelineExists(original api.PipelineJob) (*api.Pipeline, error) {
+	pipeline, err := c.getAssociatedPipeline(original)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get pipeline")
+	}
+
+	downstream, err := c.getDownstreamPipeline(original, *pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := c.kubesmithClient.Pipelines(downstream.GetNamespace()).Create(&downstream)
+	if apierrors.IsAlreadyExists(err) {
+		return c.kubesmithClient.Pipelines(downstream.GetNamespace()).Get(downstream.GetName(), metav1.GetOptions{})
+	}
+
+	return created, err
+}
+
+// getDownstreamPipeline returns the pipeline a trigger job creates; it copies
+// the pipeline of the trigger or builds the branch of its forge the way the
+// forge would, with the pipeline file loaded once the repo is cloned
+func (c *PipelineJobController) getDownstreamPipeline(original api.PipelineJob, pipeline api.Pipeline) (api.Pipeline, error) {
+	trigger := original.Spec.Job.Trigger
+	namespace := trigger.GetNamespace(original.GetNamespace())
+	downstream := api.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      original.GetJobName(),
+			Namespace: namespace,
+			Labels:    map[string]string{},
+		},
+	}
+
+	if trigger.Pipeline != "" {
+		template, err := c.kubesmithClient.Pipelines(namespace).Get(trigger.Pipeline, metav1.GetOptions{})
+		if err != nil {
+			return downstream, errors.Wrapf(err, "could not get pipeline %s/%s", namespace, trigger.Pipeline)
+		}
+
+		for key, value := range template.GetLabels() {
+			downstream.Labels[key] = value
+		}
+
+		downstream.Spec = template.GetTemplateSpec()
+	} else {
+		forge, err := c.kubesmithClient.Forges(namespace).Get(trigger.Forge, metav1.GetOptions{})
+		if err != nil {
+			return downstream, errors.Wrapf(err, "could not get forge %s/%s", namespace, trigger.Forge)
+		}
+
+		repo := forge.Spec.Repo
+		repo.Ref = fmt.Sprintf("refs/heads/%s", trigger.Branch)
+		downstream.Spec.PipelinePath = forge.GetPipelinePath()
+
+		// the forge builds the branch like one of its own pipelines apart from
+		// the name, which keeps one pipeline per attempt of the trigger job
+		name := downstream.GetName()
+		downstream = forge.GetPipelineForRepo(repo, downstream)
+		downstream.GenerateName = ""
+		downstream.Name = name
+	}
+
+	environment := map[string]string{}
+	for key, value := range downstream.Spec.Environment {
+		environment[key] = value
+	}
+
+	for key, value := range trigger.Parameters {
+		environment[key] = value
+	}
+
+	downstream.Spec.Environment = environment
+	downstream.Spec.Upstream = api.PipelineSpecUpstream{
+		Namespace:   pipeline.GetNamespace(),
+		Pipeline:    pipeline.GetName(),
+		PipelineJob: original.GetName(),
+		Job:         original.Spec.Job.Name,
+		Wait:        trigger.Wait,
+	}
+
+	// the jobs of the downstream pipeline read the artifacts with the
+	// storage secret of this namespace
+	previousStageName := original.GetPreviousPipelineStageName()
+	if trigger.Artifacts && namespace == original.GetNamespace() && previousStageName != "" {
+		downstream.Spec.Workspace.UpstreamArtifacts = api.WorkspaceUpstreamArtifacts{
+			Storage: original.Spec.Workspace.Storage,
+			Path:    fmt.Sprintf("%s/%s", original.GetPipelineName(), previousStageName),
+		}
+	}
+
+	return downstream, nil
+}
+
+// cancelDownstreamPipeline cancels the downstream pipeline a cancelled trigger
+// job was waiting on
+func (c *PipelineJobController) cancelDownstreamPipeline(original api.PipelineJob, logger logrus.FieldLogger) error {
+	if !original.Spec.Job.Trigger.Wait || original.Status.Downstream.Name == "" {
+		return nil
+	}
+
+	downstream, err := c.kubesmithClient.Pipelines(original.Status.Downstream.Namespace).Get(original.Status.Downstream.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "could not get downstream pipeline")
+	}
+
+	if downstream.HasFinished() || downstream.IsCancelRequested() {
+		return nil
+	}
+
+	logger = logger.WithField("downstream", original.Status.Downstream.String())
+	logger.Info("cancelling downstream pipeline")
+
+	updated := *downstream.DeepCopy()
+	updated.Spec.Cancel = true
+	updated.Status.FailureReason = fmt.Sprintf("upstream pipeline %s/%s was cancelled", downstream.Spec.Upstream.Namespace, downstream.Spec.Upstream.Pipeline)
+
+	if _, err := generic.PatchPipeline(c.kubesmithClient, updated, *downstream); err != nil {
+		return errors.Wrap(err, "could not cancel downstream pipeline")
+	}
+
+	logger.Info("cancelled downstream pipeline")
+	return nil
+}
+
+// requeueUpstreamPipelineJob requeues the trigger job waiting on a pipeline
+// that has finished
+func (c *PipelineJobController) requeueUpstreamPipelineJob(pipeline api.Pipeline) {
+	upstream := pipeline.Spec.Upstream
+	if !upstream.Wait || !pipeline.HasFinished() {
+		return
+	}
+
+	job, err := c.pipelineJobLister.PipelineJobs(upstream.Namespace).Get(upstream.PipelineJob)
+	if err != nil {
+		return
+	}
+
+	c.Queue.Add(sync.PipelineJobUpdateAction(*job))
+}
+
+// isPermanentError returns whether creating a downstream pipeline failed in a
+// way that trying again won't fix
+func isPermanentError(err error) bool {
+	cause := errors.Cause(err)
+
+	return apierrors.IsNotFound(cause) || apierrors.IsForbidden(cause) || apierrors.IsInvalid(cause)
+}
+
 func (c *PipelineJobController) getNextQueuedJob(original api.PipelineJob) (*api.PipelineJob, error) {
 	labelSelector := c.getResourceLabelSelector(original.GetLabels())
 	pipelineJobs, err := c.pipelineJobLister.PipelineJobs(original.GetNamespace()).List(labelSelector)
@@ -213,9 +444,12 @@ func (c *PipelineJobController) markPipelineJobAsCompleted(pipelineJob api.Pipel
 
 func (c *PipelineJobController) processFailedPipelineJob(original api.PipelineJob, logger logrus.FieldLogger) error {
 	if original.Spec.Job.AllowFailure == true {
+		c.reportCommitStatus(original, commitstatus.StateSuccess, fmt.Sprintf("job is allowed to fail: %s", original.Status.FailureReason), logger)
 		return c.processSuccessfulPipelineJob(original, logger)
 	}
 
+	c.reportCommitStatus(original, commitstatus.StateFailure, original.Status.FailureReason, logger)
+
 	logger.Info("fetching associated pipeline stage")
 	pipelineStage, err := c.getAssociatedPipelineStage(original)
 	if err != nil {
@@ -223,18 +457,16 @@ func (c *PipelineJobController) processFailedPipelineJob(original api.PipelineJo
 	}
 	logger.Info("fetched associated pipeline stage")
 
-	if pipelineStage.HasSucceeded() || pipelineStage.HasFailed() {
+	if pipelineStage.HasFinished() {
 		logger.Info("pipeline stage has already completed; skipping")
 		return nil
 	}
 
 	logger.Info("marking pipeline stage as failed")
 	updatedPipelineStage := c.markPipelineJobAsCompleted(original, *pipelineStage.DeepCopy(), api.PhaseFailed)
+	updatedPipelineStage.SetPhaseToFailed(fmt.Sprintf("job %q failed: %s", original.Spec.Job.Name, original.Status.FailureReason))
 
-	// todo: improve this failure reason
-	updatedPipelineStage.SetPhaseToFailed("pipeline job failed")
-
-	if _, err := c.patchPipelineStage(updatedPipelineStage, *pipelineStage); err != nil {
+	if _, err := generic.PatchPipelineStage(c.kubesmithClient, updatedPipelineStage, *pipelineStage); err != nil {
 		return errors.Wrap(err, "could not mark pipeline stage as failed")
 	}
 
@@ -242,6 +474,74 @@ func (c *PipelineJobController) processFailedPipelineJob(original api.PipelineJo
 	return nil
 }
 
+func (c *PipelineJobController) processCancelledPipelineJob(original api.PipelineJob, logger logrus.FieldLogger) error {
+	c.reportCommitStatus(original, commitstatus.StateCancelled, "job was cancelled", logger)
+
+	logger.Info("stopping cancelled pipeline job")
+
+	// background propagation lets the pods terminate gracefully
+	propagationPolicy := metav1.DeletePropagationBackground
+	deleteOptions := metav1.DeleteOptions{
+		PropagationPolicy: &propagationPolicy,
+	}
+
+	labelSelector := c.getResourceLabelSelector(original.GetWrappedLabels())
+	if err := c.deleteAssociatedJobs(original, labelSelector, deleteOptions, logger); err != nil {
+		return err
+	}
+
+	if err := c.cancelDownstreamPipeline(original, logger); err != nil {
+		return err
+	}
+
+	logger.Info("stopped cancelled pipeline job")
+
+	// the pipeline job no longer counts against the running pipeline jobs
+	return c.requeueQueuedPipelineJobs(original, logger)
+}
+
+func (c *PipelineJobController) requeueQueuedPipelineJobs(original api.PipelineJob, logger logrus.FieldLogger) error {
+	jobs, err := c.pipelineJobLister.PipelineJobs(original.GetNamespace()).List(labels.Everything())
+	if err != nil {
+		return errors.Wrap(err, "could not list pipeline jobs")
+	}
+
+	for _, job := range jobs {
+		if job.IsQueued() {
+			logger.WithField(logging.FieldJob, job.GetName()).Info("requeueing queued pipeline job")
+			c.Queue.Add(sync.PipelineJobUpdateAction(*job))
+		}
+	}
+
+	return nil
+}
+
+// reportCommitStatus posts the state of the pipeline job as a commit status of
+// its pipeline
+func (c *PipelineJobController) reportCommitStatus(original api.PipelineJob, state commitstatus.State, description string, logger logrus.FieldLogger) {
+	pipeline, err := c.getAssociatedPipeline(original)
+	if err != nil {
+		logger.WithError(err).Info("could not report commit status; pipeline not found")
+		return
+	}
+
+	c.commitStatusReporter.ReportPipelineJob(*pipeline, original.Spec.Job.Name, state, description)
+}
+
+func (c *PipelineJobController) getAssociatedPipeline(original api.PipelineJob) (*api.Pipeline, error) {
+	name, err := c.getLabelByKey(original, "PipelineName")
+	if err != nil {
+		return nil, err
+	}
+
+	namespace, err := c.getLabelByKey(original, "PipelineNamespace")
+	if err != nil {
+		return nil, err
+	}
+
+	return c.pipelineLister.Pipelines(namespace).Get(name)
+}
+
 func (c *PipelineJobController) getAssociatedPipelineStage(original api.PipelineJob) (*api.PipelineStage, error) {
 	name, err := c.getLabelByKey(original, "PipelineStageName")
 	if err != nil {
@@ -270,7 +570,7 @@ func (c *PipelineJobController) processDeletedPipelineJob(original api.PipelineJ
 	logger.Info("cleaning up pipeline job")
 
 	// create a selector for listing resources associated to pipelines
-	labelSelector := c.getResourceLabelSelector(c.getWrappedLabels(original))
+	labelSelector := c.getResourceLabelSelector(original.GetWrappedLabels())
 
 	// create the delete options that can help clean everything up
 	propagationPolicy := metav1.DeletePropagationBackground
@@ -308,8 +608,8 @@ func (c *PipelineJobController) deleteAssociatedConfigMaps(
 	logger.Info("deleting configmaps")
 	for _, configMap := range configMaps {
 		logger.WithFields(logrus.Fields{
-			"ConfigMapName":      configMap.GetName(),
-			"ConfigMapNamespace": configMap.GetNamespace(),
+			"configMap":            configMap.GetName(),
+			logging.FieldNamespace: configMap.GetNamespace(),
 		}).Info("deleting configmap")
 
 		if err := c.kubeClient.CoreV1().ConfigMaps(configMap.GetNamespace()).Delete(configMap.GetName(), &deleteOptions); err != nil {
@@ -343,8 +643,8 @@ func (c *PipelineJobController) deleteAssociatedJobs(
 	logger.Info("deleting jobs")
 	for _, job := range jobs {
 		logger.WithFields(logrus.Fields{
-			"JobName":      job.GetName(),
-			"JobNamespace": job.GetNamespace(),
+			"batchJob":             job.GetName(),
+			logging.FieldNamespace: job.GetNamespace(),
 		}).Info("deleting job")
 
 		if err := c.kubeClient.BatchV1().Jobs(original.GetNamespace()).Delete(job.GetName(), &deleteOptions); err != nil {
@@ -360,24 +660,16 @@ func (c *PipelineJobController) deleteAssociatedJobs(
 	return nil
 }
 
-func (c *PipelineJobController) patchPipelineJob(updated, original api.PipelineJob) (*api.PipelineJob, error) {
-	patchType, patchBytes, err := updated.GetPatchFromOriginal(original)
-	if err != nil {
-		return nil, err
-	}
-
-	return c.kubesmithClient.PipelineJobs(original.GetNamespace()).Patch(original.GetName(), patchType, patchBytes)
-}
-
 func (c *PipelineJobController) canRunAnotherPipelineJob(original api.PipelineJob) (bool, error) {
 	jobs, err := c.pipelineJobLister.PipelineJobs(original.GetNamespace()).List(labels.Everything())
 	if err != nil {
 		return false, errors.Wrap(err, "could not list pipeline jobs")
 	}
 
+	// trigger jobs run no pods and may wait on pipelines needing the room
 	currentlyRunning := 0
 	for _, job := range jobs {
-		if job.IsRunning() {
+		if job.IsRunning() && !job.Spec.Job.IsTrigger() {
 			currentlyRunning++
 		}
 	}
@@ -413,12 +705,12 @@ func (c *PipelineJobController) ensureJobConfigMapIsScheduled(original api.Pipel
 
 func (c *PipelineJobController) ensureJobIsScheduled(original api.PipelineJob, logger logrus.FieldLogger) error {
 	logger.Info("ensuring job is scheduled")
-	if _, err := c.jobLister.Jobs(original.GetNamespace()).Get(original.GetName()); err != nil {
+	if _, err := c.jobLister.Jobs(original.GetNamespace()).Get(original.GetJobName()); err != nil {
 		if apierrors.IsNotFound(err) {
 			logger.Info("job does not exist; scheduling")
 
 			// create the job
-			job := templates.GetPipelineJobJob(original)
+			job := templates.GetPipelineJobJob(original, c.logConfig)
 			if _, err := c.kubeClient.BatchV1().Jobs(original.GetNamespace()).Create(&job); err != nil {
 				return errors.Wrap(err, "could not schedule job")
 			}
@@ -434,15 +726,6 @@ func (c *PipelineJobController) ensureJobIsScheduled(original api.PipelineJob, l
 	return nil
 }
 
-func (c *PipelineJobController) getWrappedLabels(original api.PipelineJob) map[string]string {
-	labels := original.GetLabels()
-	labels[api.GetLabelKey("Controller")] = "PipelineJob"
-	labels[api.GetLabelKey("PipelineJobName")] = original.GetName()
-	labels[api.GetLabelKey("PipelineJobNamespace")] = original.GetNamespace()
-
-	return labels
-}
-
 func (c *PipelineJobController) getResourceLabelSelector(resourceLabels map[string]string) labels.Selector {
 	set := labels.Set{}
 
@@ -452,12 +735,3 @@ func (c *PipelineJobController) getResourceLabelSelector(resourceLabels map[stri
 
 	return labels.SelectorFromSet(set)
 }
-
-func (c *PipelineJobController) patchPipelineStage(updated, original api.PipelineStage) (*api.PipelineStage, error) {
-	patchType, patchBytes, err := updated.GetPatchFromOriginal(original)
-	if err != nil {
-		return nil, err
-	}
-
-	return c.kubesmithClient.PipelineStages(original.GetNamespace()).Patch(original.GetName(), patchType, patchBytes)
-}
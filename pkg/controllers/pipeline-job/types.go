@@ -1,9 +1,13 @@
 package pipelinejob
 
 import (
+	"time"
+
+	"github.com/kubesmith/kubesmith/pkg/commitstatus"
 	"github.com/kubesmith/kubesmith/pkg/controllers/generic"
 	kubesmithv1 "github.com/kubesmith/kubesmith/pkg/generated/clientset/versioned/typed/kubesmith/v1"
 	kubesmithListersv1 "github.com/kubesmith/kubesmith/pkg/generated/listers/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/logging"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/client-go/kubernetes"
@@ -11,14 +15,24 @@ import (
 	coreListersv1 "k8s.io/client-go/listers/core/v1"
 )
 
+const (
+	// downstreamPollInterval is how often a trigger job checks whether the
+	// downstream pipeline it waits on has finished; downstream pipelines in
+	// other namespaces aren't watched
+	downstreamPollInterval = 30 * time.Second
+)
+
 type PipelineJobController struct {
 	*generic.GenericController
 
 	maxRunningPipelineJobs int
 	logger                 logrus.FieldLogger
+	logConfig              logging.Config
 	kubeClient             kubernetes.Interface
 	kubesmithClient        kubesmithv1.KubesmithV1Interface
+	commitStatusReporter   *commitstatus.Reporter
 
+	pipelineLister      kubesmithListersv1.PipelineLister
 	pipelineJobLister   kubesmithListersv1.PipelineJobLister
 	pipelineStageLister kubesmithListersv1.PipelineStageLister
 	configMapLister     coreListersv1.ConfigMapLister
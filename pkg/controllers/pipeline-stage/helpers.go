@@ -3,10 +3,12 @@ package pipelinestage
 import (
 	"github.com/davecgh/go-spew/spew"
 	"github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/commitstatus"
 	"github.com/kubesmith/kubesmith/pkg/controllers"
 	"github.com/kubesmith/kubesmith/pkg/controllers/generic"
 	kubesmithv1 "github.com/kubesmith/kubesmith/pkg/generated/clientset/versioned/typed/kubesmith/v1"
 	informers "github.com/kubesmith/kubesmith/pkg/generated/informers/externalversions/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/logging"
 	"github.com/kubesmith/kubesmith/pkg/sync"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/util/clock"
@@ -18,19 +20,21 @@ func NewPipelineStageController(
 	logger *logrus.Logger,
 	kubeClient kubernetes.Interface,
 	kubesmithClient kubesmithv1.KubesmithV1Interface,
+	commitStatusReporter *commitstatus.Reporter,
 	pipelineInformer informers.PipelineInformer,
 	pipelineStageInformer informers.PipelineStageInformer,
 	pipelineJobInformer informers.PipelineJobInformer,
 ) controllers.Interface {
 	c := &PipelineStageController{
-		GenericController:   generic.NewGenericController("PipelineStage"),
-		logger:              logger.WithField("controller", "PipelineStage"),
-		kubeClient:          kubeClient,
-		kubesmithClient:     kubesmithClient,
-		pipelineLister:      pipelineInformer.Lister(),
-		pipelineStageLister: pipelineStageInformer.Lister(),
-		pipelineJobLister:   pipelineJobInformer.Lister(),
-		clock:               &clock.RealClock{},
+		GenericController:    generic.NewGenericController("PipelineStage", logger),
+		logger:               logger.WithField(logging.FieldController, "PipelineStage"),
+		kubeClient:           kubeClient,
+		kubesmithClient:      kubesmithClient,
+		commitStatusReporter: commitStatusReporter,
+		pipelineLister:       pipelineInformer.Lister(),
+		pipelineStageLister:  pipelineStageInformer.Lister(),
+		pipelineJobLister:    pipelineJobInformer.Lister(),
+		clock:                &clock.RealClock{},
 	}
 
 	c.SyncHandler = c.processPipelineStage
@@ -2,8 +2,12 @@ package pipelinestage
 
 import (
 	"fmt"
+	"sort"
 
 	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/commitstatus"
+	"github.com/kubesmith/kubesmith/pkg/controllers/generic"
+	"github.com/kubesmith/kubesmith/pkg/logging"
 	"github.com/kubesmith/kubesmith/pkg/sync"
 	"github.com/kubesmith/kubesmith/pkg/templates"
 	"github.com/pkg/errors"
@@ -13,11 +17,9 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 )
 
-func (c *PipelineStageController) processPipelineStage(action sync.SyncAction) error {
+func (c *PipelineStageController) processPipelineStage(action sync.SyncAction, logger logrus.FieldLogger) error {
 	cachedStage := action.GetObject().(api.PipelineStage)
-	logger := c.logger.WithFields(logrus.Fields{
-		"Name": cachedStage.GetName(),
-	})
+	logger = logger.WithFields(logging.GetResourceFields(&cachedStage))
 
 	switch action.GetAction() {
 	case sync.SyncActionDelete:
@@ -25,7 +27,7 @@ func (c *PipelineStageController) processPipelineStage(action sync.SyncAction) e
 	default:
 		stage, err := c.pipelineStageLister.PipelineStages(cachedStage.GetNamespace()).Get(cachedStage.GetName())
 		if apierrors.IsNotFound(err) {
-			c.logger.Info("unable to find pipeline stage")
+			logger.Info("unable to find pipeline stage")
 			return nil
 		} else if err != nil {
 			return errors.Wrap(err, "error getting pipeline stage")
@@ -33,7 +35,7 @@ func (c *PipelineStageController) processPipelineStage(action sync.SyncAction) e
 
 		// create a new logger for this pipeline stage's execution
 		logger = logger.WithFields(logrus.Fields{
-			"Phase": stage.Status.Phase,
+			"phase": stage.Status.Phase,
 		})
 
 		// determine the phase and begin execution of the pipeline stage
@@ -61,7 +63,7 @@ func (c *PipelineStageController) processEmptyPhasePipelineStage(original api.Pi
 		logger.Info("validation failed; marking as failed")
 
 		stage.SetPhaseToFailed(err.Error())
-		if _, err := c.patchPipelineStage(stage, original); err != nil {
+		if _, err := generic.PatchPipelineStage(c.kubesmithClient, stage, original); err != nil {
 			return errors.Wrap(err, "could not mark as failed")
 		}
 
@@ -71,7 +73,7 @@ func (c *PipelineStageController) processEmptyPhasePipelineStage(original api.Pi
 
 	logger.Info("validated; marking as running")
 	stage.SetPhaseToRunning()
-	if _, err := c.patchPipelineStage(stage, original); err != nil {
+	if _, err := generic.PatchPipelineStage(c.kubesmithClient, stage, original); err != nil {
 		return errors.Wrap(err, "could not mark as queued")
 	}
 
@@ -80,12 +82,14 @@ func (c *PipelineStageController) processEmptyPhasePipelineStage(original api.Pi
 }
 
 func (c *PipelineStageController) processRunningPipelineStage(original api.PipelineStage, logger logrus.FieldLogger) error {
+	c.reportRunningStage(original, logger)
+
 	if len(original.Status.CompletedPipelineJobs) == len(original.Spec.Jobs) {
 		logger.Info("marking pipeline stage as succeeded")
 		updated := *original.DeepCopy()
 		updated.SetPhaseToSucceeded()
 
-		if _, err := c.patchPipelineStage(updated, original); err != nil {
+		if _, err := generic.PatchPipelineStage(c.kubesmithClient, updated, original); err != nil {
 			return errors.Wrap(err, "could not mark pipeline stage as succeeded")
 		}
 
@@ -114,30 +118,38 @@ func (c *PipelineStageController) processSuccessfulPipelineStage(original api.Pi
 	}
 	logger.Info("fetched associated pipeline")
 
-	if pipeline.HasSucceeded() || pipeline.HasFailed() {
+	if pipeline.HasFinished() {
 		logger.Info("pipeline has already completed; skipping")
 		return nil
 	}
 
+	// pipelines that moved on to their finally stage left this one behind
+	if pipeline.Status.StageIndex != original.GetStageIndex() {
+		logger.Info("pipeline has moved on from this stage; skipping")
+		return nil
+	}
+
 	if pipeline.Status.StageIndex >= len(pipeline.Spec.Stages) {
 		logger.Info("pipeline stage index has already advanced to final stage")
-		logger.Info("marking pipeline as succceeded")
+		logger.Info("marking pipeline as finished")
 		updatedPipeline := *pipeline.DeepCopy()
-		updatedPipeline.SetPhaseToSucceeded()
+		updatedPipeline.Status.TestReport = c.getPipelineTestReport(*pipeline, logger)
+		updatedPipeline.SetPhaseAfterLastStage()
 
-		if _, err := c.patchPipeline(updatedPipeline, *pipeline); err != nil {
-			return errors.Wrap(err, "could not mark pipeline as succeeded")
+		if _, err := generic.PatchPipeline(c.kubesmithClient, updatedPipeline, *pipeline); err != nil {
+			return errors.Wrap(err, "could not mark pipeline as finished")
 		}
 
-		logger.Info("marked pipeline as succeeded")
+		logger.Info("marked pipeline as finished")
 		return nil
 	}
 
 	logger.Info("incrementing pipeline stage index")
 	updatedPipeline := *pipeline.DeepCopy()
+	updatedPipeline.Status.TestReport = c.getPipelineTestReport(*pipeline, logger)
 	updatedPipeline.Status.StageIndex++
 
-	if _, err := c.patchPipeline(updatedPipeline, *pipeline); err != nil {
+	if _, err := generic.PatchPipeline(c.kubesmithClient, updatedPipeline, *pipeline); err != nil {
 		return errors.Wrap(err, "could not increment pipeline stage index")
 	}
 
@@ -153,18 +165,24 @@ func (c *PipelineStageController) processFailedPipelineStage(original api.Pipeli
 	}
 	logger.Info("fetched associated pipeline")
 
-	if pipeline.HasSucceeded() || pipeline.HasFailed() {
+	if pipeline.HasFinished() {
 		logger.Info("pipeline has already completed; skipping")
 		return nil
 	}
 
+	// pipelines that moved on to their finally stage left this one behind
+	if pipeline.Status.StageIndex != original.GetStageIndex() {
+		logger.Info("pipeline has moved on from this stage; skipping")
+		return nil
+	}
+
+	// the finally stage still runs before the pipeline is marked as failed
 	logger.Info("marking pipeline as failed")
 	updatedPipeline := *pipeline.DeepCopy()
+	updatedPipeline.Status.TestReport = c.getPipelineTestReport(*pipeline, logger)
+	updatedPipeline.SetPhaseToFailedAtStage(fmt.Sprintf("stage %q failed: %s", pipeline.GetCurrentStageName(), original.Status.FailureReason))
 
-	// todo: improve this failure reason
-	updatedPipeline.SetPhaseToFailed("pipeline stage failed")
-
-	if _, err := c.patchPipeline(updatedPipeline, *pipeline); err != nil {
+	if _, err := generic.PatchPipeline(c.kubesmithClient, updatedPipeline, *pipeline); err != nil {
 		return errors.Wrap(err, "could not mark pipeline as failed")
 	}
 
@@ -172,6 +190,58 @@ func (c *PipelineStageController) processFailedPipelineStage(original api.Pipeli
 	return nil
 }
 
+// reportRunningStage posts the stage the pipeline is running as the commit
+// status of the pipeline
+func (c *PipelineStageController) reportRunningStage(original api.PipelineStage, logger logrus.FieldLogger) {
+	pipeline, err := c.getAssociatedPipeline(original)
+	if err != nil {
+		logger.WithError(err).Info("could not report commit status; pipeline not found")
+		return
+	}
+
+	// stages of a pipeline that moved on are not reported
+	if !pipeline.IsRunning() || pipeline.Status.StageIndex != original.GetStageIndex() {
+		return
+	}
+
+	description := fmt.Sprintf(
+		"running stage %q (%d of %d)",
+		pipeline.GetCurrentStageName(),
+		pipeline.Status.StageIndex,
+		len(pipeline.Spec.Stages),
+	)
+
+	c.commitStatusReporter.ReportPipeline(*pipeline, commitstatus.StateRunning, description)
+}
+
+// getPipelineTestReport rolls the test reports of every finished pipeline job
+// of the pipeline up into a single report
+func (c *PipelineStageController) getPipelineTestReport(pipeline api.Pipeline, logger logrus.FieldLogger) api.TestReport {
+	report := api.TestReport{}
+	labelSelector := labels.SelectorFromSet(labels.Set{
+		api.GetLabelKey("PipelineName"):      pipeline.GetName(),
+		api.GetLabelKey("PipelineNamespace"): pipeline.GetNamespace(),
+	})
+
+	jobs, err := c.pipelineJobLister.PipelineJobs(pipeline.GetNamespace()).List(labelSelector)
+	if err != nil {
+		logger.Info(errors.Wrap(err, "could not retrieve pipeline jobs for test report"))
+		return pipeline.Status.TestReport
+	}
+
+	// keep the order of the jobs stable so the failing tests are too
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].GetName() < jobs[j].GetName()
+	})
+
+	for _, job := range jobs {
+		report.Add(job.Status.TestReport)
+	}
+
+	report.Summary = report.GetSummary()
+	return report
+}
+
 func (c *PipelineStageController) getAssociatedPipeline(original api.PipelineStage) (*api.Pipeline, error) {
 	name, err := c.getLabelByKey(original, "PipelineName")
 	if err != nil {
@@ -198,7 +268,7 @@ func (c *PipelineStageController) getLabelByKey(original api.PipelineStage, key
 
 func (c *PipelineStageController) processDeletedPipelineStage(original api.PipelineStage, logger logrus.FieldLogger) error {
 	// create a selector for listing resources associated to pipeline stage
-	labelSelector := c.getResourceLabelSelector(c.getWrappedLabels(original))
+	labelSelector := c.getResourceLabelSelector(original.GetWrappedLabels())
 
 	// create the delete options that can help clean everything up
 	propagationPolicy := metav1.DeletePropagationBackground
@@ -231,8 +301,8 @@ func (c *PipelineStageController) deleteAssociatedPipelineJobs(
 	logger.Info("deleting pipeline jobs")
 	for _, job := range jobs {
 		logger.WithFields(logrus.Fields{
-			"PipelineJobName":      job.GetName(),
-			"PipelineJobNamespace": job.GetNamespace(),
+			logging.FieldJob:       job.GetName(),
+			logging.FieldNamespace: job.GetNamespace(),
 		}).Info("deleting pipeline job")
 
 		if err := c.kubesmithClient.PipelineJobs(job.GetNamespace()).Delete(job.GetName(), &deleteOptions); err != nil {
@@ -248,37 +318,19 @@ func (c *PipelineStageController) deleteAssociatedPipelineJobs(
 	return nil
 }
 
-func (c *PipelineStageController) patchPipelineStage(updated, original api.PipelineStage) (*api.PipelineStage, error) {
-	patchType, patchBytes, err := updated.GetPatchFromOriginal(original)
-	if err != nil {
-		return nil, err
-	}
-
-	return c.kubesmithClient.PipelineStages(original.GetNamespace()).Patch(original.GetName(), patchType, patchBytes)
-}
-
-func (c *PipelineStageController) getWrappedLabels(original api.PipelineStage) map[string]string {
-	labels := original.GetLabels()
-	labels[api.GetLabelKey("Controller")] = "PipelineStage"
-	labels[api.GetLabelKey("PipelineStageName")] = original.GetName()
-	labels[api.GetLabelKey("PipelineStageNamespace")] = original.GetNamespace()
-
-	return labels
-}
-
 func (c *PipelineStageController) ensureJobIsScheduled(
 	jobIndex int,
 	original api.PipelineStage,
 	jobSpec api.PipelineJobSpecJob,
 	logger logrus.FieldLogger,
 ) error {
-	name := fmt.Sprintf("%s-job-%d", original.GetName(), jobIndex+1)
+	name := original.GetPipelineJobName(jobIndex + 1)
 
 	if _, err := c.pipelineJobLister.PipelineJobs(original.GetNamespace()).Get(name); err != nil {
 		if apierrors.IsNotFound(err) {
 			logger.Info("pipeline job does not exist; scheduling")
 
-			original.ObjectMeta.Labels = c.getWrappedLabels(original)
+			original.ObjectMeta.Labels = original.GetWrappedLabels()
 			job := templates.GetPipelineJob(name, original, jobSpec)
 			if _, err := c.kubesmithClient.PipelineJobs(original.GetNamespace()).Create(&job); err != nil {
 				return errors.Wrap(err, "could not schedule pipeline job")
@@ -308,12 +360,3 @@ func (c *PipelineStageController) getResourceLabelSelector(resourceLabels map[st
 
 	return labels.SelectorFromSet(set)
 }
-
-func (c *PipelineStageController) patchPipeline(updated, original api.Pipeline) (*api.Pipeline, error) {
-	patchType, patchBytes, err := updated.GetPatchFromOriginal(original)
-	if err != nil {
-		return nil, err
-	}
-
-	return c.kubesmithClient.Pipelines(original.GetNamespace()).Patch(original.GetName(), patchType, patchBytes)
-}
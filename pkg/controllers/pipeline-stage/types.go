@@ -1,6 +1,7 @@
 package pipelinestage
 
 import (
+	"github.com/kubesmith/kubesmith/pkg/commitstatus"
 	"github.com/kubesmith/kubesmith/pkg/controllers/generic"
 	kubesmithv1 "github.com/kubesmith/kubesmith/pkg/generated/clientset/versioned/typed/kubesmith/v1"
 	kubesmithListersv1 "github.com/kubesmith/kubesmith/pkg/generated/listers/kubesmith/v1"
@@ -12,9 +13,10 @@ import (
 type PipelineStageController struct {
 	*generic.GenericController
 
-	logger          logrus.FieldLogger
-	kubeClient      kubernetes.Interface
-	kubesmithClient kubesmithv1.KubesmithV1Interface
+	logger               logrus.FieldLogger
+	kubeClient           kubernetes.Interface
+	kubesmithClient      kubesmithv1.KubesmithV1Interface
+	commitStatusReporter *commitstatus.Reporter
 
 	pipelineLister      kubesmithListersv1.PipelineLister
 	pipelineStageLister kubesmithListersv1.PipelineStageLister
@@ -3,10 +3,13 @@ package pipeline
 import (
 	"github.com/davecgh/go-spew/spew"
 	"github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/commitstatus"
 	"github.com/kubesmith/kubesmith/pkg/controllers"
 	"github.com/kubesmith/kubesmith/pkg/controllers/generic"
 	kubesmithv1 "github.com/kubesmith/kubesmith/pkg/generated/clientset/versioned/typed/kubesmith/v1"
 	informers "github.com/kubesmith/kubesmith/pkg/generated/informers/externalversions/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/logging"
+	"github.com/kubesmith/kubesmith/pkg/notification"
 	"github.com/kubesmith/kubesmith/pkg/sync"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/util/clock"
@@ -20,11 +23,16 @@ import (
 
 func NewPipelineController(
 	maxRunningPipelines int,
+	retentionPolicy RetentionPolicy,
 	logger *logrus.Logger,
+	logConfig logging.Config,
 	kubeClient kubernetes.Interface,
 	kubesmithClient kubesmithv1.KubesmithV1Interface,
+	commitStatusReporter *commitstatus.Reporter,
+	notifier *notification.Notifier,
 	pipelineInformer informers.PipelineInformer,
 	pipelineStageInformer informers.PipelineStageInformer,
+	pipelineJobInformer informers.PipelineJobInformer,
 	secretInformer coreInformersv1.SecretInformer,
 	deploymentInformer appInformersv1.DeploymentInformer,
 	serviceInformer coreInformersv1.ServiceInformer,
@@ -34,13 +42,18 @@ func NewPipelineController(
 	roleBindingInformer rbacInformersv1.RoleBindingInformer,
 ) controllers.Interface {
 	c := &PipelineController{
-		GenericController:    generic.NewGenericController("Pipeline"),
+		GenericController:    generic.NewGenericController("Pipeline", logger),
 		maxRunningPipelines:  maxRunningPipelines,
-		logger:               logger.WithField("controller", "Pipeline"),
+		retentionPolicy:      retentionPolicy,
+		logger:               logger.WithField(logging.FieldController, "Pipeline"),
+		logConfig:            logConfig,
 		kubeClient:           kubeClient,
 		kubesmithClient:      kubesmithClient,
+		commitStatusReporter: commitStatusReporter,
+		notifier:             notifier,
 		pipelineLister:       pipelineInformer.Lister(),
 		pipelineStageLister:  pipelineStageInformer.Lister(),
+		pipelineJobLister:    pipelineJobInformer.Lister(),
 		secretLister:         secretInformer.Lister(),
 		deploymentLister:     deploymentInformer.Lister(),
 		serviceLister:        serviceInformer.Lister(),
@@ -56,6 +69,7 @@ func NewPipelineController(
 		c.CacheSyncWaiters,
 		pipelineInformer.Informer().HasSynced,
 		pipelineStageInformer.Informer().HasSynced,
+		pipelineJobInformer.Informer().HasSynced,
 		secretInformer.Informer().HasSynced,
 		deploymentInformer.Informer().HasSynced,
 		serviceInformer.Informer().HasSynced,
@@ -76,10 +90,9 @@ func NewPipelineController(
 				oldPipeline := oldObj.(*v1.Pipeline)
 				updatedPipeline := updatedObj.(*v1.Pipeline)
 
-				logger := c.logger.WithFields(logrus.Fields{
-					"Name":       updatedPipeline.GetName(),
-					"Phase":      updatedPipeline.Status.Phase,
-					"StageIndex": updatedPipeline.Status.StageIndex,
+				logger := c.logger.WithFields(logging.GetResourceFields(updatedPipeline)).WithFields(logrus.Fields{
+					"phase":      updatedPipeline.Status.Phase,
+					"stageIndex": updatedPipeline.Status.StageIndex,
 				})
 
 				if updatedPipeline.Status.Phase != oldPipeline.Status.Phase {
@@ -88,6 +101,12 @@ func NewPipelineController(
 				} else if updatedPipeline.IsRunning() && (updatedPipeline.Status.StageIndex != oldPipeline.Status.StageIndex) {
 					logger.Info("queueing pipeline; stage index advanced")
 					c.Queue.Add(sync.PipelineUpdateAction(*updatedPipeline))
+				} else if updatedPipeline.IsCancelRequested() != oldPipeline.IsCancelRequested() {
+					logger.Info("queueing pipeline; cancellation requested")
+					c.Queue.Add(sync.PipelineUpdateAction(*updatedPipeline))
+				} else if updatedPipeline.Spec.Retry.Attempt != oldPipeline.Spec.Retry.Attempt {
+					logger.Info("queueing pipeline; retry requested")
+					c.Queue.Add(sync.PipelineUpdateAction(*updatedPipeline))
 				}
 			},
 			DeleteFunc: func(obj interface{}) {
@@ -1,12 +1,18 @@
 package pipeline
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/commitstatus"
+	"github.com/kubesmith/kubesmith/pkg/controllers/generic"
 	"github.com/kubesmith/kubesmith/pkg/controllers/pipeline/minio"
+	"github.com/kubesmith/kubesmith/pkg/logging"
 	"github.com/kubesmith/kubesmith/pkg/s3"
 	"github.com/kubesmith/kubesmith/pkg/sync"
 	"github.com/kubesmith/kubesmith/pkg/templates"
@@ -17,11 +23,9 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 )
 
-func (c *PipelineController) processPipeline(action sync.SyncAction) error {
+func (c *PipelineController) processPipeline(action sync.SyncAction, logger logrus.FieldLogger) error {
 	cachedPipeline := action.GetObject().(api.Pipeline)
-	logger := c.logger.WithFields(logrus.Fields{
-		"Name": cachedPipeline.GetName(),
-	})
+	logger = logger.WithFields(logging.GetResourceFields(&cachedPipeline))
 
 	switch action.GetAction() {
 	case sync.SyncActionDelete:
@@ -29,7 +33,7 @@ func (c *PipelineController) processPipeline(action sync.SyncAction) error {
 	default:
 		pipeline, err := c.pipelineLister.Pipelines(cachedPipeline.GetNamespace()).Get(cachedPipeline.GetName())
 		if apierrors.IsNotFound(err) {
-			c.logger.Info("unable to find pipeline")
+			logger.Info("unable to find pipeline")
 			return nil
 		} else if err != nil {
 			return errors.Wrap(err, "error getting pipeline")
@@ -37,10 +41,22 @@ func (c *PipelineController) processPipeline(action sync.SyncAction) error {
 
 		// create a new logger for this pipeline's execution
 		logger = logger.WithFields(logrus.Fields{
-			"Phase":      pipeline.Status.Phase,
-			"StageIndex": pipeline.Status.StageIndex,
+			"phase":      pipeline.Status.Phase,
+			"stageIndex": pipeline.Status.StageIndex,
 		})
 
+		// a cancellation takes precedence over whatever the pipeline was doing
+		// apart from the finally stage it already runs after a failure or an
+		// earlier cancellation
+		if pipeline.IsCancelRequested() && !pipeline.HasFinished() && pipeline.Status.FinallyPhase == api.PhaseEmpty {
+			return c.processCancelRequestedPipeline(*pipeline.DeepCopy(), logger)
+		}
+
+		// retries only apply to pipelines that did not succeed
+		if pipeline.IsRetryRequested() && (pipeline.HasFailed() || pipeline.IsCancelled()) {
+			return c.processRetryRequestedPipeline(*pipeline.DeepCopy(), logger)
+		}
+
 		// determine the phase and begin execution of the pipeline
 		if pipeline.HasNoPhase() {
 			return c.processEmptyPhasePipeline(*pipeline.DeepCopy(), logger)
@@ -52,6 +68,8 @@ func (c *PipelineController) processPipeline(action sync.SyncAction) error {
 			return c.processSuccessfulPipeline(*pipeline.DeepCopy(), logger)
 		} else if pipeline.HasFailed() {
 			return c.processFailedPipeline(*pipeline.DeepCopy(), logger)
+		} else if pipeline.IsCancelled() {
+			return c.processCancelledPipeline(*pipeline.DeepCopy(), logger)
 		}
 	}
 
@@ -66,7 +84,7 @@ func (c *PipelineController) processEmptyPhasePipeline(original api.Pipeline, lo
 		logger.Info("validation failed; marking as failed")
 
 		pipeline.SetPhaseToFailed(err.Error())
-		if _, err := c.patchPipeline(pipeline, original); err != nil {
+		if _, err := generic.PatchPipeline(c.kubesmithClient, pipeline, original); err != nil {
 			return errors.Wrap(err, "could not mark as failed")
 		}
 
@@ -76,7 +94,7 @@ func (c *PipelineController) processEmptyPhasePipeline(original api.Pipeline, lo
 
 	logger.Info("validated; marking as queued")
 	pipeline.SetPhaseToQueued()
-	if _, err := c.patchPipeline(pipeline, original); err != nil {
+	if _, err := generic.PatchPipeline(c.kubesmithClient, pipeline, original); err != nil {
 		return errors.Wrap(err, "could not mark as queued")
 	}
 
@@ -86,6 +104,20 @@ func (c *PipelineController) processEmptyPhasePipeline(original api.Pipeline, lo
 
 func (c *PipelineController) processQueuedPipeline(original api.Pipeline, logger logrus.FieldLogger) error {
 	pipeline := *original.DeepCopy()
+	c.commitStatusReporter.ReportPipeline(original, commitstatus.StatePending, "pipeline is queued")
+
+	if original.GetConcurrencyGroup() != "" {
+		logger.Info("checking the concurrency group")
+		canRun, err := c.processConcurrencyGroup(original, logger)
+		if err != nil {
+			return errors.Wrap(err, "could not check the concurrency group")
+		}
+
+		if !canRun {
+			logger.Info("cannot run another pipeline of the concurrency group")
+			return nil
+		}
+	}
 
 	logger.Info("checking if another pipeline can be run")
 	canRunAnotherPipeline, err := c.canRunAnotherPipeline(pipeline)
@@ -100,21 +132,22 @@ func (c *PipelineController) processQueuedPipeline(original api.Pipeline, logger
 
 	logger.Info("marking as running")
 	pipeline.SetPhaseToRunning()
-	if _, err := c.patchPipeline(pipeline, original); err != nil {
+	if _, err := generic.PatchPipeline(c.kubesmithClient, pipeline, original); err != nil {
 		return errors.Wrap(err, "could not mark as running")
 	}
 
 	logger.Info("marked as running")
+	c.commitStatusReporter.ReportPipeline(pipeline, commitstatus.StateRunning, "pipeline is running")
 	return nil
 }
 
 func (c *PipelineController) processRunningPipeline(original api.Pipeline, logger logrus.FieldLogger) error {
-	if original.Status.StageIndex > len(original.Spec.Jobs) {
+	if !original.NeedsPipelineFile() && original.Status.StageIndex > len(original.Spec.Jobs) {
 		logger.Info("marking pipeline as succceeded")
 		pipeline := *original.DeepCopy()
 		pipeline.SetPhaseToSucceeded()
 
-		if _, err := c.patchPipeline(pipeline, original); err != nil {
+		if _, err := generic.PatchPipeline(c.kubesmithClient, pipeline, original); err != nil {
 			return errors.Wrap(err, "could not mark pipeline as succeeded")
 		}
 
@@ -134,7 +167,7 @@ func (c *PipelineController) processRunningPipeline(original api.Pipeline, logge
 		return errors.Wrap(err, "could not ensure role binding exists")
 	}
 
-	if c.pipelineNeedsMinio(original) == true {
+	if original.NeedsMinioServer() {
 		minioServer, err := c.ensureMinioServerIsRunning(original, logger)
 		if err != nil {
 			return errors.Wrap(err, "could not ensure minio server is running")
@@ -143,7 +176,7 @@ func (c *PipelineController) processRunningPipeline(original api.Pipeline, logge
 		logger.Info("updating pipeline with minio storage configuration")
 		pipeline := c.setMinioInfoForPipeline(*original.DeepCopy(), minioServer)
 
-		updated, err := c.patchPipeline(pipeline, original)
+		updated, err := generic.PatchPipeline(c.kubesmithClient, pipeline, original)
 		if err != nil {
 			return errors.Wrap(err, "could not update pipeline with minio storage configuration")
 		}
@@ -156,6 +189,26 @@ func (c *PipelineController) processRunningPipeline(original api.Pipeline, logge
 		return errors.Wrap(err, "could not ensure repo artifact exists")
 	}
 
+	if original.Status.Commit == "" {
+		loaded, err := c.loadCommit(original, logger)
+		if err != nil {
+			return errors.Wrap(err, "could not load commit")
+		}
+
+		original = *loaded.DeepCopy()
+	}
+
+	if original.NeedsPipelineFile() {
+		loaded, err := c.loadPipelineFile(original, logger)
+		if err != nil {
+			return errors.Wrap(err, "could not load pipeline file")
+		} else if loaded == nil {
+			return nil
+		}
+
+		original = *loaded.DeepCopy()
+	}
+
 	if err := c.ensureCurrentPipelineStageIsScheduled(original, logger); err != nil {
 		return errors.Wrap(err, "could not ensure current pipeline stage was scheduled")
 	}
@@ -177,15 +230,591 @@ func (c *PipelineController) setMinioInfoForPipeline(original api.Pipeline, mini
 }
 
 func (c *PipelineController) processSuccessfulPipeline(original api.Pipeline, logger logrus.FieldLogger) error {
-	logger.Info("todo: processing successful pipeline")
-	return nil
+	duration := original.Status.EndTime.Sub(original.Status.StartTime.Time).Round(time.Second)
+	c.commitStatusReporter.ReportPipeline(original, commitstatus.StateSuccess, fmt.Sprintf("pipeline succeeded in %s", duration))
+
+	if err := c.notifier.NotifyPipeline(original); err != nil {
+		return errors.Wrap(err, "could not queue notifications")
+	}
+
+	// the pipeline no longer counts against the running pipelines
+	if err := c.requeueQueuedPipelines(original, logger); err != nil {
+		return err
+	}
+
+	return c.enforceRetentionPolicy(original, logger)
 }
 
 func (c *PipelineController) processFailedPipeline(original api.Pipeline, logger logrus.FieldLogger) error {
-	logger.Info("todo: processing failed pipeline")
+	c.commitStatusReporter.ReportPipeline(original, commitstatus.StateFailure, original.Status.FailureReason)
+
+	if err := c.notifier.NotifyPipeline(original); err != nil {
+		return errors.Wrap(err, "could not queue notifications")
+	}
+
+	// the pipeline no longer counts against the running pipelines
+	if err := c.requeueQueuedPipelines(original, logger); err != nil {
+		return err
+	}
+
+	return c.enforceRetentionPolicy(original, logger)
+}
+
+// processConcurrencyGroup cancels or discards the older pipelines of the
+// concurrency group of a queued pipeline (or discards the pipeline itself when
+// a newer one only queues the latest) and returns whether it can be run; only
+// one pipeline of a group runs at a time and they start in order
+func (c *PipelineController) processConcurrencyGroup(original api.Pipeline, logger logrus.FieldLogger) (bool, error) {
+	group := original.GetConcurrencyGroup()
+	pipelines, err := c.pipelineLister.Pipelines(original.GetNamespace()).List(labels.Everything())
+	if err != nil {
+		return false, errors.Wrap(err, "could not list pipelines")
+	}
+
+	canRun := true
+	for _, pipeline := range pipelines {
+		if pipeline.GetName() == original.GetName() || pipeline.HasFinished() || pipeline.GetConcurrencyGroup() != group {
+			continue
+		}
+
+		pipelineLogger := logger.WithField(logging.FieldPipeline, pipeline.GetName())
+
+		if pipeline.IsNewerThan(original) {
+			if pipeline.Spec.Concurrency.QueueLatestOnly {
+				pipelineLogger.Info("superseded by a newer pipeline of the concurrency group; discarding")
+				return false, c.supersedePipeline(original, *pipeline)
+			}
+		} else if pipeline.IsCancelRequested() {
+			// the pipeline is already on its way out
+		} else if pipeline.IsRunning() && original.Spec.Concurrency.CancelInProgress {
+			pipelineLogger.Info("cancelling older pipeline of the concurrency group")
+			if err := c.supersedePipeline(*pipeline, original); err != nil {
+				return false, err
+			}
+
+			// the pipeline is requeued once the cancelled one has finished
+		} else if !pipeline.IsRunning() && original.Spec.Concurrency.QueueLatestOnly {
+			pipelineLogger.Info("discarding older queued pipeline of the concurrency group")
+			if err := c.supersedePipeline(*pipeline, original); err != nil {
+				return false, err
+			}
+
+			continue
+		} else if !pipeline.IsRunning() {
+			pipelineLogger.Info("older pipeline of the concurrency group is queued")
+			canRun = false
+		}
+
+		if pipeline.IsRunning() {
+			pipelineLogger.Info("pipeline of the concurrency group is running")
+			canRun = false
+		}
+	}
+
+	return canRun, nil
+}
+
+// supersedePipeline requests the cancellation of the pipeline in favor of the
+// newer one
+func (c *PipelineController) supersedePipeline(original, newer api.Pipeline) error {
+	pipeline := *original.DeepCopy()
+	pipeline.Supersede(newer.GetName())
+
+	if _, err := generic.PatchPipeline(c.kubesmithClient, pipeline, original); err != nil {
+		return errors.Wrapf(err, "could not cancel pipeline %s", original.GetName())
+	}
+
+	return nil
+}
+
+func (c *PipelineController) processCancelRequestedPipeline(original api.Pipeline, logger logrus.FieldLogger) error {
+	pipeline := *original.DeepCopy()
+
+	// the stages before the finally stage are cancelled and the finally stage
+	// still runs; the pipeline is marked as cancelled once it finished
+	if original.CanRunFinallyStage() {
+		if err := c.cancelAssociatedPipelineStages(original, logger); err != nil {
+			return err
+		}
+
+		if err := c.cancelAssociatedPipelineJobs(original, logger); err != nil {
+			return err
+		}
+
+		logger.Info("cancellation requested; moving on to the finally stage")
+		pipeline.RunFinallyStage(api.PhaseCancelled, "")
+		if _, err := generic.PatchPipeline(c.kubesmithClient, pipeline, original); err != nil {
+			return errors.Wrap(err, "could not move on to the finally stage")
+		}
+
+		logger.Info("moved on to the finally stage")
+		return nil
+	}
+
+	if original.IsRunningFinallyStage() {
+		logger.Info("cancellation requested; letting the finally stage finish")
+		pipeline.RunFinallyStage(api.PhaseCancelled, "")
+		if _, err := generic.PatchPipeline(c.kubesmithClient, pipeline, original); err != nil {
+			return errors.Wrap(err, "could not mark the finally stage as running after the cancellation")
+		}
+
+		logger.Info("marked the finally stage as running after the cancellation")
+		return nil
+	}
+
+	logger.Info("cancellation requested; marking as cancelled")
+	pipeline.SetPhaseToCancelled()
+	if _, err := generic.PatchPipeline(c.kubesmithClient, pipeline, original); err != nil {
+		return errors.Wrap(err, "could not mark as cancelled")
+	}
+
+	logger.Info("marked as cancelled")
+	return nil
+}
+
+func (c *PipelineController) processRetryRequestedPipeline(original api.Pipeline, logger logrus.FieldLogger) error {
+	logger = logger.WithFields(logrus.Fields{
+		"attempt":  original.Spec.Retry.Attempt,
+		"strategy": original.GetRetryStrategy(),
+	})
+
+	if err := original.Validate(); err != nil {
+		logger.Info("pipeline is invalid; ignoring retry request")
+		return nil
+	}
+
+	logger.Info("checking if another pipeline can be run")
+	canRunAnotherPipeline, err := c.canRunAnotherPipeline(original)
+	if err != nil {
+		return errors.Wrap(err, "could not check if another pipeline could be run")
+	}
+
+	if !canRunAnotherPipeline {
+		logger.Info("cannot run another pipeline; postponing retry")
+		c.Queue.AddAfter(sync.PipelineUpdateAction(original), retryPostponeDelay)
+		return nil
+	}
+
+	logger.Info("retrieving pipeline stages")
+	stages, err := c.getSortedPipelineStages(original)
+	if err != nil {
+		return err
+	}
+	logger.Info("retrieved pipeline stages")
+
+	// resume from the first stage that did not succeed; the stages before it
+	// already left their artifacts behind
+	stageIndex := len(stages) + 1
+	for _, stage := range stages {
+		if stage.HasSucceeded() {
+			continue
+		}
+
+		stageIndex = stage.GetStageIndex()
+		if err := c.resetPipelineStage(original, *stage, logger); err != nil {
+			return err
+		}
+
+		break
+	}
+
+	// stages after the one the retry resumes from only exist when the finally
+	// stage ran; they're scheduled again once the pipeline gets to them
+	if err := c.deletePipelineStagesAfter(original, stages, stageIndex, logger); err != nil {
+		return err
+	}
+
+	// the pipeline never made it to a stage; make sure the repo gets cloned again
+	if len(stages) == 0 {
+		propagationPolicy := metav1.DeletePropagationBackground
+		deleteOptions := metav1.DeleteOptions{
+			PropagationPolicy: &propagationPolicy,
+		}
+
+		labelSelector := c.getResourceLabelSelector(original.GetWrappedLabels())
+		if err := c.deleteAssociatedJobs(original, labelSelector, deleteOptions, logger); err != nil {
+			return err
+		}
+	}
+
+	logger.WithField("stageIndex", stageIndex).Info("marking as running for retry")
+	pipeline := *original.DeepCopy()
+	pipeline.ResetForRetry(stageIndex)
+
+	if _, err := generic.PatchPipeline(c.kubesmithClient, pipeline, original); err != nil {
+		return errors.Wrap(err, "could not mark as running for retry")
+	}
+
+	logger.Info("marked as running for retry")
+	c.commitStatusReporter.ReportPipeline(pipeline, commitstatus.StateRunning, fmt.Sprintf("pipeline is running attempt %d", pipeline.GetAttempt()))
+	return nil
+}
+
+func (c *PipelineController) getSortedPipelineStages(original api.Pipeline) ([]*api.PipelineStage, error) {
+	labelSelector := c.getResourceLabelSelector(original.GetWrappedLabels())
+	stages, err := c.pipelineStageLister.PipelineStages(original.GetNamespace()).List(labelSelector)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not retrieve pipeline stages")
+	}
+
+	sort.Slice(stages, func(i, j int) bool {
+		return stages[i].GetStageIndex() < stages[j].GetStageIndex()
+	})
+
+	return stages, nil
+}
+
+func (c *PipelineController) deletePipelineStagesAfter(original api.Pipeline, stages []*api.PipelineStage, stageIndex int, logger logrus.FieldLogger) error {
+	for _, stage := range stages {
+		if stage.GetStageIndex() <= stageIndex {
+			continue
+		}
+
+		// the pipeline stage controller deletes the pipeline jobs of the stage
+		logger.WithField(logging.FieldStage, stage.GetName()).Info("deleting pipeline stage of previous attempt")
+		if err := c.kubesmithClient.PipelineStages(stage.GetNamespace()).Delete(stage.GetName(), &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "could not delete pipeline stage: %s/%s", stage.GetNamespace(), stage.GetName())
+		}
+	}
+
+	return nil
+}
+
+func (c *PipelineController) resetPipelineStage(pipeline api.Pipeline, original api.PipelineStage, logger logrus.FieldLogger) error {
+	logger = logger.WithField(logging.FieldStage, original.GetName())
+
+	logger.Info("retrieving pipeline jobs of pipeline stage")
+	labelSelector := c.getResourceLabelSelector(map[string]string{
+		api.GetLabelKey("PipelineStageName"):      original.GetName(),
+		api.GetLabelKey("PipelineStageNamespace"): original.GetNamespace(),
+	})
+
+	jobs, err := c.pipelineJobLister.PipelineJobs(original.GetNamespace()).List(labelSelector)
+	if err != nil {
+		return errors.Wrap(err, "could not retrieve pipeline jobs")
+	}
+	logger.Info("retrieved pipeline jobs of pipeline stage")
+
+	// the pipeline jobs are reset before the stage so that the stage can never
+	// be failed again by a pipeline job that has not been reset yet
+	resetJobKeys := []string{}
+	for _, job := range jobs {
+		if !c.pipelineJobNeedsRetry(pipeline, *job) {
+			continue
+		}
+
+		if err := c.resetPipelineJob(*job, logger); err != nil {
+			return err
+		}
+
+		resetJobKeys = append(resetJobKeys, fmt.Sprintf("%s/%s", job.GetNamespace(), job.GetName()))
+	}
+
+	logger.Info("marking pipeline stage as running for retry")
+	stage := *original.DeepCopy()
+	stage.ResetForRetry(resetJobKeys)
+
+	if _, err := generic.PatchPipelineStage(c.kubesmithClient, stage, original); err != nil {
+		return errors.Wrap(err, "could not mark pipeline stage as running for retry")
+	}
+
+	logger.Info("marked pipeline stage as running for retry")
+	return nil
+}
+
+func (c *PipelineController) pipelineJobNeedsRetry(pipeline api.Pipeline, job api.PipelineJob) bool {
+	if pipeline.GetRetryStrategy() == api.RetryStrategyFailedStage {
+		return true
+	}
+
+	// failures that were allowed count as a success for the stage
+	if job.HasFailed() && job.IsAllowedToFail() {
+		return false
+	}
+
+	return !job.HasSucceeded()
+}
+
+func (c *PipelineController) resetPipelineJob(original api.PipelineJob, logger logrus.FieldLogger) error {
+	logger = logger.WithField(logging.FieldJob, original.GetName())
+
+	// background propagation lets the pods of the previous attempt terminate gracefully
+	logger.Info("deleting job of previous attempt")
+	propagationPolicy := metav1.DeletePropagationBackground
+	deleteOptions := metav1.DeleteOptions{
+		PropagationPolicy: &propagationPolicy,
+	}
+
+	if err := c.kubeClient.BatchV1().Jobs(original.GetNamespace()).Delete(original.GetJobName(), &deleteOptions); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "could not delete job: %s/%s", original.GetNamespace(), original.GetJobName())
+	}
+	logger.Info("deleted job of previous attempt")
+
+	logger.Info("resetting pipeline job")
+	job := *original.DeepCopy()
+	job.ResetForRetry()
+
+	if _, err := generic.PatchPipelineJob(c.kubesmithClient, job, original); err != nil {
+		return errors.Wrapf(err, "could not reset pipeline job: %s/%s", original.GetNamespace(), original.GetName())
+	}
+
+	logger.Info("reset pipeline job")
+	return nil
+}
+
+func (c *PipelineController) processCancelledPipeline(original api.Pipeline, logger logrus.FieldLogger) error {
+	c.commitStatusReporter.ReportPipeline(original, commitstatus.StateCancelled, original.Status.FailureReason)
+
+	if err := c.cancelAssociatedPipelineStages(original, logger); err != nil {
+		return err
+	}
+
+	// the pipeline job controller stops the batch jobs of cancelled pipeline jobs
+	if err := c.cancelAssociatedPipelineJobs(original, logger); err != nil {
+		return err
+	}
+
+	// stop the clone repo job in case the pipeline was cancelled while cloning
+	propagationPolicy := metav1.DeletePropagationBackground
+	deleteOptions := metav1.DeleteOptions{
+		PropagationPolicy: &propagationPolicy,
+	}
+
+	labelSelector := c.getResourceLabelSelector(original.GetWrappedLabels())
+	if err := c.deleteAssociatedJobs(original, labelSelector, deleteOptions, logger); err != nil {
+		return err
+	}
+
+	// the pipeline no longer counts against the running pipelines
+	if err := c.requeueQueuedPipelines(original, logger); err != nil {
+		return err
+	}
+
+	return c.enforceRetentionPolicy(original, logger)
+}
+
+func (c *PipelineController) cancelAssociatedPipelineStages(original api.Pipeline, logger logrus.FieldLogger) error {
+	logger.Info("retrieving pipeline stages")
+	labelSelector := c.getResourceLabelSelector(original.GetWrappedLabels())
+	stages, err := c.pipelineStageLister.PipelineStages(original.GetNamespace()).List(labelSelector)
+	if err != nil {
+		return errors.Wrap(err, "could not retrieve pipeline stages")
+	}
+
+	logger.Info("retrieved pipeline stages")
+
+	logger.Info("cancelling pipeline stages")
+	for _, stage := range stages {
+		if stage.HasFinished() {
+			continue
+		}
+
+		logger.WithField(logging.FieldStage, stage.GetName()).Info("cancelling pipeline stage")
+		updated := *stage.DeepCopy()
+		updated.SetPhaseToCancelled()
+
+		if _, err := generic.PatchPipelineStage(c.kubesmithClient, updated, *stage); err != nil {
+			return errors.Wrapf(err, "could not cancel pipeline stage: %s/%s", stage.GetNamespace(), stage.GetName())
+		}
+	}
+
+	logger.Info("cancelled pipeline stages")
 	return nil
 }
 
+func (c *PipelineController) cancelAssociatedPipelineJobs(original api.Pipeline, logger logrus.FieldLogger) error {
+	logger.Info("retrieving pipeline jobs")
+	labelSelector := c.getResourceLabelSelector(map[string]string{
+		api.GetLabelKey("PipelineName"):      original.GetName(),
+		api.GetLabelKey("PipelineNamespace"): original.GetNamespace(),
+	})
+
+	jobs, err := c.pipelineJobLister.PipelineJobs(original.GetNamespace()).List(labelSelector)
+	if err != nil {
+		return errors.Wrap(err, "could not retrieve pipeline jobs")
+	}
+
+	logger.Info("retrieved pipeline jobs")
+
+	logger.Info("cancelling pipeline jobs")
+	for _, job := range jobs {
+		if job.HasFinished() {
+			continue
+		}
+
+		logger.WithField(logging.FieldJob, job.GetName()).Info("cancelling pipeline job")
+		updated := *job.DeepCopy()
+		updated.SetPhaseToCancelled()
+
+		if _, err := generic.PatchPipelineJob(c.kubesmithClient, updated, *job); err != nil {
+			return errors.Wrapf(err, "could not cancel pipeline job: %s/%s", job.GetNamespace(), job.GetName())
+		}
+	}
+
+	logger.Info("cancelled pipeline jobs")
+	return nil
+}
+
+func (c *PipelineController) requeueQueuedPipelines(original api.Pipeline, logger logrus.FieldLogger) error {
+	pipelines, err := c.pipelineLister.Pipelines(original.GetNamespace()).List(labels.Everything())
+	if err != nil {
+		return errors.Wrap(err, "could not list pipelines")
+	}
+
+	for _, pipeline := range pipelines {
+		if pipeline.IsQueued() {
+			logger.WithField(logging.FieldPipeline, pipeline.GetName()).Info("requeueing queued pipeline")
+			c.Queue.Add(sync.PipelineUpdateAction(*pipeline))
+		}
+	}
+
+	return nil
+}
+
+func (c *PipelineController) enforceRetentionPolicy(original api.Pipeline, logger logrus.FieldLogger) error {
+	logger.Info("enforcing retention policy")
+
+	if c.retentionPolicy.KeepLast > 0 {
+		logger.Info("retrieving pipelines beyond the number to keep")
+		expired, err := c.getPipelinesBeyondKeepLast(original)
+		if err != nil {
+			return errors.Wrap(err, "could not retrieve pipelines beyond the number to keep")
+		}
+
+		logger.Info("retrieved pipelines beyond the number to keep")
+		expiredOriginal := false
+
+		for _, pipeline := range expired {
+			if err := c.expirePipeline(*pipeline.DeepCopy(), logger.WithFields(logging.GetResourceFields(pipeline))); err != nil {
+				return err
+			}
+
+			if pipeline.GetName() == original.GetName() {
+				expiredOriginal = true
+			}
+		}
+
+		if expiredOriginal {
+			return nil
+		}
+	}
+
+	ttl := c.getRetentionTTL(original)
+	if ttl <= 0 {
+		logger.Info("pipeline has no ttl; retaining")
+		return nil
+	}
+
+	remaining := original.GetFinishedTime().Add(ttl).Sub(c.clock.Now())
+	if remaining > 0 {
+		logger.WithField("expiresIn", remaining.String()).Info("pipeline has not expired; requeueing")
+		c.Queue.AddAfter(sync.PipelineUpdateAction(original), remaining)
+		return nil
+	}
+
+	return c.expirePipeline(original, logger)
+}
+
+func (c *PipelineController) getRetentionTTL(original api.Pipeline) time.Duration {
+	if original.HasSucceeded() && c.retentionPolicy.SucceededTTL > 0 {
+		return c.retentionPolicy.SucceededTTL
+	} else if original.HasFailed() && c.retentionPolicy.FailedTTL > 0 {
+		return c.retentionPolicy.FailedTTL
+	}
+
+	return c.retentionPolicy.TTL
+}
+
+func (c *PipelineController) getPipelinesBeyondKeepLast(original api.Pipeline) ([]*api.Pipeline, error) {
+	pipelines, err := c.pipelineLister.Pipelines(original.GetNamespace()).List(labels.Everything())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list pipelines")
+	}
+
+	group := original.GetRetentionGroup()
+	finished := []*api.Pipeline{}
+
+	for _, pipeline := range pipelines {
+		if pipeline.HasFinished() && pipeline.GetRetentionGroup() == group {
+			finished = append(finished, pipeline)
+		}
+	}
+
+	if len(finished) <= c.retentionPolicy.KeepLast {
+		return []*api.Pipeline{}, nil
+	}
+
+	// newest first, so everything after the pipelines to keep has expired
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].GetFinishedTime().After(finished[j].GetFinishedTime())
+	})
+
+	return finished[c.retentionPolicy.KeepLast:], nil
+}
+
+func (c *PipelineController) expirePipeline(original api.Pipeline, logger logrus.FieldLogger) error {
+	logger.Info("pipeline has expired")
+
+	// pipelines using their own minio server lose their storage along with
+	// the server; everything else needs its files removed explicitly
+	if !c.pipelineUsesOwnMinioServer(original) {
+		logger.Info("deleting pipeline files from storage")
+		s3Client, err := c.getS3ClientForPipeline(original)
+		if err != nil {
+			return err
+		}
+
+		if err := s3Client.DeletePath(original.Spec.Workspace.Storage.S3.BucketName, original.GetResourcePrefix()); err != nil {
+			return errors.Wrap(err, "could not delete pipeline files from storage")
+		}
+
+		logger.Info("deleted pipeline files from storage")
+	}
+
+	// deleting the pipeline cleans up the rest of its resources
+	logger.Info("deleting pipeline")
+	propagationPolicy := metav1.DeletePropagationBackground
+	deleteOptions := metav1.DeleteOptions{
+		PropagationPolicy: &propagationPolicy,
+	}
+
+	if err := c.kubesmithClient.Pipelines(original.GetNamespace()).Delete(original.GetName(), &deleteOptions); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrap(err, "could not delete pipeline")
+		}
+	}
+
+	logger.Info("deleted pipeline")
+	return nil
+}
+
+func (c *PipelineController) pipelineUsesOwnMinioServer(original api.Pipeline) bool {
+	minioServerName := fmt.Sprintf("%s-minio-server", original.GetResourcePrefix())
+
+	return original.NeedsMinioServer() || original.Spec.Workspace.Storage.S3.Credentials.Secret.Name == minioServerName
+}
+
+func (c *PipelineController) getS3ClientForPipeline(original api.Pipeline) (*s3.S3Client, error) {
+	secret, err := c.secretLister.Secrets(original.GetNamespace()).Get(original.Spec.Workspace.Storage.S3.Credentials.Secret.Name)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch secret information")
+	}
+
+	s3Client, err := s3.NewS3Client(
+		original.Spec.Workspace.Storage.S3.Host,
+		original.Spec.Workspace.Storage.S3.Port,
+		string(secret.Data[original.Spec.Workspace.Storage.S3.Credentials.Secret.AccessKeyKey]),
+		string(secret.Data[original.Spec.Workspace.Storage.S3.Credentials.Secret.SecretKeyKey]),
+		original.Spec.Workspace.Storage.S3.UseSSL,
+	)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create an s3 client")
+	}
+
+	return s3Client, nil
+}
+
 func (c *PipelineController) processDeletedPipeline(original api.Pipeline, logger logrus.FieldLogger) error {
 	logger.Info("cleaning up pipeline")
 	if err := c.cleanupMinioServerForPipeline(original, logger); err != nil {
@@ -193,7 +822,7 @@ func (c *PipelineController) processDeletedPipeline(original api.Pipeline, logge
 	}
 
 	// create a selector for listing resources associated to pipelines
-	labelSelector := c.getResourceLabelSelector(c.getWrappedLabels(original))
+	labelSelector := c.getResourceLabelSelector(original.GetWrappedLabels())
 
 	// create the delete options that can help clean everything up
 	propagationPolicy := metav1.DeletePropagationBackground
@@ -243,8 +872,8 @@ func (c *PipelineController) deleteAssociatedJobs(
 	logger.Info("deleting jobs")
 	for _, job := range jobs {
 		logger.WithFields(logrus.Fields{
-			"JobName":      job.GetName(),
-			"JobNamespace": job.GetNamespace(),
+			"batchJob":             job.GetName(),
+			logging.FieldNamespace: job.GetNamespace(),
 		}).Info("deleting job")
 
 		if err := c.kubeClient.BatchV1().Jobs(job.GetNamespace()).Delete(job.GetName(), &deleteOptions); err != nil {
@@ -322,8 +951,8 @@ func (c *PipelineController) deleteAssociatedPipelineStages(
 	logger.Info("deleting pipeline stages")
 	for _, stage := range stages {
 		logger.WithFields(logrus.Fields{
-			"PipelineStageName":      stage.GetName(),
-			"PipelineStageNamespace": stage.GetNamespace(),
+			logging.FieldStage:     stage.GetName(),
+			logging.FieldNamespace: stage.GetNamespace(),
 		}).Info("deleting pipeline stage")
 
 		if err := c.kubesmithClient.PipelineStages(stage.GetNamespace()).Delete(stage.GetName(), &deleteOptions); err != nil {
@@ -349,16 +978,13 @@ func (c *PipelineController) getResourceLabelSelector(resourceLabels map[string]
 	return labels.SelectorFromSet(set)
 }
 
-func (c *PipelineController) patchPipeline(updated, original api.Pipeline) (*api.Pipeline, error) {
-	patchType, patchBytes, err := updated.GetPatchFromOriginal(original)
-	if err != nil {
-		return nil, err
+func (c *PipelineController) canRunAnotherPipeline(original api.Pipeline) (bool, error) {
+	// a pipeline that is waited on runs in the place of its upstream pipeline,
+	// which would otherwise wait forever on a pipeline that cannot start
+	if waited, err := c.isWaitedOnByRunningUpstream(original); err != nil || waited {
+		return waited, err
 	}
 
-	return c.kubesmithClient.Pipelines(original.GetNamespace()).Patch(original.GetName(), patchType, patchBytes)
-}
-
-func (c *PipelineController) canRunAnotherPipeline(original api.Pipeline) (bool, error) {
 	pipelines, err := c.pipelineLister.Pipelines(original.GetNamespace()).List(labels.Everything())
 	if err != nil {
 		return false, errors.Wrap(err, "could not list pipelines")
@@ -378,11 +1004,45 @@ func (c *PipelineController) canRunAnotherPipeline(original api.Pipeline) (bool,
 	return false, nil
 }
 
-func (c *PipelineController) getWrappedLabels(pipeline api.Pipeline) map[string]string {
-	labels := pipeline.GetResourceLabels()
-	labels[api.GetLabelKey("Controller")] = "Pipeline"
+// isWaitedOnByRunningUpstream returns whether the pipeline was created by a
+// waiting trigger job of a running pipeline; the trigger job has to name the
+// pipeline as its downstream so that no other pipeline can claim the place of
+// its upstream by pointing at it
+func (c *PipelineController) isWaitedOnByRunningUpstream(original api.Pipeline) (bool, error) {
+	spec := original.Spec.Upstream
+	if !original.HasUpstream() || !spec.Wait || spec.PipelineJob == "" {
+		return false, nil
+	}
+
+	// the upstream pipeline may be in a namespace the listers don't see
+	upstream, err := c.kubesmithClient.Pipelines(spec.Namespace).Get(spec.Pipeline, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Wrap(err, "could not get upstream pipeline")
+	} else if !upstream.IsRunning() {
+		return false, nil
+	}
+
+	job, err := c.kubesmithClient.PipelineJobs(spec.Namespace).Get(spec.PipelineJob, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Wrap(err, "could not get upstream pipeline job")
+	}
+
+	if job.GetLabels()[api.GetLabelKey("PipelineName")] != upstream.GetName() || !job.IsRunning() || !job.Spec.Job.Trigger.Wait {
+		return false, nil
+	}
 
-	return labels
+	downstream := job.Status.Downstream
+	if downstream.Name == "" {
+		// the trigger job records the pipeline right after creating it
+		c.Queue.AddAfter(sync.PipelineUpdateAction(original), upstreamRecordDelay)
+		return false, nil
+	}
+
+	return downstream.Namespace == original.GetNamespace() && downstream.Name == original.GetName(), nil
 }
 
 func (c *PipelineController) ensureServiceAccountExists(original api.Pipeline, logger logrus.FieldLogger) error {
@@ -457,7 +1117,7 @@ func (c *PipelineController) ensureMinioServerIsRunning(original api.Pipeline, l
 		original.GetNamespace(),
 		original.GetResourcePrefix(),
 		logger,
-		c.getWrappedLabels(original),
+		original.GetWrappedLabels(),
 		c.kubeClient,
 		c.secretLister,
 		c.deploymentLister,
@@ -537,7 +1197,7 @@ func (c *PipelineController) ensureRepoArtifactExists(original api.Pipeline, log
 	if exists := <-repoArtifactCreated; !exists {
 		logger.Info("repo artifact was not created; requeueing pipeline")
 		new := *original.DeepCopy()
-		if _, err := c.patchPipeline(new, original); err != nil {
+		if _, err := generic.PatchPipeline(c.kubesmithClient, new, original); err != nil {
 			return errors.Wrap(err, "could not requeue pipeline")
 		}
 
@@ -549,6 +1209,107 @@ func (c *PipelineController) ensureRepoArtifactExists(original api.Pipeline, log
 	return nil
 }
 
+// loadCommit records the commit the clone repo job checked the repo out at in
+// the status of the pipeline; pipelines whose clone didn't record it are
+// returned as they are
+func (c *PipelineController) loadCommit(original api.Pipeline, logger logrus.FieldLogger) (*api.Pipeline, error) {
+	s3Client, err := c.getS3ClientForPipeline(original)
+	if err != nil {
+		return nil, err
+	}
+
+	fileName := fmt.Sprintf("%s/repo/commit", original.GetResourcePrefix())
+	bucketName := original.Spec.Workspace.Storage.S3.BucketName
+
+	exists, err := s3Client.FileExists(bucketName, fileName)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not check for commit file")
+	} else if !exists {
+		logger.Debug("commit file does not exist; skipping")
+		return &original, nil
+	}
+
+	data := bytes.Buffer{}
+	if err := s3Client.StreamFile(bucketName, fileName, &data); err != nil {
+		return nil, errors.Wrap(err, "could not download commit file")
+	}
+
+	pipeline := *original.DeepCopy()
+	pipeline.Status.Commit = strings.TrimSpace(data.String())
+	if pipeline.Status.Commit == "" {
+		return &original, nil
+	}
+
+	updated, err := generic.PatchPipeline(c.kubesmithClient, pipeline, original)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not update pipeline with commit")
+	}
+
+	logger.WithField("commit", updated.Status.Commit).Info("recorded commit")
+
+	// pipelines of a ref had no commit to post their status on until now
+	if original.Spec.Workspace.Repo.Commit == "" {
+		c.commitStatusReporter.ReportPipeline(*updated, commitstatus.StateRunning, "pipeline is running")
+	}
+
+	return updated, nil
+}
+
+// loadPipelineFile loads the stages and jobs of the pipeline from the pipeline
+// file the clone repo job uploaded next to the repo; pipelines whose file is
+// missing or invalid are failed (and nil is returned)
+func (c *PipelineController) loadPipelineFile(original api.Pipeline, logger logrus.FieldLogger) (*api.Pipeline, error) {
+	s3Client, err := c.getS3ClientForPipeline(original)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("loading pipeline file")
+	fileName := fmt.Sprintf("%s/repo/pipeline.yaml", original.GetResourcePrefix())
+	bucketName := original.Spec.Workspace.Storage.S3.BucketName
+	pipeline := *original.DeepCopy()
+
+	exists, err := s3Client.FileExists(bucketName, fileName)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not check for pipeline file")
+	}
+
+	if exists {
+		data := bytes.Buffer{}
+		if err := s3Client.StreamFile(bucketName, fileName, &data); err != nil {
+			return nil, errors.Wrap(err, "could not download pipeline file")
+		}
+
+		err = pipeline.LoadPipelineFile(data.Bytes())
+		if err == nil {
+			err = pipeline.Validate()
+		}
+	} else {
+		err = fmt.Errorf("pipeline file %s does not exist in the repo", original.Spec.PipelinePath)
+	}
+
+	if err != nil {
+		logger.WithError(err).Info("pipeline file is invalid; marking as failed")
+		failed := *original.DeepCopy()
+		failed.SetPhaseToFailed(err.Error())
+
+		if _, err := generic.PatchPipeline(c.kubesmithClient, failed, original); err != nil {
+			return nil, errors.Wrap(err, "could not mark as failed")
+		}
+
+		logger.Info("marked as failed")
+		return nil, nil
+	}
+
+	updated, err := generic.PatchPipeline(c.kubesmithClient, pipeline, original)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not update pipeline with pipeline file")
+	}
+
+	logger.WithField("stages", len(updated.Spec.Stages)).Info("loaded pipeline file")
+	return updated, nil
+}
+
 func (c *PipelineController) ensureRepoArtifactJobIsScheduled(original api.Pipeline, logger logrus.FieldLogger) error {
 	pipelineName := original.GetResourcePrefix()
 	name := fmt.Sprintf("%s-clone-repo", pipelineName)
@@ -559,8 +1320,8 @@ func (c *PipelineController) ensureRepoArtifactJobIsScheduled(original api.Pipel
 		if apierrors.IsNotFound(err) {
 			logger.Info("clone repo job was not found; scheduling...")
 
-			original.ObjectMeta.Labels = c.getWrappedLabels(original)
-			job := templates.GetJobCloneRepo(original)
+			original.ObjectMeta.Labels = original.GetWrappedLabels()
+			job := templates.GetJobCloneRepo(original, c.logConfig)
 			if _, err := c.kubeClient.BatchV1().Jobs(original.GetNamespace()).Create(&job); err != nil {
 				return errors.Wrap(err, "could not schedule clone repo job")
 			}
@@ -610,7 +1371,7 @@ func (c *PipelineController) cleanupMinioServerForPipeline(original api.Pipeline
 		original.GetNamespace(),
 		original.GetResourcePrefix(),
 		logger,
-		c.getWrappedLabels(original),
+		original.GetWrappedLabels(),
 		c.kubeClient,
 		c.secretLister,
 		c.deploymentLister,
@@ -627,13 +1388,13 @@ func (c *PipelineController) cleanupMinioServerForPipeline(original api.Pipeline
 
 func (c *PipelineController) ensureCurrentPipelineStageIsScheduled(original api.Pipeline, logger logrus.FieldLogger) error {
 	logger.Info("ensuring pipeline stage is scheduled")
-	name := fmt.Sprintf("%s-stage-%d", original.GetResourcePrefix(), original.Status.StageIndex)
+	name := original.GetPipelineStageName(original.Status.StageIndex)
 
 	if _, err := c.pipelineStageLister.PipelineStages(original.GetNamespace()).Get(name); err != nil {
 		if apierrors.IsNotFound(err) {
 			logger.Info("pipeline stage was not found; scheduling...")
 
-			original.ObjectMeta.Labels = c.getWrappedLabels(original)
+			original.ObjectMeta.Labels = original.GetWrappedLabels()
 			pipelineStage := templates.GetPipelineStage(name, original)
 			if _, err := c.kubesmithClient.PipelineStages(original.GetNamespace()).Create(&pipelineStage); err != nil {
 				return errors.Wrap(err, "could not schedule pipeline stage")
@@ -649,14 +1410,3 @@ func (c *PipelineController) ensureCurrentPipelineStageIsScheduled(original api.
 	logger.Info("pipeline stage is scheduled")
 	return nil
 }
-
-func (c *PipelineController) pipelineNeedsMinio(original api.Pipeline) bool {
-	s3 := original.Spec.Workspace.Storage.S3
-
-	return (s3.Host == "") ||
-		(s3.Port <= 0) ||
-		(s3.BucketName == "") ||
-		(s3.Credentials.Secret.Name == "") ||
-		(s3.Credentials.Secret.AccessKeyKey == "") ||
-		(s3.Credentials.Secret.SecretKeyKey == "")
-}
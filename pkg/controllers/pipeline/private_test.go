@@ -0,0 +1,326 @@
+package pipeline
+
+import (
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/controllers/generic"
+	"github.com/kubesmith/kubesmith/pkg/generated/clientset/versioned/fake"
+	kubesmithListersv1 "github.com/kubesmith/kubesmith/pkg/generated/listers/kubesmith/v1"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	ktesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+)
+
+var testNow = time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+// newTestController creates a pipeline controller whose client and listers
+// hold the pipelines
+func newTestController(policy RetentionPolicy, pipelines ...api.Pipeline) (*PipelineController, *fake.Clientset) {
+	objects := []runtime.Object{}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+
+	for _, pipeline := range pipelines {
+		pipeline := pipeline.DeepCopy()
+		objects = append(objects, pipeline)
+		indexer.Add(pipeline)
+	}
+
+	client := fake.NewSimpleClientset(objects...)
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+
+	controller := &PipelineController{
+		GenericController: generic.NewGenericController("Pipeline", logger),
+		retentionPolicy:   policy,
+		logger:            logger,
+		kubesmithClient:   client.KubesmithV1(),
+		pipelineLister:    kubesmithListersv1.NewPipelineLister(indexer),
+		clock:             clock.NewFakeClock(testNow),
+	}
+
+	return controller, client
+}
+
+// newTestPipeline creates a pipeline of the ref that finished in the phase
+// the given time ago; pipelines without storage run their own minio server,
+// so expiring them doesn't need any storage
+func newTestPipeline(name, ref string, phase api.Phase, finishedAgo time.Duration) api.Pipeline {
+	pipeline := api.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+	}
+
+	pipeline.Spec.Workspace.Repo.URL = "git@github.com:kubesmith/app.git"
+	pipeline.Spec.Workspace.Repo.Ref = ref
+	pipeline.Status.Phase = phase
+	pipeline.Status.EndTime = metav1.NewTime(testNow.Add(-finishedAgo))
+
+	return pipeline
+}
+
+// getDeletedPipelines returns the names of the pipelines deleted through the
+// client in alphabetical order
+func getDeletedPipelines(client *fake.Clientset) []string {
+	deleted := []string{}
+
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "delete" && action.GetResource().Resource == "pipelines" {
+			deleted = append(deleted, action.(ktesting.DeleteAction).GetName())
+		}
+	}
+
+	sort.Strings(deleted)
+	return deleted
+}
+
+func TestEnforceRetentionPolicyKeepLast(t *testing.T) {
+	pipelines := []api.Pipeline{
+		newTestPipeline("master-1", "refs/heads/master", api.PhaseSucceeded, 3*time.Hour),
+		newTestPipeline("master-2", "refs/heads/master", api.PhaseFailed, 2*time.Hour),
+		newTestPipeline("master-3", "refs/heads/master", api.PhaseSucceeded, time.Hour),
+		newTestPipeline("feature-1", "refs/heads/feature", api.PhaseSucceeded, 4*time.Hour),
+	}
+
+	running := newTestPipeline("master-4", "refs/heads/master", api.PhaseRunning, 0)
+	running.Status.EndTime = metav1.Time{}
+	pipelines = append(pipelines, running)
+
+	// a pipeline of another repo on the same branch
+	other := newTestPipeline("other-1", "refs/heads/master", api.PhaseSucceeded, 5*time.Hour)
+	other.Spec.Workspace.Repo.URL = "git@github.com:kubesmith/other.git"
+	pipelines = append(pipelines, other)
+
+	// a pipeline of a tag is grouped by the branch label of the forge
+	tag := newTestPipeline("tag-1", "refs/tags/v1.0.0", api.PhaseSucceeded, 6*time.Hour)
+	tag.SetLabels(map[string]string{api.GetLabelKey("PipelineBranch"): "master"})
+	pipelines = append(pipelines, tag)
+
+	tests := []struct {
+		name     string
+		original api.Pipeline
+		keepLast int
+		expected []string
+	}{
+		{
+			name:     "keeps the newest pipelines of the branch",
+			original: pipelines[2],
+			keepLast: 2,
+			expected: []string{"master-1", "tag-1"},
+		},
+		{
+			name:     "keeps only the newest pipeline of the branch",
+			original: pipelines[2],
+			keepLast: 1,
+			expected: []string{"master-1", "master-2", "tag-1"},
+		},
+		{
+			name:     "doesn't count the pipelines of other branches",
+			original: pipelines[3],
+			keepLast: 1,
+			expected: []string{},
+		},
+		{
+			name:     "keeps everything below the limit",
+			original: pipelines[2],
+			keepLast: 5,
+			expected: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			controller, client := newTestController(RetentionPolicy{KeepLast: test.keepLast}, pipelines...)
+
+			if err := controller.enforceRetentionPolicy(test.original, controller.logger); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if deleted := getDeletedPipelines(client); !reflect.DeepEqual(deleted, test.expected) {
+				t.Errorf("expected deleted pipelines %q, got %q", test.expected, deleted)
+			}
+		})
+	}
+}
+
+func TestEnforceRetentionPolicyTTL(t *testing.T) {
+	tests := []struct {
+		name     string
+		original api.Pipeline
+		policy   RetentionPolicy
+		expired  bool
+	}{
+		{
+			name:     "no ttl",
+			original: newTestPipeline("app-1", "refs/heads/master", api.PhaseSucceeded, 48*time.Hour),
+			policy:   RetentionPolicy{},
+			expired:  false,
+		},
+		{
+			name:     "expired",
+			original: newTestPipeline("app-1", "refs/heads/master", api.PhaseSucceeded, 2*time.Hour),
+			policy:   RetentionPolicy{TTL: time.Hour},
+			expired:  true,
+		},
+		{
+			name:     "not expired",
+			original: newTestPipeline("app-1", "refs/heads/master", api.PhaseSucceeded, 30*time.Minute),
+			policy:   RetentionPolicy{TTL: time.Hour},
+			expired:  false,
+		},
+		{
+			name:     "succeeded ttl overrides the ttl",
+			original: newTestPipeline("app-1", "refs/heads/master", api.PhaseSucceeded, 2*time.Hour),
+			policy:   RetentionPolicy{TTL: time.Hour, SucceededTTL: 3 * time.Hour},
+			expired:  false,
+		},
+		{
+			name:     "failed ttl overrides the ttl",
+			original: newTestPipeline("app-1", "refs/heads/master", api.PhaseFailed, 2*time.Hour),
+			policy:   RetentionPolicy{TTL: 3 * time.Hour, FailedTTL: time.Hour},
+			expired:  true,
+		},
+		{
+			name:     "succeeded ttl doesn't apply to failed pipelines",
+			original: newTestPipeline("app-1", "refs/heads/master", api.PhaseFailed, 2*time.Hour),
+			policy:   RetentionPolicy{TTL: time.Hour, SucceededTTL: 3 * time.Hour},
+			expired:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			controller, client := newTestController(test.policy, test.original)
+
+			if err := controller.enforceRetentionPolicy(test.original, controller.logger); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			expected := []string{}
+			if test.expired {
+				expected = append(expected, test.original.GetName())
+			}
+
+			if deleted := getDeletedPipelines(client); !reflect.DeepEqual(deleted, expected) {
+				t.Errorf("expected deleted pipelines %q, got %q", expected, deleted)
+			}
+		})
+	}
+}
+
+func TestProcessConcurrencyGroup(t *testing.T) {
+	newPipeline := func(name string, phase api.Phase, createdAgo time.Duration, concurrency api.PipelineSpecConcurrency) api.Pipeline {
+		pipeline := newTestPipeline(name, "refs/heads/master", phase, 0)
+		pipeline.CreationTimestamp = metav1.NewTime(testNow.Add(-createdAgo))
+		pipeline.Spec.Concurrency = concurrency
+		pipeline.Status.EndTime = metav1.Time{}
+
+		return pipeline
+	}
+
+	group := api.PipelineSpecConcurrency{Group: "{repo}"}
+	cancelInProgress := api.PipelineSpecConcurrency{Group: "{repo}", CancelInProgress: true}
+	queueLatestOnly := api.PipelineSpecConcurrency{Group: "{repo}", QueueLatestOnly: true}
+
+	cancelling := newPipeline("app-1", api.PhaseRunning, 2*time.Hour, group)
+	cancelling.Spec.Cancel = true
+
+	tests := []struct {
+		name      string
+		original  api.Pipeline
+		pipelines []api.Pipeline
+		canRun    bool
+		cancelled []string
+	}{
+		{
+			name:      "no other pipeline of the group",
+			original:  newPipeline("app-2", api.PhaseQueued, time.Hour, group),
+			pipelines: []api.Pipeline{newPipeline("app-1", api.PhaseSucceeded, 2*time.Hour, group)},
+			canRun:    true,
+			cancelled: []string{},
+		},
+		{
+			name:      "older pipeline is running",
+			original:  newPipeline("app-2", api.PhaseQueued, time.Hour, group),
+			pipelines: []api.Pipeline{newPipeline("app-1", api.PhaseRunning, 2*time.Hour, group)},
+			canRun:    false,
+			cancelled: []string{},
+		},
+		{
+			name:      "older pipeline is queued",
+			original:  newPipeline("app-2", api.PhaseQueued, time.Hour, group),
+			pipelines: []api.Pipeline{newPipeline("app-1", api.PhaseQueued, 2*time.Hour, group)},
+			canRun:    false,
+			cancelled: []string{},
+		},
+		{
+			name:      "older running pipeline is cancelled and waited on",
+			original:  newPipeline("app-2", api.PhaseQueued, time.Hour, cancelInProgress),
+			pipelines: []api.Pipeline{newPipeline("app-1", api.PhaseRunning, 2*time.Hour, group)},
+			canRun:    false,
+			cancelled: []string{"app-1"},
+		},
+		{
+			name:      "older pipeline that is being cancelled is waited on",
+			original:  newPipeline("app-2", api.PhaseQueued, time.Hour, cancelInProgress),
+			pipelines: []api.Pipeline{cancelling},
+			canRun:    false,
+			cancelled: []string{},
+		},
+		{
+			name:      "older queued pipeline is discarded",
+			original:  newPipeline("app-2", api.PhaseQueued, time.Hour, queueLatestOnly),
+			pipelines: []api.Pipeline{newPipeline("app-1", api.PhaseQueued, 2*time.Hour, group)},
+			canRun:    true,
+			cancelled: []string{"app-1"},
+		},
+		{
+			name:      "pipeline is discarded for a newer one",
+			original:  newPipeline("app-1", api.PhaseQueued, 2*time.Hour, group),
+			pipelines: []api.Pipeline{newPipeline("app-2", api.PhaseQueued, time.Hour, queueLatestOnly)},
+			canRun:    false,
+			cancelled: []string{"app-1"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			controller, client := newTestController(RetentionPolicy{}, append(test.pipelines, test.original)...)
+
+			canRun, err := controller.processConcurrencyGroup(test.original, controller.logger)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if canRun != test.canRun {
+				t.Errorf("expected can run to be %t, got %t", test.canRun, canRun)
+			}
+
+			cancelled := []string{}
+			for _, pipeline := range append(test.pipelines, test.original) {
+				patched, err := client.KubesmithV1().Pipelines(pipeline.GetNamespace()).Get(pipeline.GetName(), metav1.GetOptions{})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if patched.IsCancelRequested() && !pipeline.IsCancelRequested() {
+					cancelled = append(cancelled, pipeline.GetName())
+				}
+			}
+
+			if !reflect.DeepEqual(cancelled, test.cancelled) {
+				t.Errorf("expected cancelled pipelines %q, got %q", test.cancelled, cancelled)
+			}
+		})
+	}
+}
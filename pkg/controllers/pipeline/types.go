@@ -1,6 +1,12 @@
 package pipeline
 
 import (
+	"time"
+
+	"github.com/kubesmith/kubesmith/pkg/commitstatus"
+	"github.com/kubesmith/kubesmith/pkg/logging"
+	"github.com/kubesmith/kubesmith/pkg/notification"
+
 	"github.com/kubesmith/kubesmith/pkg/controllers/generic"
 	kubesmithv1 "github.com/kubesmith/kubesmith/pkg/generated/clientset/versioned/typed/kubesmith/v1"
 	kubesmithListersv1 "github.com/kubesmith/kubesmith/pkg/generated/listers/kubesmith/v1"
@@ -13,16 +19,31 @@ import (
 	rbacListersv1 "k8s.io/client-go/listers/rbac/v1"
 )
 
+const (
+	// retryPostponeDelay is how long a retry waits before checking again
+	// whether another pipeline can be run
+	retryPostponeDelay = 30 * time.Second
+
+	// upstreamRecordDelay is how long a queued downstream pipeline waits for
+	// the trigger job that created it to record it
+	upstreamRecordDelay = 5 * time.Second
+)
+
 type PipelineController struct {
 	*generic.GenericController
 
-	maxRunningPipelines int
-	logger              logrus.FieldLogger
-	kubeClient          kubernetes.Interface
-	kubesmithClient     kubesmithv1.KubesmithV1Interface
+	maxRunningPipelines  int
+	retentionPolicy      RetentionPolicy
+	logger               logrus.FieldLogger
+	logConfig            logging.Config
+	kubeClient           kubernetes.Interface
+	kubesmithClient      kubesmithv1.KubesmithV1Interface
+	commitStatusReporter *commitstatus.Reporter
+	notifier             *notification.Notifier
 
 	pipelineLister       kubesmithListersv1.PipelineLister
 	pipelineStageLister  kubesmithListersv1.PipelineStageLister
+	pipelineJobLister    kubesmithListersv1.PipelineJobLister
 	secretLister         coreListersv1.SecretLister
 	deploymentLister     appListersv1.DeploymentLister
 	serviceLister        coreListersv1.ServiceLister
@@ -32,3 +53,20 @@ type PipelineController struct {
 	roleBindingLister    rbacListersv1.RoleBindingLister
 	clock                clock.Clock
 }
+
+// RetentionPolicy describes how long finished pipelines (and everything they
+// created) are kept around before being garbage collected. A zero value for any
+// of the fields disables that part of the policy.
+type RetentionPolicy struct {
+	// KeepLast is the number of finished pipelines kept per repo/branch
+	KeepLast int
+
+	// TTL is how long any finished pipeline is kept after completion
+	TTL time.Duration
+
+	// SucceededTTL overrides TTL for pipelines that succeeded
+	SucceededTTL time.Duration
+
+	// FailedTTL overrides TTL for pipelines that failed
+	FailedTTL time.Duration
+}
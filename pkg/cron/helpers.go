@@ -0,0 +1,43 @@
+package cron
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NewSchedule parses a standard cron expression (minute, hour, day of month,
+// month and day of week) or one of the @yearly, @monthly, @weekly, @daily and
+// @hourly macros
+func NewSchedule(expression string) (*Schedule, error) {
+	expression = strings.TrimSpace(expression)
+	if macro, ok := macros[strings.ToLower(expression)]; ok {
+		expression = macro
+	}
+
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields; found %d", expression, len(fields))
+	}
+
+	schedule := &Schedule{}
+	parsed := []*uint64{&schedule.minute, &schedule.hour, &schedule.dayOfMonth, &schedule.month, &schedule.dayOfWeek}
+
+	for index, f := range []field{minuteField, hourField, dayOfMonthField, monthField, dayOfWeekField} {
+		bits, err := f.parse(fields[index])
+		if err != nil {
+			return nil, err
+		}
+
+		*parsed[index] = bits
+	}
+
+	// sunday is both 0 and 7
+	if schedule.dayOfWeek&(1<<7) != 0 {
+		schedule.dayOfWeek |= 1
+	}
+
+	schedule.dayOfMonthWildcard = isWildcard(fields[2])
+	schedule.dayOfWeekWildcard = isWildcard(fields[4])
+
+	return schedule, nil
+}
@@ -0,0 +1,101 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func (f field) parse(expression string) (uint64, error) {
+	bits := uint64(0)
+
+	for _, part := range strings.Split(expression, ",") {
+		start, end, step := f.min, f.max, uint(1)
+		rangeExpression := part
+
+		if index := strings.Index(part, "/"); index >= 0 {
+			value, err := strconv.ParseUint(part[index+1:], 10, 8)
+			if err != nil || value == 0 {
+				return 0, fmt.Errorf("%s step %q is invalid", f.name, part[index+1:])
+			}
+
+			step = uint(value)
+			rangeExpression = part[:index]
+		}
+
+		switch {
+		case rangeExpression == "*" || rangeExpression == "?":
+		case strings.Contains(rangeExpression, "-"):
+			bounds := strings.SplitN(rangeExpression, "-", 2)
+
+			var err error
+			if start, err = f.parseValue(bounds[0]); err != nil {
+				return 0, err
+			}
+
+			if end, err = f.parseValue(bounds[1]); err != nil {
+				return 0, err
+			}
+
+			if start > end {
+				return 0, fmt.Errorf("%s range %q is invalid", f.name, rangeExpression)
+			}
+		default:
+			value, err := f.parseValue(rangeExpression)
+			if err != nil {
+				return 0, err
+			}
+
+			// a single value with a step (like 5/15) runs up to the maximum
+			start, end = value, value
+			if step > 1 {
+				end = f.max
+			}
+		}
+
+		for value := start; value <= end; value += step {
+			bits |= 1 << value
+		}
+	}
+
+	return bits, nil
+}
+
+func (f field) parseValue(expression string) (uint, error) {
+	if value, ok := f.names[strings.ToLower(expression)]; ok {
+		return value, nil
+	}
+
+	value, err := strconv.ParseUint(expression, 10, 8)
+	if err != nil || uint(value) < f.min || uint(value) > f.max {
+		return 0, fmt.Errorf("%s %q must be between %d and %d", f.name, expression, f.min, f.max)
+	}
+
+	return uint(value), nil
+}
+
+func (s *Schedule) matchesDay(t time.Time) bool {
+	dayOfMonth := s.dayOfMonth&(1<<uint(t.Day())) != 0
+	dayOfWeek := s.dayOfWeek&(1<<uint(t.Weekday())) != 0
+
+	if s.dayOfMonthWildcard || s.dayOfWeekWildcard {
+		return dayOfMonth && dayOfWeek
+	}
+
+	return dayOfMonth || dayOfWeek
+}
+
+func isWildcard(expression string) bool {
+	return expression == "*" || expression == "?"
+}
+
+// getLater guards against time.Date going back in time when the time it builds
+// falls in a daylight saving time gap
+func getLater(current, next time.Time) time.Time {
+	if next.After(current) {
+		return next
+	}
+
+	return current.Add(time.Hour)
+}
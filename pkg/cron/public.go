@@ -0,0 +1,41 @@
+package cron
+
+import (
+	"time"
+)
+
+// Next returns the first activation of the schedule after the time, in the
+// location of the time; the zero time is returned when there is none
+func (s *Schedule) Next(t time.Time) time.Time {
+	location := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Year() + yearLimit
+
+	for t.Year() <= limit {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = getLater(t, time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, location))
+			continue
+		}
+
+		if !s.matchesDay(t) {
+			t = getLater(t, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, location))
+			continue
+		}
+
+		// hours are added rather than built with time.Date so that the
+		// hour skipped by daylight saving time doesn't loop back
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Add(time.Duration(60-t.Minute()) * time.Minute)
+			continue
+		}
+
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
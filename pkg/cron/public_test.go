@@ -0,0 +1,304 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s is not available: %v", name, err)
+	}
+
+	return location
+}
+
+func TestNewScheduleErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"30-10 * * * *",
+		"* * * foo *",
+		"* * * * monday",
+		"@every 5m",
+	}
+
+	for _, expression := range tests {
+		t.Run(expression, func(t *testing.T) {
+			if _, err := NewSchedule(expression); err == nil {
+				t.Errorf("expected an error for %q", expression)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	// 2021-01-01 is a friday
+	from := time.Date(2021, time.January, 1, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		expression string
+		expected   []time.Time
+	}{
+		{
+			name:       "every minute",
+			expression: "* * * * *",
+			expected: []time.Time{
+				time.Date(2021, time.January, 1, 10, 8, 0, 0, time.UTC),
+				time.Date(2021, time.January, 1, 10, 9, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:       "step",
+			expression: "*/15 * * * *",
+			expected: []time.Time{
+				time.Date(2021, time.January, 1, 10, 15, 0, 0, time.UTC),
+				time.Date(2021, time.January, 1, 10, 30, 0, 0, time.UTC),
+				time.Date(2021, time.January, 1, 10, 45, 0, 0, time.UTC),
+				time.Date(2021, time.January, 1, 11, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:       "step from a value runs up to the maximum",
+			expression: "5/20 * * * *",
+			expected: []time.Time{
+				time.Date(2021, time.January, 1, 10, 25, 0, 0, time.UTC),
+				time.Date(2021, time.January, 1, 10, 45, 0, 0, time.UTC),
+				time.Date(2021, time.January, 1, 11, 5, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:       "range with a step",
+			expression: "0 0-12/6 * * *",
+			expected: []time.Time{
+				time.Date(2021, time.January, 1, 12, 0, 0, 0, time.UTC),
+				time.Date(2021, time.January, 2, 0, 0, 0, 0, time.UTC),
+				time.Date(2021, time.January, 2, 6, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:       "lists",
+			expression: "0,30 8,17 * * *",
+			expected: []time.Time{
+				time.Date(2021, time.January, 1, 17, 0, 0, 0, time.UTC),
+				time.Date(2021, time.January, 1, 17, 30, 0, 0, time.UTC),
+				time.Date(2021, time.January, 2, 8, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:       "month and day names",
+			expression: "0 9 * FEB-mar mon-WED",
+			expected: []time.Time{
+				time.Date(2021, time.February, 1, 9, 0, 0, 0, time.UTC),
+				time.Date(2021, time.February, 2, 9, 0, 0, 0, time.UTC),
+				time.Date(2021, time.February, 3, 9, 0, 0, 0, time.UTC),
+				time.Date(2021, time.February, 8, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:       "sunday is 7 as well as 0",
+			expression: "0 0 * * 7",
+			expected: []time.Time{
+				time.Date(2021, time.January, 3, 0, 0, 0, 0, time.UTC),
+				time.Date(2021, time.January, 10, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:       "day of month and day of week are ored when both are restricted",
+			expression: "0 0 13 * 5",
+			expected: []time.Time{
+				time.Date(2021, time.January, 8, 0, 0, 0, 0, time.UTC),
+				time.Date(2021, time.January, 13, 0, 0, 0, 0, time.UTC),
+				time.Date(2021, time.January, 15, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:       "day of month with a wildcard day of week",
+			expression: "0 0 13 * *",
+			expected: []time.Time{
+				time.Date(2021, time.January, 13, 0, 0, 0, 0, time.UTC),
+				time.Date(2021, time.February, 13, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:       "day of week with a wildcard day of month",
+			expression: "0 0 ? * 5",
+			expected: []time.Time{
+				time.Date(2021, time.January, 8, 0, 0, 0, 0, time.UTC),
+				time.Date(2021, time.January, 15, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:       "day of month that not every month has",
+			expression: "0 0 31 * *",
+			expected: []time.Time{
+				time.Date(2021, time.January, 31, 0, 0, 0, 0, time.UTC),
+				time.Date(2021, time.March, 31, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:       "leap day",
+			expression: "0 0 29 2 *",
+			expected: []time.Time{
+				time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:       "yearly macro",
+			expression: "@yearly",
+			expected: []time.Time{
+				time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:       "monthly macro",
+			expression: "@monthly",
+			expected: []time.Time{
+				time.Date(2021, time.February, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:       "weekly macro",
+			expression: "@weekly",
+			expected: []time.Time{
+				time.Date(2021, time.January, 3, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:       "daily macro is case insensitive",
+			expression: " @DAILY ",
+			expected: []time.Time{
+				time.Date(2021, time.January, 2, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:       "hourly macro",
+			expression: "@hourly",
+			expected: []time.Time{
+				time.Date(2021, time.January, 1, 11, 0, 0, 0, time.UTC),
+				time.Date(2021, time.January, 1, 12, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:       "never",
+			expression: "0 0 30 2 *",
+			expected:   []time.Time{time.Time{}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schedule, err := NewSchedule(test.expression)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			next := from
+			for _, expected := range test.expected {
+				next = schedule.Next(next)
+				if !next.Equal(expected) {
+					t.Fatalf("expected %s, got %s", expected, next)
+				}
+			}
+		})
+	}
+}
+
+func TestNextDaylightSavingTime(t *testing.T) {
+	location := mustLoadLocation(t, "America/New_York")
+
+	// clocks go from 02:00 to 03:00 on 2021-03-14 and from 02:00 back to
+	// 01:00 on 2021-11-07
+	tests := []struct {
+		name       string
+		expression string
+		from       time.Time
+		expected   []time.Time
+	}{
+		{
+			name:       "the skipped hour doesn't run",
+			expression: "30 2 * * *",
+			from:       time.Date(2021, time.March, 13, 12, 0, 0, 0, location),
+			expected: []time.Time{
+				time.Date(2021, time.March, 15, 2, 30, 0, 0, location),
+			},
+		},
+		{
+			name:       "the hours around the skipped hour still run",
+			expression: "0 * * * *",
+			from:       time.Date(2021, time.March, 14, 0, 30, 0, 0, location),
+			expected: []time.Time{
+				time.Date(2021, time.March, 14, 1, 0, 0, 0, location),
+				time.Date(2021, time.March, 14, 3, 0, 0, 0, location),
+				time.Date(2021, time.March, 14, 4, 0, 0, 0, location),
+			},
+		},
+		{
+			name:       "the day after the skipped hour runs at midnight",
+			expression: "@daily",
+			from:       time.Date(2021, time.March, 13, 12, 0, 0, 0, location),
+			expected: []time.Time{
+				time.Date(2021, time.March, 14, 0, 0, 0, 0, location),
+				time.Date(2021, time.March, 15, 0, 0, 0, 0, location),
+			},
+		},
+		{
+			name:       "the repeated hour runs twice",
+			expression: "30 1 * * *",
+			from:       time.Date(2021, time.November, 7, 0, 0, 0, 0, location),
+			expected: []time.Time{
+				time.Date(2021, time.November, 7, 5, 30, 0, 0, time.UTC),
+				time.Date(2021, time.November, 7, 6, 30, 0, 0, time.UTC),
+				time.Date(2021, time.November, 8, 6, 30, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schedule, err := NewSchedule(test.expression)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			next := test.from
+			for _, expected := range test.expected {
+				next = schedule.Next(next)
+				if !next.Equal(expected) {
+					t.Fatalf("expected %s, got %s", expected.In(location), next)
+				}
+
+				if next.Location() != location {
+					t.Errorf("expected %s to be in %s", next, location)
+				}
+			}
+		})
+	}
+}
+
+func TestNextSkippedMidnight(t *testing.T) {
+	location := mustLoadLocation(t, "America/Sao_Paulo")
+
+	// clocks went from 00:00 to 01:00 on 2018-11-04, so the start of that day
+	// is built as 01:00 and the search must not go back in time
+	schedule, err := NewSchedule("0 * 4 11 *")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := time.Date(2018, time.November, 4, 1, 0, 0, 0, location)
+	if next := schedule.Next(time.Date(2018, time.November, 3, 12, 0, 0, 0, location)); !next.Equal(expected) {
+		t.Errorf("expected %s, got %s", expected, next)
+	}
+}
@@ -0,0 +1,62 @@
+package cron
+
+const (
+	// yearLimit is how many years ahead of a time its next activation is
+	// searched for; expressions like "0 0 30 2 *" never activate
+	yearLimit = 5
+)
+
+// Schedule is a parsed cron expression; every field is a bitset of the values
+// it matches
+type Schedule struct {
+	minute     uint64
+	hour       uint64
+	dayOfMonth uint64
+	month      uint64
+	dayOfWeek  uint64
+
+	// the day of the month and the day of the week match when either does,
+	// unless one of them is a wildcard
+	dayOfMonthWildcard bool
+	dayOfWeekWildcard  bool
+}
+
+type field struct {
+	name  string
+	min   uint
+	max   uint
+	names map[string]uint
+}
+
+var (
+	minuteField     = field{name: "minute", min: 0, max: 59}
+	hourField       = field{name: "hour", min: 0, max: 23}
+	dayOfMonthField = field{name: "day of month", min: 1, max: 31}
+	monthField      = field{
+		name: "month",
+		min:  1,
+		max:  12,
+		names: map[string]uint{
+			"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+			"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+		},
+	}
+	dayOfWeekField = field{
+		name: "day of week",
+		min:  0,
+		max:  7,
+		names: map[string]uint{
+			"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+		},
+	}
+
+	macros = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+)
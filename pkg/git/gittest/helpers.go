@@ -0,0 +1,30 @@
+// Package gittest creates git repos for the tests of the packages that talk
+// to git; it needs the git binary.
+package gittest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// NewRepo creates an empty bare repo along with a working copy that has it as
+// its origin; both are removed once the test finished
+func NewRepo(t *testing.T) *Repo {
+	dir, err := ioutil.TempDir("", "kubesmith-git-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+
+	repo := &Repo{t: t, Bare: filepath.Join(dir, "repo.git"), Work: filepath.Join(dir, "work")}
+	repo.Git(dir, "init", "--quiet", "--bare", repo.Bare)
+	repo.Git(dir, "init", "--quiet", repo.Work)
+	repo.Git(repo.Work, "remote", "add", "origin", repo.Bare)
+
+	return repo
+}
@@ -0,0 +1,70 @@
+package gittest
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Git runs git in the directory and returns its trimmed output; the test
+// fails when git does
+func (r *Repo) Git(dir string, args ...string) string {
+	args = append([]string{"-c", "user.name=kubesmith", "-c", "user.email=kubesmith@example.com", "-c", "init.defaultBranch=main"}, args...)
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, output)
+	}
+
+	return strings.TrimSpace(string(output))
+}
+
+// Commit commits the files (an empty content removes a file) to the branch
+// and pushes it; the commit is returned
+func (r *Repo) Commit(branch string, files map[string]string) string {
+	r.Git(r.Work, "checkout", "--quiet", "-B", branch)
+
+	for name, content := range files {
+		path := filepath.Join(r.Work, name)
+		if content == "" {
+			os.Remove(path)
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			r.t.Fatal(err)
+		}
+
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			r.t.Fatal(err)
+		}
+	}
+
+	r.Git(r.Work, "add", "--all")
+	r.Git(r.Work, "commit", "--quiet", "--allow-empty", "-m", "commit")
+	r.Git(r.Work, "push", "--quiet", "--force", "origin", branch)
+
+	return r.RevParse("HEAD")
+}
+
+// Tag tags the commit the working copy is at and pushes the tag
+func (r *Repo) Tag(name string, annotated bool) {
+	if annotated {
+		r.Git(r.Work, "tag", "-a", "-m", name, name)
+	} else {
+		r.Git(r.Work, "tag", name)
+	}
+
+	r.Git(r.Work, "push", "--quiet", "origin", name)
+}
+
+// RevParse returns the commit (or object) the revision of the working copy
+// points to
+func (r *Repo) RevParse(revision string) string {
+	return r.Git(r.Work, "rev-parse", revision)
+}
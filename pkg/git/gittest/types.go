@@ -0,0 +1,12 @@
+package gittest
+
+import "testing"
+
+// Repo is a bare repo that tests push to from a working copy; the bare repo
+// is what the code under test clones or fetches from
+type Repo struct {
+	Bare string
+	Work string
+
+	t *testing.T
+}
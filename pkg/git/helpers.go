@@ -0,0 +1,10 @@
+package git
+
+// NewGitClient creates a client for the repo at the url; the private key is
+// only used for ssh urls and may be empty for public repos
+func NewGitClient(url string, sshPrivateKey []byte) *GitClient {
+	return &GitClient{
+		url:           url,
+		sshPrivateKey: sshPrivateKey,
+	}
+}
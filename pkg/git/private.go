@@ -0,0 +1,100 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+func (g *GitClient) run(dir string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	if len(g.sshPrivateKey) > 0 {
+		keyPath, err := g.writePrivateKey()
+		if err != nil {
+			return nil, err
+		}
+
+		defer os.Remove(keyPath)
+
+		// like the clone repo job, hosts aren't verified
+		cmd.Env = append(cmd.Env, fmt.Sprintf(
+			"GIT_SSH_COMMAND=ssh -i %s -o IdentitiesOnly=yes -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null -o LogLevel=error",
+			keyPath,
+		))
+	}
+
+	stderr := bytes.Buffer{}
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, errors.Wrap(err, message)
+		}
+
+		return nil, err
+	}
+
+	return output, nil
+}
+
+func (g *GitClient) writePrivateKey() (string, error) {
+	file, err := ioutil.TempFile("", "kubesmith-ssh-")
+	if err != nil {
+		return "", errors.Wrap(err, "could not create the ssh key file")
+	}
+
+	defer file.Close()
+
+	if err := file.Chmod(0600); err != nil {
+		os.Remove(file.Name())
+		return "", errors.Wrap(err, "could not restrict the ssh key file")
+	}
+
+	if _, err := file.Write(g.sshPrivateKey); err != nil {
+		os.Remove(file.Name())
+		return "", errors.Wrap(err, "could not write the ssh key file")
+	}
+
+	return file.Name(), nil
+}
+
+// parseRefs parses the output of ls-remote; the peeled entries of annotated
+// tags (refs/tags/v1^{}) replace the commit of the tag object
+func parseRefs(output string) []Ref {
+	refs := []Ref{}
+	indexes := map[string]int{}
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+
+		commit, name := fields[0], fields[1]
+		if strings.HasSuffix(name, "^{}") {
+			name = strings.TrimSuffix(name, "^{}")
+			if index, ok := indexes[name]; ok {
+				refs[index].Commit = commit
+				continue
+			}
+		}
+
+		indexes[name] = len(refs)
+		refs = append(refs, Ref{Name: name, Commit: commit})
+	}
+
+	return refs
+}
@@ -0,0 +1,67 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRefs(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected []Ref
+	}{
+		{
+			name:     "no refs",
+			output:   "",
+			expected: []Ref{},
+		},
+		{
+			name: "branches and lightweight tags",
+			output: "1111111111111111111111111111111111111111\trefs/heads/main\n" +
+				"2222222222222222222222222222222222222222\trefs/heads/feature/login\n" +
+				"3333333333333333333333333333333333333333\trefs/tags/v1.0.0\n",
+			expected: []Ref{
+				{Name: "refs/heads/main", Commit: "1111111111111111111111111111111111111111"},
+				{Name: "refs/heads/feature/login", Commit: "2222222222222222222222222222222222222222"},
+				{Name: "refs/tags/v1.0.0", Commit: "3333333333333333333333333333333333333333"},
+			},
+		},
+		{
+			name: "annotated tags point to the commit they tag",
+			output: "1111111111111111111111111111111111111111\trefs/heads/main\n" +
+				"4444444444444444444444444444444444444444\trefs/tags/v2.0.0\n" +
+				"1111111111111111111111111111111111111111\trefs/tags/v2.0.0^{}\n" +
+				"3333333333333333333333333333333333333333\trefs/tags/v1.0.0\n",
+			expected: []Ref{
+				{Name: "refs/heads/main", Commit: "1111111111111111111111111111111111111111"},
+				{Name: "refs/tags/v2.0.0", Commit: "1111111111111111111111111111111111111111"},
+				{Name: "refs/tags/v1.0.0", Commit: "3333333333333333333333333333333333333333"},
+			},
+		},
+		{
+			name:   "peeled entries without their tag are kept",
+			output: "1111111111111111111111111111111111111111\trefs/tags/v3.0.0^{}\n",
+			expected: []Ref{
+				{Name: "refs/tags/v3.0.0", Commit: "1111111111111111111111111111111111111111"},
+			},
+		},
+		{
+			name: "malformed lines and windows line endings are skipped",
+			output: "warning: redirecting to https://example.com/repo.git/\r\n" +
+				"1111111111111111111111111111111111111111\trefs/heads/main\r\n" +
+				"\n",
+			expected: []Ref{
+				{Name: "refs/heads/main", Commit: "1111111111111111111111111111111111111111"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if refs := parseRefs(test.output); !reflect.DeepEqual(refs, test.expected) {
+				t.Errorf("expected refs %+v, got %+v", test.expected, refs)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package git
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// GetRefs lists the branches and tags of the repo; annotated tags point to
+// the commit they tag
+func (g *GitClient) GetRefs() ([]Ref, error) {
+	output, err := g.run("", "ls-remote", "--heads", "--tags", g.url)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list remote refs")
+	}
+
+	return parseRefs(string(output)), nil
+}
+
+// GetFile fetches the ref and returns the content of the file at the path
+// together with the commit the ref pointed to when it was fetched
+func (g *GitClient) GetFile(ref, path string) ([]byte, string, error) {
+	dir, err := ioutil.TempDir("", "kubesmith-git-")
+	if err != nil {
+		return nil, "", errors.Wrap(err, "could not create a temporary directory")
+	}
+
+	defer os.RemoveAll(dir)
+
+	if _, err := g.run(dir, "init", "--quiet"); err != nil {
+		return nil, "", errors.Wrap(err, "could not initialize a repo")
+	}
+
+	if _, err := g.run(dir, "fetch", "--quiet", "--depth", "1", "--no-tags", g.url, ref); err != nil {
+		return nil, "", errors.Wrapf(err, "could not fetch %s", ref)
+	}
+
+	output, err := g.run(dir, "rev-parse", "FETCH_HEAD^{commit}")
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "could not resolve %s", ref)
+	}
+
+	commit := strings.TrimSpace(string(output))
+	object := fmt.Sprintf("%s:%s", commit, strings.TrimPrefix(path, "./"))
+
+	if _, err := g.run(dir, "cat-file", "-e", object); err != nil {
+		return nil, commit, ErrFileNotFound
+	}
+
+	content, err := g.run(dir, "cat-file", "blob", object)
+	if err != nil {
+		return nil, commit, errors.Wrapf(err, "could not read %s", path)
+	}
+
+	return content, commit, nil
+}
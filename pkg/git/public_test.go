@@ -0,0 +1,144 @@
+package git
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/kubesmith/kubesmith/pkg/git/gittest"
+)
+
+func getRefsByName(refs []Ref) map[string]string {
+	byName := map[string]string{}
+	for _, ref := range refs {
+		byName[ref.Name] = ref.Commit
+	}
+
+	return byName
+}
+
+func TestGetRefs(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	first := repo.Commit("main", map[string]string{"README": "first"})
+	repo.Tag("v1.0.0", false)
+	repo.Tag("v1.1.0", true)
+	feature := repo.Commit("feature/login", map[string]string{"README": "feature"})
+
+	refs, err := NewGitClient(repo.Bare, nil).GetRefs()
+	if err != nil {
+		t.Fatalf("could not get refs: %v", err)
+	}
+
+	expected := map[string]string{
+		"refs/heads/main":          first,
+		"refs/heads/feature/login": feature,
+		"refs/tags/v1.0.0":         first,
+		"refs/tags/v1.1.0":         first,
+	}
+
+	byName := getRefsByName(refs)
+	if len(refs) != len(expected) {
+		t.Errorf("expected %d refs, got %+v", len(expected), refs)
+	}
+
+	for name, commit := range expected {
+		if byName[name] != commit {
+			t.Errorf("expected %s to point to %s, got %q", name, commit, byName[name])
+		}
+	}
+}
+
+func TestGetRefsMissingRepo(t *testing.T) {
+	repo := gittest.NewRepo(t)
+
+	if _, err := NewGitClient(filepath.Join(repo.Bare, "missing"), nil).GetRefs(); err == nil {
+		t.Error("expected an error for a repo that does not exist")
+	}
+}
+
+func TestGetFile(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	first := repo.Commit("main", map[string]string{".kubesmith.yml": "first", "ci/pipeline.yml": "nested"})
+	repo.Tag("v1.0.0", true)
+	second := repo.Commit("main", map[string]string{".kubesmith.yml": "second"})
+	feature := repo.Commit("feature", map[string]string{"other.yml": "feature"})
+
+	tests := []struct {
+		name    string
+		ref     string
+		path    string
+		content string
+		commit  string
+		err     error
+	}{
+		{
+			name:    "branch",
+			ref:     "refs/heads/main",
+			path:    ".kubesmith.yml",
+			content: "second",
+			commit:  second,
+		},
+		{
+			name:    "short branch name",
+			ref:     "main",
+			path:    ".kubesmith.yml",
+			content: "second",
+			commit:  second,
+		},
+		{
+			name:    "annotated tag resolves to the commit it tags",
+			ref:     "refs/tags/v1.0.0",
+			path:    ".kubesmith.yml",
+			content: "first",
+			commit:  first,
+		},
+		{
+			name:    "commit at the tip of a branch",
+			ref:     second,
+			path:    ".kubesmith.yml",
+			content: "second",
+			commit:  second,
+		},
+		{
+			name:    "nested path with a leading ./",
+			ref:     "refs/heads/main",
+			path:    "./ci/pipeline.yml",
+			content: "nested",
+			commit:  second,
+		},
+		{
+			name:   "missing file",
+			ref:    "refs/heads/feature",
+			path:   "missing.yml",
+			commit: feature,
+			err:    ErrFileNotFound,
+		},
+	}
+
+	client := NewGitClient(repo.Bare, nil)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			content, commit, err := client.GetFile(test.ref, test.path)
+			if err != test.err {
+				t.Fatalf("expected error %v, got %v", test.err, err)
+			}
+
+			if string(content) != test.content {
+				t.Errorf("expected content %q, got %q", test.content, content)
+			}
+
+			if commit != test.commit {
+				t.Errorf("expected commit %s, got %s", test.commit, commit)
+			}
+		})
+	}
+}
+
+func TestGetFileMissingRef(t *testing.T) {
+	repo := gittest.NewRepo(t)
+	repo.Commit("main", map[string]string{"README": "first"})
+
+	_, _, err := NewGitClient(repo.Bare, nil).GetFile("refs/heads/missing", "README")
+	if err == nil || err == ErrFileNotFound {
+		t.Errorf("expected a fetch error, got %v", err)
+	}
+}
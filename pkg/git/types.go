@@ -0,0 +1,26 @@
+package git
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	commandTimeout = time.Minute * 2
+)
+
+// ErrFileNotFound is returned when a file doesn't exist in a commit
+var ErrFileNotFound = errors.New("file does not exist in the commit")
+
+// GitClient runs git against a remote repo; it's a thin wrapper around the git
+// binary so that the usual transports (ssh, https and local paths) work
+type GitClient struct {
+	url           string
+	sshPrivateKey []byte
+}
+
+// Ref is a branch or tag of a remote repo and the commit it points to
+type Ref struct {
+	Name   string
+	Commit string
+}
@@ -0,0 +1,247 @@
+package install
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	timeType     = reflect.TypeOf(metav1.Time{})
+	durationType = reflect.TypeOf(metav1.Duration{})
+	metaType     = reflect.TypeOf(metav1.ObjectMeta{})
+)
+
+func getCustomResourceDefinition(kind, plural string, object interface{}) CustomResourceDefinition {
+	objectType := reflect.TypeOf(object)
+	if objectType.Kind() == reflect.Ptr {
+		objectType = objectType.Elem()
+	}
+
+	return CustomResourceDefinition{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "apiextensions.k8s.io/v1beta1",
+			Kind:       KindCustomResourceDefinition,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   fmt.Sprintf("%s.%s", plural, api.GroupName),
+			Labels: getLabels(),
+		},
+		Spec: CustomResourceDefinitionSpec{
+			Group:   api.GroupName,
+			Version: api.SchemeGroupVersion.Version,
+			Scope:   "Namespaced",
+			Names: CustomResourceDefinitionNames{
+				Plural:     plural,
+				Singular:   strings.ToLower(kind),
+				Kind:       kind,
+				ListKind:   fmt.Sprintf("%sList", kind),
+				Categories: []string{"all", "kubesmith"},
+			},
+			Validation: CustomResourceValidation{
+				OpenAPIV3Schema: getSchema(objectType),
+			},
+			Subresources: CustomResourceSubresources{
+				Status: &CustomResourceSubresourceStatus{},
+			},
+			AdditionalPrinterColumns: getPrinterColumns(kind),
+		},
+	}
+}
+
+// getSchema derives a structural schema from the json encoding of a type;
+// values that encode as null when they are empty (times, slices, maps and
+// pointers) are nullable since the forge writes them that way
+func getSchema(t reflect.Type) JSONSchemaProps {
+	switch t {
+	case timeType:
+		return JSONSchemaProps{Type: "string", Format: "date-time", Nullable: true}
+	case durationType:
+		return JSONSchemaProps{Type: "string"}
+	case metaType:
+		return JSONSchemaProps{Type: "object"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := getSchema(t.Elem())
+		schema.Nullable = true
+		return schema
+	case reflect.Bool:
+		return JSONSchemaProps{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Uint, reflect.Uint8, reflect.Uint16:
+		return JSONSchemaProps{Type: "integer"}
+	case reflect.Int32, reflect.Uint32:
+		return JSONSchemaProps{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return JSONSchemaProps{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return JSONSchemaProps{Type: "number"}
+	case reflect.String:
+		return JSONSchemaProps{Type: "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return JSONSchemaProps{Type: "string", Format: "byte", Nullable: true}
+		}
+
+		items := getSchema(t.Elem())
+		return JSONSchemaProps{Type: "array", Items: &items, Nullable: true}
+	case reflect.Map:
+		values := getSchema(t.Elem())
+		return JSONSchemaProps{Type: "object", AdditionalProperties: &values, Nullable: true}
+	case reflect.Struct:
+		schema := JSONSchemaProps{Type: "object", Properties: map[string]JSONSchemaProps{}}
+		addStructProperties(t, schema.Properties)
+		return schema
+	}
+
+	return JSONSchemaProps{Type: "object", PreserveUnknown: true}
+}
+
+func addStructProperties(t reflect.Type, properties map[string]JSONSchemaProps) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		name, inline := getJSONName(field)
+		if name == "-" {
+			continue
+		}
+
+		// embedded structs without a json name are encoded in their parent
+		if inline {
+			addStructProperties(field.Type, properties)
+			continue
+		}
+
+		properties[name] = getSchema(field.Type)
+	}
+}
+
+func getJSONName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	name := strings.Split(tag, ",")[0]
+
+	if name == "" && field.Anonymous && field.Type.Kind() == reflect.Struct {
+		return "", true
+	} else if name == "" {
+		return field.Name, false
+	}
+
+	return name, false
+}
+
+func getPrinterColumns(kind string) []CustomResourceColumnDefinition {
+	columns := []CustomResourceColumnDefinition{}
+
+	switch kind {
+	case "Forge":
+		columns = append(
+			columns,
+			CustomResourceColumnDefinition{Name: "Repo", Type: "string", Description: "The repo polled by the forge", JSONPath: ".spec.repo.url"},
+			CustomResourceColumnDefinition{Name: "Last Polled", Type: "date", Description: "When the forge last polled its repo", JSONPath: ".status.lastPolledTime"},
+			CustomResourceColumnDefinition{Name: "Reason", Type: "string", Description: "The reason the forge could not poll its repo", JSONPath: ".status.failureReason", Priority: 1},
+		)
+	case "Pipeline":
+		columns = append(
+			columns,
+			CustomResourceColumnDefinition{Name: "Phase", Type: "string", Description: "The current phase of the pipeline", JSONPath: ".status.phase"},
+			CustomResourceColumnDefinition{Name: "Stage", Type: "integer", Description: "The current stage of the pipeline", JSONPath: ".status.stageIndex"},
+			CustomResourceColumnDefinition{Name: "Attempt", Type: "integer", Description: "The attempt of the pipeline", JSONPath: ".status.attempt", Priority: 1},
+			CustomResourceColumnDefinition{Name: "Reason", Type: "string", Description: "The reason the pipeline failed", JSONPath: ".status.failureReason"},
+			CustomResourceColumnDefinition{Name: "Tests", Type: "string", Description: "The summary of the test reports of the pipeline", JSONPath: ".status.testReport.summary"},
+		)
+	case "PipelineStage":
+		columns = append(
+			columns,
+			CustomResourceColumnDefinition{Name: "Phase", Type: "string", Description: "The current phase of the pipeline stage", JSONPath: ".status.phase"},
+			CustomResourceColumnDefinition{Name: "Reason", Type: "string", Description: "The reason the pipeline stage failed", JSONPath: ".status.failureReason", Priority: 1},
+		)
+	case "PipelineJob":
+		columns = append(
+			columns,
+			CustomResourceColumnDefinition{Name: "Phase", Type: "string", Description: "The current phase of the pipeline job", JSONPath: ".status.phase"},
+			CustomResourceColumnDefinition{Name: "Attempt", Type: "integer", Description: "The attempt of the pipeline job", JSONPath: ".status.attempt", Priority: 1},
+			CustomResourceColumnDefinition{Name: "Reason", Type: "string", Description: "The reason the pipeline job failed", JSONPath: ".status.failureReason"},
+			CustomResourceColumnDefinition{Name: "Tests", Type: "string", Description: "The summary of the test reports of the pipeline job", JSONPath: ".status.testReport.summary"},
+		)
+	}
+
+	// kubectl only shows the age on its own when there are no custom columns
+	return append(columns, CustomResourceColumnDefinition{
+		Name:     "Age",
+		Type:     "date",
+		JSONPath: ".metadata.creationTimestamp",
+	})
+}
+
+func getLabels() map[string]string {
+	return map[string]string{
+		"app": "kubesmith",
+	}
+}
+
+func getForgeLabels() map[string]string {
+	labels := getLabels()
+	labels["component"] = "forge"
+
+	return labels
+}
+
+func getForgeArgs(config Config) []string {
+	args := []string{
+		"forge",
+		"server",
+		"--max-running-pipelines", strconv.Itoa(config.MaxRunningPipelines),
+		"--max-running-pipeline-jobs", strconv.Itoa(config.MaxRunningPipelineJobs),
+	}
+
+	if config.RetentionKeepLast > 0 {
+		args = append(args, "--retention-keep-last", strconv.Itoa(config.RetentionKeepLast))
+	}
+
+	if config.RetentionTTL > 0 {
+		args = append(args, "--retention-ttl", config.RetentionTTL.String())
+	}
+
+	if config.RetentionSucceededTTL > 0 {
+		args = append(args, "--retention-succeeded-ttl", config.RetentionSucceededTTL.String())
+	}
+
+	if config.RetentionFailedTTL > 0 {
+		args = append(args, "--retention-failed-ttl", config.RetentionFailedTTL.String())
+	}
+
+	return append(args, config.ExtraArgs...)
+}
+
+func getResourcePath(collectionPath, name string) string {
+	return fmt.Sprintf("%s/%s", collectionPath, name)
+}
+
+// getObjectBody encodes the object for the api server; the resource version
+// is only set when an existing object is replaced
+func getObjectBody(object metav1.Object, resourceVersion string) ([]byte, error) {
+	object.SetResourceVersion(resourceVersion)
+	defer object.SetResourceVersion("")
+
+	return json.Marshal(object)
+}
+
+func getSortedCustomResourceKinds() []string {
+	kinds := []string{}
+	for kind := range api.CustomResources() {
+		kinds = append(kinds, kind)
+	}
+
+	sort.Strings(kinds)
+	return kinds
+}
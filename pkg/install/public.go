@@ -0,0 +1,356 @@
+package install
+
+import (
+	"encoding/json"
+	"fmt"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/utils"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/rest"
+)
+
+// GetCustomResourceDefinitions returns the crds of the kubesmith api group;
+// their schemas are derived from the go types so they can't drift from them
+func GetCustomResourceDefinitions() []CustomResourceDefinition {
+	definitions := []CustomResourceDefinition{}
+	resources := api.CustomResources()
+
+	for _, kind := range getSortedCustomResourceKinds() {
+		resource := resources[kind]
+		definitions = append(definitions, getCustomResourceDefinition(kind, resource.PluralName, resource.ItemType))
+	}
+
+	return definitions
+}
+
+// GetResources returns everything that is installed, in the order it has to
+// be created in
+func GetResources(config Config) []Resource {
+	resources := []Resource{}
+
+	for _, definition := range GetCustomResourceDefinitions() {
+		definition := definition
+		resources = append(resources, Resource{
+			Kind:   KindCustomResourceDefinition,
+			Path:   "/apis/apiextensions.k8s.io/v1beta1/customresourcedefinitions",
+			Object: &definition,
+		})
+	}
+
+	return append(
+		resources,
+		Resource{Kind: KindNamespace, Path: "/api/v1/namespaces", Object: GetNamespace(config)},
+		Resource{Kind: KindServiceAccount, Path: fmt.Sprintf("/api/v1/namespaces/%s/serviceaccounts", config.Namespace), Object: GetServiceAccount(config)},
+		Resource{Kind: KindClusterRole, Path: "/apis/rbac.authorization.k8s.io/v1/clusterroles", Object: GetClusterRole(config)},
+		Resource{Kind: KindClusterRoleBinding, Path: "/apis/rbac.authorization.k8s.io/v1/clusterrolebindings", Object: GetClusterRoleBinding(config)},
+		Resource{Kind: KindRole, Path: fmt.Sprintf("/apis/rbac.authorization.k8s.io/v1/namespaces/%s/roles", config.Namespace), Object: GetRole(config)},
+		Resource{Kind: KindRoleBinding, Path: fmt.Sprintf("/apis/rbac.authorization.k8s.io/v1/namespaces/%s/rolebindings", config.Namespace), Object: GetRoleBinding(config)},
+		Resource{Kind: KindDeployment, Path: fmt.Sprintf("/apis/apps/v1/namespaces/%s/deployments", config.Namespace), Object: GetDeployment(config)},
+		Resource{Kind: KindService, Path: fmt.Sprintf("/api/v1/namespaces/%s/services", config.Namespace), Object: GetService(config)},
+	)
+}
+
+func GetNamespace(config Config) *corev1.Namespace {
+	return &corev1.Namespace{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: KindNamespace},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   config.Namespace,
+			Labels: getLabels(),
+		},
+	}
+}
+
+func GetServiceAccount(config Config) *corev1.ServiceAccount {
+	return &corev1.ServiceAccount{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: KindServiceAccount},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      forgeName,
+			Namespace: config.Namespace,
+			Labels:    getLabels(),
+		},
+	}
+}
+
+// GetClusterRole only grants what the forge needs outside of its namespace;
+// it checks that its namespace exists when it starts
+func GetClusterRole(config Config) *rbacv1.ClusterRole {
+	return &rbacv1.ClusterRole{
+		TypeMeta: metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: KindClusterRole},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   fmt.Sprintf("%s-%s", forgeName, config.Namespace),
+			Labels: getLabels(),
+		},
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups:     []string{""},
+				Resources:     []string{"namespaces"},
+				ResourceNames: []string{config.Namespace},
+				Verbs:         []string{"get"},
+			},
+		},
+	}
+}
+
+func GetClusterRoleBinding(config Config) *rbacv1.ClusterRoleBinding {
+	name := fmt.Sprintf("%s-%s", forgeName, config.Namespace)
+
+	return &rbacv1.ClusterRoleBinding{
+		TypeMeta: metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: KindClusterRoleBinding},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: getLabels(),
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      forgeName,
+				Namespace: config.Namespace,
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     KindClusterRole,
+			Name:     name,
+		},
+	}
+}
+
+// GetRole grants the forge what its controllers read, create and clean up in
+// its namespace. The role it creates for every pipeline can only be bound when
+// the forge holds the same permissions (pods list and pods/log get).
+func GetRole(config Config) *rbacv1.Role {
+	readVerbs := []string{"get", "list", "watch"}
+	manageVerbs := []string{"get", "list", "watch", "create", "delete"}
+
+	return &rbacv1.Role{
+		TypeMeta: metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: KindRole},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      forgeName,
+			Namespace: config.Namespace,
+			Labels:    getLabels(),
+		},
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{api.GroupName},
+				Resources: []string{"forges", "pipelines", "pipelinestages", "pipelinejobs"},
+				Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+			},
+			{
+				APIGroups: []string{api.GroupName},
+				Resources: []string{"forges/status", "pipelines/status", "pipelinestages/status", "pipelinejobs/status"},
+				Verbs:     []string{"get", "update", "patch"},
+			},
+			{
+				APIGroups: []string{""},
+				Resources: []string{"pods"},
+				Verbs:     readVerbs,
+			},
+			{
+				APIGroups: []string{""},
+				Resources: []string{"pods/log"},
+				Verbs:     []string{"get"},
+			},
+			{
+				APIGroups: []string{""},
+				Resources: []string{"configmaps", "secrets", "services", "serviceaccounts"},
+				Verbs:     manageVerbs,
+			},
+			{
+				APIGroups: []string{"apps"},
+				Resources: []string{"deployments"},
+				Verbs:     manageVerbs,
+			},
+			{
+				APIGroups: []string{"batch"},
+				Resources: []string{"jobs"},
+				Verbs:     manageVerbs,
+			},
+			{
+				APIGroups: []string{rbacv1.GroupName},
+				Resources: []string{"roles", "rolebindings"},
+				Verbs:     manageVerbs,
+			},
+		},
+	}
+}
+
+func GetRoleBinding(config Config) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		TypeMeta: metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: KindRoleBinding},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      forgeName,
+			Namespace: config.Namespace,
+			Labels:    getLabels(),
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      forgeName,
+				Namespace: config.Namespace,
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     KindRole,
+			Name:     forgeName,
+		},
+	}
+}
+
+func GetDeployment(config Config) *appsv1.Deployment {
+	labels := getForgeLabels()
+	port := intstr.FromString("http")
+
+	return &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: KindDeployment},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      forgeName,
+			Namespace: config.Namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			// the forge doesn't elect a leader, so only one may run at a time
+			Replicas: utils.Int32Ptr(1),
+			Strategy: appsv1.DeploymentStrategy{
+				Type: appsv1.RecreateDeploymentStrategyType,
+			},
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: forgeName,
+					Containers: []corev1.Container{
+						{
+							Name:            forgeName,
+							Image:           config.Image,
+							ImagePullPolicy: config.ImagePullPolicy,
+							Command:         []string{"kubesmith"},
+							Args:            getForgeArgs(config),
+							Ports: []corev1.ContainerPort{
+								{
+									Name:          "http",
+									ContainerPort: forgePort,
+								},
+							},
+							LivenessProbe: &corev1.Probe{
+								Handler: corev1.Handler{
+									HTTPGet: &corev1.HTTPGetAction{Path: "/healthz", Port: port},
+								},
+								InitialDelaySeconds: 10,
+								PeriodSeconds:       10,
+							},
+							ReadinessProbe: &corev1.Probe{
+								Handler: corev1.Handler{
+									HTTPGet: &corev1.HTTPGetAction{Path: "/readyz", Port: port},
+								},
+								PeriodSeconds: 10,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// GetService exposes the webhooks (/webhooks/<forge>) of the forge; it's up to
+// the cluster to route the git servers to it (with an ingress, for example)
+func GetService(config Config) *corev1.Service {
+	return &corev1.Service{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: KindService},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      forgeName,
+			Namespace: config.Namespace,
+			Labels:    getForgeLabels(),
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: getForgeLabels(),
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "http",
+					Port:       forgePort,
+					TargetPort: intstr.FromString("http"),
+				},
+			},
+		},
+	}
+}
+
+// Apply creates the resource or replaces the existing one; existing
+// namespaces are left as they are
+func Apply(client rest.Interface, resource Resource) (string, error) {
+	body, err := getObjectBody(resource.Object, "")
+	if err != nil {
+		return "", err
+	}
+
+	err = client.Post().AbsPath(resource.Path).Body(body).Do().Error()
+	if err == nil {
+		return ActionCreated, nil
+	} else if !apierrors.IsAlreadyExists(err) {
+		return "", errors.Wrapf(err, "could not create %s %s", resource.Kind, resource.Object.GetName())
+	} else if resource.Kind == KindNamespace {
+		return ActionUnchanged, nil
+	}
+
+	path := getResourcePath(resource.Path, resource.Object.GetName())
+	data, err := client.Get().AbsPath(path).Do().Raw()
+	if err != nil {
+		return "", errors.Wrapf(err, "could not get %s %s", resource.Kind, resource.Object.GetName())
+	}
+
+	existing := struct {
+		Metadata metav1.ObjectMeta `json:"metadata"`
+	}{}
+
+	if err := json.Unmarshal(data, &existing); err != nil {
+		return "", errors.Wrapf(err, "could not decode %s %s", resource.Kind, resource.Object.GetName())
+	}
+
+	body, err = getObjectBody(resource.Object, existing.Metadata.GetResourceVersion())
+	if err != nil {
+		return "", err
+	}
+
+	if err := client.Put().AbsPath(path).Body(body).Do().Error(); err != nil {
+		return "", errors.Wrapf(err, "could not update %s %s", resource.Kind, resource.Object.GetName())
+	}
+
+	return ActionUpdated, nil
+}
+
+// Delete removes the resource; dependents (like the pods of the forge and the
+// objects of a crd) are deleted in the background
+func Delete(client rest.Interface, resource Resource) (string, error) {
+	propagationPolicy := metav1.DeletePropagationBackground
+	body, err := json.Marshal(metav1.DeleteOptions{
+		TypeMeta:          metav1.TypeMeta{APIVersion: "v1", Kind: "DeleteOptions"},
+		PropagationPolicy: &propagationPolicy,
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	path := getResourcePath(resource.Path, resource.Object.GetName())
+	if err := client.Delete().AbsPath(path).Body(body).Do().Error(); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ActionNotFound, nil
+		}
+
+		return "", errors.Wrapf(err, "could not delete %s %s", resource.Kind, resource.Object.GetName())
+	}
+
+	return ActionDeleted, nil
+}
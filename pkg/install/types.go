@@ -0,0 +1,109 @@
+package install
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ActionCreated   = "created"
+	ActionUpdated   = "configured"
+	ActionUnchanged = "unchanged"
+	ActionDeleted   = "deleted"
+	ActionNotFound  = "not found"
+
+	KindCustomResourceDefinition = "CustomResourceDefinition"
+	KindNamespace                = "Namespace"
+	KindServiceAccount           = "ServiceAccount"
+	KindClusterRole              = "ClusterRole"
+	KindClusterRoleBinding       = "ClusterRoleBinding"
+	KindRole                     = "Role"
+	KindRoleBinding              = "RoleBinding"
+	KindDeployment               = "Deployment"
+	KindService                  = "Service"
+
+	forgeName = "kubesmith-forge"
+	forgePort = 8080
+)
+
+// Config describes the forge that is installed
+type Config struct {
+	Namespace              string
+	Image                  string
+	ImagePullPolicy        corev1.PullPolicy
+	MaxRunningPipelines    int
+	MaxRunningPipelineJobs int
+	RetentionKeepLast      int
+	RetentionTTL           time.Duration
+	RetentionSucceededTTL  time.Duration
+	RetentionFailedTTL     time.Duration
+	ExtraArgs              []string
+}
+
+// Resource is an object that is installed, together with the api path of the
+// collection it's created in
+type Resource struct {
+	Kind   string
+	Path   string
+	Object metav1.Object
+}
+
+// The apiextensions types aren't vendored; these mirror the parts of
+// apiextensions.k8s.io/v1beta1 that are needed to install the crds
+
+type CustomResourceDefinition struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec CustomResourceDefinitionSpec `json:"spec"`
+}
+
+type CustomResourceDefinitionSpec struct {
+	Group                    string                           `json:"group"`
+	Version                  string                           `json:"version"`
+	Scope                    string                           `json:"scope"`
+	Names                    CustomResourceDefinitionNames    `json:"names"`
+	Validation               CustomResourceValidation         `json:"validation"`
+	Subresources             CustomResourceSubresources       `json:"subresources"`
+	AdditionalPrinterColumns []CustomResourceColumnDefinition `json:"additionalPrinterColumns,omitempty"`
+	PreserveUnknownFields    bool                             `json:"preserveUnknownFields"`
+}
+
+type CustomResourceDefinitionNames struct {
+	Plural     string   `json:"plural"`
+	Singular   string   `json:"singular"`
+	Kind       string   `json:"kind"`
+	ListKind   string   `json:"listKind"`
+	Categories []string `json:"categories,omitempty"`
+}
+
+type CustomResourceValidation struct {
+	OpenAPIV3Schema JSONSchemaProps `json:"openAPIV3Schema"`
+}
+
+type CustomResourceSubresources struct {
+	Status *CustomResourceSubresourceStatus `json:"status,omitempty"`
+}
+
+type CustomResourceSubresourceStatus struct{}
+
+type CustomResourceColumnDefinition struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+	Priority    int32  `json:"priority,omitempty"`
+	JSONPath    string `json:"JSONPath"`
+}
+
+type JSONSchemaProps struct {
+	Type                 string                     `json:"type,omitempty"`
+	Format               string                     `json:"format,omitempty"`
+	Description          string                     `json:"description,omitempty"`
+	Nullable             bool                       `json:"nullable,omitempty"`
+	Properties           map[string]JSONSchemaProps `json:"properties,omitempty"`
+	Items                *JSONSchemaProps           `json:"items,omitempty"`
+	AdditionalProperties *JSONSchemaProps           `json:"additionalProperties,omitempty"`
+	PreserveUnknown      bool                       `json:"x-kubernetes-preserve-unknown-fields,omitempty"`
+}
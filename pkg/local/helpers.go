@@ -0,0 +1,22 @@
+package local
+
+import (
+	"io"
+	gosync "sync"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+)
+
+// NewExecutor creates an executor that runs the pipeline with the contents of
+// sourcePath as its repo and workPath as the directory holding the workspaces
+// and artifacts of its jobs
+func NewExecutor(pipeline api.Pipeline, sourcePath, workPath string, parallel bool, out io.Writer) *Executor {
+	return &Executor{
+		pipeline:   *pipeline.DeepCopy(),
+		sourcePath: sourcePath,
+		workPath:   workPath,
+		parallel:   parallel,
+		out:        out,
+		outLock:    &gosync.Mutex{},
+	}
+}
@@ -0,0 +1,303 @@
+package local
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	gosync "sync"
+	"time"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/cmd/util/archive"
+	"github.com/kubesmith/kubesmith/pkg/cmd/util/artifacts"
+	"github.com/kubesmith/kubesmith/pkg/cmd/util/junit"
+	"github.com/kubesmith/kubesmith/pkg/cmd/util/prefix"
+	"github.com/pkg/errors"
+)
+
+// archiveSource archives the source directory the same way the clone repo job
+// archives a freshly cloned repo so that every job starts from a pristine copy
+func (e *Executor) archiveSource() error {
+	sourcePath, err := filepath.Abs(e.sourcePath)
+	if err != nil {
+		return errors.Wrapf(err, "could not resolve source directory %s", e.sourcePath)
+	}
+
+	workPath, err := filepath.Abs(e.workPath)
+	if err != nil {
+		return errors.Wrapf(err, "could not resolve work directory %s", e.workPath)
+	}
+
+	entries, err := ioutil.ReadDir(sourcePath)
+	if err != nil {
+		return errors.Wrapf(err, "could not read source directory %s", sourcePath)
+	}
+
+	files := []string{}
+	for _, entry := range entries {
+		path := filepath.Join(sourcePath, entry.Name())
+
+		if path == workPath {
+			continue
+		} else if strings.HasPrefix(workPath, path+string(os.PathSeparator)) {
+			return fmt.Errorf("the work directory %s must not be nested inside the source directory", workPath)
+		}
+
+		files = append(files, path)
+	}
+
+	if len(files) == 0 {
+		return nil
+	}
+
+	if err := archive.CreateArchive(e.getRepoArchivePath(), files); err != nil {
+		return errors.Wrap(err, "could not archive the source directory")
+	}
+
+	return nil
+}
+
+func (e *Executor) getRepoArchivePath() string {
+	return filepath.Join(e.workPath, repoArchiveFileName)
+}
+
+func (e *Executor) runStage(stageIndex int, specs []api.PipelineJobSpecJob) ([]JobResult, error) {
+	jobs := []localJob{}
+	for index, spec := range specs {
+		path := filepath.Join(e.workPath, "jobs", fmt.Sprintf("stage-%d", stageIndex), fmt.Sprintf("job-%d", index+1))
+
+		jobs = append(jobs, localJob{
+			index:     index + 1,
+			stage:     e.pipeline.GetStageNameByIndex(stageIndex),
+			spec:      spec,
+			path:      path,
+			workspace: filepath.Join(path, "workspace"),
+		})
+	}
+
+	for _, job := range jobs {
+		if err := e.prepareWorkspace(stageIndex, job); err != nil {
+			return nil, err
+		}
+	}
+
+	results := make([]JobResult, len(jobs))
+	if !e.parallel {
+		for index, job := range jobs {
+			results[index] = e.runJob(stageIndex, job)
+		}
+
+		return results, nil
+	}
+
+	wg := gosync.WaitGroup{}
+	for index, job := range jobs {
+		wg.Add(1)
+
+		go func(index int, job localJob) {
+			defer wg.Done()
+			results[index] = e.runJob(stageIndex, job)
+		}(index, job)
+	}
+
+	wg.Wait()
+	return results, nil
+}
+
+// prepareWorkspace fills the workspace of the job with the repo and the
+// artifacts of the previous stage
+func (e *Executor) prepareWorkspace(stageIndex int, job localJob) error {
+	if err := os.RemoveAll(job.path); err != nil {
+		return errors.Wrapf(err, "could not clean up %s", job.path)
+	}
+
+	if err := os.MkdirAll(job.workspace, 0755); err != nil {
+		return errors.Wrapf(err, "could not create workspace %s", job.workspace)
+	}
+
+	archives := []string{}
+	if _, err := os.Stat(e.getRepoArchivePath()); err == nil {
+		archives = append(archives, e.getRepoArchivePath())
+	}
+
+	previousArtifacts, err := filepath.Glob(filepath.Join(e.GetArtifactsPath(stageIndex-1), "*.tar.gz"))
+	if err != nil {
+		return errors.Wrap(err, "could not find the artifacts of the previous stage")
+	}
+
+	sort.Strings(previousArtifacts)
+	archives = append(archives, previousArtifacts...)
+
+	for _, archivePath := range archives {
+		if err := archive.ExtractArchive(archivePath, job.workspace); err != nil {
+			return errors.Wrapf(err, "could not extract %s into %s", archivePath, job.workspace)
+		}
+	}
+
+	return nil
+}
+
+func (e *Executor) runJob(stageIndex int, job localJob) JobResult {
+	result := JobResult{
+		Stage:        job.stage,
+		Name:         job.spec.Name,
+		AllowFailure: job.spec.AllowFailure,
+	}
+
+	e.printf("job %s: running in %s\n", job.spec.Name, job.workspace)
+	start := time.Now()
+
+	if err := e.executeJob(job); err != nil {
+		result.Phase = api.PhaseFailed
+		result.FailureReason = err.Error()
+	} else {
+		result.Phase = api.PhaseSucceeded
+	}
+
+	result.Duration = time.Since(start).Round(time.Millisecond)
+	e.collectTestReport(job)
+
+	jobArtifacts := job.spec.Artifacts.OnSuccess
+	if result.Phase == api.PhaseFailed {
+		jobArtifacts = job.spec.Artifacts.OnFail
+	}
+
+	if err := e.collectArtifacts(stageIndex, job, jobArtifacts); err != nil {
+		e.printf("job %s: %v\n", job.spec.Name, err)
+	}
+
+	if result.Phase == api.PhaseFailed {
+		e.printf("job %s: failed (%s): %s\n", job.spec.Name, result.Duration, result.FailureReason)
+	} else {
+		e.printf("job %s: succeeded (%s)\n", job.spec.Name, result.Duration)
+	}
+
+	return result
+}
+
+func (e *Executor) executeJob(job localJob) error {
+	args := append([]string{}, job.spec.Command...)
+	args = append(args, job.spec.Args...)
+
+	if len(job.spec.Runner) > 0 {
+		scriptPath := filepath.Join(job.path, "scripts", runnerScriptName)
+		if err := os.MkdirAll(filepath.Dir(scriptPath), 0755); err != nil {
+			return errors.Wrap(err, "could not create scripts directory")
+		}
+
+		if err := ioutil.WriteFile(scriptPath, []byte(strings.Join(job.spec.Runner, "\n")), 0755); err != nil {
+			return errors.Wrap(err, "could not write runner script")
+		}
+
+		args = []string{"/bin/sh", "-x", scriptPath}
+	}
+
+	if len(args) == 0 {
+		return errors.New("job has no runner or command to run locally")
+	}
+
+	stdout := prefix.NewWriter(fmt.Sprintf("[%s] ", job.spec.Name), e.out, e.outLock)
+	defer stdout.Flush()
+
+	command := exec.Command(args[0], args[1:]...)
+	command.Dir = job.workspace
+	command.Env = append(os.Environ(), getEnvironment(job.spec.Environment)...)
+	command.Stdout = stdout
+	command.Stderr = stdout
+
+	if err := command.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return fmt.Errorf("exited with code %d", exitErr.ExitCode())
+		}
+
+		return errors.Wrapf(err, "could not run %s", args[0])
+	}
+
+	return nil
+}
+
+// collectArtifacts archives the artifacts of the job where the jobs of the
+// next stage pick them up
+func (e *Executor) collectArtifacts(stageIndex int, job localJob, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	patterns := []string{}
+	for _, path := range paths {
+		patterns = append(patterns, filepath.Join(job.workspace, path))
+	}
+
+	detected := artifacts.DetectFromCSV(strings.Join(patterns, ","))
+	if len(detected) == 0 {
+		return errors.New("no artifacts were detected")
+	}
+
+	artifactsPath := e.GetArtifactsPath(stageIndex)
+	if err := os.MkdirAll(artifactsPath, 0755); err != nil {
+		return errors.Wrapf(err, "could not create artifacts directory %s", artifactsPath)
+	}
+
+	archivePath := filepath.Join(artifactsPath, fmt.Sprintf("job-%d.tar.gz", job.index))
+	if err := archive.CreateArchive(archivePath, detected); err != nil {
+		return errors.Wrapf(err, "could not create artifact archive at %s", archivePath)
+	}
+
+	return nil
+}
+
+func (e *Executor) collectTestReport(job localJob) {
+	if len(job.spec.Artifacts.Reports.JUnit) == 0 {
+		return
+	}
+
+	patterns := []string{}
+	for _, path := range job.spec.Artifacts.Reports.JUnit {
+		patterns = append(patterns, filepath.Join(job.workspace, path))
+	}
+
+	detected := artifacts.DetectFromCSV(strings.Join(patterns, ","))
+	if len(detected) == 0 {
+		e.printf("job %s: no junit reports were detected\n", job.spec.Name)
+		return
+	}
+
+	report, err := junit.ParseFiles(detected)
+	if err != nil {
+		e.printf("job %s: could not parse junit reports: %v\n", job.spec.Name, err)
+		return
+	}
+
+	e.printf("job %s: tests: %s\n", job.spec.Name, report.GetSummary())
+}
+
+func (e *Executor) printf(format string, args ...interface{}) {
+	e.outLock.Lock()
+	defer e.outLock.Unlock()
+
+	fmt.Fprintf(e.out, format, args...)
+}
+
+func getEnvironment(environment map[string]string) []string {
+	env := []string{}
+	for key, value := range environment {
+		env = append(env, fmt.Sprintf("%s=%s", key, value))
+	}
+
+	sort.Strings(env)
+	return env
+}
+
+func getFailedJob(results []JobResult) *JobResult {
+	for index, result := range results {
+		if result.Phase == api.PhaseFailed && !result.AllowFailure {
+			return &results[index]
+		}
+	}
+
+	return nil
+}
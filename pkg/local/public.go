@@ -0,0 +1,69 @@
+package local
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/pkg/errors"
+)
+
+// Run executes the stages of the pipeline in order, stopping at the first
+// stage that fails after running the finally stage; the returned error is
+// only set when the pipeline could not be executed at all
+func (e *Executor) Run() (Result, error) {
+	result := Result{Jobs: []JobResult{}}
+
+	if err := os.MkdirAll(e.workPath, 0755); err != nil {
+		return result, errors.Wrapf(err, "could not create work directory %s", e.workPath)
+	}
+
+	e.printf("copying %s to the workspace\n", e.sourcePath)
+	if err := e.archiveSource(); err != nil {
+		return result, err
+	}
+
+	// the stage index drives the execution just like it does in a cluster
+	e.pipeline.SetPhaseToRunning()
+	for e.pipeline.IsRunning() {
+		stage := e.pipeline.GetCurrentStageName()
+		e.printf("stage %s: running\n", stage)
+
+		stageResults, err := e.runStage(e.pipeline.Status.StageIndex, e.pipeline.GetExpandedJobsForCurrentStage())
+		if err != nil {
+			return result, err
+		}
+
+		result.Jobs = append(result.Jobs, stageResults...)
+		if failed := getFailedJob(stageResults); failed != nil {
+			e.printf("stage %s: failed\n", stage)
+			e.pipeline.SetPhaseToFailedAtStage(fmt.Sprintf("stage %q failed: job %q failed: %s", stage, failed.Name, failed.FailureReason))
+			continue
+		}
+
+		e.printf("stage %s: succeeded\n", stage)
+		e.pipeline.AdvanceCurrentStage()
+	}
+
+	result.Phase = e.pipeline.Status.Phase
+	result.FailureReason = e.pipeline.Status.FailureReason
+
+	return result, nil
+}
+
+// GetArtifactsPath returns the directory the artifacts of the jobs of the
+// stage at the given (1-based) index are written to
+func (e *Executor) GetArtifactsPath(stageIndex int) string {
+	return filepath.Join(e.workPath, "artifacts", fmt.Sprintf("stage-%d", stageIndex))
+}
+
+// Validate checks the parts of the pipeline that matter when running it
+// locally; the workspace repo is replaced by the local source directory
+func Validate(pipeline api.Pipeline) error {
+	if err := pipeline.ValidateStages(); err != nil {
+		return err
+	}
+
+	return pipeline.ValidateJobs()
+}
@@ -0,0 +1,257 @@
+package local
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+)
+
+func newTestPipeline(stages []string, jobs []api.PipelineSpecJob) api.Pipeline {
+	return api.Pipeline{
+		Spec: api.PipelineSpec{
+			Stages: stages,
+			Jobs:   jobs,
+		},
+	}
+}
+
+func newTestJob(name, stage string, runner ...string) api.PipelineSpecJob {
+	return api.PipelineSpecJob{
+		Name:   name,
+		Stage:  stage,
+		Image:  "alpine",
+		Runner: runner,
+	}
+}
+
+func runTestPipeline(t *testing.T, pipeline api.Pipeline, parallel bool) (Result, string) {
+	dir, err := ioutil.TempDir("", "kubesmith-local")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+
+	sourcePath := filepath.Join(dir, "source")
+	if err := os.MkdirAll(sourcePath, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(sourcePath, "README"), []byte("source\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the jobs record the order they ran in outside of their workspaces
+	logPath := filepath.Join(dir, "order.log")
+	for index := range pipeline.Spec.Jobs {
+		job := &pipeline.Spec.Jobs[index]
+		job.Environment = map[string]string{"ORDER_LOG": logPath}
+	}
+
+	executor := NewExecutor(pipeline, sourcePath, filepath.Join(dir, "work"), parallel, ioutil.Discard)
+	result, err := executor.Run()
+	if err != nil {
+		t.Fatalf("could not run pipeline: %v", err)
+	}
+
+	order, err := ioutil.ReadFile(logPath)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+
+	return result, strings.TrimSpace(string(order))
+}
+
+func getResultPhases(result Result) []string {
+	phases := []string{}
+	for _, job := range result.Jobs {
+		phases = append(phases, job.Name+"="+string(job.Phase))
+	}
+
+	return phases
+}
+
+func TestRunStageOrdering(t *testing.T) {
+	tests := []struct {
+		name     string
+		pipeline api.Pipeline
+		parallel bool
+		phase    api.Phase
+		order    string
+		jobs     []string
+	}{
+		{
+			name: "stages run in the order they are listed",
+			pipeline: newTestPipeline([]string{"build", "test", "deploy"}, []api.PipelineSpecJob{
+				newTestJob("deploy", "deploy", `echo deploy >> "$ORDER_LOG"`),
+				newTestJob("test", "test", `echo test >> "$ORDER_LOG"`),
+				newTestJob("build", "build", `echo build >> "$ORDER_LOG"`),
+			}),
+			phase: api.PhaseSucceeded,
+			order: "build\ntest\ndeploy",
+			jobs:  []string{"build=Succeeded", "test=Succeeded", "deploy=Succeeded"},
+		},
+		{
+			name: "a failed job stops the stages after it",
+			pipeline: newTestPipeline([]string{"build", "test", "deploy"}, []api.PipelineSpecJob{
+				newTestJob("build", "build", `echo build >> "$ORDER_LOG"`),
+				newTestJob("test", "test", `echo test >> "$ORDER_LOG"`, "exit 1"),
+				newTestJob("deploy", "deploy", `echo deploy >> "$ORDER_LOG"`),
+			}),
+			phase: api.PhaseFailed,
+			order: "build\ntest",
+			jobs:  []string{"build=Succeeded", "test=Failed"},
+		},
+		{
+			name: "the finally stage still runs after a failed stage",
+			pipeline: func() api.Pipeline {
+				pipeline := newTestPipeline([]string{"build", "test", "cleanup"}, []api.PipelineSpecJob{
+					newTestJob("build", "build", `echo build >> "$ORDER_LOG"`, "exit 1"),
+					newTestJob("test", "test", `echo test >> "$ORDER_LOG"`),
+					newTestJob("cleanup", "cleanup", `echo cleanup >> "$ORDER_LOG"`),
+				})
+				pipeline.Spec.Finally = "cleanup"
+				return pipeline
+			}(),
+			phase: api.PhaseFailed,
+			order: "build\ncleanup",
+			jobs:  []string{"build=Failed", "cleanup=Succeeded"},
+		},
+		{
+			name: "the finally stage runs last when every stage succeeds",
+			pipeline: func() api.Pipeline {
+				pipeline := newTestPipeline([]string{"build", "cleanup"}, []api.PipelineSpecJob{
+					newTestJob("build", "build", `echo build >> "$ORDER_LOG"`),
+					newTestJob("cleanup", "cleanup", `echo cleanup >> "$ORDER_LOG"`),
+				})
+				pipeline.Spec.Finally = "cleanup"
+				return pipeline
+			}(),
+			phase: api.PhaseSucceeded,
+			order: "build\ncleanup",
+			jobs:  []string{"build=Succeeded", "cleanup=Succeeded"},
+		},
+		{
+			name: "a job that is allowed to fail does not stop the pipeline",
+			pipeline: newTestPipeline([]string{"build", "deploy"}, []api.PipelineSpecJob{
+				func() api.PipelineSpecJob {
+					job := newTestJob("lint", "build", `echo lint >> "$ORDER_LOG"`, "exit 1")
+					job.AllowFailure = true
+					return job
+				}(),
+				newTestJob("deploy", "deploy", `echo deploy >> "$ORDER_LOG"`),
+			}),
+			phase: api.PhaseSucceeded,
+			order: "lint\ndeploy",
+			jobs:  []string{"lint=Failed", "deploy=Succeeded"},
+		},
+		{
+			name: "every job of a stage runs before the next stage when running in parallel",
+			pipeline: newTestPipeline([]string{"build", "deploy"}, []api.PipelineSpecJob{
+				newTestJob("build-1", "build", "sleep 1", `echo build >> "$ORDER_LOG"`),
+				newTestJob("build-2", "build", `echo build >> "$ORDER_LOG"`),
+				newTestJob("deploy", "deploy", `echo deploy >> "$ORDER_LOG"`),
+			}),
+			parallel: true,
+			phase:    api.PhaseSucceeded,
+			order:    "build\nbuild\ndeploy",
+			jobs:     []string{"build-1=Succeeded", "build-2=Succeeded", "deploy=Succeeded"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, order := runTestPipeline(t, test.pipeline, test.parallel)
+
+			if result.Phase != test.phase {
+				t.Errorf("expected phase %s, got %s (%s)", test.phase, result.Phase, result.FailureReason)
+			}
+
+			if order != test.order {
+				t.Errorf("expected jobs to run in order %q, got %q", test.order, order)
+			}
+
+			if phases := getResultPhases(result); !reflect.DeepEqual(phases, test.jobs) {
+				t.Errorf("expected job results %v, got %v", test.jobs, phases)
+			}
+		})
+	}
+}
+
+func TestRunArtifactHandoff(t *testing.T) {
+	withArtifacts := func(job api.PipelineSpecJob, onSuccess, onFail []string) api.PipelineSpecJob {
+		job.Artifacts = api.PipelineJobArtifacts{OnSuccess: onSuccess, OnFail: onFail}
+		return job
+	}
+
+	tests := []struct {
+		name     string
+		pipeline api.Pipeline
+		phase    api.Phase
+	}{
+		{
+			name: "the artifacts of a job are extracted into the workspaces of the next stage",
+			pipeline: newTestPipeline([]string{"build", "test"}, []api.PipelineSpecJob{
+				withArtifacts(newTestJob("build", "build", "mkdir -p dist", "echo binary > dist/app"), []string{"dist"}, nil),
+				newTestJob("test-1", "test", `test "$(cat dist/app)" = binary`),
+				newTestJob("test-2", "test", `test "$(cat dist/app)" = binary`, "test -f README"),
+			}),
+			phase: api.PhaseSucceeded,
+		},
+		{
+			name: "the artifacts of every job of a stage are handed off",
+			pipeline: newTestPipeline([]string{"build", "test"}, []api.PipelineSpecJob{
+				withArtifacts(newTestJob("build-app", "build", "mkdir -p app", "echo app > app/out"), []string{"app"}, nil),
+				withArtifacts(newTestJob("build-docs", "build", "mkdir -p docs", "echo docs > docs/out"), []string{"docs"}, nil),
+				newTestJob("test", "test", "test -f app/out", "test -f docs/out"),
+			}),
+			phase: api.PhaseSucceeded,
+		},
+		{
+			name: "artifacts are only handed off to the next stage",
+			pipeline: newTestPipeline([]string{"build", "test", "deploy"}, []api.PipelineSpecJob{
+				withArtifacts(newTestJob("build", "build", "mkdir -p dist", "echo binary > dist/app"), []string{"dist"}, nil),
+				newTestJob("test", "test", "test -f dist/app"),
+				newTestJob("deploy", "deploy", "test ! -e dist/app"),
+			}),
+			phase: api.PhaseSucceeded,
+		},
+		{
+			name: "the changes a job makes to its workspace are not seen by the next stage",
+			pipeline: newTestPipeline([]string{"build", "test"}, []api.PipelineSpecJob{
+				newTestJob("build", "build", "echo changed > README"),
+				newTestJob("test", "test", `test "$(cat README)" = source`),
+			}),
+			phase: api.PhaseSucceeded,
+		},
+		{
+			name: "the failure artifacts are collected when a job fails",
+			pipeline: newTestPipeline([]string{"build", "report"}, []api.PipelineSpecJob{
+				func() api.PipelineSpecJob {
+					job := withArtifacts(newTestJob("build", "build", "mkdir -p logs", "echo failed > logs/build.log", "exit 1"), []string{"dist"}, []string{"logs"})
+					job.AllowFailure = true
+					return job
+				}(),
+				newTestJob("report", "report", "test -f logs/build.log", "test ! -e dist"),
+			}),
+			phase: api.PhaseSucceeded,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, _ := runTestPipeline(t, test.pipeline, false)
+
+			if result.Phase != test.phase {
+				t.Errorf("expected phase %s, got %s (%s)", test.phase, result.Phase, result.FailureReason)
+			}
+		})
+	}
+}
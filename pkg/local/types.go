@@ -0,0 +1,51 @@
+package local
+
+import (
+	"io"
+	gosync "sync"
+	"time"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+)
+
+const (
+	repoArchiveFileName = "repo.tar.gz"
+	runnerScriptName    = "pipeline-script.sh"
+)
+
+// Executor runs a pipeline on the local machine; every job runs as a local
+// process in its own copy of the workspace and hands its artifacts to the
+// next stage the same way it would in a cluster
+type Executor struct {
+	pipeline   api.Pipeline
+	sourcePath string
+	workPath   string
+	parallel   bool
+	out        io.Writer
+	outLock    *gosync.Mutex
+}
+
+// Result is the outcome of a local pipeline run
+type Result struct {
+	Phase         api.Phase
+	FailureReason string
+	Jobs          []JobResult
+}
+
+// JobResult is the outcome of a single job of a local pipeline run
+type JobResult struct {
+	Stage         string
+	Name          string
+	Phase         api.Phase
+	FailureReason string
+	Duration      time.Duration
+	AllowFailure  bool
+}
+
+type localJob struct {
+	index     int
+	stage     string
+	spec      api.PipelineJobSpecJob
+	path      string
+	workspace string
+}
@@ -0,0 +1,69 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func GetSupportedFormats() []string {
+	return []string{FormatText, FormatJSON}
+}
+
+func NewLogger(format, level string) (*logrus.Logger, error) {
+	logger := logrus.New()
+	logger.Out = os.Stderr
+
+	switch strings.ToLower(format) {
+	case FormatText:
+		logger.Formatter = &logrus.TextFormatter{FullTimestamp: true}
+	case FormatJSON:
+		logger.Formatter = &logrus.JSONFormatter{}
+	default:
+		return nil, fmt.Errorf("invalid log format %q; valid formats are: %s", format, strings.Join(GetSupportedFormats(), ", "))
+	}
+
+	parsedLevel, err := logrus.ParseLevel(level)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log level %q", level)
+	}
+
+	logger.SetLevel(parsedLevel)
+	return logger, nil
+}
+
+// GetResourceFields returns the log fields that identify a kubesmith resource
+// (or a resource created on behalf of one) using its name and labels.
+func GetResourceFields(obj metav1.Object) logrus.Fields {
+	fields := logrus.Fields{
+		FieldNamespace: obj.GetNamespace(),
+	}
+
+	labels := obj.GetLabels()
+	if value, ok := labels[api.GetLabelKey("PipelineName")]; ok {
+		fields[FieldPipeline] = value
+	}
+
+	if value, ok := labels[api.GetLabelKey("PipelineStageName")]; ok {
+		fields[FieldStage] = value
+	}
+
+	if value, ok := labels[api.GetLabelKey("PipelineJobName")]; ok {
+		fields[FieldJob] = value
+	}
+
+	switch obj.(type) {
+	case *api.Pipeline:
+		fields[FieldPipeline] = obj.GetName()
+	case *api.PipelineStage:
+		fields[FieldStage] = obj.GetName()
+	case *api.PipelineJob:
+		fields[FieldJob] = obj.GetName()
+	}
+
+	return fields
+}
@@ -0,0 +1,25 @@
+package logging
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Config is the log format and level a kubesmith process was started with
+type Config struct {
+	Format string
+	Level  string
+}
+
+// the field names that are used consistently across all kubesmith log entries
+const (
+	FieldCommand     = "command"
+	FieldController  = "controller"
+	FieldNamespace   = "namespace"
+	FieldForge       = "forge"
+	FieldPipeline    = "pipeline"
+	FieldStage       = "stage"
+	FieldJob         = "job"
+	FieldPod         = "pod"
+	FieldReconcileID = "reconcileID"
+)
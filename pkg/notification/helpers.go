@@ -0,0 +1,84 @@
+package notification
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	kubesmithv1 "github.com/kubesmith/kubesmith/pkg/generated/clientset/versioned/typed/kubesmith/v1"
+	informers "github.com/kubesmith/kubesmith/pkg/generated/informers/externalversions/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/logging"
+	"github.com/sirupsen/logrus"
+	coreInformersv1 "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// NewNotifier creates a notifier reading the previous runs of pipelines from
+// the pipeline informer and the credentials from the secret informer; it
+// sends nothing until it's run
+func NewNotifier(
+	logger logrus.FieldLogger,
+	kubesmithClient kubesmithv1.KubesmithV1Interface,
+	pipelineInformer informers.PipelineInformer,
+	secretInformer coreInformersv1.SecretInformer,
+) *Notifier {
+	return &Notifier{
+		logger:          logger.WithField(logging.FieldController, "Notification"),
+		queue:           workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(retryBaseDelay, retryMaxDelay), "Notification"),
+		kubesmithClient: kubesmithClient,
+		pipelineLister:  pipelineInformer.Lister(),
+		pipelineSynced:  pipelineInformer.Informer().HasSynced,
+		secretLister:    secretInformer.Lister(),
+		secretSynced:    secretInformer.Informer().HasSynced,
+		requests:        map[string]request{},
+		notified:        map[string]notifiedPipeline{},
+	}
+}
+
+// NewEvent describes the pipeline for a notification of the trigger
+func NewEvent(pipeline api.Pipeline, trigger api.NotificationTrigger) Event {
+	repo := pipeline.Spec.Workspace.Repo
+	event := Event{
+		Trigger:        trigger,
+		Namespace:      pipeline.GetNamespace(),
+		Pipeline:       pipeline.GetName(),
+		Phase:          pipeline.Status.Phase,
+		Attempt:        pipeline.GetAttempt(),
+		FailureReason:  pipeline.Status.FailureReason,
+		Repo:           repo.URL,
+		Ref:            repo.Ref,
+		Commit:         pipeline.GetCommit(),
+		Author:         repo.Author,
+		PullRequest:    repo.PullRequest.Number,
+		PullRequestURL: repo.PullRequest.URL,
+		URL: strings.NewReplacer(
+			"{namespace}", pipeline.GetNamespace(),
+			"{pipeline}", pipeline.GetName(),
+		).Replace(repo.CommitStatus.TargetURL),
+	}
+
+	if !pipeline.Status.StartTime.IsZero() && !pipeline.Status.EndTime.IsZero() {
+		event.Duration = pipeline.Status.EndTime.Sub(pipeline.Status.StartTime.Time).Round(time.Second).String()
+	}
+
+	return event
+}
+
+// NewSender creates the sender delivering the notification; secret is the
+// value of the secret it refers to, which is the incoming webhook url of slack
+// notifications and the smtp password of email notifications
+func NewSender(notification api.Notification, secret string) (Sender, error) {
+	client := &http.Client{Timeout: requestTimeout}
+
+	if notification.IsWebhook() {
+		return &webhookSender{client: client, webhook: notification.Webhook}, nil
+	} else if notification.IsSlack() {
+		return &slackSender{client: client, url: secret, slack: notification.Slack}, nil
+	} else if notification.IsEmail() {
+		return &emailSender{email: notification.Email, password: secret}, nil
+	}
+
+	return nil, fmt.Errorf("notification %q has nothing to send it to", notification.Name)
+}
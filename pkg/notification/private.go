@@ -0,0 +1,490 @@
+package notification
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/controllers/generic"
+	"github.com/kubesmith/kubesmith/pkg/logging"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// getTriggers returns the triggers a finished pipeline fires, ordered from the
+// most to the least specific
+func (n *Notifier) getTriggers(pipeline api.Pipeline) ([]api.NotificationTrigger, error) {
+	trigger := api.NotificationTrigger(api.NotificationTriggerFailure)
+	if pipeline.HasSucceeded() {
+		trigger = api.NotificationTriggerSuccess
+	}
+
+	previous, err := n.getPreviousPipeline(pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	if previous != nil && pipeline.HasSucceeded() && previous.HasFailed() {
+		return []api.NotificationTrigger{api.NotificationTriggerFixed, trigger}, nil
+	} else if previous != nil && pipeline.HasFailed() && previous.HasSucceeded() {
+		return []api.NotificationTrigger{api.NotificationTriggerRegressed, trigger}, nil
+	}
+
+	return []api.NotificationTrigger{trigger}, nil
+}
+
+// getPreviousPipeline returns the latest pipeline of the same repo and ref
+// created before the pipeline that succeeded or failed; pipelines without a
+// repo have no previous pipeline
+func (n *Notifier) getPreviousPipeline(original api.Pipeline) (*api.Pipeline, error) {
+	if original.Spec.Workspace.Repo.URL == "" {
+		return nil, nil
+	}
+
+	pipelines, err := n.pipelineLister.Pipelines(original.GetNamespace()).List(labels.Everything())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list pipelines")
+	}
+
+	var previous *api.Pipeline
+	repo := original.Spec.Workspace.Repo
+
+	for _, pipeline := range pipelines {
+		if pipeline.GetName() == original.GetName() {
+			continue
+		} else if pipeline.Spec.Workspace.Repo.URL != repo.URL || pipeline.Spec.Workspace.Repo.Ref != repo.Ref {
+			continue
+		} else if !(pipeline.HasSucceeded() || pipeline.HasFailed()) || !original.IsNewerThan(*pipeline) {
+			continue
+		}
+
+		if previous == nil || pipeline.IsNewerThan(*previous) {
+			previous = pipeline
+		}
+	}
+
+	return previous, nil
+}
+
+// resumePendingNotifications queues the notifications of the current attempt
+// of the pipeline that are pending and not queued yet
+func (n *Notifier) resumePendingNotifications(pipeline api.Pipeline) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	for _, status := range pipeline.Status.Notifications {
+		if status.Attempt != pipeline.GetAttempt() || !status.IsPending() {
+			continue
+		}
+
+		key := getRequestKey(pipeline, status.Name, status.Attempt)
+		if _, ok := n.requests[key]; ok {
+			continue
+		}
+
+		for _, notification := range pipeline.Spec.Notifications {
+			if notification.Name != status.Name {
+				continue
+			}
+
+			n.logger.WithFields(logging.GetResourceFields(&pipeline)).WithField("notification", status.Name).Debug("queueing notification")
+			n.requests[key] = request{
+				namespace:    pipeline.GetNamespace(),
+				pipeline:     pipeline.GetName(),
+				notification: notification,
+				event:        NewEvent(pipeline, status.Trigger),
+			}
+
+			n.queue.Add(key)
+		}
+	}
+}
+
+func (n *Notifier) runWorker() {
+	for n.processNextItem() {
+		//
+	}
+}
+
+func (n *Notifier) processNextItem() bool {
+	key, quit := n.queue.Get()
+	if quit {
+		return false
+	}
+	defer n.queue.Done(key)
+
+	n.lock.Lock()
+	req, ok := n.requests[key.(string)]
+	n.lock.Unlock()
+
+	if !ok {
+		n.queue.Forget(key)
+		return true
+	}
+
+	logger := n.logger.WithFields(logrus.Fields{
+		logging.FieldNamespace: req.namespace,
+		logging.FieldPipeline:  req.pipeline,
+		"notification":         req.notification.Name,
+		"trigger":              req.event.Trigger,
+	})
+
+	retry, err := n.deliver(req, logger)
+	if err != nil {
+		logger.Error(err)
+	}
+
+	if retry {
+		n.queue.AddRateLimited(key)
+		return true
+	}
+
+	n.lock.Lock()
+	delete(n.requests, key.(string))
+	n.lock.Unlock()
+
+	n.queue.Forget(key)
+	n.pruneNotified()
+
+	return true
+}
+
+// deliver sends the notification unless it's no longer pending and records
+// the outcome in the status of the pipeline; it returns whether the
+// notification is to be retried
+func (n *Notifier) deliver(req request, logger logrus.FieldLogger) (bool, error) {
+	// the lister may not have caught up with the previous tries yet
+	pipeline, err := n.kubesmithClient.Pipelines(req.namespace).Get(req.pipeline, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return true, errors.Wrap(err, "could not get pipeline")
+	}
+
+	index := -1
+	for i, status := range pipeline.Status.Notifications {
+		if status.Name == req.notification.Name && status.Attempt == req.event.Attempt {
+			index = i
+		}
+	}
+
+	if index < 0 || !pipeline.Status.Notifications[index].IsPending() {
+		return false, nil
+	}
+
+	updated := *pipeline.DeepCopy()
+	status := &updated.Status.Notifications[index]
+	status.Tries++
+	status.LastTriedTime = metav1.Now()
+
+	sendErr := n.send(req)
+	if sendErr == nil {
+		logger.Info("delivered notification")
+		status.State = api.NotificationStateDelivered
+		status.Error = ""
+	} else if status.Tries < maxTries {
+		logger.Error(errors.Wrap(sendErr, "could not deliver notification; retrying"))
+		status.Error = sendErr.Error()
+	} else {
+		logger.Error(errors.Wrap(sendErr, "could not deliver notification; giving up"))
+		status.State = api.NotificationStateFailed
+		status.Error = sendErr.Error()
+	}
+
+	if _, err := generic.PatchPipeline(n.kubesmithClient, updated, *pipeline); err != nil {
+		// a notification that was delivered is not sent again only because
+		// its delivery could not be recorded
+		return status.IsPending(), errors.Wrap(err, "could not record notification delivery")
+	}
+
+	return status.IsPending(), nil
+}
+
+func (n *Notifier) send(req request) error {
+	secret := api.NotificationSecret{}
+	if req.notification.IsSlack() {
+		secret = req.notification.Slack.Secret
+	} else if req.notification.IsEmail() {
+		secret = req.notification.Email.Password
+	}
+
+	value := ""
+	if secret.Name != "" {
+		data, err := n.getSecretValue(req.namespace, secret)
+		if err != nil {
+			return err
+		}
+
+		value = data
+	}
+
+	sender, err := NewSender(req.notification, value)
+	if err != nil {
+		return err
+	}
+
+	return sender.Send(req.event)
+}
+
+func (n *Notifier) getSecretValue(namespace string, notificationSecret api.NotificationSecret) (string, error) {
+	secret, err := n.secretLister.Secrets(namespace).Get(notificationSecret.Name)
+	if err != nil {
+		return "", errors.Wrap(err, "could not get notification secret")
+	}
+
+	value, ok := secret.Data[notificationSecret.Key]
+	if !ok {
+		return "", fmt.Errorf("notification secret does not have key %q", notificationSecret.Key)
+	}
+
+	return strings.TrimSpace(string(value)), nil
+}
+
+// wasNotified returns whether the current attempt of the pipeline was notified
+// while its status may not have caught up yet
+func (n *Notifier) wasNotified(pipeline api.Pipeline) bool {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return n.notified[getPipelineKey(pipeline)].attempt >= pipeline.GetAttempt()
+}
+
+func (n *Notifier) setNotified(pipeline api.Pipeline) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.notified[getPipelineKey(pipeline)] = notifiedPipeline{attempt: pipeline.GetAttempt(), time: time.Now()}
+}
+
+// pruneNotified forgets the pipelines that were notified a while ago; their
+// status has caught up by then
+func (n *Notifier) pruneNotified() {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	now := time.Now()
+	if now.Sub(n.lastPruned) < time.Minute {
+		return
+	}
+
+	for key, notified := range n.notified {
+		if now.Sub(notified.time) > notifiedTTL {
+			delete(n.notified, key)
+		}
+	}
+
+	n.lastPruned = now
+}
+
+func (n *Notifier) setRunning(running bool) {
+	n.runningLock.Lock()
+	defer n.runningLock.Unlock()
+
+	n.running = running
+}
+
+func (n *Notifier) isRunning() bool {
+	n.runningLock.RLock()
+	defer n.runningLock.RUnlock()
+
+	return n.running
+}
+
+// sendMail sends a plain text mail; the connection is upgraded with starttls
+// when the server supports it and authenticated when there's a username
+func (s *emailSender) sendMail(subject, body string) error {
+	host := s.email.Host
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(s.email.GetPort())), requestTimeout)
+	if err != nil {
+		return errors.Wrap(err, "could not connect to smtp server")
+	}
+
+	conn.SetDeadline(time.Now().Add(requestTimeout))
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return errors.Wrap(err, "could not connect to smtp server")
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return errors.Wrap(err, "could not start tls")
+		}
+	}
+
+	if s.email.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.email.Username, s.password, host)); err != nil {
+			return errors.Wrap(err, "could not authenticate")
+		}
+	}
+
+	if err := client.Mail(s.email.From); err != nil {
+		return errors.Wrap(err, "smtp server refused the sender")
+	}
+
+	for _, to := range s.email.To {
+		if err := client.Rcpt(to); err != nil {
+			return errors.Wrapf(err, "smtp server refused the recipient %s", to)
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return errors.Wrap(err, "could not send mail")
+	}
+
+	header := []string{
+		fmt.Sprintf("From: %s", s.email.From),
+		fmt.Sprintf("To: %s", strings.Join(s.email.To, ", ")),
+		fmt.Sprintf("Subject: %s", mime.QEncoding.Encode("utf-8", strings.Join(strings.Fields(subject), " "))),
+		fmt.Sprintf("Date: %s", time.Now().Format(time.RFC1123Z)),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8",
+	}
+
+	if _, err := fmt.Fprintf(writer, "%s\n\n%s\n", strings.Join(header, "\n"), body); err != nil {
+		return errors.Wrap(err, "could not send mail")
+	}
+
+	if err := writer.Close(); err != nil {
+		return errors.Wrap(err, "could not send mail")
+	}
+
+	return client.Quit()
+}
+
+// render executes the template of a notification with the event; empty
+// templates render nothing so that the caller falls back to its default
+func render(name, text string, event Event) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+
+	tmpl, err := template.New(name).Funcs(api.NotificationTemplateFuncs).Parse(text)
+	if err != nil {
+		return "", errors.Wrapf(err, "could not parse %s", name)
+	}
+
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, event); err != nil {
+		return "", errors.Wrapf(err, "could not render %s", name)
+	}
+
+	return buffer.String(), nil
+}
+
+// getSummary describes the event in a line
+func getSummary(event Event) string {
+	summary := ""
+	name := fmt.Sprintf("%s/%s", event.Namespace, event.Pipeline)
+
+	switch event.Trigger {
+	case api.NotificationTriggerSuccess:
+		summary = fmt.Sprintf("pipeline %s succeeded", name)
+	case api.NotificationTriggerFixed:
+		summary = fmt.Sprintf("pipeline %s was fixed; it succeeded", name)
+	case api.NotificationTriggerRegressed:
+		summary = fmt.Sprintf("pipeline %s regressed: %s", name, event.FailureReason)
+	default:
+		summary = fmt.Sprintf("pipeline %s failed: %s", name, event.FailureReason)
+	}
+
+	if event.Phase == api.PhaseSucceeded && event.Duration != "" {
+		summary = fmt.Sprintf("%s in %s", summary, event.Duration)
+	}
+
+	if event.Repo != "" {
+		summary = fmt.Sprintf("%s (%s)", summary, strings.TrimSpace(fmt.Sprintf("%s %s", event.Repo, event.Ref)))
+	}
+
+	return summary
+}
+
+// getDetails describes the event in a few lines
+func getDetails(event Event) string {
+	lines := []string{getSummary(event), ""}
+	fields := [][]string{
+		{"Pipeline", fmt.Sprintf("%s/%s", event.Namespace, event.Pipeline)},
+		{"Phase", string(event.Phase)},
+		{"Attempt", strconv.Itoa(event.Attempt)},
+		{"Duration", event.Duration},
+		{"Failure reason", event.FailureReason},
+		{"Repo", event.Repo},
+		{"Ref", event.Ref},
+		{"Commit", event.Commit},
+		{"Author", event.Author},
+		{"Pull request", event.PullRequestURL},
+		{"Details", event.URL},
+	}
+
+	for _, field := range fields {
+		if field[1] != "" {
+			lines = append(lines, fmt.Sprintf("%s: %s", field[0], field[1]))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// post sends the json body to the endpoint; any status other than 2xx is an
+// error
+func post(client *http.Client, endpoint string, header map[string]string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "could not create request")
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+
+	// the url of slack webhooks is a secret that mustn't end up in the
+	// status of the pipeline
+	resp, err := client.Do(req)
+	if urlErr, ok := err.(*url.Error); ok {
+		return fmt.Errorf("could not send request to %s: %v", req.URL.Host, urlErr.Err)
+	} else if err != nil {
+		return errors.Wrap(err, "could not send request")
+	}
+	defer resp.Body.Close()
+
+	response, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s responded with %s: %s", req.URL.Host, resp.Status, truncate(strings.TrimSpace(string(response)), 200))
+	}
+
+	return nil
+}
+
+func getPipelineKey(pipeline api.Pipeline) string {
+	return fmt.Sprintf("%s/%s", pipeline.GetNamespace(), pipeline.GetName())
+}
+
+func getRequestKey(pipeline api.Pipeline, name string, attempt int) string {
+	return fmt.Sprintf("%s#%s#%d", getPipelineKey(pipeline), name, attempt)
+}
+
+func truncate(value string, length int) string {
+	if len(value) <= length {
+		return value
+	}
+
+	return value[:length-3] + "..."
+}
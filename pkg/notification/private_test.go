@@ -0,0 +1,504 @@
+package notification
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/generated/clientset/versioned/fake"
+	kubesmithListersv1 "github.com/kubesmith/kubesmith/pkg/generated/listers/kubesmith/v1"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+var testNow = time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+// newTestNotifier creates a notifier whose client and lister hold the
+// pipelines
+func newTestNotifier(t *testing.T, pipelines ...api.Pipeline) (*Notifier, *fake.Clientset) {
+	objects := []runtime.Object{}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+
+	for _, pipeline := range pipelines {
+		pipeline := pipeline.DeepCopy()
+		objects = append(objects, pipeline)
+		indexer.Add(pipeline)
+	}
+
+	client := fake.NewSimpleClientset(objects...)
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+
+	notifier := &Notifier{
+		logger:          logger,
+		queue:           workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "Notification"),
+		kubesmithClient: client.KubesmithV1(),
+		pipelineLister:  kubesmithListersv1.NewPipelineLister(indexer),
+		requests:        map[string]request{},
+		notified:        map[string]notifiedPipeline{},
+	}
+
+	t.Cleanup(notifier.queue.ShutDown)
+	return notifier, client
+}
+
+// newTestPipeline creates a pipeline of the ref in the phase that was created
+// the given time ago
+func newTestPipeline(name, ref string, phase api.Phase, createdAgo time.Duration) api.Pipeline {
+	pipeline := api.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(testNow.Add(-createdAgo)),
+		},
+	}
+
+	pipeline.Spec.Workspace.Repo.URL = "git@github.com:kubesmith/app.git"
+	pipeline.Spec.Workspace.Repo.Ref = ref
+	pipeline.Status.Phase = phase
+
+	return pipeline
+}
+
+// newNotifiedPipeline creates a pipeline that finished in the phase with a
+// notification of every trigger
+func newNotifiedPipeline(name string, phase api.Phase) api.Pipeline {
+	pipeline := newTestPipeline(name, "refs/heads/master", phase, time.Minute)
+	pipeline.Spec.Notifications = []api.Notification{
+		{Name: "fixed", Triggers: []api.NotificationTrigger{api.NotificationTriggerFixed}},
+		{Name: "regressed", Triggers: []api.NotificationTrigger{api.NotificationTriggerRegressed}},
+		{Name: "finished", Triggers: []api.NotificationTrigger{api.NotificationTriggerSuccess, api.NotificationTriggerFailure}},
+		{Name: "failed", Triggers: []api.NotificationTrigger{api.NotificationTriggerRegressed, api.NotificationTriggerFailure}},
+	}
+
+	return pipeline
+}
+
+// getPatchCount returns how many times the pipelines were patched through
+// the client
+func getPatchCount(client *fake.Clientset) int {
+	count := 0
+
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "patch" && action.GetResource().Resource == "pipelines" {
+			count++
+		}
+	}
+
+	return count
+}
+
+func getPipeline(t *testing.T, client *fake.Clientset, name string) api.Pipeline {
+	pipeline, err := client.KubesmithV1().Pipelines("default").Get(name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return *pipeline
+}
+
+func TestGetTriggers(t *testing.T) {
+	master := "refs/heads/master"
+	tests := []struct {
+		name     string
+		ref      string
+		phase    api.Phase
+		previous []api.Pipeline
+		expected []api.NotificationTrigger
+	}{
+		{
+			name:     "first success",
+			phase:    api.PhaseSucceeded,
+			expected: []api.NotificationTrigger{api.NotificationTriggerSuccess},
+		},
+		{
+			name:     "first failure",
+			phase:    api.PhaseFailed,
+			expected: []api.NotificationTrigger{api.NotificationTriggerFailure},
+		},
+		{
+			name:     "fixed",
+			phase:    api.PhaseSucceeded,
+			previous: []api.Pipeline{newTestPipeline("previous", master, api.PhaseFailed, time.Hour)},
+			expected: []api.NotificationTrigger{api.NotificationTriggerFixed, api.NotificationTriggerSuccess},
+		},
+		{
+			name:     "regressed",
+			phase:    api.PhaseFailed,
+			previous: []api.Pipeline{newTestPipeline("previous", master, api.PhaseSucceeded, time.Hour)},
+			expected: []api.NotificationTrigger{api.NotificationTriggerRegressed, api.NotificationTriggerFailure},
+		},
+		{
+			name:     "still failing",
+			phase:    api.PhaseFailed,
+			previous: []api.Pipeline{newTestPipeline("previous", master, api.PhaseFailed, time.Hour)},
+			expected: []api.NotificationTrigger{api.NotificationTriggerFailure},
+		},
+		{
+			name:     "still succeeding",
+			phase:    api.PhaseSucceeded,
+			previous: []api.Pipeline{newTestPipeline("previous", master, api.PhaseSucceeded, time.Hour)},
+			expected: []api.NotificationTrigger{api.NotificationTriggerSuccess},
+		},
+		{
+			name:  "latest previous pipeline",
+			phase: api.PhaseFailed,
+			previous: []api.Pipeline{
+				newTestPipeline("older", master, api.PhaseFailed, 2*time.Hour),
+				newTestPipeline("previous", master, api.PhaseSucceeded, time.Hour),
+			},
+			expected: []api.NotificationTrigger{api.NotificationTriggerRegressed, api.NotificationTriggerFailure},
+		},
+		{
+			name:  "unfinished and cancelled pipelines",
+			phase: api.PhaseSucceeded,
+			previous: []api.Pipeline{
+				newTestPipeline("failed", master, api.PhaseFailed, 3*time.Hour),
+				newTestPipeline("cancelled", master, api.PhaseCancelled, 2*time.Hour),
+				newTestPipeline("running", master, api.PhaseRunning, time.Hour),
+			},
+			expected: []api.NotificationTrigger{api.NotificationTriggerFixed, api.NotificationTriggerSuccess},
+		},
+		{
+			name:     "newer pipeline",
+			phase:    api.PhaseSucceeded,
+			previous: []api.Pipeline{newTestPipeline("newer", master, api.PhaseFailed, 0)},
+			expected: []api.NotificationTrigger{api.NotificationTriggerSuccess},
+		},
+		{
+			name:     "other branch",
+			phase:    api.PhaseSucceeded,
+			previous: []api.Pipeline{newTestPipeline("previous", "refs/heads/feature", api.PhaseFailed, time.Hour)},
+			expected: []api.NotificationTrigger{api.NotificationTriggerSuccess},
+		},
+		{
+			name:     "tag",
+			phase:    api.PhaseSucceeded,
+			previous: []api.Pipeline{newTestPipeline("previous", "refs/tags/v1.0.0", api.PhaseFailed, time.Hour)},
+			expected: []api.NotificationTrigger{api.NotificationTriggerSuccess},
+		},
+		{
+			name:     "other tag",
+			ref:      "refs/tags/v1.1.0",
+			phase:    api.PhaseSucceeded,
+			previous: []api.Pipeline{newTestPipeline("previous", "refs/tags/v1.0.0", api.PhaseFailed, time.Hour)},
+			expected: []api.NotificationTrigger{api.NotificationTriggerSuccess},
+		},
+		{
+			name:  "other repo",
+			phase: api.PhaseSucceeded,
+			previous: func() []api.Pipeline {
+				pipeline := newTestPipeline("previous", master, api.PhaseFailed, time.Hour)
+				pipeline.Spec.Workspace.Repo.URL = "git@github.com:kubesmith/other.git"
+				return []api.Pipeline{pipeline}
+			}(),
+			expected: []api.NotificationTrigger{api.NotificationTriggerSuccess},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ref := test.ref
+			if ref == "" {
+				ref = master
+			}
+
+			pipeline := newTestPipeline("pipeline", ref, test.phase, time.Minute)
+			notifier, _ := newTestNotifier(t, append(test.previous, pipeline)...)
+
+			triggers, err := notifier.getTriggers(pipeline)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(triggers, test.expected) {
+				t.Errorf("expected triggers %v, got %v", test.expected, triggers)
+			}
+		})
+	}
+}
+
+func TestGetTriggersWithoutRepo(t *testing.T) {
+	previous := newTestPipeline("previous", "", api.PhaseFailed, time.Hour)
+	previous.Spec.Workspace.Repo.URL = ""
+	pipeline := newTestPipeline("pipeline", "", api.PhaseSucceeded, time.Minute)
+	pipeline.Spec.Workspace.Repo.URL = ""
+
+	notifier, _ := newTestNotifier(t, previous, pipeline)
+
+	triggers, err := notifier.getTriggers(pipeline)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []api.NotificationTrigger{api.NotificationTriggerSuccess}
+	if !reflect.DeepEqual(triggers, expected) {
+		t.Errorf("expected triggers %v, got %v", expected, triggers)
+	}
+}
+
+func TestNotifyPipeline(t *testing.T) {
+	tests := []struct {
+		name     string
+		phase    api.Phase
+		previous api.Phase
+		expected []api.PipelineNotificationStatus
+	}{
+		{
+			name:     "fixed",
+			phase:    api.PhaseSucceeded,
+			previous: api.PhaseFailed,
+			expected: []api.PipelineNotificationStatus{
+				{Name: "fixed", Trigger: api.NotificationTriggerFixed, Attempt: 1, State: api.NotificationStatePending},
+				{Name: "finished", Trigger: api.NotificationTriggerSuccess, Attempt: 1, State: api.NotificationStatePending},
+			},
+		},
+		{
+			name:     "regressed",
+			phase:    api.PhaseFailed,
+			previous: api.PhaseSucceeded,
+			expected: []api.PipelineNotificationStatus{
+				{Name: "regressed", Trigger: api.NotificationTriggerRegressed, Attempt: 1, State: api.NotificationStatePending},
+				{Name: "finished", Trigger: api.NotificationTriggerFailure, Attempt: 1, State: api.NotificationStatePending},
+				{Name: "failed", Trigger: api.NotificationTriggerRegressed, Attempt: 1, State: api.NotificationStatePending},
+			},
+		},
+		{
+			name:     "failed",
+			phase:    api.PhaseFailed,
+			previous: api.PhaseFailed,
+			expected: []api.PipelineNotificationStatus{
+				{Name: "finished", Trigger: api.NotificationTriggerFailure, Attempt: 1, State: api.NotificationStatePending},
+				{Name: "failed", Trigger: api.NotificationTriggerFailure, Attempt: 1, State: api.NotificationStatePending},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			previous := newTestPipeline("previous", "refs/heads/master", test.previous, time.Hour)
+			pipeline := newNotifiedPipeline("pipeline", test.phase)
+			notifier, client := newTestNotifier(t, previous, pipeline)
+
+			if err := notifier.NotifyPipeline(pipeline); err != nil {
+				t.Fatal(err)
+			}
+
+			updated := getPipeline(t, client, "pipeline")
+			if updated.Status.NotifiedAttempt != 1 {
+				t.Errorf("expected notified attempt 1, got %d", updated.Status.NotifiedAttempt)
+			}
+
+			if !reflect.DeepEqual(updated.Status.Notifications, test.expected) {
+				t.Errorf("expected notifications %+v, got %+v", test.expected, updated.Status.Notifications)
+			}
+
+			if notifier.queue.Len() != len(test.expected) {
+				t.Errorf("expected %d queued notifications, got %d", len(test.expected), notifier.queue.Len())
+			}
+		})
+	}
+}
+
+func TestNotifyPipelineUnfinished(t *testing.T) {
+	pipeline := newNotifiedPipeline("pipeline", api.PhaseRunning)
+	notifier, client := newTestNotifier(t, pipeline)
+
+	if err := notifier.NotifyPipeline(pipeline); err != nil {
+		t.Fatal(err)
+	}
+
+	if count := getPatchCount(client); count != 0 {
+		t.Errorf("expected no patches, got %d", count)
+	}
+}
+
+func TestNotifyPipelineRetry(t *testing.T) {
+	pipeline := newNotifiedPipeline("pipeline", api.PhaseSucceeded)
+	pipeline.Status.Attempt = 2
+	pipeline.Status.NotifiedAttempt = 1
+	pipeline.Status.Notifications = []api.PipelineNotificationStatus{
+		{Name: "finished", Trigger: api.NotificationTriggerFailure, Attempt: 1, State: api.NotificationStateDelivered, Tries: 1},
+		{Name: "failed", Trigger: api.NotificationTriggerFailure, Attempt: 1, State: api.NotificationStatePending, Tries: 2},
+	}
+
+	notifier, client := newTestNotifier(t, pipeline)
+
+	if err := notifier.NotifyPipeline(pipeline); err != nil {
+		t.Fatal(err)
+	}
+
+	// the pending notification of the first attempt is left alone
+	updated := getPipeline(t, client, "pipeline")
+	expected := append(pipeline.Status.Notifications, api.PipelineNotificationStatus{
+		Name: "finished", Trigger: api.NotificationTriggerSuccess, Attempt: 2, State: api.NotificationStatePending,
+	})
+
+	if updated.Status.NotifiedAttempt != 2 {
+		t.Errorf("expected notified attempt 2, got %d", updated.Status.NotifiedAttempt)
+	}
+
+	if !reflect.DeepEqual(updated.Status.Notifications, expected) {
+		t.Errorf("expected notifications %+v, got %+v", expected, updated.Status.Notifications)
+	}
+
+	key := getRequestKey(pipeline, "finished", 2)
+	if _, ok := notifier.requests[key]; !ok || len(notifier.requests) != 1 {
+		t.Errorf("expected only %s to be queued, got %v", key, notifier.requests)
+	}
+}
+
+func TestNotifyPipelineNotifiedAttempt(t *testing.T) {
+	pipeline := newNotifiedPipeline("pipeline", api.PhaseSucceeded)
+	pipeline.Status.NotifiedAttempt = 1
+	pipeline.Status.Notifications = []api.PipelineNotificationStatus{
+		{Name: "fixed", Trigger: api.NotificationTriggerFixed, Attempt: 1, State: api.NotificationStateDelivered},
+		{Name: "finished", Trigger: api.NotificationTriggerSuccess, Attempt: 1, State: api.NotificationStatePending},
+	}
+
+	notifier, client := newTestNotifier(t, pipeline)
+
+	// resyncs resume the pending notifications without queueing them twice
+	for i := 0; i < 2; i++ {
+		if err := notifier.NotifyPipeline(pipeline); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if count := getPatchCount(client); count != 0 {
+		t.Errorf("expected no patches, got %d", count)
+	}
+
+	key := getRequestKey(pipeline, "finished", 1)
+	if _, ok := notifier.requests[key]; !ok || len(notifier.requests) != 1 {
+		t.Errorf("expected only %s to be queued, got %v", key, notifier.requests)
+	}
+
+	if notifier.queue.Len() != 1 {
+		t.Errorf("expected 1 queued notification, got %d", notifier.queue.Len())
+	}
+}
+
+func TestNotifyPipelineStaleStatus(t *testing.T) {
+	pipeline := newNotifiedPipeline("pipeline", api.PhaseSucceeded)
+	notifier, client := newTestNotifier(t, pipeline)
+
+	if err := notifier.NotifyPipeline(pipeline); err != nil {
+		t.Fatal(err)
+	}
+
+	// the lister hasn't caught up with the notified attempt the second time
+	patched := getPatchCount(client)
+	if err := notifier.NotifyPipeline(pipeline); err != nil {
+		t.Fatal(err)
+	}
+
+	if count := getPatchCount(client); count != patched {
+		t.Errorf("expected no patches the second time, got %d", count-patched)
+	}
+
+	updated := getPipeline(t, client, "pipeline")
+	if len(updated.Status.Notifications) != 1 {
+		t.Errorf("expected 1 notification, got %+v", updated.Status.Notifications)
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		tries         int
+		expectedRetry bool
+		expectedState api.NotificationState
+		expectedTries int
+	}{
+		{
+			name:          "delivered",
+			status:        http.StatusOK,
+			expectedState: api.NotificationStateDelivered,
+			expectedTries: 1,
+		},
+		{
+			name:          "retried",
+			status:        http.StatusInternalServerError,
+			expectedRetry: true,
+			expectedState: api.NotificationStatePending,
+			expectedTries: 1,
+		},
+		{
+			name:          "given up",
+			status:        http.StatusInternalServerError,
+			tries:         maxTries - 1,
+			expectedState: api.NotificationStateFailed,
+			expectedTries: maxTries,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			received := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received++
+				w.WriteHeader(test.status)
+			}))
+			t.Cleanup(server.Close)
+
+			notification := api.Notification{
+				Name:     "finished",
+				Triggers: []api.NotificationTrigger{api.NotificationTriggerSuccess},
+				Webhook:  api.NotificationWebhook{URL: server.URL},
+			}
+
+			pipeline := newTestPipeline("pipeline", "refs/heads/master", api.PhaseSucceeded, time.Minute)
+			pipeline.Spec.Notifications = []api.Notification{notification}
+			pipeline.Status.NotifiedAttempt = 1
+			pipeline.Status.Notifications = []api.PipelineNotificationStatus{
+				{Name: "finished", Trigger: api.NotificationTriggerSuccess, Attempt: 1, State: api.NotificationStatePending, Tries: test.tries},
+			}
+
+			notifier, client := newTestNotifier(t, pipeline)
+			req := request{
+				namespace:    "default",
+				pipeline:     "pipeline",
+				notification: notification,
+				event:        NewEvent(pipeline, api.NotificationTriggerSuccess),
+			}
+
+			retry, err := notifier.deliver(req, notifier.logger)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if retry != test.expectedRetry {
+				t.Errorf("expected retry %t, got %t", test.expectedRetry, retry)
+			}
+
+			status := getPipeline(t, client, "pipeline").Status.Notifications[0]
+			if status.State != test.expectedState || status.Tries != test.expectedTries {
+				t.Errorf("expected state %s after %d tries, got %s after %d tries", test.expectedState, test.expectedTries, status.State, status.Tries)
+			}
+
+			// notifications that are no longer pending aren't sent again
+			if _, err := notifier.deliver(req, notifier.logger); err != nil {
+				t.Fatal(err)
+			}
+
+			expectedReceived := 1
+			if test.expectedRetry {
+				expectedReceived = 2
+			}
+
+			if received != expectedReceived {
+				t.Errorf("expected %d requests, got %d", expectedReceived, received)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	gosync "sync"
+	"time"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/controllers/generic"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+)
+
+func (n *Notifier) Run(ctx context.Context, workers int) error {
+	var wg gosync.WaitGroup
+
+	defer func() {
+		n.setRunning(false)
+		n.queue.ShutDown()
+		wg.Wait()
+	}()
+
+	n.logger.Debug("Waiting for caches to sync")
+	if !cache.WaitForCacheSync(ctx.Done(), n.pipelineSynced, n.secretSynced) {
+		return errors.New("timed out waiting for caches to sync")
+	}
+
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			wait.Until(n.runWorker, time.Second, ctx.Done())
+			wg.Done()
+		}()
+	}
+
+	n.setRunning(true)
+	<-ctx.Done()
+
+	return nil
+}
+
+func (n *Notifier) Healthy() error {
+	if !n.isRunning() {
+		return errors.New("notifier is not running")
+	}
+
+	if !n.pipelineSynced() || !n.secretSynced() {
+		return errors.New("notifier caches have not synced")
+	}
+
+	return nil
+}
+
+// NotifyPipeline queues the notifications of a pipeline that succeeded or
+// failed. The notifications of an attempt are only evaluated once; they're
+// recorded as pending in the status of the pipeline before being sent so that
+// the ones that weren't delivered are resumed whenever the pipeline is
+// notified again.
+func (n *Notifier) NotifyPipeline(pipeline api.Pipeline) error {
+	if len(pipeline.Spec.Notifications) == 0 || !(pipeline.HasSucceeded() || pipeline.HasFailed()) {
+		return nil
+	}
+
+	if pipeline.Status.NotifiedAttempt >= pipeline.GetAttempt() || n.wasNotified(pipeline) {
+		n.resumePendingNotifications(pipeline)
+		return nil
+	}
+
+	triggers, err := n.getTriggers(pipeline)
+	if err != nil {
+		return err
+	}
+
+	updated := *pipeline.DeepCopy()
+	updated.Status.NotifiedAttempt = pipeline.GetAttempt()
+
+	for _, notification := range pipeline.Spec.Notifications {
+		trigger := notification.GetTrigger(triggers)
+		if trigger == "" {
+			continue
+		}
+
+		updated.Status.Notifications = append(updated.Status.Notifications, api.PipelineNotificationStatus{
+			Name:    notification.Name,
+			Trigger: trigger,
+			Attempt: pipeline.GetAttempt(),
+			State:   api.NotificationStatePending,
+		})
+	}
+
+	if _, err := generic.PatchPipeline(n.kubesmithClient, updated, pipeline); err != nil {
+		return err
+	}
+
+	n.setNotified(pipeline)
+	n.resumePendingNotifications(updated)
+
+	return nil
+}
+
+func (s *webhookSender) Send(event Event) error {
+	body, err := render("webhook body", s.webhook.Body, event)
+	if err != nil {
+		return err
+	}
+
+	if body == "" {
+		data, _ := json.Marshal(event)
+		body = string(data)
+	}
+
+	return post(s.client, s.webhook.URL, s.webhook.Headers, []byte(body))
+}
+
+func (s *slackSender) Send(event Event) error {
+	text, err := render("slack text", s.slack.Text, event)
+	if err != nil {
+		return err
+	}
+
+	if text == "" {
+		text = getSummary(event)
+		if event.URL != "" {
+			text = fmt.Sprintf("%s\n%s", text, event.URL)
+		}
+	}
+
+	payload := map[string]string{"text": text}
+	if s.slack.Channel != "" {
+		payload["channel"] = s.slack.Channel
+	}
+
+	body, _ := json.Marshal(payload)
+	return post(s.client, s.url, nil, body)
+}
+
+func (s *emailSender) Send(event Event) error {
+	subject, err := render("email subject", s.email.Subject, event)
+	if err != nil {
+		return err
+	}
+
+	if subject == "" {
+		subject = fmt.Sprintf("[kubesmith] %s", getSummary(event))
+	}
+
+	body, err := render("email body", s.email.Body, event)
+	if err != nil {
+		return err
+	}
+
+	if body == "" {
+		body = getDetails(event)
+	}
+
+	return s.sendMail(subject, body)
+}
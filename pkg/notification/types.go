@@ -0,0 +1,105 @@
+package notification
+
+import (
+	"net/http"
+	gosync "sync"
+	"time"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	kubesmithv1 "github.com/kubesmith/kubesmith/pkg/generated/clientset/versioned/typed/kubesmith/v1"
+	kubesmithListersv1 "github.com/kubesmith/kubesmith/pkg/generated/listers/kubesmith/v1"
+	"github.com/sirupsen/logrus"
+	coreListersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	// maxTries is how many times a notification is tried before it's marked
+	// as failed; the retries back off from retryBaseDelay up to retryMaxDelay
+	maxTries       = 5
+	retryBaseDelay = 5 * time.Second
+	retryMaxDelay  = 5 * time.Minute
+
+	// notifiedTTL is how long the pipelines that were notified are remembered
+	// to skip notifying them again before their status caught up
+	notifiedTTL = time.Hour
+
+	requestTimeout = 30 * time.Second
+)
+
+// Event is what a notification is about; the templates of the notifications
+// are executed with it and webhooks post it as json by default
+type Event struct {
+	Trigger        api.NotificationTrigger `json:"trigger"`
+	Namespace      string                  `json:"namespace"`
+	Pipeline       string                  `json:"pipeline"`
+	Phase          api.Phase               `json:"phase"`
+	Attempt        int                     `json:"attempt"`
+	FailureReason  string                  `json:"failureReason"`
+	Duration       string                  `json:"duration"`
+	Repo           string                  `json:"repo"`
+	Ref            string                  `json:"ref"`
+	Commit         string                  `json:"commit"`
+	Author         string                  `json:"author"`
+	PullRequest    int                     `json:"pullRequest"`
+	PullRequestURL string                  `json:"pullRequestURL"`
+	URL            string                  `json:"url"`
+}
+
+// Sender delivers a notification event
+type Sender interface {
+	Send(event Event) error
+}
+
+// Notifier sends the notifications of finished pipelines in the background
+// and records their delivery in the status of the pipelines; failed
+// deliveries are retried with a backoff. Pending deliveries are picked up
+// again when the pipelines are resynced after a restart.
+type Notifier struct {
+	logger          logrus.FieldLogger
+	queue           workqueue.RateLimitingInterface
+	kubesmithClient kubesmithv1.KubesmithV1Interface
+	pipelineLister  kubesmithListersv1.PipelineLister
+	pipelineSynced  cache.InformerSynced
+	secretLister    coreListersv1.SecretLister
+	secretSynced    cache.InformerSynced
+
+	lock       gosync.Mutex
+	requests   map[string]request
+	notified   map[string]notifiedPipeline
+	lastPruned time.Time
+
+	runningLock gosync.RWMutex
+	running     bool
+}
+
+// request is a notification waiting to be delivered for an attempt of a
+// pipeline
+type request struct {
+	namespace    string
+	pipeline     string
+	notification api.Notification
+	event        Event
+}
+
+type notifiedPipeline struct {
+	attempt int
+	time    time.Time
+}
+
+type webhookSender struct {
+	client  *http.Client
+	webhook api.NotificationWebhook
+}
+
+type slackSender struct {
+	client *http.Client
+	url    string
+	slack  api.NotificationSlack
+}
+
+type emailSender struct {
+	email    api.NotificationEmail
+	password string
+}
@@ -101,3 +101,71 @@ func (s3 *S3Client) GetFilesFromPath(bucketName, path string) ([]string, error)
 
 	return files, nil
 }
+
+func (s3 *S3Client) GetObjectsFromPath(bucketName, path string) ([]Object, error) {
+	objects := []Object{}
+	doneCh := make(chan struct{})
+
+	defer close(doneCh)
+
+	objectCh := s3.client.ListObjectsV2(bucketName, strings.Trim(path, "/"), true, doneCh)
+	for object := range objectCh {
+		if object.Err != nil {
+			return nil, object.Err
+		}
+
+		objects = append(objects, Object{
+			Key:          object.Key,
+			Size:         object.Size,
+			LastModified: object.LastModified,
+		})
+	}
+
+	return objects, nil
+}
+
+func (s3 *S3Client) DeletePath(bucketName, path string) error {
+	// a missing bucket has nothing to delete
+	bucketExists, err := s3.client.BucketExists(bucketName)
+	if err != nil {
+		return err
+	} else if !bucketExists {
+		return nil
+	}
+
+	files, err := s3.GetFilesFromPath(bucketName, path)
+	if err != nil {
+		return err
+	}
+
+	// only delete the files that are actually nested beneath the path
+	prefix := fmt.Sprintf("%s/", strings.Trim(path, "/"))
+	for _, file := range files {
+		if !strings.HasPrefix(file, prefix) {
+			continue
+		}
+
+		if err := s3.client.RemoveObject(bucketName, file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s3 *S3Client) StreamFile(bucketName, remoteFilePath string, writer io.Writer) error {
+	// get the remote object stream
+	object, err := s3.client.GetObject(bucketName, remoteFilePath, minio.GetObjectOptions{})
+	if err != nil {
+		return err
+	}
+
+	defer object.Close()
+
+	// copy the remote object stream to the writer
+	if _, err = io.Copy(writer, object); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -1,7 +1,18 @@
 package s3
 
-import minio "github.com/minio/minio-go"
+import (
+	"time"
+
+	minio "github.com/minio/minio-go"
+)
 
 type S3Client struct {
 	client *minio.Client
 }
+
+// Object describes a file stored in a bucket
+type Object struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
@@ -2,11 +2,13 @@ package sync
 
 import (
 	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/webhook"
 )
 
 type ForgeSyncAction struct {
 	action SyncActionType
 	forge  api.Forge
+	event  webhook.Event
 }
 
 func (a *ForgeSyncAction) GetAction() SyncActionType {
@@ -17,6 +19,11 @@ func (a *ForgeSyncAction) GetObject() interface{} {
 	return a.forge
 }
 
+// GetEvent returns the webhook event of webhook actions
+func (a *ForgeSyncAction) GetEvent() webhook.Event {
+	return a.event
+}
+
 func ForgeAddAction(forge api.Forge) *ForgeSyncAction {
 	return NewForgeSyncAction(forge, SyncActionAdd)
 }
@@ -29,6 +36,15 @@ func ForgeDeleteAction(forge api.Forge) *ForgeSyncAction {
 	return NewForgeSyncAction(forge, SyncActionDelete)
 }
 
+// ForgeWebhookAction creates the pipeline of an event sent to the webhook of
+// the forge
+func ForgeWebhookAction(forge api.Forge, event webhook.Event) *ForgeSyncAction {
+	action := NewForgeSyncAction(forge, SyncActionWebhook)
+	action.event = event
+
+	return action
+}
+
 func NewForgeSyncAction(forge api.Forge, actionType SyncActionType) *ForgeSyncAction {
 	return &ForgeSyncAction{
 		action: actionType,
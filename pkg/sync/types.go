@@ -4,6 +4,9 @@ const (
 	SyncActionAdd    = "add"
 	SyncActionUpdate = "update"
 	SyncActionDelete = "delete"
+
+	// SyncActionWebhook is only used by forges for the events of webhooks
+	SyncActionWebhook = "webhook"
 )
 
 type SyncActionType string
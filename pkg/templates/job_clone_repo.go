@@ -2,8 +2,10 @@ package templates
 
 import (
 	"fmt"
+	"strconv"
 
 	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/logging"
 	"github.com/kubesmith/kubesmith/pkg/utils"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -12,17 +14,28 @@ import (
 
 func GetJobCloneRepo(
 	pipeline api.Pipeline,
+	logConfig logging.Config,
 ) batchv1.Job {
-	return batchv1.Job{
+	labels := map[string]string{}
+	for key, value := range pipeline.GetLabels() {
+		labels[key] = value
+	}
+
+	// the job controller fails the pipeline when the clone of its current
+	// attempt fails
+	labels[api.GetLabelKey("PipelineCloneRepo")] = "true"
+	labels[api.GetLabelKey("PipelineAttempt")] = strconv.Itoa(pipeline.GetAttempt())
+
+	template := batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:   fmt.Sprintf("%s-clone-repo", pipeline.GetResourcePrefix()),
-			Labels: pipeline.GetLabels(),
+			Labels: labels,
 		},
 		Spec: batchv1.JobSpec{
 			BackoffLimit: utils.Int32Ptr(0),
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: pipeline.GetLabels(),
+					Labels: labels,
 				},
 				Spec: corev1.PodSpec{
 					ServiceAccountName: pipeline.GetResourcePrefix(),
@@ -72,4 +85,7 @@ func GetJobCloneRepo(
 			},
 		},
 	}
+
+	setLogConfig(&template.Spec.Template.Spec, logConfig)
+	return template
 }
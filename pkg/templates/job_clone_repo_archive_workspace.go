@@ -2,6 +2,7 @@ package templates
 
 import (
 	"fmt"
+	"path"
 	"strconv"
 
 	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
@@ -14,6 +15,14 @@ func GetJobCloneRepoArchiveWorkspaceContainer(pipeline api.Pipeline) corev1.Cont
 		s3UseSSL = "true"
 	}
 
+	// the pipeline controller loads the stages and jobs of the pipeline from
+	// the pipeline file and the commit that was checked out from the commit
+	// file that are uploaded next to the repo
+	pipelineFile := ""
+	if pipeline.NeedsPipelineFile() {
+		pipelineFile = path.Join("/git/workspace", pipeline.Spec.PipelinePath)
+	}
+
 	return corev1.Container{
 		Name:            "copy-workspace-to-s3",
 		Image:           "kubesmith/kubesmith",
@@ -95,6 +104,22 @@ func GetJobCloneRepoArchiveWorkspaceContainer(pipeline api.Pipeline) corev1.Cont
 				Name:  "SUCCESS_ARTIFACT_PATHS",
 				Value: "/git/workspace/**",
 			},
+			corev1.EnvVar{
+				Name:  "PIPELINE_FILE",
+				Value: pipelineFile,
+			},
+			corev1.EnvVar{
+				Name:  "PIPELINE_FILE_NAME",
+				Value: "pipeline.yaml",
+			},
+			corev1.EnvVar{
+				Name:  "COMMIT_FILE",
+				Value: JobCloneRepoCommitFile,
+			},
+			corev1.EnvVar{
+				Name:  "COMMIT_FILE_NAME",
+				Value: "commit",
+			},
 		},
 		VolumeMounts: []corev1.VolumeMount{
 			corev1.VolumeMount{
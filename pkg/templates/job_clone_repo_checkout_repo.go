@@ -8,17 +8,111 @@ import (
 	corev1 "k8s.io/api/core/v1"
 )
 
+const (
+	JobCloneRepoCheckoutRepoContainerName = "checkout-git-repo"
+
+	// JobCloneRepoCommitFile is where the commit that was checked out is
+	// written to; it's uploaded next to the repo
+	JobCloneRepoCommitFile = "/git/commit"
+)
+
 func GetJobCloneRepoCheckoutRepoContainer(pipeline api.Pipeline) corev1.Container {
+	repo := pipeline.Spec.Workspace.Repo
+	depth := repo.GetCloneDepth()
+	sparseCheckout := pipeline.GetSparseCheckoutPatterns()
+
+	clone := "git clone"
+	fetch := "git fetch -q"
+	if depth > 0 {
+		clone = fmt.Sprintf("%s --depth %d", clone, depth)
+		fetch = fmt.Sprintf("%s --depth %d", fetch, depth)
+	}
+
+	// sparse checkouts start from an empty working tree
+	if len(sparseCheckout) > 0 {
+		clone = fmt.Sprintf("%s --no-checkout", clone)
+	}
+
+	// the url, ref and commit may come from webhooks so they're quoted
+	url := quoteShellArgument(repo.URL)
+	ref := quoteShellArgument(repo.Ref)
+	commit := quoteShellArgument(repo.Commit)
+
+	commands := []string{
+		fmt.Sprintf("%s -- %s /git/workspace", clone, url),
+		"cd /git/workspace",
+	}
+
+	if len(sparseCheckout) > 0 {
+		patterns := []string{}
+		for _, pattern := range sparseCheckout {
+			patterns = append(patterns, quoteShellArgument(pattern))
+		}
+
+		commands = append(commands,
+			"git config core.sparseCheckout true",
+			fmt.Sprintf("printf '%%s\\n' %s > .git/info/sparse-checkout", strings.Join(patterns, " ")),
+		)
+	}
+
+	// pipelines created by a forge build the commit the forge saw; refs like
+	// the ones of pull requests aren't cloned so they're fetched first
+	if repo.Ref != "" {
+		commands = append(commands, fmt.Sprintf("%s origin %s", fetch, ref))
+	}
+
+	if repo.Commit != "" {
+		// shallow clones miss the commit once the ref moved on too far
+		if depth > 0 {
+			commands = append(commands, fmt.Sprintf("{ git cat-file -e %s^{commit} || %s origin %s; }", commit, fetch, commit))
+		}
+
+		commands = append(commands, fmt.Sprintf("git checkout -q %s --", commit))
+	} else if repo.Ref != "" {
+		commands = append(commands, "git checkout -q FETCH_HEAD")
+	} else if len(sparseCheckout) > 0 {
+		commands = append(commands, "git read-tree -mu HEAD")
+	}
+
+	// the commit is recorded in the status of the pipeline; it's the one of
+	// the pull request rather than the result of merging it
+	commands = append(commands, fmt.Sprintf("git rev-parse HEAD > %s", JobCloneRepoCommitFile))
+
+	// pull requests may build the result of merging them into their target
+	// branch; conflicts fail the job with the conflicting files as its message
+	if repo.IsPullRequest() && repo.PullRequest.Merge {
+		target := fmt.Sprintf("origin/%s", repo.PullRequest.TargetBranch)
+		conflict := fmt.Sprintf("pull request #%d cannot be merged into %s; conflicting files:", repo.PullRequest.Number, repo.PullRequest.TargetBranch)
+
+		commands = append(commands, fmt.Sprintf(
+			"{ git -c user.name=kubesmith -c user.email=kubesmith@kubesmith.io merge -q --no-ff --no-edit %s || { echo %s $(git diff --name-only --diff-filter=U) | tee /dev/termination-log; exit 1; }; }",
+			quoteShellArgument(target),
+			quoteShellArgument(conflict),
+		))
+	}
+
+	if repo.Submodules.Enabled {
+		commands = append(commands, fmt.Sprintf("git submodule update -q --init%s", getSubmodulesRecursiveFlag(repo)))
+	}
+
+	// git-lfs is installed unless the image comes with it
+	if repo.LFS {
+		pull := "git lfs install --local && git lfs pull"
+		commands = append(commands, "{ command -v git-lfs > /dev/null || apk add -q --no-cache git-lfs; }", pull)
+
+		if repo.Submodules.Enabled {
+			commands = append(commands, fmt.Sprintf("git submodule foreach -q%s %s", getSubmodulesRecursiveFlag(repo), quoteShellArgument(pull)))
+		}
+	}
+
+	commands = append(commands, "rm -rf /git/workspace/.git", "ls -la /git/workspace")
+
 	return corev1.Container{
-		Name:    "checkout-git-repo",
+		Name:    JobCloneRepoCheckoutRepoContainerName,
 		Image:   "alpine/git",
 		Command: []string{"/bin/sh", "-xc"},
 		Args: []string{
-			strings.Join([]string{
-				fmt.Sprintf("git clone %s /git/workspace", pipeline.Spec.Workspace.Repo.URL),
-				"rm -rf /git/workspace/.git",
-				"ls -la /git/workspace",
-			}, "; "),
+			strings.Join(commands, " && "),
 		},
 		VolumeMounts: []corev1.VolumeMount{
 			corev1.VolumeMount{
@@ -30,5 +124,9 @@ func GetJobCloneRepoCheckoutRepoContainer(pipeline api.Pipeline) corev1.Containe
 				MountPath: "/git",
 			},
 		},
+		// failed clones (and fetches) surface the end of their log in the
+		// pipeline
+		TerminationMessagePath:   corev1.TerminationMessagePathDefault,
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 	}
 }
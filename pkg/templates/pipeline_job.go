@@ -21,8 +21,10 @@ func GetPipelineJob(
 		},
 		Spec: api.PipelineJobSpec{
 			Workspace: api.PipelineJobWorkspace{
-				Path:    stage.Spec.Workspace.Path,
-				Storage: stage.Spec.Workspace.Storage,
+				Path:              stage.Spec.Workspace.Path,
+				Storage:           stage.Spec.Workspace.Storage,
+				UpstreamArtifacts: stage.Spec.Workspace.UpstreamArtifacts,
+				Restricted:        stage.Spec.Workspace.Restricted,
 			},
 			Job: job,
 		},
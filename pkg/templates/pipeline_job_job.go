@@ -1,24 +1,38 @@
 package templates
 
 import (
+	"strconv"
+
 	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/logging"
 	"github.com/kubesmith/kubesmith/pkg/utils"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func GetPipelineJobJob(job api.PipelineJob) batchv1.Job {
+// serviceAccountMountPath is where the kubernetes clients look for the token
+// of the service account when running in a cluster
+const serviceAccountMountPath = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+func GetPipelineJobJob(job api.PipelineJob, logConfig logging.Config) batchv1.Job {
+	labels := map[string]string{}
+	for key, value := range job.GetLabels() {
+		labels[key] = value
+	}
+
+	labels[api.GetLabelKey("PipelineJobAttempt")] = strconv.Itoa(job.GetAttempt())
+
 	template := batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:   job.GetName(),
-			Labels: job.GetLabels(),
+			Name:   job.GetJobName(),
+			Labels: labels,
 		},
 		Spec: batchv1.JobSpec{
 			BackoffLimit: utils.Int32Ptr(0),
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: job.GetLabels(),
+					Labels: labels,
 				},
 				Spec: corev1.PodSpec{
 					ServiceAccountName: job.GetPipelineName(),
@@ -66,5 +80,75 @@ func GetPipelineJobJob(job api.PipelineJob) batchv1.Job {
 			GetPipelineJobJobDownloadArtifactsInitContainer(job))
 	}
 
+	if job.Spec.Workspace.UpstreamArtifacts.Path != "" {
+		template.Spec.Template.Spec.InitContainers = append(template.Spec.Template.Spec.InitContainers,
+			GetPipelineJobJobDownloadUpstreamArtifactsInitContainer(job))
+	}
+
+	// the service account may read the pods and logs of the namespace, so
+	// the jobs of restricted pipelines (like the ones of pull requests from
+	// forks) don't get its token; only the anvil sidecar does
+	if job.Spec.Workspace.Restricted {
+		podSpec := &template.Spec.Template.Spec
+		podSpec.AutomountServiceAccountToken = utils.BoolPtr(false)
+		podSpec.Volumes = append(podSpec.Volumes, getPipelineJobJobServiceAccountVolume())
+
+		for i, container := range podSpec.Containers {
+			if container.Name == PipelineJobAnvilSidecarContainerName {
+				podSpec.Containers[i].VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+					Name:      "service-account",
+					MountPath: serviceAccountMountPath,
+					ReadOnly:  true,
+				})
+			}
+		}
+	}
+
+	setLogConfig(&template.Spec.Template.Spec, logConfig)
 	return template
 }
+
+// getPipelineJobJobServiceAccountVolume projects the token of the service
+// account of the pod along with what is mounted next to it by default
+func getPipelineJobJobServiceAccountVolume() corev1.Volume {
+	return corev1.Volume{
+		Name: "service-account",
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{
+					corev1.VolumeProjection{
+						ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+							Path:              "token",
+							ExpirationSeconds: utils.Int64Ptr(3600),
+						},
+					},
+					corev1.VolumeProjection{
+						ConfigMap: &corev1.ConfigMapProjection{
+							LocalObjectReference: corev1.LocalObjectReference{
+								Name: "kube-root-ca.crt",
+							},
+							Items: []corev1.KeyToPath{
+								corev1.KeyToPath{
+									Key:  "ca.crt",
+									Path: "ca.crt",
+								},
+							},
+						},
+					},
+					corev1.VolumeProjection{
+						DownwardAPI: &corev1.DownwardAPIProjection{
+							Items: []corev1.DownwardAPIVolumeFile{
+								corev1.DownwardAPIVolumeFile{
+									Path: "namespace",
+									FieldRef: &corev1.ObjectFieldSelector{
+										FieldPath: "metadata.namespace",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
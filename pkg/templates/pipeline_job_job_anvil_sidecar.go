@@ -1,7 +1,6 @@
 package templates
 
 import (
-	"fmt"
 	"strconv"
 	"strings"
 
@@ -9,6 +8,8 @@ import (
 	corev1 "k8s.io/api/core/v1"
 )
 
+const PipelineJobAnvilSidecarContainerName = "anvil-sidecar"
+
 func GetPipelineJobJobAnvilSidecarContainer(job api.PipelineJob) corev1.Container {
 	s3UseSSL := "false"
 	if job.Spec.Workspace.Storage.S3.UseSSL == true {
@@ -16,7 +17,7 @@ func GetPipelineJobJobAnvilSidecarContainer(job api.PipelineJob) corev1.Containe
 	}
 
 	return corev1.Container{
-		Name:            "anvil-sidecar",
+		Name:            PipelineJobAnvilSidecarContainerName,
 		Image:           "kubesmith/kubesmith",
 		ImagePullPolicy: "Always",
 		Command:         []string{"kubesmith", "anvil", "sidecar"},
@@ -40,7 +41,7 @@ func GetPipelineJobJobAnvilSidecarContainer(job api.PipelineJob) corev1.Containe
 			},
 			corev1.EnvVar{
 				Name:  "SIDECAR_NAME",
-				Value: "anvil-sidecar",
+				Value: PipelineJobAnvilSidecarContainerName,
 			},
 			corev1.EnvVar{
 				Name:  "S3_HOST",
@@ -82,11 +83,27 @@ func GetPipelineJobJobAnvilSidecarContainer(job api.PipelineJob) corev1.Containe
 			},
 			corev1.EnvVar{
 				Name:  "S3_PATH",
-				Value: fmt.Sprintf("%s/%s", job.GetPipelineName(), job.GetPipelineStageName()),
+				Value: job.GetStoragePath(),
 			},
 			corev1.EnvVar{
 				Name:  "ARCHIVE_FILE_NAME",
-				Value: fmt.Sprintf("%s.tar.gz", job.GetPipelineJobName()),
+				Value: job.GetArchiveFileName(),
+			},
+			corev1.EnvVar{
+				Name:  "LOG_CONTAINER_NAME",
+				Value: PipelineJobPrimaryContainerName,
+			},
+			corev1.EnvVar{
+				Name:  "LOG_FILE_NAME",
+				Value: job.GetLogFileName(),
+			},
+			corev1.EnvVar{
+				Name:  "JUNIT_REPORT_PATHS",
+				Value: strings.Join(job.GetJUnitReportPaths(), ","),
+			},
+			corev1.EnvVar{
+				Name:  "REPORTS_ARCHIVE_FILE_NAME",
+				Value: job.GetReportsArchiveFileName(),
 			},
 			corev1.EnvVar{
 				Name:  "ARCHIVE_FILE_PATH",
@@ -111,5 +128,9 @@ func GetPipelineJobJobAnvilSidecarContainer(job api.PipelineJob) corev1.Containe
 				MountPath: "/kubesmith/artifacts",
 			},
 		},
+		// the sidecar reports the summary of any junit reports through its
+		// termination message
+		TerminationMessagePath:   corev1.TerminationMessagePathDefault,
+		TerminationMessagePolicy: corev1.TerminationMessageReadFile,
 	}
 }
@@ -5,9 +5,11 @@ import (
 	corev1 "k8s.io/api/core/v1"
 )
 
+const PipelineJobPrimaryContainerName = "pipeline-job"
+
 func GetPipelineJobJobPrimaryContainer(job api.PipelineJob) corev1.Container {
 	return corev1.Container{
-		Name:       "pipeline-job",
+		Name:       PipelineJobPrimaryContainerName,
 		Image:      job.Spec.Job.Image,
 		Command:    job.Spec.Job.Command,
 		Args:       job.Spec.Job.Args,
@@ -27,6 +29,6 @@ func GetPipelineJobJobPrimaryContainer(job api.PipelineJob) corev1.Container {
 				MountPath: "/kubesmith/artifacts",
 			},
 		},
-		Env: convertEnvironentToEnvVar(job.Spec.Job.Environment),
+		Env: append(convertEnvironentToEnvVar(job.Spec.Job.Environment), convertSecretsToEnvVar(job.Spec.Job.Secrets)...),
 	}
 }
@@ -17,6 +17,11 @@ func GetPipelineRole(pipeline api.Pipeline) rbacv1.Role {
 				Resources: []string{"pods"},
 				Verbs:     []string{"list"},
 			},
+			rbacv1.PolicyRule{
+				APIGroups: []string{""},
+				Resources: []string{"pods/log"},
+				Verbs:     []string{"get"},
+			},
 		},
 	}
 }
@@ -6,6 +6,12 @@ import (
 )
 
 func GetPipelineStage(name string, pipeline api.Pipeline) api.PipelineStage {
+	// only the first stage starts with the artifacts of the upstream pipeline
+	upstreamArtifacts := api.WorkspaceUpstreamArtifacts{}
+	if pipeline.Status.StageIndex == 1 {
+		upstreamArtifacts = pipeline.Spec.Workspace.UpstreamArtifacts
+	}
+
 	return api.PipelineStage{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: api.SchemeGroupVersion.String(),
@@ -17,8 +23,10 @@ func GetPipelineStage(name string, pipeline api.Pipeline) api.PipelineStage {
 		},
 		Spec: api.PipelineStageSpec{
 			Workspace: api.PipelineStageWorkspace{
-				Path:    pipeline.GetWorkspacePath(),
-				Storage: pipeline.Spec.Workspace.Storage,
+				Path:              pipeline.GetWorkspacePath(),
+				Storage:           pipeline.Spec.Workspace.Storage,
+				UpstreamArtifacts: upstreamArtifacts,
+				Restricted:        pipeline.Spec.Secrets.Restricted,
 			},
 			Jobs: pipeline.GetExpandedJobsForCurrentStage(),
 		},
@@ -1,6 +1,10 @@
 package templates
 
 import (
+	"strings"
+
+	api "github.com/kubesmith/kubesmith/pkg/apis/kubesmith/v1"
+	"github.com/kubesmith/kubesmith/pkg/logging"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -16,3 +20,57 @@ func convertEnvironentToEnvVar(environment map[string]string) []corev1.EnvVar {
 
 	return env
 }
+
+func convertSecretsToEnvVar(secrets []api.PipelineJobSecret) []corev1.EnvVar {
+	env := []corev1.EnvVar{}
+
+	for _, secret := range secrets {
+		env = append(env, corev1.EnvVar{
+			Name: secret.Name,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: secret.Secret,
+					},
+					Key: secret.Key,
+				},
+			},
+		})
+	}
+
+	return env
+}
+
+// quoteShellArgument quotes a value so that the shell passes it on as is
+func quoteShellArgument(value string) string {
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
+
+func getSubmodulesRecursiveFlag(repo api.WorkspaceRepo) string {
+	if repo.Submodules.Recursive {
+		return " --recursive"
+	}
+
+	return ""
+}
+
+// setLogConfig hands the log format and level of the forge on to the kubesmith
+// containers of the pod
+func setLogConfig(podSpec *corev1.PodSpec, config logging.Config) {
+	env := []corev1.EnvVar{}
+	if config.Format != "" {
+		env = append(env, corev1.EnvVar{Name: "LOG_FORMAT", Value: config.Format})
+	}
+
+	if config.Level != "" {
+		env = append(env, corev1.EnvVar{Name: "LOG_LEVEL", Value: config.Level})
+	}
+
+	for _, containers := range [][]corev1.Container{podSpec.InitContainers, podSpec.Containers} {
+		for i, container := range containers {
+			if len(container.Command) > 0 && container.Command[0] == "kubesmith" {
+				containers[i].Env = append(container.Env, env...)
+			}
+		}
+	}
+}
@@ -30,3 +30,11 @@ func GenerateRandomString(s int, letters ...string) string {
 func Int32Ptr(i int32) *int32 {
 	return &i
 }
+
+func Int64Ptr(i int64) *int64 {
+	return &i
+}
+
+func BoolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,30 @@
+package webhook
+
+import (
+	"net/http"
+)
+
+// NewPayload detects the git server that sent a webhook from its headers;
+// gitea also sends the github headers so it's checked first
+func NewPayload(header http.Header, body []byte) (*Payload, error) {
+	provider := Provider("")
+
+	switch {
+	case header.Get("X-Gitea-Event") != "":
+		provider = ProviderGitea
+	case header.Get("X-GitHub-Event") != "":
+		provider = ProviderGitHub
+	case header.Get("X-Gitlab-Event") != "":
+		provider = ProviderGitLab
+	case header.Get("X-Event-Key") != "":
+		provider = ProviderBitbucket
+	default:
+		return nil, ErrUnknownProvider
+	}
+
+	return &Payload{
+		provider: provider,
+		header:   header,
+		body:     body,
+	}, nil
+}
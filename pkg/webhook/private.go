@@ -0,0 +1,211 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// zeroCommit is sent as the new commit of deleted refs
+const zeroCommit = "0000000000000000000000000000000000000000"
+
+func isValidSignature(algorithm func() hash.Hash, secret, body []byte, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(algorithm, secret)
+	mac.Write(body)
+
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+func (p *Payload) unmarshal(value interface{}) error {
+	if err := json.Unmarshal(p.body, value); err != nil {
+		return errors.Wrapf(err, "could not parse %s webhook", p.provider)
+	}
+
+	return nil
+}
+
+// getGitHubEvents parses github and gitea payloads; gitea copied the github
+// format for pushes and pull requests
+func (p *Payload) getGitHubEvents(eventType string) ([]Event, error) {
+	switch eventType {
+	case "push":
+		push := gitHubPush{}
+		if err := p.unmarshal(&push); err != nil {
+			return nil, err
+		}
+
+		author := push.Pusher.Name
+		if push.Pusher.Login != "" {
+			author = push.Pusher.Login
+		} else if push.Pusher.Username != "" {
+			author = push.Pusher.Username
+		}
+
+		return []Event{getPushEvent(push.Ref, push.After, author, push.Deleted)}, nil
+	case "pull_request":
+		pr := gitHubPullRequest{}
+		if err := p.unmarshal(&pr); err != nil {
+			return nil, err
+		}
+
+		switch pr.Action {
+		case "opened", "reopened", "synchronize", "synchronized":
+		default:
+			return []Event{}, nil
+		}
+
+		return []Event{
+			{
+				Type:   EventTypePullRequest,
+				Ref:    fmt.Sprintf("refs/pull/%d/head", pr.PullRequest.Number),
+				Commit: pr.PullRequest.Head.SHA,
+				Author: pr.PullRequest.User.Login,
+				PullRequest: PullRequest{
+					Number:       pr.PullRequest.Number,
+					Title:        pr.PullRequest.Title,
+					URL:          pr.PullRequest.HTMLURL,
+					SourceBranch: pr.PullRequest.Head.Ref,
+					TargetBranch: pr.PullRequest.Base.Ref,
+				},
+			},
+		}, nil
+	}
+
+	return []Event{}, nil
+}
+
+func (p *Payload) getGitLabEvents() ([]Event, error) {
+	switch p.header.Get("X-Gitlab-Event") {
+	case "Push Hook", "Tag Push Hook":
+		push := gitLabPush{}
+		if err := p.unmarshal(&push); err != nil {
+			return nil, err
+		}
+
+		return []Event{getPushEvent(push.Ref, push.After, push.UserUsername, push.After == zeroCommit)}, nil
+	case "Merge Request Hook":
+		mr := gitLabMergeRequest{}
+		if err := p.unmarshal(&mr); err != nil {
+			return nil, err
+		}
+
+		// updates without an old revision changed the description, labels...
+		attributes := mr.ObjectAttributes
+		switch {
+		case attributes.Action == "open", attributes.Action == "reopen":
+		case attributes.Action == "update" && attributes.OldRev != "":
+		default:
+			return []Event{}, nil
+		}
+
+		return []Event{
+			{
+				Type:   EventTypePullRequest,
+				Ref:    fmt.Sprintf("refs/merge-requests/%d/head", attributes.IID),
+				Commit: attributes.LastCommit.ID,
+				Author: mr.User.Username,
+				PullRequest: PullRequest{
+					Number:       attributes.IID,
+					Title:        attributes.Title,
+					URL:          attributes.URL,
+					SourceBranch: attributes.SourceBranch,
+					TargetBranch: attributes.TargetBranch,
+				},
+			},
+		}, nil
+	}
+
+	return []Event{}, nil
+}
+
+func (p *Payload) getBitbucketEvents() ([]Event, error) {
+	switch p.header.Get("X-Event-Key") {
+	case "repo:push":
+		push := bitbucketPush{}
+		if err := p.unmarshal(&push); err != nil {
+			return nil, err
+		}
+
+		events := []Event{}
+		for _, change := range push.Push.Changes {
+			refType, name, commit := change.New.Type, change.New.Name, change.New.Target.Hash
+			if change.Closed {
+				refType, name, commit = change.Old.Type, change.Old.Name, zeroCommit
+			}
+
+			ref := fmt.Sprintf("refs/heads/%s", name)
+			if refType == "tag" {
+				ref = fmt.Sprintf("refs/tags/%s", name)
+			} else if refType != "branch" {
+				continue
+			}
+
+			events = append(events, getPushEvent(ref, commit, push.Actor.getName(), change.Closed))
+		}
+
+		return events, nil
+	case "pullrequest:created", "pullrequest:updated":
+		pr := bitbucketPullRequest{}
+		if err := p.unmarshal(&pr); err != nil {
+			return nil, err
+		}
+
+		// bitbucket has no pull request refs so the source branch is built;
+		// the branches of forks don't exist in the repo so their pull
+		// requests are skipped
+		if pr.PullRequest.Source.Repository.FullName != pr.PullRequest.Destination.Repository.FullName {
+			return []Event{}, nil
+		}
+
+		return []Event{
+			{
+				Type:   EventTypePullRequest,
+				Ref:    fmt.Sprintf("refs/heads/%s", pr.PullRequest.Source.Branch.Name),
+				Commit: pr.PullRequest.Source.Commit.Hash,
+				Author: pr.Actor.getName(),
+				PullRequest: PullRequest{
+					Number:       pr.PullRequest.ID,
+					Title:        pr.PullRequest.Title,
+					URL:          pr.PullRequest.Links.HTML.Href,
+					SourceBranch: pr.PullRequest.Source.Branch.Name,
+					TargetBranch: pr.PullRequest.Destination.Branch.Name,
+				},
+			},
+		}, nil
+	}
+
+	return []Event{}, nil
+}
+
+func getPushEvent(ref, commit, author string, deleted bool) Event {
+	eventType := EventTypePush
+	if strings.HasPrefix(ref, "refs/tags/") {
+		eventType = EventTypeTag
+	}
+
+	return Event{
+		Type:    eventType,
+		Ref:     ref,
+		Commit:  commit,
+		Author:  author,
+		Deleted: deleted || commit == zeroCommit,
+	}
+}
+
+func (a bitbucketActor) getName() string {
+	if a.Nickname != "" {
+		return a.Nickname
+	}
+
+	return a.DisplayName
+}
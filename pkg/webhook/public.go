@@ -0,0 +1,69 @@
+package webhook
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"hash"
+	"strings"
+)
+
+func (p *Payload) GetProvider() Provider {
+	return p.provider
+}
+
+// Verify checks the payload against the secret shared with the git server;
+// gitlab sends the secret itself instead of signing the payload. Nothing is
+// valid for an empty secret, since anyone can sign a payload with it.
+func (p *Payload) Verify(secret []byte) error {
+	if len(secret) == 0 {
+		return ErrInvalidSignature
+	}
+
+	signature := ""
+	algorithm := sha256.New
+
+	switch p.provider {
+	case ProviderGitLab:
+		if subtle.ConstantTimeCompare([]byte(p.header.Get("X-Gitlab-Token")), secret) != 1 {
+			return ErrInvalidSignature
+		}
+
+		return nil
+	case ProviderGitea:
+		signature = p.header.Get("X-Gitea-Signature")
+	case ProviderGitHub:
+		signature = strings.TrimPrefix(p.header.Get("X-Hub-Signature-256"), "sha256=")
+
+		// older github servers only sign with sha1
+		if signature == "" && p.header.Get("X-Hub-Signature") != "" {
+			signature = strings.TrimPrefix(p.header.Get("X-Hub-Signature"), "sha1=")
+			algorithm = func() hash.Hash { return sha1.New() }
+		}
+	case ProviderBitbucket:
+		signature = strings.TrimPrefix(p.header.Get("X-Hub-Signature"), "sha256=")
+	}
+
+	if signature == "" || !isValidSignature(algorithm, secret, p.body, signature) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// GetEvents returns the pushes, tags and pull requests of the payload; other
+// payloads (pings, closed pull requests, ...) have no events
+func (p *Payload) GetEvents() ([]Event, error) {
+	switch p.provider {
+	case ProviderGitHub:
+		return p.getGitHubEvents(p.header.Get("X-GitHub-Event"))
+	case ProviderGitea:
+		return p.getGitHubEvents(p.header.Get("X-Gitea-Event"))
+	case ProviderGitLab:
+		return p.getGitLabEvents()
+	case ProviderBitbucket:
+		return p.getBitbucketEvents()
+	}
+
+	return nil, ErrUnknownProvider
+}
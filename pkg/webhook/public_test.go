@@ -0,0 +1,459 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var testSecret = []byte("s3cr3t")
+
+func readFixture(t *testing.T, name string) []byte {
+	body, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return body
+}
+
+func newHeader(values ...string) http.Header {
+	header := http.Header{}
+	for i := 0; i+1 < len(values); i += 2 {
+		header.Set(values[i], values[i+1])
+	}
+
+	return header
+}
+
+func sign(algorithm func() hash.Hash, secret, body []byte) string {
+	mac := hmac.New(algorithm, secret)
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestNewPayload(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   http.Header
+		provider Provider
+		err      error
+	}{
+		{
+			name:     "github",
+			header:   newHeader("X-GitHub-Event", "push"),
+			provider: ProviderGitHub,
+		},
+		{
+			name:     "gitea sends the github headers too",
+			header:   newHeader("X-GitHub-Event", "push", "X-Gitea-Event", "push"),
+			provider: ProviderGitea,
+		},
+		{
+			name:     "gitlab",
+			header:   newHeader("X-Gitlab-Event", "Push Hook"),
+			provider: ProviderGitLab,
+		},
+		{
+			name:     "bitbucket",
+			header:   newHeader("X-Event-Key", "repo:push"),
+			provider: ProviderBitbucket,
+		},
+		{
+			name:   "unknown",
+			header: newHeader("X-Unknown-Event", "push"),
+			err:    ErrUnknownProvider,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			payload, err := NewPayload(test.header, []byte("{}"))
+			if err != test.err {
+				t.Fatalf("expected error %v, got %v", test.err, err)
+			} else if err != nil {
+				return
+			}
+
+			if payload.GetProvider() != test.provider {
+				t.Errorf("expected provider %s, got %s", test.provider, payload.GetProvider())
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := readFixture(t, "github-push.json")
+
+	tests := []struct {
+		name   string
+		header http.Header
+		err    error
+	}{
+		{
+			name:   "github sha256 signature",
+			header: newHeader("X-GitHub-Event", "push", "X-Hub-Signature-256", "sha256="+sign(sha256.New, testSecret, body)),
+		},
+		{
+			name:   "github sha1 signature of older servers",
+			header: newHeader("X-GitHub-Event", "push", "X-Hub-Signature", "sha1="+sign(sha1.New, testSecret, body)),
+		},
+		{
+			name: "github sha256 signature is preferred over sha1",
+			header: newHeader(
+				"X-GitHub-Event", "push",
+				"X-Hub-Signature-256", "sha256="+sign(sha256.New, []byte("wrong"), body),
+				"X-Hub-Signature", "sha1="+sign(sha1.New, testSecret, body),
+			),
+			err: ErrInvalidSignature,
+		},
+		{
+			name:   "github signature with the wrong secret",
+			header: newHeader("X-GitHub-Event", "push", "X-Hub-Signature-256", "sha256="+sign(sha256.New, []byte("wrong"), body)),
+			err:    ErrInvalidSignature,
+		},
+		{
+			name:   "github signature that isn't hex",
+			header: newHeader("X-GitHub-Event", "push", "X-Hub-Signature-256", "sha256=not-hex"),
+			err:    ErrInvalidSignature,
+		},
+		{
+			name:   "github without signature",
+			header: newHeader("X-GitHub-Event", "push"),
+			err:    ErrInvalidSignature,
+		},
+		{
+			name:   "gitea signature",
+			header: newHeader("X-Gitea-Event", "push", "X-Gitea-Signature", sign(sha256.New, testSecret, body)),
+		},
+		{
+			name:   "gitea ignores the github signature",
+			header: newHeader("X-Gitea-Event", "push", "X-Hub-Signature-256", "sha256="+sign(sha256.New, testSecret, body)),
+			err:    ErrInvalidSignature,
+		},
+		{
+			name:   "gitlab token",
+			header: newHeader("X-Gitlab-Event", "Push Hook", "X-Gitlab-Token", string(testSecret)),
+		},
+		{
+			name:   "gitlab wrong token",
+			header: newHeader("X-Gitlab-Event", "Push Hook", "X-Gitlab-Token", "wrong"),
+			err:    ErrInvalidSignature,
+		},
+		{
+			name:   "gitlab without token",
+			header: newHeader("X-Gitlab-Event", "Push Hook"),
+			err:    ErrInvalidSignature,
+		},
+		{
+			name:   "bitbucket signature",
+			header: newHeader("X-Event-Key", "repo:push", "X-Hub-Signature", "sha256="+sign(sha256.New, testSecret, body)),
+		},
+		{
+			name:   "bitbucket sha1 signature",
+			header: newHeader("X-Event-Key", "repo:push", "X-Hub-Signature", "sha1="+sign(sha1.New, testSecret, body)),
+			err:    ErrInvalidSignature,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			payload, err := NewPayload(test.header, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := payload.Verify(testSecret); err != test.err {
+				t.Errorf("expected error %v, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestVerifyEmptySecret(t *testing.T) {
+	body := readFixture(t, "github-push.json")
+
+	tests := []struct {
+		name   string
+		header http.Header
+	}{
+		{
+			name:   "github signature with the empty secret",
+			header: newHeader("X-GitHub-Event", "push", "X-Hub-Signature-256", "sha256="+sign(sha256.New, []byte{}, body)),
+		},
+		{
+			name:   "gitea signature with the empty secret",
+			header: newHeader("X-Gitea-Event", "push", "X-Gitea-Signature", sign(sha256.New, []byte{}, body)),
+		},
+		{
+			name:   "bitbucket signature with the empty secret",
+			header: newHeader("X-Event-Key", "repo:push", "X-Hub-Signature", "sha256="+sign(sha256.New, []byte{}, body)),
+		},
+		{
+			name:   "gitlab empty token",
+			header: newHeader("X-Gitlab-Event", "Push Hook", "X-Gitlab-Token", ""),
+		},
+		{
+			name:   "gitlab without token",
+			header: newHeader("X-Gitlab-Event", "Push Hook"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			payload, err := NewPayload(test.header, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, secret := range [][]byte{nil, []byte{}} {
+				if err := payload.Verify(secret); err != ErrInvalidSignature {
+					t.Errorf("expected error %v for secret %q, got %v", ErrInvalidSignature, secret, err)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyTamperedBody(t *testing.T) {
+	body := readFixture(t, "github-push.json")
+	header := newHeader("X-GitHub-Event", "push", "X-Hub-Signature-256", "sha256="+sign(sha256.New, testSecret, body))
+
+	tampered := append([]byte{}, body...)
+	tampered[len(tampered)-2] = ' '
+
+	payload, err := NewPayload(header, tampered)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := payload.Verify(testSecret); err != ErrInvalidSignature {
+		t.Errorf("expected error %v, got %v", ErrInvalidSignature, err)
+	}
+}
+
+func TestGetEvents(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   http.Header
+		fixture  string
+		expected []Event
+	}{
+		{
+			name:    "github push",
+			header:  newHeader("X-GitHub-Event", "push"),
+			fixture: "github-push.json",
+			expected: []Event{
+				{Type: EventTypePush, Ref: "refs/heads/main", Commit: "0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c", Author: "octocat"},
+			},
+		},
+		{
+			name:    "github deleted branch",
+			header:  newHeader("X-GitHub-Event", "push"),
+			fixture: "github-push-deleted.json",
+			expected: []Event{
+				{Type: EventTypePush, Ref: "refs/heads/feature", Commit: zeroCommit, Author: "octocat", Deleted: true},
+			},
+		},
+		{
+			name:    "github tag",
+			header:  newHeader("X-GitHub-Event", "push"),
+			fixture: "github-push-tag.json",
+			expected: []Event{
+				{Type: EventTypeTag, Ref: "refs/tags/v1.2.0", Commit: "5b1e0ae0d8a1c7c2e93c1fbb2b9a3e4f1d6c7a8b", Author: "octocat"},
+			},
+		},
+		{
+			name:    "github opened pull request",
+			header:  newHeader("X-GitHub-Event", "pull_request"),
+			fixture: "github-pull-request.json",
+			expected: []Event{
+				{
+					Type:   EventTypePullRequest,
+					Ref:    "refs/pull/42/head",
+					Commit: "a10867b14bb761a232cd80139fbd4c0d33264240",
+					Author: "hubot",
+					PullRequest: PullRequest{
+						Number:       42,
+						Title:        "Add a deploy stage",
+						URL:          "https://github.com/kubesmith/app/pull/42",
+						SourceBranch: "deploy",
+						TargetBranch: "main",
+					},
+				},
+			},
+		},
+		{
+			name:     "github closed pull request",
+			header:   newHeader("X-GitHub-Event", "pull_request"),
+			fixture:  "github-pull-request-closed.json",
+			expected: []Event{},
+		},
+		{
+			name:     "github ping",
+			header:   newHeader("X-GitHub-Event", "ping"),
+			fixture:  "github-ping.json",
+			expected: []Event{},
+		},
+		{
+			name:    "gitea push",
+			header:  newHeader("X-Gitea-Event", "push", "X-GitHub-Event", "push"),
+			fixture: "gitea-push.json",
+			expected: []Event{
+				{Type: EventTypePush, Ref: "refs/heads/develop", Commit: "bffeb74224043ba2feb48d137756c8a9331c449a", Author: "gitea"},
+			},
+		},
+		{
+			name:    "gitea synchronized pull request",
+			header:  newHeader("X-Gitea-Event", "pull_request"),
+			fixture: "gitea-pull-request.json",
+			expected: []Event{
+				{
+					Type:   EventTypePullRequest,
+					Ref:    "refs/pull/7/head",
+					Commit: "e8a6d4f3b7c2a1908f7e6d5c4b3a29180f7e6d5c",
+					Author: "gitea",
+					PullRequest: PullRequest{
+						Number:       7,
+						Title:        "Fix the build",
+						URL:          "https://gitea.example.com/kubesmith/app/pulls/7",
+						SourceBranch: "fix-build",
+						TargetBranch: "main",
+					},
+				},
+			},
+		},
+		{
+			name:    "gitlab push",
+			header:  newHeader("X-Gitlab-Event", "Push Hook"),
+			fixture: "gitlab-push.json",
+			expected: []Event{
+				{Type: EventTypePush, Ref: "refs/heads/main", Commit: "da1560886d4f094c3e6c9ef40349f7d38b5d27d7", Author: "jsmith"},
+			},
+		},
+		{
+			name:    "gitlab deleted branch",
+			header:  newHeader("X-Gitlab-Event", "Push Hook"),
+			fixture: "gitlab-push-deleted.json",
+			expected: []Event{
+				{Type: EventTypePush, Ref: "refs/heads/feature", Commit: zeroCommit, Author: "jsmith", Deleted: true},
+			},
+		},
+		{
+			name:    "gitlab tag",
+			header:  newHeader("X-Gitlab-Event", "Tag Push Hook"),
+			fixture: "gitlab-tag-push.json",
+			expected: []Event{
+				{Type: EventTypeTag, Ref: "refs/tags/v1.0.0", Commit: "82b3d5ae55f7080f1e6022629cdb57bfae7cccc7", Author: "jsmith"},
+			},
+		},
+		{
+			name:    "gitlab merge request with new commits",
+			header:  newHeader("X-Gitlab-Event", "Merge Request Hook"),
+			fixture: "gitlab-merge-request.json",
+			expected: []Event{
+				{
+					Type:   EventTypePullRequest,
+					Ref:    "refs/merge-requests/1/head",
+					Commit: "da1560886d4f094c3e6c9ef40349f7d38b5d27d7",
+					Author: "root",
+					PullRequest: PullRequest{
+						Number:       1,
+						Title:        "MS-Viewport",
+						URL:          "https://gitlab.example.com/kubesmith/app/merge_requests/1",
+						SourceBranch: "ms-viewport",
+						TargetBranch: "main",
+					},
+				},
+			},
+		},
+		{
+			name:     "gitlab merge request without new commits",
+			header:   newHeader("X-Gitlab-Event", "Merge Request Hook"),
+			fixture:  "gitlab-merge-request-edited.json",
+			expected: []Event{},
+		},
+		{
+			name:    "bitbucket push of branches and tags",
+			header:  newHeader("X-Event-Key", "repo:push"),
+			fixture: "bitbucket-push.json",
+			expected: []Event{
+				{Type: EventTypePush, Ref: "refs/heads/main", Commit: "709d658dc5b6d6afcd46049c2f332ee3f515a67d", Author: "emma"},
+				{Type: EventTypeTag, Ref: "refs/tags/v2.0.0", Commit: "709d658dc5b6d6afcd46049c2f332ee3f515a67d", Author: "emma"},
+				{Type: EventTypePush, Ref: "refs/heads/feature", Commit: zeroCommit, Author: "emma", Deleted: true},
+			},
+		},
+		{
+			name:    "bitbucket pull request",
+			header:  newHeader("X-Event-Key", "pullrequest:created"),
+			fixture: "bitbucket-pull-request.json",
+			expected: []Event{
+				{
+					Type:   EventTypePullRequest,
+					Ref:    "refs/heads/faster-tests",
+					Commit: "d3adb33fd3ad",
+					Author: "Emma",
+					PullRequest: PullRequest{
+						Number:       3,
+						Title:        "Speed up the tests",
+						URL:          "https://bitbucket.org/kubesmith/app/pull-requests/3",
+						SourceBranch: "faster-tests",
+						TargetBranch: "main",
+					},
+				},
+			},
+		},
+		{
+			name:     "bitbucket pull request from a fork",
+			header:   newHeader("X-Event-Key", "pullrequest:updated"),
+			fixture:  "bitbucket-pull-request-fork.json",
+			expected: []Event{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			payload, err := NewPayload(test.header, readFixture(t, test.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			events, err := payload.GetEvents()
+			if err != nil {
+				t.Fatalf("could not get events: %v", err)
+			}
+
+			if !reflect.DeepEqual(events, test.expected) {
+				t.Errorf("expected events %+v, got %+v", test.expected, events)
+			}
+		})
+	}
+}
+
+func TestGetEventsInvalidPayload(t *testing.T) {
+	headers := []http.Header{
+		newHeader("X-GitHub-Event", "push"),
+		newHeader("X-Gitea-Event", "pull_request"),
+		newHeader("X-Gitlab-Event", "Push Hook"),
+		newHeader("X-Event-Key", "repo:push"),
+	}
+
+	for _, header := range headers {
+		payload, err := NewPayload(header, []byte("not json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := payload.GetEvents(); err == nil {
+			t.Errorf("expected an error for the invalid %s payload", payload.GetProvider())
+		}
+	}
+}
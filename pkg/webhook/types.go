@@ -0,0 +1,166 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+)
+
+type Provider string
+
+const (
+	ProviderGitHub    Provider = "github"
+	ProviderGitLab    Provider = "gitlab"
+	ProviderGitea     Provider = "gitea"
+	ProviderBitbucket Provider = "bitbucket"
+)
+
+type EventType string
+
+const (
+	EventTypePush        EventType = "push"
+	EventTypeTag         EventType = "tag"
+	EventTypePullRequest EventType = "pullRequest"
+)
+
+var (
+	// ErrUnknownProvider is returned for requests that weren't sent by one
+	// of the supported git servers
+	ErrUnknownProvider = errors.New("webhook was not sent by a supported git server")
+
+	// ErrInvalidSignature is returned when the signature (or token) of a
+	// webhook doesn't match the secret
+	ErrInvalidSignature = errors.New("webhook signature is invalid")
+)
+
+// Payload is a webhook sent by a git server
+type Payload struct {
+	provider Provider
+	header   http.Header
+	body     []byte
+}
+
+// Event is a push, tag or pull request that happened in a repo; a payload can
+// carry several of them (bitbucket pushes list every changed ref)
+type Event struct {
+	Type        EventType
+	Ref         string
+	Commit      string
+	Author      string
+	Deleted     bool
+	PullRequest PullRequest
+}
+
+type PullRequest struct {
+	Number       int
+	Title        string
+	URL          string
+	SourceBranch string
+	TargetBranch string
+}
+
+type gitHubPush struct {
+	Ref     string `json:"ref"`
+	After   string `json:"after"`
+	Deleted bool   `json:"deleted"`
+	Pusher  struct {
+		Name     string `json:"name"`
+		Login    string `json:"login"`
+		Username string `json:"username"`
+	} `json:"pusher"`
+}
+
+type gitHubPullRequest struct {
+	Action      string `json:"action"`
+	PullRequest struct {
+		Number  int    `json:"number"`
+		Title   string `json:"title"`
+		HTMLURL string `json:"html_url"`
+		User    struct {
+			Login string `json:"login"`
+		} `json:"user"`
+		Head struct {
+			Ref string `json:"ref"`
+			SHA string `json:"sha"`
+		} `json:"head"`
+		Base struct {
+			Ref string `json:"ref"`
+		} `json:"base"`
+	} `json:"pull_request"`
+}
+
+type gitLabPush struct {
+	Ref          string `json:"ref"`
+	After        string `json:"after"`
+	CheckoutSHA  string `json:"checkout_sha"`
+	UserUsername string `json:"user_username"`
+}
+
+type gitLabMergeRequest struct {
+	User struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	ObjectAttributes struct {
+		IID          int    `json:"iid"`
+		Title        string `json:"title"`
+		URL          string `json:"url"`
+		Action       string `json:"action"`
+		OldRev       string `json:"oldrev"`
+		SourceBranch string `json:"source_branch"`
+		TargetBranch string `json:"target_branch"`
+		LastCommit   struct {
+			ID string `json:"id"`
+		} `json:"last_commit"`
+	} `json:"object_attributes"`
+}
+
+type bitbucketPush struct {
+	Actor bitbucketActor `json:"actor"`
+	Push  struct {
+		Changes []struct {
+			Closed bool `json:"closed"`
+			New    struct {
+				Type   string `json:"type"`
+				Name   string `json:"name"`
+				Target struct {
+					Hash string `json:"hash"`
+				} `json:"target"`
+			} `json:"new"`
+			Old struct {
+				Type string `json:"type"`
+				Name string `json:"name"`
+			} `json:"old"`
+		} `json:"changes"`
+	} `json:"push"`
+}
+
+type bitbucketPullRequest struct {
+	Actor       bitbucketActor `json:"actor"`
+	PullRequest struct {
+		ID    int    `json:"id"`
+		Title string `json:"title"`
+		Links struct {
+			HTML struct {
+				Href string `json:"href"`
+			} `json:"html"`
+		} `json:"links"`
+		Source      bitbucketPullRequestEndpoint `json:"source"`
+		Destination bitbucketPullRequestEndpoint `json:"destination"`
+	} `json:"pullrequest"`
+}
+
+type bitbucketPullRequestEndpoint struct {
+	Branch struct {
+		Name string `json:"name"`
+	} `json:"branch"`
+	Commit struct {
+		Hash string `json:"hash"`
+	} `json:"commit"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+type bitbucketActor struct {
+	Nickname    string `json:"nickname"`
+	DisplayName string `json:"display_name"`
+}